@@ -0,0 +1,105 @@
+package api_test
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/api"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/sdk"
+	"github.com/convox/logger"
+	"github.com/convox/stdapi"
+	"github.com/convox/stdsdk"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var fxAccessPolicy = structs.AccessPolicy{
+	Id:          "payments",
+	Description: "payments team",
+	Rules: []structs.AccessRule{
+		{Apps: []string{"payments-*"}, Actions: []string{"write"}},
+		{Apps: []string{"*"}, Actions: []string{"read"}},
+	},
+}
+
+func TestAccessPolicyList(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		a1 := structs.AccessPolicies{fxAccessPolicy}
+		a2 := structs.AccessPolicies{}
+		p.On("AccessPolicyList").Return(a1, nil)
+		err := c.Get("/system/access/policies", stdsdk.RequestOptions{}, &a2)
+		require.NoError(t, err)
+		require.Equal(t, a1, a2)
+	})
+}
+
+func TestAccessPolicySet(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		a1 := fxAccessPolicy
+		a2 := structs.AccessPolicy{}
+		opts := structs.AccessPolicyOptions{
+			Description: options.String("payments team"),
+			Rules:       options.String("payments-*=write *=read"),
+		}
+		ro := stdsdk.RequestOptions{
+			Params: stdsdk.Params{
+				"description": "payments team",
+				"rules":       "payments-*=write *=read",
+			},
+		}
+		p.On("AccessPolicySet", "payments", opts).Return(&a1, nil)
+		err := c.Put("/system/access/policies/payments", ro, &a2)
+		require.NoError(t, err)
+		require.Equal(t, a1, a2)
+	})
+}
+
+func TestAccessPolicyEnforcement(t *testing.T) {
+	p := &structs.MockProvider{}
+	p.On("Initialize", mock.Anything).Return(nil)
+	p.On("Start").Return(nil)
+	p.On("WithContext", mock.Anything).Return(p).Maybe()
+	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
+	p.On("AccessPolicyList").Return(structs.AccessPolicies{fxAccessPolicy}, nil)
+	p.On("ServiceRestart", "payments-api", "web").Return(nil)
+
+	s := api.NewWithProvider(p)
+	s.Logger = logger.Discard
+	s.Server.Recover = func(err error, c *stdapi.Context) {
+		require.NoError(t, err, "httptest server panic")
+	}
+
+	ht := httptest.NewServer(s)
+	defer ht.Close()
+
+	client := func(policies ...string) *sdk.Client {
+		tk, err := s.JwtMngr.WriteToken(time.Hour, policies...)
+		require.NoError(t, err)
+
+		u, err := url.Parse(ht.URL)
+		require.NoError(t, err)
+		u.User = url.UserPassword("jwt", tk)
+
+		c, err := sdk.New(u.String())
+		require.NoError(t, err)
+
+		return c
+	}
+
+	c := client("payments")
+
+	require.NoError(t, c.ServiceRestart("payments-api", "web"))
+
+	err := c.ServiceRestart("marketing", "web")
+	require.EqualError(t, err, "access policy does not allow ServiceRestart on app marketing")
+
+	err = client("missing").ServiceRestart("payments-api", "web")
+	require.EqualError(t, err, "access policy does not allow ServiceRestart on app payments-api")
+
+	p.AssertExpectations(t)
+}
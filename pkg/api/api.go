@@ -18,6 +18,8 @@ type Server struct {
 	Password string
 	Provider structs.Provider
 	JwtMngr  *jwt.JwtManager
+
+	tokens *systemTokens
 }
 
 func New() (*Server, error) {
@@ -49,8 +51,11 @@ func NewWithProvider(p structs.Provider) *Server {
 		Provider: p,
 		Server:   stdapi.New("api", "api"),
 		JwtMngr:  jwtMngr,
+		tokens:   newSystemTokens(p),
 	}
 
+	jwtMngr.SetRevocationCheck(s.tokens.revoked)
+
 	s.Server.Router.Router = s.Server.Router.Router.SkipClean(true)
 
 	s.Subrouter("/", func(auth *stdapi.Router) {
@@ -75,6 +80,15 @@ func (s *Server) authenticate(next stdapi.HandlerFunc) stdapi.HandlerFunc {
 			}
 			c.Set(structs.ConvoxRoleParam, data.Role)
 			c.Set(structs.ConvoxJwtUserParam, data.User)
+			if len(data.Policies) > 0 {
+				c.Set(structs.ConvoxPoliciesParam, data.Policies)
+			}
+			if len(data.Apps) > 0 {
+				c.Set(structs.ConvoxAppsParam, data.Apps)
+			}
+			if data.Id != "" {
+				s.tokens.used(data.Id)
+			}
 		} else {
 			if s.Password != "" && subtle.ConstantTimeCompare([]byte(s.Password), []byte(pass)) != 1 {
 				c.Response().Header().Set("WWW-Authenticate", `Basic realm="convox"`)
@@ -26,6 +26,7 @@ func testServer(t *testing.T, fn func(*stdsdk.Client, *structs.MockProvider)) {
 	p.On("Start").Return(nil)
 	p.On("WithContext", mock.Anything).Return(p).Maybe()
 	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
 
 	s := api.NewWithProvider(p)
 	s.Logger = logger.Discard
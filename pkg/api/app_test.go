@@ -4,9 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	cjwt "github.com/convox/convox/pkg/jwt"
 	"github.com/convox/convox/pkg/options"
 	"github.com/convox/convox/pkg/structs"
 	"github.com/convox/stdsdk"
@@ -293,6 +298,31 @@ func TestAppUpdate(t *testing.T) {
 	})
 }
 
+func TestAppUpdateDeployParametersRequireAdmin(t *testing.T) {
+	budgetTestServer(t, func(ht *httptest.Server, p *structs.MockProvider, jm *cjwt.JwtManager) {
+		tk, err := jm.WriteToken(time.Hour)
+		require.NoError(t, err)
+
+		for param, value := range map[string]string{"DeployApprovals": "0", "SecretPaths": "aws-sm/prod"} {
+			body := url.Values{"parameters": {param + "=" + value}}.Encode()
+			req, err := http.NewRequest(http.MethodPut, ht.URL+"/apps/app1", strings.NewReader(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.SetBasicAuth("jwt", tk)
+
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			data, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			require.Equal(t, http.StatusForbidden, res.StatusCode)
+			require.Contains(t, string(data), "admin role required to set "+param)
+		}
+
+		p.AssertNotCalled(t, "AppUpdate")
+	})
+}
+
 func TestAppUpdateError(t *testing.T) {
 	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
 		p.On("AppUpdate", "app1", structs.AppUpdateOptions{}).Return(fmt.Errorf("err1"))
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/convox/convox/pkg/audit"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/stdapi"
+)
+
+const auditErrorLen = 256
+
+// Audit records every mutating request once it has been handled, including
+// requests that Authorize turned away. A failure to record is logged but does
+// not fail the request, which has already taken effect.
+func (s *Server) Audit(next stdapi.HandlerFunc) stdapi.HandlerFunc {
+	return func(c *stdapi.Context) error {
+		if c.Request().Method == http.MethodGet {
+			return next(c)
+		}
+
+		err := next(c)
+
+		e := auditEntry(c, err)
+
+		if rerr := s.provider(c).AuditRecord(*e); rerr != nil {
+			fmt.Printf("ns=api at=audit route=%s error=%q\n", e.Route, rerr)
+		}
+
+		return err
+	}
+}
+
+func auditEntry(c *stdapi.Context, err error) *structs.AuditEntry {
+	e := structs.NewAuditEntry()
+
+	actor, _ := c.Get(structs.ConvoxJwtUserParam).(string)
+
+	e.Actor = audit.SanitizeActor(actor)
+	e.Route = c.Name()
+	e.Method = c.Request().Method
+	e.Path = c.Request().URL.Path
+	e.App = policyApp(c)
+
+	// only params the handler already parsed are recorded so that streamed
+	// request bodies are never consumed here
+	if form := c.Request().Form; form != nil {
+		e.Params = audit.SanitizeParams(form)
+	} else {
+		e.Params = audit.SanitizeParams(c.Request().URL.Query())
+	}
+
+	switch {
+	case err != nil:
+		var ae stdapi.Error
+		if errors.As(err, &ae) {
+			e.Status = ae.Code()
+		} else {
+			e.Status = http.StatusInternalServerError
+		}
+		e.Error = err.Error()
+		if len(e.Error) > auditErrorLen {
+			e.Error = e.Error[:auditErrorLen]
+		}
+	case c.Response().Code() != 0:
+		e.Status = c.Response().Code()
+	default:
+		e.Status = http.StatusOK
+	}
+
+	return e
+}
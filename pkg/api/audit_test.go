@@ -0,0 +1,131 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/api"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/logger"
+	"github.com/convox/stdapi"
+	"github.com/convox/stdsdk"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var fxAuditEntry = structs.AuditEntry{
+	Id:     "EABCDEFGHIJ",
+	Time:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	Actor:  "user@example.org",
+	Route:  "ReleaseCreate",
+	Method: "POST",
+	Path:   "/apps/app1/releases",
+	App:    "app1",
+	Params: map[string]string{"env": "FOO"},
+	Status: 200,
+}
+
+func TestAuditList(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		e1 := structs.AuditEntries{fxAuditEntry}
+		e2 := structs.AuditEntries{}
+		opts := structs.AuditListOptions{
+			Actor: options.String("user@example.org"),
+			App:   options.String("app1"),
+			Since: options.Duration(6 * time.Hour),
+		}
+		ro := stdsdk.RequestOptions{
+			Query: stdsdk.Query{
+				"actor": "user@example.org",
+				"app":   "app1",
+				"since": "6h",
+			},
+		}
+		p.On("AuditList", opts).Return(e1, nil)
+		err := c.Get("/system/audit", ro, &e2)
+		require.NoError(t, err)
+		require.Equal(t, e1, e2)
+	})
+}
+
+func auditTestServer(t *testing.T, fn func(*stdsdk.Client, *structs.MockProvider, chan structs.AuditEntry)) {
+	entries := make(chan structs.AuditEntry, 1)
+
+	p := &structs.MockProvider{}
+	p.On("Initialize", mock.Anything).Return(nil)
+	p.On("Start").Return(nil)
+	p.On("WithContext", mock.Anything).Return(p).Maybe()
+	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Run(func(args mock.Arguments) {
+		entries <- args.Get(0).(structs.AuditEntry)
+	}).Return(nil).Maybe()
+
+	s := api.NewWithProvider(p)
+	s.Logger = logger.Discard
+	s.Server.Recover = func(err error, c *stdapi.Context) {
+		require.NoError(t, err, "httptest server panic")
+	}
+
+	ht := httptest.NewServer(s)
+	defer ht.Close()
+
+	c, err := stdsdk.New(ht.URL)
+	require.NoError(t, err)
+
+	fn(c, p, entries)
+
+	p.AssertExpectations(t)
+}
+
+func TestAuditRecordsMutatingCalls(t *testing.T) {
+	auditTestServer(t, func(c *stdsdk.Client, p *structs.MockProvider, entries chan structs.AuditEntry) {
+		opts := structs.ReleaseCreateOptions{
+			Env: options.String("FOO=secret\nBAR=secret"),
+		}
+		ro := stdsdk.RequestOptions{
+			Headers: stdsdk.Headers{"Convox-Actor": "user@example.org"},
+			Params:  stdsdk.Params{"env": "FOO=secret\nBAR=secret"},
+		}
+		p.On("ReleaseCreate", "app1", opts).Return(&structs.Release{}, nil)
+		err := c.Post("/apps/app1/releases", ro, nil)
+		require.NoError(t, err)
+
+		e := <-entries
+		require.Equal(t, "user@example.org", e.Actor)
+		require.Equal(t, "ReleaseCreate", e.Route)
+		require.Equal(t, "POST", e.Method)
+		require.Equal(t, "/apps/app1/releases", e.Path)
+		require.Equal(t, "app1", e.App)
+		require.Equal(t, map[string]string{"env": "BAR,FOO"}, e.Params)
+		require.Equal(t, http.StatusOK, e.Status)
+		require.NotEmpty(t, e.Id)
+	})
+}
+
+func TestAuditRecordsFailedCalls(t *testing.T) {
+	auditTestServer(t, func(c *stdsdk.Client, p *structs.MockProvider, entries chan structs.AuditEntry) {
+		p.On("AppDelete", "app1").Return(structs.ErrNotFound("no such app: app1"))
+		err := c.Delete("/apps/app1", stdsdk.RequestOptions{}, nil)
+		require.EqualError(t, err, "no such app: app1")
+
+		e := <-entries
+		require.Equal(t, "rack-password", e.Actor)
+		require.Equal(t, "AppDelete", e.Route)
+		require.Equal(t, "app1", e.App)
+		require.Equal(t, http.StatusNotFound, e.Status)
+		require.Equal(t, "no such app: app1", e.Error)
+	})
+}
+
+func TestAuditSkipsReads(t *testing.T) {
+	auditTestServer(t, func(c *stdsdk.Client, p *structs.MockProvider, entries chan structs.AuditEntry) {
+		p.On("AppList").Return(structs.Apps{}, nil)
+		err := c.Get("/apps", stdsdk.RequestOptions{}, nil)
+		require.NoError(t, err)
+		require.Len(t, entries, 0)
+		p.AssertNotCalled(t, "AuditRecord", mock.Anything)
+	})
+}
@@ -20,6 +20,7 @@ func TestAuthentication(t *testing.T) {
 	p.On("Initialize", mock.Anything).Return(nil)
 	p.On("Start").Return(nil)
 	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
 
 	s := api.NewWithProvider(p)
 	s.Logger = logger.Discard
@@ -20,6 +20,21 @@ func (s *Server) Authorize(next stdapi.HandlerFunc) stdapi.HandlerFunc {
 				return stdapi.Errorf(http.StatusUnauthorized, "you are unauthorized to access this")
 			}
 		}
+
+		if apps := Apps(c); len(apps) > 0 && !appsAllow(c, apps) {
+			return stdapi.Errorf(http.StatusForbidden, "token is not scoped to %s", policyTarget(c))
+		}
+
+		if ids := Policies(c); len(ids) > 0 {
+			ok, err := s.policiesAllow(c, ids)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return stdapi.Errorf(http.StatusForbidden, "access policy does not allow %s", policyTarget(c))
+			}
+		}
+
 		return next(c)
 	}
 }
@@ -48,6 +63,18 @@ func CanAdmin(c *stdapi.Context) bool {
 	return false
 }
 
+// Apps returns the apps the request token is scoped to, if any.
+func Apps(c *stdapi.Context) []string {
+	v, _ := c.Get(structs.ConvoxAppsParam).([]string)
+	return v
+}
+
+// Policies returns the access policy ids carried by the request token, if any.
+func Policies(c *stdapi.Context) []string {
+	v, _ := c.Get(structs.ConvoxPoliciesParam).([]string)
+	return v
+}
+
 func SetReadRole(c *stdapi.Context) {
 	c.Set(structs.ConvoxRoleParam, structs.ConvoxRoleRead)
 }
@@ -59,3 +86,79 @@ func SetReadWriteRole(c *stdapi.Context) {
 func SetAdminRole(c *stdapi.Context) {
 	c.Set(structs.ConvoxRoleParam, structs.ConvoxRoleAdmin)
 }
+
+func SetPolicies(c *stdapi.Context, ids []string) {
+	c.Set(structs.ConvoxPoliciesParam, ids)
+}
+
+// appsAllow permits app scoped tokens to act on their apps and to read, but
+// not change, anything at the rack level.
+func appsAllow(c *stdapi.Context, apps []string) bool {
+	app := policyApp(c)
+
+	if app == "" {
+		return c.Request().Method == http.MethodGet
+	}
+
+	for _, a := range apps {
+		if a == app {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policiesAllow fails closed: a token naming a policy that no longer exists
+// is denied rather than treated as unrestricted.
+func (s *Server) policiesAllow(c *stdapi.Context, ids []string) (bool, error) {
+	aps, err := s.provider(c).AccessPolicyList()
+	if err != nil {
+		return false, err
+	}
+
+	byId := map[string]structs.AccessPolicy{}
+	for _, ap := range aps {
+		byId[ap.Id] = ap
+	}
+
+	route := c.Name()
+	app := policyApp(c)
+	write := c.Request().Method != http.MethodGet
+
+	for _, id := range ids {
+		ap, ok := byId[id]
+		if !ok {
+			continue
+		}
+		if ap.Allows(route, app, write) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// policyApp extracts the app a route targets from its path parameters. App
+// routes name the app {name} rather than {app}.
+func policyApp(c *stdapi.Context) string {
+	if app := c.Var("app"); app != "" {
+		return app
+	}
+
+	if strings.HasPrefix(c.Name(), "App") {
+		if c.Name() == "AppCreate" {
+			return c.Value("name")
+		}
+		return c.Var("name")
+	}
+
+	return ""
+}
+
+func policyTarget(c *stdapi.Context) string {
+	if app := policyApp(c); app != "" {
+		return c.Name() + " on app " + app
+	}
+	return c.Name()
+}
@@ -33,6 +33,7 @@ func budgetTestServer(t *testing.T, fn func(*httptest.Server, *structs.MockProvi
 	p.On("Start").Return(nil)
 	p.On("WithContext", mock.Anything).Return(p).Maybe()
 	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
 
 	s := api.NewWithProvider(p)
 	s.Logger = logger.Discard
@@ -166,6 +167,7 @@ func TestAppBudgetClear_BasicAuth_AckByIsRackPassword(t *testing.T) {
 	p.On("Start").Return(nil)
 	p.On("WithContext", mock.Anything).Return(p).Maybe()
 	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
 	p.On("AppBudgetClear", "myapp", "rack-password").Return(nil)
 
 	s := api.NewWithProvider(p)
@@ -294,6 +296,7 @@ func TestAuthenticate_BasicAuth_SetsConvoxJwtUserParam_RackPassword_E2E(t *testi
 	p.On("Start").Return(nil)
 	p.On("WithContext", mock.Anything).Return(p).Maybe()
 	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
 
 	s := api.NewWithProvider(p)
 	s.Logger = logger.Discard
@@ -566,6 +569,7 @@ func TestAppBudgetClear_BasicAuth_WithAckByOverride_HonorsOverride(t *testing.T)
 	p.On("Start").Return(nil)
 	p.On("WithContext", mock.Anything).Return(p).Maybe()
 	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
 	p.On("AppBudgetClear", "myapp", "alice@example.com").Return(nil)
 
 	s := api.NewWithProvider(p)
@@ -23,6 +23,89 @@ import (
 	"github.com/convox/stdsdk"
 )
 
+func (s *Server) AccessPolicyDelete(c *stdapi.Context) error {
+	if !CanAdmin(c) {
+		return stdapi.Errorf(http.StatusForbidden, "admin role required")
+	}
+
+	if err := s.hook("AccessPolicyDeleteValidate", c); err != nil {
+		return err
+	}
+
+	id := c.Var("id")
+
+	err := s.provider(c).WithContext(contextFrom(c)).AccessPolicyDelete(id)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
+func (s *Server) AccessPolicyGet(c *stdapi.Context) error {
+	if !CanAdmin(c) {
+		return stdapi.Errorf(http.StatusForbidden, "admin role required")
+	}
+
+	if err := s.hook("AccessPolicyGetValidate", c); err != nil {
+		return err
+	}
+
+	id := c.Var("id")
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).AccessPolicyGet(id)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) AccessPolicyList(c *stdapi.Context) error {
+	if !CanAdmin(c) {
+		return stdapi.Errorf(http.StatusForbidden, "admin role required")
+	}
+
+	if err := s.hook("AccessPolicyListValidate", c); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).AccessPolicyList()
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) AccessPolicySet(c *stdapi.Context) error {
+	if !CanAdmin(c) {
+		return stdapi.Errorf(http.StatusForbidden, "admin role required")
+	}
+
+	if err := s.hook("AccessPolicySetValidate", c); err != nil {
+		return err
+	}
+
+	id := c.Var("id")
+
+	var opts structs.AccessPolicyOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).AccessPolicySet(id, opts)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(v)
+}
+
 func (s *Server) AppCancel(c *stdapi.Context) error {
 	if err := s.hook("AppCancelValidate", c); err != nil {
 		return err
@@ -242,6 +325,42 @@ func (s *Server) AppMetrics(c *stdapi.Context) error {
 	return c.RenderJSON(v)
 }
 
+func (s *Server) AppDrift(c *stdapi.Context) error {
+	if err := s.hook("AppDriftValidate", c); err != nil {
+		return err
+	}
+
+	name := c.Var("name")
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).AppDrift(name)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) AppPreviewCreate(c *stdapi.Context) error {
+	if err := s.hook("AppPreviewCreateValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	name := c.Value("name")
+
+	var opts structs.AppPreviewCreateOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).AppPreviewCreate(app, name, opts)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(v)
+}
+
 // GPU metrics concurrency and bounds defaults.
 const (
 	gpuMetricsConcurrencyDefault = 10
@@ -478,6 +597,14 @@ func (s *Server) AppUpdate(c *stdapi.Context) error {
 		return err
 	}
 
+	// Admin gate: the deploy policy guards promotes and the secret paths guard
+	// the rack's secret stores, writers must not be able to lift either.
+	for _, k := range []string{structs.AppParamDeployApprovals, structs.AppParamDeployFreeze, structs.AppParamSecretPaths} {
+		if _, ok := opts.Parameters[k]; ok && !CanAdmin(c) {
+			return stdapi.Errorf(http.StatusForbidden, "AppUpdate: admin role required to set %s", k)
+		}
+	}
+
 	err := s.provider(c).WithContext(contextFrom(c)).AppUpdate(name, opts)
 	if err != nil {
 		return err
@@ -637,6 +764,32 @@ func (s *Server) AppCost(c *stdapi.Context) error {
 	return c.RenderJSON(v)
 }
 
+func (s *Server) AuditList(c *stdapi.Context) error {
+	if !CanAdmin(c) {
+		return stdapi.Errorf(http.StatusForbidden, "admin role required")
+	}
+
+	if err := s.hook("AuditListValidate", c); err != nil {
+		return err
+	}
+
+	var opts structs.AuditListOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).AuditList(opts)
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
 func (s *Server) BalancerList(c *stdapi.Context) error {
 	if err := s.hook("BalancerListValidate", c); err != nil {
 		return err
@@ -1655,6 +1808,27 @@ func (s *Server) RegistryRemove(c *stdapi.Context) error {
 	return c.RenderOK()
 }
 
+func (s *Server) ReleaseApprove(c *stdapi.Context) error {
+	if err := s.hook("ReleaseApproveValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	id := c.Var("id")
+
+	var opts structs.ReleaseApproveOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).ReleaseApprove(app, id, opts)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(v)
+}
+
 func (s *Server) ReleaseCreate(c *stdapi.Context) error {
 	if err := s.hook("ReleaseCreateValidate", c); err != nil {
 		return err
@@ -1723,6 +1897,21 @@ func (s *Server) ReleaseList(c *stdapi.Context) error {
 	return c.RenderJSON(v)
 }
 
+func (s *Server) ReleasePause(c *stdapi.Context) error {
+	if err := s.hook("ReleasePauseValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+
+	err := s.provider(c).WithContext(contextFrom(c)).ReleasePause(app)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
 func (s *Server) ReleasePromote(c *stdapi.Context) error {
 	if err := s.hook("ReleasePromoteValidate", c); err != nil {
 		return err
@@ -1736,6 +1925,11 @@ func (s *Server) ReleasePromote(c *stdapi.Context) error {
 		return err
 	}
 
+	// Admin gate: only admins may promote through a deploy freeze.
+	if opts.OverrideFreeze != nil && !CanAdmin(c) {
+		return stdapi.Errorf(http.StatusForbidden, "ReleasePromote: admin role required to override a deploy freeze")
+	}
+
 	err := s.provider(c).WithContext(contextFrom(c)).ReleasePromote(app, id, opts)
 	if err != nil {
 		return err
@@ -1744,6 +1938,74 @@ func (s *Server) ReleasePromote(c *stdapi.Context) error {
 	return c.RenderOK()
 }
 
+func (s *Server) ReleaseResume(c *stdapi.Context) error {
+	if err := s.hook("ReleaseResumeValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+
+	err := s.provider(c).WithContext(contextFrom(c)).ReleaseResume(app)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
+func (s *Server) ReleaseTimeline(c *stdapi.Context) error {
+	if err := s.hook("ReleaseTimelineValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	id := c.Var("id")
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).ReleaseTimeline(app, id)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) ResourceBackupList(c *stdapi.Context) error {
+	if err := s.hook("ResourceBackupListValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	name := c.Var("name")
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).ResourceBackupList(app, name)
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) ResourceBackupRestore(c *stdapi.Context) error {
+	if err := s.hook("ResourceBackupRestoreValidate", c); err != nil {
+		return err
+	}
+
+	app := c.Var("app")
+	name := c.Var("name")
+	id := c.Var("id")
+
+	err := s.provider(c).WithContext(contextFrom(c)).ResourceBackupRestore(app, name, id)
+	if err != nil {
+		return err
+	}
+
+	return c.RenderOK()
+}
+
 func (s *Server) ResourceConsole(c *stdapi.Context) error {
 	if err := s.hook("ResourceConsoleValidate", c); err != nil {
 		return err
@@ -2147,16 +2409,32 @@ func (s *Server) SystemJwtToken(c *stdapi.Context) error {
 		return stdapi.Errorf(http.StatusBadRequest, "duration must be between 1 and %d hours", maxJwtDurationHours)
 	}
 
+	if len(Apps(c)) > 0 {
+		return stdapi.Errorf(http.StatusForbidden, "app scoped credentials cannot issue tokens")
+	}
+
+	requested := []string{}
+	for _, id := range strings.Split(c.Value("policies"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			requested = append(requested, id)
+		}
+	}
+
+	policies, err := s.tokenPolicies(c, requested)
+	if err != nil {
+		return err
+	}
+
 	var tk string
 
 	switch role {
 	case "read":
-		tk, err = s.JwtMngr.ReadToken(time.Hour * time.Duration(durationInHour))
+		tk, err = s.JwtMngr.ReadToken(time.Hour*time.Duration(durationInHour), policies...)
 		if err != nil {
 			return err
 		}
 	case "write":
-		tk, err = s.JwtMngr.WriteToken(time.Hour * time.Duration(durationInHour))
+		tk, err = s.JwtMngr.WriteToken(time.Hour*time.Duration(durationInHour), policies...)
 		if err != nil {
 			return err
 		}
@@ -2164,7 +2442,7 @@ func (s *Server) SystemJwtToken(c *stdapi.Context) error {
 		if !CanAdmin(c) {
 			return stdapi.Errorf(http.StatusForbidden, "admin role required to mint admin tokens")
 		}
-		tk, err = s.JwtMngr.AdminToken(time.Hour * time.Duration(durationInHour))
+		tk, err = s.JwtMngr.AdminToken(time.Hour*time.Duration(durationInHour), policies...)
 		if err != nil {
 			return err
 		}
@@ -2177,6 +2455,37 @@ func (s *Server) SystemJwtToken(c *stdapi.Context) error {
 	})
 }
 
+// tokenPolicies resolves the policies a minted token carries. A caller
+// restricted by policies can only mint tokens restricted to a subset of its
+// own policies, so minting can never widen access.
+func (s *Server) tokenPolicies(c *stdapi.Context, requested []string) ([]string, error) {
+	caller := Policies(c)
+
+	if len(requested) == 0 {
+		return caller, nil
+	}
+
+	if len(caller) > 0 {
+		allowed := map[string]bool{}
+		for _, id := range caller {
+			allowed[id] = true
+		}
+		for _, id := range requested {
+			if !allowed[id] {
+				return nil, stdapi.Errorf(http.StatusForbidden, "cannot mint a token with access policy outside your own: %s", id)
+			}
+		}
+	}
+
+	for _, id := range requested {
+		if _, err := s.provider(c).WithContext(contextFrom(c)).AccessPolicyGet(id); err != nil {
+			return nil, err
+		}
+	}
+
+	return requested, nil
+}
+
 func (s *Server) SystemProcesses(c *stdapi.Context) error {
 	if err := s.hook("SystemProcessesValidate", c); err != nil {
 		return err
@@ -2398,6 +2707,94 @@ func (*Server) SystemUninstall(_ *stdapi.Context) error {
 	return stdapi.Errorf(404, "not available via api")
 }
 
+func (s *Server) SystemTokenCreate(c *stdapi.Context) error {
+	if err := s.hook("SystemTokenCreateValidate", c); err != nil {
+		return err
+	}
+
+	if len(Apps(c)) > 0 {
+		return stdapi.Errorf(http.StatusForbidden, "app scoped credentials cannot issue tokens")
+	}
+
+	var opts structs.SystemTokenCreateOptions
+	if err := stdapi.UnmarshalOptions(c.Request(), &opts); err != nil {
+		return err
+	}
+
+	if opts.Role != nil {
+		role, err := structs.SystemTokenRole(*opts.Role)
+		if err != nil {
+			return err
+		}
+		if role == structs.ConvoxRoleAdmin && !CanAdmin(c) {
+			return stdapi.Errorf(http.StatusForbidden, "admin role required to issue admin tokens")
+		}
+	}
+
+	policies, err := s.tokenPolicies(c, opts.Policies)
+	if err != nil {
+		return err
+	}
+
+	opts.Policies = policies
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).SystemTokenCreate(opts)
+	if err != nil {
+		return err
+	}
+
+	tk, err := s.JwtMngr.SystemToken(v)
+	if err != nil {
+		return err
+	}
+
+	v.Token = tk
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) SystemTokenList(c *stdapi.Context) error {
+	if !CanAdmin(c) {
+		return stdapi.Errorf(http.StatusForbidden, "admin role required")
+	}
+
+	if err := s.hook("SystemTokenListValidate", c); err != nil {
+		return err
+	}
+
+	v, err := s.provider(c).WithContext(contextFrom(c)).SystemTokenList()
+	if err != nil {
+		return err
+	}
+
+	if vs, ok := interface{}(v).(Sortable); ok {
+		sort.Slice(v, vs.Less)
+	}
+
+	return c.RenderJSON(v)
+}
+
+func (s *Server) SystemTokenRevoke(c *stdapi.Context) error {
+	if !CanAdmin(c) {
+		return stdapi.Errorf(http.StatusForbidden, "admin role required")
+	}
+
+	if err := s.hook("SystemTokenRevokeValidate", c); err != nil {
+		return err
+	}
+
+	id := c.Var("id")
+
+	err := s.provider(c).WithContext(contextFrom(c)).SystemTokenRevoke(id)
+	if err != nil {
+		return err
+	}
+
+	s.tokens.forget(id)
+
+	return c.RenderOK()
+}
+
 func (s *Server) SystemUpdate(c *stdapi.Context) error {
 	if err := s.hook("SystemUpdateValidate", c); err != nil {
 		return err
@@ -35,6 +35,7 @@ func testHTTPServer(t *testing.T, fn func(serverURL string, p *structs.MockProvi
 	p.On("Start").Return(nil)
 	p.On("WithContext", mock.Anything).Return(p).Maybe()
 	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
 
 	s := api.NewWithProvider(p)
 	s.Logger = logger.Discard
@@ -2,9 +2,15 @@ package api_test
 
 import (
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	cjwt "github.com/convox/convox/pkg/jwt"
 	"github.com/convox/convox/pkg/options"
 	"github.com/convox/convox/pkg/structs"
 	"github.com/convox/stdsdk"
@@ -20,6 +26,26 @@ var fxRelease = structs.Release{
 	Created:  time.Now().UTC(),
 }
 
+func TestReleaseApprove(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		r1 := fxRelease
+		r1.Approvals = []structs.ReleaseApproval{{Actor: "alice@example.com", Comment: "lgtm"}}
+		r2 := structs.Release{}
+		p.On("ReleaseApprove", "app1", "release1", structs.ReleaseApproveOptions{Comment: options.String("lgtm")}).Return(&r1, nil)
+		err := c.Post("/apps/app1/releases/release1/approvals", stdsdk.RequestOptions{Params: stdsdk.Params{"comment": "lgtm"}}, &r2)
+		require.NoError(t, err)
+		require.Equal(t, r1, r2)
+	})
+}
+
+func TestReleaseApproveError(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		p.On("ReleaseApprove", "app1", "release1", structs.ReleaseApproveOptions{}).Return(nil, fmt.Errorf("err1"))
+		err := c.Post("/apps/app1/releases/release1/approvals", stdsdk.RequestOptions{}, nil)
+		require.EqualError(t, err, "err1")
+	})
+}
+
 func TestReleaseCreate(t *testing.T) {
 	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
 		r1 := fxRelease
@@ -180,3 +206,42 @@ func TestReleasePromoteExistingReleaseNewer(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestReleasePromoteOverrideFreeze(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		r1 := fxRelease
+		p.On("AppGet", "app1").Return(&structs.App{Status: "running"}, nil)
+		p.On("ReleaseGet", "app1", "release1").Return(&r1, nil)
+		opts := structs.ReleasePromoteOptions{OverrideFreeze: options.String("hotfix")}
+		p.On("ReleasePromote", "app1", "release1", opts).Return(nil)
+		err := c.Post("/apps/app1/releases/release1/promote", stdsdk.RequestOptions{Params: stdsdk.Params{"override-freeze": "hotfix"}}, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestReleasePromoteOverrideFreezeRequiresAdmin(t *testing.T) {
+	budgetTestServer(t, func(ht *httptest.Server, p *structs.MockProvider, jm *cjwt.JwtManager) {
+		r1 := fxRelease
+		p.On("AppGet", "app1").Return(&structs.App{Status: "running"}, nil).Maybe()
+		p.On("ReleaseGet", "app1", "release1").Return(&r1, nil).Maybe()
+
+		tk, err := jm.WriteToken(time.Hour)
+		require.NoError(t, err)
+
+		body := url.Values{"override-freeze": {"hotfix"}}.Encode()
+		req, err := http.NewRequest(http.MethodPost, ht.URL+"/apps/app1/releases/release1/promote", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("jwt", tk)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		data, _ := io.ReadAll(res.Body)
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.Contains(t, string(data), "admin role required to override a deploy freeze")
+
+		p.AssertNotCalled(t, "ReleasePromote")
+	})
+}
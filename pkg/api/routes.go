@@ -3,18 +3,25 @@ package api
 import "github.com/convox/stdapi"
 
 func (s *Server) setupRoutes(r stdapi.Router) {
+	r.Use(s.Audit)
 	r.Use(s.Authorize)
 
+	r.Route("DELETE", "/system/access/policies/{id}", s.AccessPolicyDelete)
+	r.Route("GET", "/system/access/policies/{id}", s.AccessPolicyGet)
+	r.Route("GET", "/system/access/policies", s.AccessPolicyList)
+	r.Route("PUT", "/system/access/policies/{id}", s.AccessPolicySet)
 	r.Route("POST", "/apps/{name}/cancel", s.AppCancel)
 	r.Route("POST", "/apps", s.AppCreate)
 	r.Route("DELETE", "/apps/{name}", s.AppDelete)
 	r.Route("GET", "/apps/{app}/diagnose", s.AppDiagnose)
+	r.Route("GET", "/apps/{name}/drift", s.AppDrift)
 	r.Route("GET", "/apps/{name}", s.AppGet)
 	r.Route("GET", "/apps", s.AppList)
 	r.Route("SOCKET", "/apps/{name}/logs", s.AppLogs)
 	r.Route("GET", "/apps/{app}/manifest/services/{service}", s.AppManifestService)
 	r.Route("GET", "/apps/{name}/metrics", s.AppMetrics)
 	r.Route("GET", "/apps/{app}/metrics-by-service", s.MetricsByService)
+	r.Route("POST", "/apps/{app}/previews", s.AppPreviewCreate)
 	r.Route("PUT", "/apps/{name}", s.AppUpdate)
 	r.Route("GET", "/apps/{app}/budget", s.AppBudgetGet)
 	r.Route("POST", "/apps/{app}/budget", s.AppBudgetSet)
@@ -24,6 +31,7 @@ func (s *Server) setupRoutes(r stdapi.Router) {
 	r.Route("POST", "/apps/{app}/budget/simulate-shutdown", s.AppBudgetSimulate)
 	r.Route("POST", "/apps/{app}/budget/dismiss-recovery", s.AppBudgetDismissRecovery)
 	r.Route("GET", "/apps/{app}/cost", s.AppCost)
+	r.Route("GET", "/system/audit", s.AuditList)
 	r.Route("GET", "/apps/{app}/balancers", s.BalancerList)
 	r.Route("POST", "/apps/{app}/builds", s.BuildCreate)
 	r.Route("GET", "/apps/{app}/builds/{id}.tgz", s.BuildExport)
@@ -72,9 +80,15 @@ func (s *Server) setupRoutes(r stdapi.Router) {
 	r.Route("ANY", "/v2/{path:.*}", s.RegistryProxy)
 	r.Route("DELETE", "/registries/{server:.*}", s.RegistryRemove)
 	r.Route("POST", "/apps/{app}/releases", s.ReleaseCreate)
+	r.Route("POST", "/apps/{app}/releases/{id}/approvals", s.ReleaseApprove)
 	r.Route("GET", "/apps/{app}/releases/{id}", s.ReleaseGet)
 	r.Route("GET", "/apps/{app}/releases", s.ReleaseList)
+	r.Route("POST", "/apps/{app}/releases/pause", s.ReleasePause)
 	r.Route("POST", "/apps/{app}/releases/{id}/promote", s.ReleasePromote)
+	r.Route("POST", "/apps/{app}/releases/resume", s.ReleaseResume)
+	r.Route("GET", "/apps/{app}/releases/{id}/timeline", s.ReleaseTimeline)
+	r.Route("GET", "/apps/{app}/resources/{name}/backups", s.ResourceBackupList)
+	r.Route("POST", "/apps/{app}/resources/{name}/backups/{id}/restore", s.ResourceBackupRestore)
 	r.Route("SOCKET", "/apps/{app}/resources/{name}/console", s.ResourceConsole)
 	r.Route("GET", "/apps/{app}/resources/{name}/data", s.ResourceExport)
 	r.Route("GET", "/apps/{app}/resources/{name}", s.ResourceGet)
@@ -97,6 +111,9 @@ func (s *Server) setupRoutes(r stdapi.Router) {
 	r.Route("POST", "/system/jwt/token", s.SystemJwtToken)
 	r.Route("GET", "/system/processes", s.SystemProcesses)
 	r.Route("GET", "/system/releases", s.SystemReleases)
+	r.Route("POST", "/system/tokens", s.SystemTokenCreate)
+	r.Route("GET", "/system/tokens", s.SystemTokenList)
+	r.Route("DELETE", "/system/tokens/{id}", s.SystemTokenRevoke)
 	r.Route("POST", "/resources", s.SystemResourceCreate)
 	r.Route("DELETE", "/resources/{name}", s.SystemResourceDelete)
 	r.Route("GET", "/resources/{name}", s.SystemResourceGet)
@@ -46,6 +46,7 @@ func TestServiceMetricsConcurrencyCap503(t *testing.T) {
 	p.On("Start").Return(nil)
 	p.On("WithContext", mock.Anything).Return(p).Maybe()
 	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
 	p.On("ServiceMetrics", "app1", "web", mock.Anything).Return(
 		structs.Metrics{}, nil,
 	).Run(func(args mock.Arguments) {
@@ -271,6 +271,7 @@ func jwtAuthTestServer(t *testing.T, fn func(*httptest.Server, *structs.MockProv
 	p.On("Start").Return(nil)
 	p.On("WithContext", mock.Anything).Return(p).Maybe()
 	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
 
 	s := api.NewWithProvider(p)
 	s.Logger = logger.Discard
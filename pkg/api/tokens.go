@@ -0,0 +1,108 @@
+package api
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/convox/convox/pkg/structs"
+)
+
+const (
+	systemTokensRefresh      = 10 * time.Second
+	systemTokensUsedInterval = time.Minute
+)
+
+// systemTokens caches the rack issued tokens so revocation can be checked on
+// every request without a round trip to the provider, and throttles the
+// last-used bookkeeping to one write per token per interval.
+type systemTokens struct {
+	provider structs.Provider
+
+	lock     sync.Mutex
+	loaded   time.Time
+	tokens   map[string]structs.SystemToken
+	lastUsed map[string]time.Time
+}
+
+func newSystemTokens(p structs.Provider) *systemTokens {
+	return &systemTokens{
+		provider: p,
+		tokens:   map[string]structs.SystemToken{},
+		lastUsed: map[string]time.Time{},
+	}
+}
+
+// revoked fails closed: a token the rack has no record of, or whose record
+// cannot be loaded, is treated as revoked.
+func (st *systemTokens) revoked(id string) bool {
+	if st == nil {
+		return true
+	}
+
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	t, ok := st.tokens[id]
+
+	if !ok || time.Since(st.loaded) > systemTokensRefresh {
+		if err := st.refresh(); err != nil {
+			fmt.Printf("ns=api at=tokens.refresh error=%q\n", err)
+		}
+		t, ok = st.tokens[id]
+	}
+
+	return !ok || !t.Revoked.IsZero()
+}
+
+func (st *systemTokens) used(id string) {
+	if st == nil {
+		return
+	}
+
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	if time.Since(st.lastUsed[id]) < systemTokensUsedInterval {
+		return
+	}
+
+	st.lastUsed[id] = time.Now()
+
+	go func() {
+		if err := st.provider.SystemTokenUsed(id); err != nil {
+			fmt.Printf("ns=api at=tokens.used id=%s error=%q\n", id, err)
+		}
+	}()
+}
+
+// forget drops a token from the cache so a revoke takes effect immediately
+// on this api instance.
+func (st *systemTokens) forget(id string) {
+	if st == nil {
+		return
+	}
+
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	delete(st.tokens, id)
+}
+
+func (st *systemTokens) refresh() error {
+	ts, err := st.provider.SystemTokenList()
+	if err != nil {
+		return err
+	}
+
+	tokens := map[string]structs.SystemToken{}
+
+	for _, t := range ts {
+		tokens[t.Id] = t
+	}
+
+	st.tokens = tokens
+	st.loaded = time.Now()
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package api_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/api"
+	cjwt "github.com/convox/convox/pkg/jwt"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/sdk"
+	"github.com/convox/logger"
+	"github.com/convox/stdapi"
+	"github.com/convox/stdsdk"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var fxSystemToken = structs.SystemToken{
+	Id:      "TABCDEFGHIJ",
+	Name:    "ci",
+	Role:    structs.ConvoxRoleReadWrite,
+	Apps:    []string{"app1"},
+	Created: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	Expires: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+}
+
+func TestSystemTokenCreate(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		t1 := fxSystemToken
+		t2 := structs.SystemToken{}
+		opts := structs.SystemTokenCreateOptions{
+			Apps:    []string{"app1"},
+			Name:    options.String("ci"),
+			Expires: options.String("30d"),
+		}
+		ro := stdsdk.RequestOptions{
+			Params: stdsdk.Params{
+				"apps":    "app1",
+				"expires": "30d",
+				"name":    "ci",
+			},
+		}
+		p.On("SystemTokenCreate", opts).Return(&t1, nil)
+		err := c.Post("/system/tokens", ro, &t2)
+		require.NoError(t, err)
+		require.Equal(t, "ci", t2.Name)
+		require.NotEmpty(t, t2.Token)
+	})
+}
+
+func TestSystemTokenList(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		t1 := structs.SystemTokens{fxSystemToken}
+		t2 := structs.SystemTokens{}
+		p.On("SystemTokenList").Return(t1, nil)
+		err := c.Get("/system/tokens", stdsdk.RequestOptions{}, &t2)
+		require.NoError(t, err)
+		require.Equal(t, t1, t2)
+	})
+}
+
+func TestSystemTokenRevoke(t *testing.T) {
+	testServer(t, func(c *stdsdk.Client, p *structs.MockProvider) {
+		p.On("SystemTokenRevoke", "TABCDEFGHIJ").Return(nil)
+		err := c.Delete("/system/tokens/TABCDEFGHIJ", stdsdk.RequestOptions{}, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestSystemTokenEnforcement(t *testing.T) {
+	active := fxSystemToken
+	active.Expires = time.Now().Add(time.Hour)
+
+	revoked := active
+	revoked.Id = "TREVOKED000"
+	revoked.Revoked = time.Now()
+
+	p := &structs.MockProvider{}
+	p.On("Initialize", mock.Anything).Return(nil)
+	p.On("Start").Return(nil)
+	p.On("WithContext", mock.Anything).Return(p).Maybe()
+	p.On("SystemJwtSignKey").Return("test", nil)
+	p.On("AuditRecord", mock.Anything).Return(nil).Maybe()
+	p.On("SystemTokenList").Return(structs.SystemTokens{active, revoked}, nil)
+	p.On("SystemTokenUsed", mock.Anything).Return(nil).Maybe()
+	p.On("ServiceRestart", "app1", "web").Return(nil)
+
+	s := api.NewWithProvider(p)
+	s.Logger = logger.Discard
+	s.Server.Recover = func(err error, c *stdapi.Context) {
+		require.NoError(t, err, "httptest server panic")
+	}
+
+	ht := httptest.NewServer(s)
+	defer ht.Close()
+
+	client := func(st structs.SystemToken) *sdk.Client {
+		tk, err := s.JwtMngr.SystemToken(&st)
+		require.NoError(t, err)
+
+		u, err := url.Parse(ht.URL)
+		require.NoError(t, err)
+		u.User = url.UserPassword("jwt", tk)
+
+		c, err := sdk.New(u.String())
+		require.NoError(t, err)
+
+		return c
+	}
+
+	c := client(active)
+
+	require.NoError(t, c.ServiceRestart("app1", "web"))
+
+	err := c.ServiceRestart("app2", "web")
+	require.EqualError(t, err, "token is not scoped to ServiceRestart on app app2")
+
+	err = client(revoked).ServiceRestart("app1", "web")
+	require.Error(t, err)
+
+	unknown := active
+	unknown.Id = "TUNKNOWN000"
+
+	err = client(unknown).ServiceRestart("app1", "web")
+	require.Error(t, err)
+
+	p.AssertExpectations(t)
+}
+
+func TestSystemTokenListRequiresAdmin(t *testing.T) {
+	jwtAuthTestServer(t, func(ht *httptest.Server, p *structs.MockProvider, jm *cjwt.JwtManager) {
+		tk, err := jm.WriteToken(time.Hour)
+		require.NoError(t, err)
+
+		req, err := http.NewRequest(http.MethodGet, ht.URL+"/system/tokens", nil)
+		require.NoError(t, err)
+		req.SetBasicAuth("jwt", tk)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		data, _ := io.ReadAll(res.Body)
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.Contains(t, string(data), "admin role required")
+	})
+}
@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	maxParamLen = 256
+	redacted    = "[redacted]"
+)
+
+var sensitiveParam = regexp.MustCompile(`(?i)(auth|cert|credential|key|pass|private|secret|token)`)
+
+// SanitizeParams flattens request params for an audit record. Values of
+// sensitive looking params are redacted, including inside encoded parameter
+// maps, and environment params are reduced to the variable names so secrets
+// never reach the audit log.
+func SanitizeParams(params url.Values) map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+
+	out := map[string]string{}
+
+	for k, vs := range params {
+		v := strings.Join(vs, ",")
+
+		switch {
+		case k == "env" || k == "environment":
+			v = envKeys(v)
+		case k == "parameters":
+			v = encodedParams(v)
+		case sensitiveParam.MatchString(k):
+			v = redacted
+		default:
+			v = stripUserinfo(v)
+		}
+
+		if len(v) > maxParamLen {
+			v = v[:maxParamLen] + "..."
+		}
+
+		out[SanitizeActor(k)] = v
+	}
+
+	return out
+}
+
+func envKeys(env string) string {
+	keys := []string{}
+
+	for _, line := range strings.Split(env, "\n") {
+		k, _, _ := strings.Cut(strings.TrimSpace(line), "=")
+		if k == "" || strings.HasPrefix(k, "#") {
+			continue
+		}
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return strings.Join(keys, ",")
+}
+
+func encodedParams(v string) string {
+	uv, err := url.ParseQuery(v)
+	if err != nil {
+		return redacted
+	}
+
+	for k := range uv {
+		if sensitiveParam.MatchString(k) {
+			uv.Set(k, redacted)
+		}
+	}
+
+	return uv.Encode()
+}
+
+func stripUserinfo(v string) string {
+	if !strings.Contains(v, "://") {
+		return v
+	}
+
+	u, err := url.Parse(v)
+	if err != nil || u.User == nil {
+		return v
+	}
+
+	u.User = url.User("redacted")
+
+	return u.String()
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/sdk"
+	"github.com/convox/stdcli"
+)
+
+func init() {
+	register("rack access policies", "list access policies", RackAccessPolicies, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack},
+		Validate: stdcli.Args(0),
+	})
+
+	register("rack access policy delete", "delete an access policy", RackAccessPolicyDelete, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack},
+		Usage:    "<id>",
+		Validate: stdcli.Args(1),
+	})
+
+	register("rack access policy info", "get information about an access policy", RackAccessPolicyInfo, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack},
+		Usage:    "<id>",
+		Validate: stdcli.Args(1),
+	})
+
+	register("rack access policy set", "create or update an access policy", RackAccessPolicySet, stdcli.CommandOptions{
+		Flags: []stdcli.Flag{
+			flagRack,
+			stdcli.StringFlag("description", "d", "policy description"),
+		},
+		Usage:    "<id> <apps=actions> [apps=actions]...",
+		Validate: stdcli.ArgsMin(1),
+	})
+}
+
+func RackAccessPolicies(rack sdk.Interface, c *stdcli.Context) error {
+	aps, err := rack.AccessPolicyList()
+	if err != nil {
+		return err
+	}
+
+	t := c.Table("ID", "RULES", "DESCRIPTION")
+
+	for _, ap := range aps {
+		t.AddRow(ap.Id, accessRulesString(ap.Rules), ap.Description)
+	}
+
+	return t.Print()
+}
+
+func RackAccessPolicyDelete(rack sdk.Interface, c *stdcli.Context) error {
+	c.Startf("Deleting access policy <id>%s</id>", c.Arg(0))
+
+	if err := rack.AccessPolicyDelete(c.Arg(0)); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
+func RackAccessPolicyInfo(rack sdk.Interface, c *stdcli.Context) error {
+	ap, err := rack.AccessPolicyGet(c.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	i := c.Info()
+
+	i.Add("Id", ap.Id)
+	i.Add("Description", ap.Description)
+	i.Add("Rules", accessRulesString(ap.Rules))
+
+	return i.Print()
+}
+
+func RackAccessPolicySet(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.AccessPolicyOptions
+
+	if len(c.Args) > 1 {
+		opts.Rules = options.String(strings.Join(c.Args[1:], " "))
+	}
+
+	if d := c.String("description"); d != "" {
+		opts.Description = options.String(d)
+	}
+
+	c.Startf("Setting access policy <id>%s</id>", c.Arg(0))
+
+	if _, err := rack.AccessPolicySet(c.Arg(0), opts); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
+func accessRulesString(rs []structs.AccessRule) string {
+	ss := make([]string, len(rs))
+
+	for i, r := range rs {
+		ss[i] = r.String()
+	}
+
+	return strings.Join(ss, " ")
+}
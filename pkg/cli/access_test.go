@@ -0,0 +1,51 @@
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/convox/convox/pkg/cli"
+	mocksdk "github.com/convox/convox/pkg/mock/sdk"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRackAccessPolicies(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AccessPolicyList").Return(structs.AccessPolicies{
+			{
+				Id:          "payments",
+				Description: "payments team",
+				Rules: []structs.AccessRule{
+					{Apps: []string{"payments-*"}, Actions: []string{"write"}},
+					{Apps: []string{"*"}, Actions: []string{"read"}},
+				},
+			},
+		}, nil)
+
+		res, err := testExecute(e, "rack access policies", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"ID        RULES                    DESCRIPTION",
+			"payments  payments-*=write *=read  payments team",
+		})
+	})
+}
+
+func TestRackAccessPolicySet(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.AccessPolicyOptions{
+			Description: options.String("on call"),
+			Rules:       options.String("*=read,ProcessStop,ServiceRestart"),
+		}
+		i.On("AccessPolicySet", "on-call", opts).Return(&structs.AccessPolicy{Id: "on-call"}, nil)
+
+		res, err := testExecute(e, "rack access policy set on-call *=read,ProcessStop,ServiceRestart -d 'on call'", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Setting access policy on-call... OK"})
+	})
+}
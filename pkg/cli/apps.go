@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/convox/convox/pkg/common"
 	"github.com/convox/convox/pkg/options"
@@ -19,7 +20,7 @@ import (
 
 func init() {
 	register("apps", "list apps", watch(Apps), stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack, flagWatchInterval},
+		Flags:    []stdcli.Flag{flagRack, flagWatchInterval, flagFormat},
 		Validate: stdcli.Args(0),
 	}, WithCloud())
 
@@ -41,6 +42,12 @@ func init() {
 		Validate: stdcli.Args(1),
 	}, WithCloud())
 
+	register("apps drift", "compare the running objects of an app with its active release", AppsDrift, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagApp, flagRack, flagFormat},
+		Usage:    "[app]",
+		Validate: stdcli.ArgsMax(1),
+	})
+
 	register("apps export", "export an app", AppsExport, stdcli.CommandOptions{
 		Flags: []stdcli.Flag{
 			flagApp,
@@ -62,7 +69,7 @@ func init() {
 	}, WithCloud())
 
 	register("apps info", "get information about an app", AppsInfo, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagApp, flagRack},
+		Flags:    []stdcli.Flag{flagApp, flagRack, flagFormat},
 		Usage:    "[app]",
 		Validate: stdcli.ArgsMax(1),
 	}, WithCloud())
@@ -85,6 +92,30 @@ func init() {
 		Validate: stdcli.ArgsMin(1),
 	}, WithCloud())
 
+	register("apps preview", "list preview apps", AppsPreview, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack, flagFormat},
+		Usage:    "[base-app]",
+		Validate: stdcli.ArgsMax(1),
+	})
+
+	register("apps preview create", "create a preview app from the active release of an app", AppsPreviewCreate, stdcli.CommandOptions{
+		Flags: []stdcli.Flag{
+			flagRack,
+			stdcli.StringFlag("build", "", "build of the base app to run (default: build of the active release)"),
+			stdcli.StringFlag("link", "", "resources of the base app to share instead of copying (comma separated)"),
+			stdcli.StringFlag("name", "n", "name of the preview app"),
+			stdcli.StringFlag("ttl", "", "delete the preview after this long, e.g. 12h or 3d (default 72h)"),
+		},
+		Usage:    "<base-app> [Key=Value]...",
+		Validate: stdcli.ArgsMin(1),
+	})
+
+	register("apps preview delete", "delete a preview app", AppsPreviewDelete, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack},
+		Usage:    "<app>",
+		Validate: stdcli.Args(1),
+	})
+
 	register("apps unlock", "disable termination protection", AppsUnlock, stdcli.CommandOptions{
 		Flags:    []stdcli.Flag{flagApp, flagRack},
 		Usage:    "[app]",
@@ -98,6 +129,10 @@ func Apps(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, as); ok {
+		return err
+	}
+
 	t := c.Table("APP", "STATUS", "RELEASE")
 
 	for _, a := range as {
@@ -170,6 +205,36 @@ func AppsDelete(rack sdk.Interface, c *stdcli.Context) error {
 	return c.OK()
 }
 
+func AppsDrift(rack sdk.Interface, c *stdcli.Context) error {
+	ds, err := rack.AppDrift(coalesce(c.Arg(0), app(c)))
+	if err != nil {
+		return err
+	}
+
+	if ok, err := formatted(c, ds); ok {
+		return err
+	}
+
+	if len(ds) == 0 {
+		return c.Writef("no drift\n")
+	}
+
+	t := c.Table("KIND", "NAME", "FIELD", "EXPECTED", "ACTUAL")
+
+	for _, d := range ds {
+		if d.Missing {
+			t.AddRow(d.Kind, d.Name, "", "present", "missing")
+			continue
+		}
+
+		for _, ch := range d.Changes {
+			t.AddRow(d.Kind, d.Name, ch.Path, ch.Expected, ch.Actual)
+		}
+	}
+
+	return t.Print()
+}
+
 func AppsExport(rack sdk.Interface, c *stdcli.Context) error {
 	app := coalesce(c.Arg(0), app(c))
 
@@ -230,6 +295,10 @@ func AppsInfo(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, a); ok {
+		return err
+	}
+
 	i := c.Info()
 
 	i.Add("Name", a.Name)
@@ -239,6 +308,11 @@ func AppsInfo(rack sdk.Interface, c *stdcli.Context) error {
 	i.Add("Locked", fmt.Sprintf("%t", a.Locked))
 	i.Add("Release", a.Release)
 
+	if a.Preview != nil {
+		i.Add("Preview Of", a.Preview.Base)
+		i.Add("Expires", a.Preview.Expires.Format(time.RFC3339))
+	}
+
 	if a.Router != "" {
 		i.Add("Router", a.Router)
 	}
@@ -350,6 +424,124 @@ func AppsParamsSet(rack sdk.Interface, c *stdcli.Context) error {
 	return c.OK()
 }
 
+func AppsPreview(rack sdk.Interface, c *stdcli.Context) error {
+	as, err := rack.AppList()
+	if err != nil {
+		return err
+	}
+
+	ps := structs.Apps{}
+
+	for _, a := range as {
+		if a.Preview != nil && (c.Arg(0) == "" || a.Preview.Base == c.Arg(0)) {
+			ps = append(ps, a)
+		}
+	}
+
+	if ok, err := formatted(c, ps); ok {
+		return err
+	}
+
+	t := c.Table("APP", "BASE", "STATUS", "RELEASE", "EXPIRES")
+
+	for _, a := range ps {
+		t.AddRow(a.Name, a.Preview.Base, a.Status, a.Release, common.Ago(a.Preview.Expires))
+	}
+
+	return t.Print()
+}
+
+func AppsPreviewCreate(rack sdk.Interface, c *stdcli.Context) error {
+	base := c.Arg(0)
+	name := c.String("name")
+
+	if name == "" {
+		return fmt.Errorf("--name is required")
+	}
+
+	opts := structs.AppPreviewCreateOptions{
+		Environment: map[string]string{},
+	}
+
+	if b := c.String("build"); b != "" {
+		opts.Build = options.String(b)
+	}
+
+	if l := c.String("link"); l != "" {
+		opts.Resources = strings.Split(l, ",")
+	}
+
+	if v := c.String("ttl"); v != "" {
+		ttl, err := common.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid ttl: %s", v)
+		}
+		opts.Ttl = options.Duration(ttl)
+	}
+
+	for _, arg := range c.Args[1:] {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid env: %s", arg)
+		}
+		opts.Environment[parts[0]] = parts[1]
+	}
+
+	c.Startf("Creating preview <app>%s</app> of <app>%s</app>", name, base)
+
+	if _, err := rack.AppPreviewCreate(base, name, opts); err != nil {
+		return err
+	}
+
+	if err := common.WaitForAppRunning(rack, name); err != nil {
+		return err
+	}
+
+	c.OK()
+
+	ss, err := rack.ServiceList(name)
+	if err != nil {
+		return err
+	}
+
+	if len(ss) == 0 {
+		return nil
+	}
+
+	t := c.Table("SERVICE", "DOMAIN")
+
+	for _, s := range ss {
+		t.AddRow(s.Name, s.Domain)
+	}
+
+	return t.Print()
+}
+
+func AppsPreviewDelete(rack sdk.Interface, c *stdcli.Context) error {
+	app := c.Arg(0)
+
+	a, err := rack.AppGet(app)
+	if err != nil {
+		return err
+	}
+
+	if a.Preview == nil {
+		return fmt.Errorf("%s is not a preview app", app)
+	}
+
+	c.Startf("Deleting preview <app>%s</app>", app)
+
+	if err := rack.AppDelete(app); err != nil {
+		return err
+	}
+
+	if err := common.WaitForAppDeleted(rack, c, app); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
 func AppsUnlock(rack sdk.Interface, c *stdcli.Context) error {
 	app := coalesce(c.Arg(0), app(c))
 
@@ -3,6 +3,7 @@ package cli_test
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -45,6 +46,68 @@ func TestApps(t *testing.T) {
 	})
 }
 
+func TestAppsFormat(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppList").Return(structs.Apps{*fxApp()}, nil)
+
+		res, err := testExecute(e, "apps --format json", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+
+		var as structs.Apps
+		require.NoError(t, json.Unmarshal([]byte(res.Stdout), &as))
+		require.Equal(t, structs.Apps{*fxApp()}, as)
+
+		res, err = testExecute(e, "apps --format yaml", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		require.Contains(t, res.Stdout, "- generation: \"2\"\n")
+		require.Contains(t, res.Stdout, "  name: app1\n")
+
+		res, err = testExecute(e, "apps --format table", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStdout(t, []string{
+			"APP   STATUS   RELEASE",
+			"app1  running  release1",
+		})
+
+		res, err = testExecute(e, "apps --format xml", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: unknown format: xml, must be one of table, json, yaml"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestAppsFormatEmpty(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppList").Return(nil, nil)
+
+		res, err := testExecute(e, "apps --format json", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStdout(t, []string{"[]"})
+	})
+}
+
+func TestAppsInfoFormat(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppGet", "app1").Return(fxAppRouter(), nil)
+
+		res, err := testExecute(e, "apps info app1 --format json", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+
+		var a structs.App
+		require.NoError(t, json.Unmarshal([]byte(res.Stdout), &a))
+		require.Equal(t, *fxAppRouter(), a)
+	})
+}
+
 func TestAppsError(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("AppList").Return(nil, fmt.Errorf("err1"))
@@ -169,6 +232,53 @@ func TestAppsDeleteError(t *testing.T) {
 	})
 }
 
+func TestAppsDrift(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppDrift", "app1").Return(structs.Drifts{
+			{Kind: "Deployment", Name: "web", Changes: []structs.DriftChange{
+				{Path: "spec.replicas", Expected: "2", Actual: "5"},
+				{Path: "spec.template.spec.containers[app].image", Expected: "registry/app:B1", Actual: "registry/app:hotfix"},
+			}},
+			{Kind: "Service", Name: "worker", Missing: true},
+		}, nil)
+
+		res, err := testExecute(e, "apps drift app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"KIND        NAME    FIELD                                     EXPECTED         ACTUAL",
+			"Deployment  web     spec.replicas                             2                5",
+			"Deployment  web     spec.template.spec.containers[app].image  registry/app:B1  registry/app:hotfix",
+			"Service     worker                                            present          missing",
+		})
+	})
+}
+
+func TestAppsDriftNone(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppDrift", "app1").Return(structs.Drifts{}, nil)
+
+		res, err := testExecute(e, "apps drift -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"no drift"})
+	})
+}
+
+func TestAppsDriftError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppDrift", "app1").Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "apps drift app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
 func TestAppsExport(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("AppGet", "app1").Return(fxApp(), nil)
@@ -573,3 +683,105 @@ func TestAppsParamsSetClassic(t *testing.T) {
 		})
 	})
 }
+
+func TestAppsPreview(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		expires := time.Now().Add(49 * time.Hour)
+
+		i.On("AppList").Return(structs.Apps{
+			*fxApp(),
+			{Name: "pr-1", Status: "running", Release: "release2", Preview: &structs.AppPreview{Base: "app1", Expires: expires}},
+			{Name: "pr-2", Status: "running", Release: "release3", Preview: &structs.AppPreview{Base: "app2", Expires: expires}},
+		}, nil)
+
+		res, err := testExecute(e, "apps preview", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"APP   BASE  STATUS   RELEASE   EXPIRES",
+			"pr-1  app1  running  release2  2 days from now",
+			"pr-2  app2  running  release3  2 days from now",
+		})
+
+		res, err = testExecute(e, "apps preview app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStdout(t, []string{
+			"APP   BASE  STATUS   RELEASE   EXPIRES",
+			"pr-1  app1  running  release2  2 days from now",
+		})
+	})
+}
+
+func TestAppsPreviewCreate(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.AppPreviewCreateOptions{
+			Build:       options.String("BUILD1"),
+			Environment: map[string]string{"FOO": "bar", "URL": "https://pr-123.example.org"},
+			Resources:   []string{"database", "cache"},
+			Ttl:         options.Duration(48 * time.Hour),
+		}
+		i.On("AppPreviewCreate", "app1", "pr-123", opts).Return(&structs.App{Name: "pr-123"}, nil)
+		i.On("AppGet", "pr-123").Return(&structs.App{Name: "pr-123", Status: "running"}, nil)
+		i.On("ServiceList", "pr-123").Return(structs.Services{{Name: "web", Domain: "web.pr-123.rack1.example.org"}}, nil)
+
+		res, err := testExecute(e, "apps preview create app1 --name pr-123 --build BUILD1 --link database,cache --ttl 2d FOO=bar URL=https://pr-123.example.org", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Creating preview pr-123 of app1... OK",
+			"SERVICE  DOMAIN",
+			"web      web.pr-123.rack1.example.org",
+		})
+	})
+}
+
+func TestAppsPreviewCreateErrors(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		res, err := testExecute(e, "apps preview create app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: --name is required"})
+
+		res, err = testExecute(e, "apps preview create app1 --name pr-1 --ttl soon", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: invalid ttl: soon"})
+
+		i.On("AppPreviewCreate", "app1", "pr-1", structs.AppPreviewCreateOptions{Environment: map[string]string{}}).Return(nil, fmt.Errorf("err1"))
+
+		res, err = testExecute(e, "apps preview create app1 --name pr-1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Creating preview pr-1 of app1... "})
+	})
+}
+
+func TestAppsPreviewDelete(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppGet", "pr-1").Return(&structs.App{Name: "pr-1", Preview: &structs.AppPreview{Base: "app1"}}, nil).Once()
+		i.On("AppDelete", "pr-1").Return(nil)
+		i.On("AppGet", "pr-1").Return(&structs.App{Status: "deleting"}, nil).Once()
+		i.On("AppGet", "pr-1").Return(nil, fmt.Errorf("no such app: pr-1"))
+
+		res, err := testExecute(e, "apps preview delete pr-1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Deleting preview pr-1... OK"})
+	})
+}
+
+func TestAppsPreviewDeleteNotPreview(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppGet", "app1").Return(fxApp(), nil)
+
+		res, err := testExecute(e, "apps preview delete app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: app1 is not a preview app"})
+	})
+}
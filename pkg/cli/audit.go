@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/sdk"
+	"github.com/convox/stdcli"
+)
+
+func init() {
+	register("rack audit", "list audited api calls", RackAudit, stdcli.CommandOptions{
+		Flags: []stdcli.Flag{
+			flagRack,
+			stdcli.StringFlag("actor", "", "only show calls made by this actor"),
+			stdcli.StringFlag("app", "a", "only show calls against this app"),
+			stdcli.BoolFlag("json", "", "output json lines"),
+			stdcli.IntFlag("limit", "l", "show at most this many of the most recent calls"),
+			stdcli.StringFlag("route", "", "only show calls to this api route"),
+			stdcli.DurationFlag("since", "", "show calls newer than this duration (default 24h)"),
+		},
+		Validate: stdcli.Args(0),
+	})
+}
+
+func RackAudit(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.AuditListOptions
+
+	if v := c.String("actor"); v != "" {
+		opts.Actor = options.String(v)
+	}
+
+	if v := c.String("app"); v != "" {
+		opts.App = options.String(v)
+	}
+
+	if v := c.Int("limit"); v > 0 {
+		opts.Limit = options.Int(v)
+	}
+
+	if v := c.String("route"); v != "" {
+		opts.Route = options.String(v)
+	}
+
+	if v, ok := c.Value("since").(time.Duration); ok && v > 0 {
+		opts.Since = options.Duration(v)
+	}
+
+	es, err := rack.AuditList(opts)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("json") {
+		for _, e := range es {
+			data, err := json.Marshal(e)
+			if err != nil {
+				return err
+			}
+
+			if err := c.Writef("%s\n", data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	t := c.Table("TIME", "ACTOR", "ROUTE", "APP", "STATUS", "PARAMS")
+
+	for _, e := range es {
+		t.AddRow(e.Time.Format(time.RFC3339), e.Actor, e.Route, e.App, fmt.Sprintf("%d", e.Status), auditParamsString(e.Params))
+	}
+
+	return t.Print()
+}
+
+func auditParamsString(params map[string]string) string {
+	ps := make([]string, 0, len(params))
+
+	for k, v := range params {
+		ps = append(ps, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	sort.Strings(ps)
+
+	return strings.Join(ps, " ")
+}
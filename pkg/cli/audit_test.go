@@ -0,0 +1,60 @@
+package cli_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/cli"
+	mocksdk "github.com/convox/convox/pkg/mock/sdk"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+var fxAuditEntries = structs.AuditEntries{
+	{
+		Id:     "E1",
+		Time:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Actor:  "user@example.org",
+		Route:  "ReleaseCreate",
+		Method: "POST",
+		Path:   "/apps/app1/releases",
+		App:    "app1",
+		Params: map[string]string{"env": "BAR,FOO", "build": "B1"},
+		Status: 200,
+	},
+}
+
+func TestRackAudit(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.AuditListOptions{
+			Actor: options.String("user@example.org"),
+			App:   options.String("app1"),
+			Since: options.Duration(6 * time.Hour),
+		}
+		i.On("AuditList", opts).Return(fxAuditEntries, nil)
+
+		res, err := testExecute(e, "rack audit --app app1 --since 6h --actor user@example.org", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"TIME                  ACTOR             ROUTE          APP   STATUS  PARAMS",
+			"2026-01-02T03:04:05Z  user@example.org  ReleaseCreate  app1  200     build=B1 env=BAR,FOO",
+		})
+	})
+}
+
+func TestRackAuditJson(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AuditList", structs.AuditListOptions{}).Return(fxAuditEntries, nil)
+
+		res, err := testExecute(e, "rack audit --json", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			`{"id":"E1","time":"2026-01-02T03:04:05Z","actor":"user@example.org","route":"ReleaseCreate","method":"POST","path":"/apps/app1/releases","app":"app1","params":{"build":"B1","env":"BAR,FOO"},"status":200}`,
+		})
+	})
+}
@@ -83,7 +83,7 @@ func init() {
 	}, WithCloud())
 
 	register("builds", "list builds", watch(Builds), stdcli.CommandOptions{
-		Flags:    append(stdcli.OptionFlags(structs.BuildListOptions{}), flagRack, flagApp, flagWatchInterval),
+		Flags:    append(stdcli.OptionFlags(structs.BuildListOptions{}), flagRack, flagApp, flagWatchInterval, flagFormat),
 		Validate: stdcli.Args(0),
 	}, WithCloud())
 
@@ -122,7 +122,7 @@ func init() {
 	}, WithCloud())
 
 	register("builds info", "get information about a build", BuildsInfo, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack, flagApp},
+		Flags:    []stdcli.Flag{flagRack, flagApp, flagFormat},
 		Usage:    "<build>",
 		Validate: stdcli.Args(1),
 	}, WithCloud())
@@ -409,6 +409,10 @@ func Builds(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, bs); ok {
+		return err
+	}
+
 	t := c.Table("ID", "STATUS", "RELEASE", "STARTED", "ELAPSED", "DESCRIPTION")
 
 	for _, b := range bs {
@@ -626,6 +630,10 @@ func BuildsInfo(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, b); ok {
+		return err
+	}
+
 	i := c.Info()
 
 	i.Add("Id", b.Id)
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/convox/convox/pkg/common"
 	"github.com/convox/convox/pkg/options"
@@ -16,7 +17,7 @@ import (
 
 func init() {
 	register("certs", "list certificates", watch(Certs), stdcli.CommandOptions{
-		Flags:    append(stdcli.OptionFlags(structs.CertificateListOptions{}), flagRack, flagWatchInterval),
+		Flags:    append(stdcli.OptionFlags(structs.CertificateListOptions{}), flagRack, flagWatchInterval, flagFormat),
 		Validate: stdcli.Args(0),
 	})
 
@@ -106,10 +107,22 @@ func Certs(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
-	t := c.Table("ID", "DOMAIN", "EXPIRES", "Status")
+	if ok, err := formatted(c, cs); ok {
+		return err
+	}
+
+	t := c.Table("ID", "DOMAIN", "EXPIRES", "DAYS", "Status", "RENEW ERROR")
+
+	now := time.Now()
 
 	for _, c := range cs {
-		t.AddRow(c.Id, c.Domain, common.Ago(c.Expiration), common.CoalesceString(c.Status, "Ready"))
+		days := ""
+
+		if !c.Expiration.IsZero() {
+			days = strconv.Itoa(c.DaysToExpiry(now))
+		}
+
+		t.AddRow(c.Id, c.Domain, common.Ago(c.Expiration), days, common.CoalesceString(c.Status, "Ready"), c.RenewError)
 	}
 
 	return t.Print()
@@ -14,16 +14,20 @@ import (
 
 func TestCerts(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
-		i.On("CertificateList", mock.Anything).Return(structs.Certificates{*fxCertificate(), *fxCertificate()}, nil)
+		failed := fxCertificate()
+		failed.RenewError = "acme: challenge failed"
+
+		i.On("CertificateList", mock.Anything).Return(structs.Certificates{*fxCertificate(), *failed, {Id: "cert2", Domain: "example.net", Status: "Not Ready"}}, nil)
 
 		res, err := testExecute(e, "certs", nil)
 		require.NoError(t, err)
 		require.Equal(t, 0, res.Code)
 		res.RequireStderr(t, []string{""})
 		res.RequireStdout(t, []string{
-			"ID     DOMAIN       EXPIRES          Status",
-			"cert1  example.org  2 days from now  Ready",
-			"cert1  example.org  2 days from now  Ready",
+			"ID     DOMAIN       EXPIRES          DAYS  Status     RENEW ERROR",
+			"cert1  example.org  2 days from now  2     Ready      ",
+			"cert1  example.org  2 days from now  2     Ready      acme: challenge failed",
+			"cert2  example.net                         Not Ready  ",
 		})
 	})
 }
@@ -22,11 +22,15 @@ var (
 	flagApp           = stdcli.StringFlag("app", "a", "app name")
 	flagForce         = stdcli.BoolFlag("force", "", "force version update")
 	flagForceParams   = stdcli.BoolFlag("force", "f", "override known-key and managed-param guards")
+	flagPlan          = stdcli.BoolFlag("plan", "", "show the terraform plan and confirm before applying it")
+	flagYes           = stdcli.BoolFlag("yes", "", "apply the plan without confirmation")
 	flagId            = stdcli.BoolFlag("id", "", "put logs on stderr, release id on stdout")
 	flagNoFollow      = stdcli.BoolFlag("no-follow", "", "do not follow logs")
 	flagRack          = stdcli.StringFlag("rack", "r", "rack name")
+	flagRacks         = stdcli.StringFlag("racks", "", "rack group or comma separated rack names to run against")
 	flagWatchInterval = stdcli.StringFlag("watch", "", "cmd watch/rerun interval in seconds")
 	flagWait          = stdcli.BoolFlag("wait", "w", "wait for completion")
+	flagWaveSize      = stdcli.IntFlag("wave-size", "", "number of racks to run at a time with --racks, stopping after a failed wave")
 	flagMachine       = stdcli.StringFlag("machine", "i", "machine name")
 )
 
@@ -11,10 +11,10 @@ import (
 
 func init() {
 	register("deploy", "create and promote a build", Deploy, stdcli.CommandOptions{
-		Flags:    append(stdcli.OptionFlags(structs.BuildCreateOptions{}), flagApp, flagId, flagRack, flagForce),
+		Flags:    append(append(stdcli.OptionFlags(structs.BuildCreateOptions{}), flagApp, flagId, flagRack, flagForce, flagOverrideFreeze), flagsPromoteStrategy...),
 		Usage:    "[dir]",
 		Validate: stdcli.ArgsMax(1),
-	}, WithCloud())
+	}, WithCloud(), WithFanout())
 }
 
 func Deploy(rack sdk.Interface, c *stdcli.Context) error {
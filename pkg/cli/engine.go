@@ -15,7 +15,11 @@ type Engine struct {
 }
 
 func (e *Engine) Command(command, description string, fn HandlerFunc, opts stdcli.CommandOptions) {
-	wfn := func(c *stdcli.Context) error {
+	e.command(command, description, rackHandler(fn), opts)
+}
+
+func rackHandler(fn HandlerFunc) stdcli.HandlerFunc {
+	return func(c *stdcli.Context) error {
 		r, err := rack.Current(c)
 		if err != nil {
 			return err
@@ -34,12 +38,6 @@ func (e *Engine) Command(command, description string, fn HandlerFunc, opts stdcl
 
 		return err
 	}
-
-	// the wait command flag is added for making the cli tool v2 backwards compatible
-	flagWait.SkipHelpCommand = true
-	opts.Flags = append(opts.Flags, flagWait)
-
-	e.Engine.Command(command, description, wfn, opts)
 }
 
 func (e *Engine) CommandWithCloud(command, description string, fn HandlerFunc, opts stdcli.CommandOptions) {
@@ -63,20 +61,46 @@ func (e *Engine) CommandWithCloud(command, description string, fn HandlerFunc, o
 }
 
 func (e *Engine) CommandWithoutProvider(command, description string, fn HandlerFunc, opts stdcli.CommandOptions) {
-	wfn := func(c *stdcli.Context) error {
+	e.command(command, description, func(c *stdcli.Context) error {
 		return fn(nil, c)
+	}, opts)
+}
+
+// CommandWithFanout registers a command that runs once for each rack of
+// --racks when it is given and otherwise against a single rack as usual.
+func (e *Engine) CommandWithFanout(command, description string, fn HandlerFunc, opts stdcli.CommandOptions, withRack bool) {
+	h := rackHandler(fn)
+
+	if !withRack {
+		h = func(c *stdcli.Context) error {
+			return fn(nil, c)
+		}
 	}
 
+	opts.Flags = append(opts.Flags, flagRacks, flagWaveSize)
+
+	e.command(command, description, func(c *stdcli.Context) error {
+		if c.String("racks") != "" {
+			return fanout(c, command)
+		}
+
+		return h(c)
+	}, opts)
+}
+
+func (e *Engine) command(command, description string, fn stdcli.HandlerFunc, opts stdcli.CommandOptions) {
 	// the wait command flag is added for making the cli tool v2 backwards compatible
 	flagWait.SkipHelpCommand = true
 	opts.Flags = append(opts.Flags, flagWait)
 
-	e.Engine.Command(command, description, wfn, opts)
+	e.Engine.Command(command, description, fn, opts)
 }
 
 func (e *Engine) RegisterCommands() {
 	for _, c := range commands {
-		if c.Rack {
+		if c.Fanout {
+			e.CommandWithFanout(c.Command, c.Description, c.Handler, c.Opts, c.Rack)
+		} else if c.Rack {
 			e.Command(c.Command, c.Description, c.Handler, c.Opts)
 		} else if c.Cloud {
 			e.CommandWithCloud(c.Command, c.Description, c.Handler, c.Opts)
@@ -95,14 +119,24 @@ type command struct {
 	Opts        stdcli.CommandOptions
 	Rack        bool
 	Cloud       bool
+	Fanout      bool
 }
 
 type RegisterCmdOptions struct {
-	Cloud bool
+	Cloud  bool
+	Fanout bool
 }
 
 type RegisterCmdOptionsFunc func(*RegisterCmdOptions)
 
+// WithFanout adds --racks to a command so that it can be run against a group
+// of racks.
+func WithFanout() RegisterCmdOptionsFunc {
+	return func(opts *RegisterCmdOptions) {
+		opts.Fanout = true
+	}
+}
+
 func WithCloud() RegisterCmdOptionsFunc {
 	return func(opts *RegisterCmdOptions) {
 		opts.Cloud = true
@@ -122,6 +156,7 @@ func register(cmd, description string, fn HandlerFunc, opts stdcli.CommandOption
 		Opts:        opts,
 		Rack:        true,
 		Cloud:       false,
+		Fanout:      rco.Fanout,
 	})
 
 	if rco.Cloud {
@@ -144,7 +179,12 @@ func register(cmd, description string, fn HandlerFunc, opts stdcli.CommandOption
 	}
 }
 
-func registerWithoutProvider(cmd, description string, fn HandlerFunc, opts stdcli.CommandOptions) {
+func registerWithoutProvider(cmd, description string, fn HandlerFunc, opts stdcli.CommandOptions, regOpts ...RegisterCmdOptionsFunc) {
+	rco := &RegisterCmdOptions{}
+	for _, ro := range regOpts {
+		ro(rco)
+	}
+
 	commands = append(commands, command{
 		Command:     cmd,
 		Description: description,
@@ -152,6 +192,7 @@ func registerWithoutProvider(cmd, description string, fn HandlerFunc, opts stdcl
 		Opts:        opts,
 		Rack:        false,
 		Cloud:       false,
+		Fanout:      rco.Fanout,
 	})
 }
 
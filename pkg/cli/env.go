@@ -14,6 +14,7 @@ import (
 
 	"github.com/convox/convox/pkg/common"
 	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/secrets"
 	"github.com/convox/convox/pkg/structs"
 	"github.com/convox/convox/sdk"
 	"github.com/convox/stdcli"
@@ -80,7 +81,7 @@ func init() {
 			stdcli.StringFlag("release", "", "id of the release"),
 		},
 		Usage: "<key=value> [key=value]...",
-	}, WithCloud())
+	}, WithCloud(), WithFanout())
 
 	register("env unset", "unset env var(s)", EnvUnset, stdcli.CommandOptions{
 		Flags: []stdcli.Flag{
@@ -123,7 +124,7 @@ func Env(rack sdk.Interface, c *stdcli.Context) error {
 	// Short-circuit: skip masking path entirely when --reveal is set OR stdout is not a TTY.
 	// Avoids an extra AppConfigGet call and keeps existing TestEnv mocks unchanged.
 	if !c.Bool("reveal") && IsTerminalFn(c) {
-		if masked := maskedKeysSet(rack, app(c)); masked != nil {
+		if masked := withSecretRefs(maskedKeysSet(rack, app(c)), env); masked != nil {
 			_ = c.Writef("%s\n", env.StringMasked(masked))
 			return nil
 		}
@@ -593,6 +594,24 @@ func maskedKeysSet(rack sdk.Interface, appName string) map[string]bool {
 	return set
 }
 
+// withSecretRefs adds the keys of env that reference external secrets to
+// the masked set. Returns nil when nothing is masked.
+func withSecretRefs(masked map[string]bool, env structs.Environment) map[string]bool {
+	refs := secrets.Refs(env)
+	if len(refs) == 0 {
+		return masked
+	}
+
+	set := make(map[string]bool, len(masked)+len(refs))
+	for k := range masked {
+		set[k] = true
+	}
+	for _, k := range refs {
+		set[k] = true
+	}
+	return set
+}
+
 // isAppConfigUnsupported detects errors from racks that don't have the AppConfig
 // endpoint (V2 racks, V3 pre-3.19.7). The SDK flattens HTTP errors to plain Go
 // errors.
@@ -435,6 +435,31 @@ func TestEnvMaskedTTY(t *testing.T) {
 	})
 }
 
+func TestEnvMaskedSecretRefTTY(t *testing.T) {
+	prev := cli.IsTerminalFn
+	cli.IsTerminalFn = func(_ *stdcli.Context) bool { return true }
+	t.Cleanup(func() { cli.IsTerminalFn = prev })
+
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		r := fxRelease()
+		r.Env = "DB_PASS=secretref://vault/secret/data/db#password\nFOO=bar"
+
+		opts := structs.ReleaseListOptions{Limit: options.Int(1)}
+		i.On("ReleaseList", "app1", opts).Return(structs.Releases{*r}, nil)
+		i.On("ReleaseGet", "app1", "release1").Return(r, nil)
+		i.On("AppConfigGet", "app1", "cli-env-mask").Return(nil, fmt.Errorf("app config not found"))
+
+		res, err := testExecute(e, "env -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"DB_PASS=****",
+			"FOO=bar",
+		})
+	})
+}
+
 func TestEnvRevealTTY(t *testing.T) {
 	prev := cli.IsTerminalFn
 	cli.IsTerminalFn = func(_ *stdcli.Context) bool { return true }
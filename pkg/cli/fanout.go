@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/convox/convox/pkg/rack"
+	"github.com/convox/stdcli"
+)
+
+// fanoutResult is the outcome of running a command against one rack of a
+// --racks selection.
+type fanoutResult struct {
+	Rack   string
+	Wave   int
+	Status string
+	Output string
+}
+
+// fanout runs a command once for each rack of --racks by running this cli
+// again with --rack. Each run is a separate process so that racks do not
+// share a working directory or output. The racks run in waves of
+// --wave-size, all at once by default, and the waves after a failed one
+// are skipped.
+func fanout(c *stdcli.Context, command string) error {
+	if c.String("rack") != "" {
+		return fmt.Errorf("--rack and --racks can not be used together")
+	}
+
+	if os.Getenv("RACK_URL") != "" {
+		return fmt.Errorf("--racks can not be used with RACK_URL")
+	}
+
+	// each rack runs without a terminal, so it can not answer a prompt
+	if c.Bool("plan") && !c.Bool("yes") {
+		return fmt.Errorf("--plan with --racks requires --yes")
+	}
+
+	racks, err := rack.Select(c, c.String("racks"))
+	if err != nil {
+		return err
+	}
+
+	// a misspelled rack fails before any rack is changed
+	for i, rn := range racks {
+		r, err := rack.Match(c, rn)
+		if err != nil {
+			return err
+		}
+
+		racks[i] = r.Name()
+	}
+
+	size := c.Int("wave-size")
+
+	if size < 0 {
+		return fmt.Errorf("--wave-size must be greater than 0")
+	}
+
+	if size == 0 || size > len(racks) {
+		size = len(racks)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	rs := make([]fanoutResult, len(racks))
+
+	for i, rn := range racks {
+		rs[i] = fanoutResult{Rack: rn, Wave: i/size + 1, Status: "skipped"}
+	}
+
+	failed := 0
+
+	for start := 0; start < len(racks) && failed == 0; start += size {
+		end := min(start+size, len(racks))
+
+		if size < len(racks) {
+			c.Writef("Wave %d: %s\n", rs[start].Wave, strings.Join(racks[start:end], ", "))
+		}
+
+		var wg sync.WaitGroup
+
+		for i := start; i < end; i++ {
+			wg.Add(1)
+
+			go func(r *fanoutResult) {
+				defer wg.Done()
+
+				data, err := c.Execute(exe, fanoutArgs(c, command, r.Rack)...)
+
+				r.Output = strings.TrimSpace(string(data))
+				r.Status = "ok"
+
+				if err != nil {
+					r.Status = "failed"
+				}
+			}(&rs[i])
+		}
+
+		wg.Wait()
+
+		for _, r := range rs[start:end] {
+			if r.Output != "" {
+				for _, line := range strings.Split(r.Output, "\n") {
+					c.Writef("<rack>%s</rack>: %s\n", r.Rack, line)
+				}
+			}
+
+			if r.Status == "failed" {
+				failed++
+			}
+		}
+	}
+
+	c.Writef("\n")
+
+	t := c.Table("RACK", "WAVE", "STATUS", "RESULT")
+
+	for _, r := range rs {
+		t.AddRow(r.Rack, fmt.Sprintf("%d", r.Wave), r.Status, fanoutSummary(r.Output))
+	}
+
+	if err := t.Print(); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d racks failed", failed, len(racks))
+	}
+
+	return nil
+}
+
+// fanoutArgs rebuilds the command line of a context for one rack.
+func fanoutArgs(c *stdcli.Context, command, name string) []string {
+	args := strings.Split(command, " ")
+
+	for _, f := range c.Flags {
+		switch f.Name {
+		case "rack", "racks", "wave-size":
+			continue
+		}
+
+		switch v := f.Value.(type) {
+		case nil:
+		case []string:
+			for _, s := range v {
+				args = append(args, fmt.Sprintf("--%s=%s", f.Name, s))
+			}
+		default:
+			args = append(args, fmt.Sprintf("--%s=%v", f.Name, v))
+		}
+	}
+
+	args = append(args, fmt.Sprintf("--rack=%s", name), "--")
+
+	return append(args, c.Args...)
+}
+
+// fanoutSummary is the last line of output of a rack.
+func fanoutSummary(output string) string {
+	lines := strings.Split(output, "\n")
+
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,127 @@
+package cli_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/convox/pkg/cli"
+	mocksdk "github.com/convox/convox/pkg/mock/sdk"
+	mockstdcli "github.com/convox/convox/pkg/mock/stdcli"
+	"github.com/stretchr/testify/require"
+)
+
+// fanoutRacks adds direct racks to the settings of e.
+func fanoutRacks(t *testing.T, e *cli.Engine, names ...string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(e.Settings, "racks"), 0700))
+
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(e.Settings, "racks", name), []byte("https://"+name+".example.org"), 0600))
+	}
+}
+
+func TestFanoutEnvSet(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		exe, err := os.Executable()
+		require.NoError(t, err)
+
+		fanoutRacks(t, e, "us-east", "eu-west")
+
+		me := &mockstdcli.Executor{}
+		me.On("Execute", exe, "env", "set", "--app=app1", "--rack=us-east", "--", "FOO=bar").Return([]byte("Setting FOO... OK\nRelease: release1\n"), nil)
+		me.On("Execute", exe, "env", "set", "--app=app1", "--rack=eu-west", "--", "FOO=bar").Return([]byte("Setting FOO... OK\nRelease: release2\n"), nil)
+		e.Executor = me
+
+		res, err := testExecute(e, "env set FOO=bar -a app1 --racks us-east,eu-west", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"us-east: Setting FOO... OK",
+			"us-east: Release: release1",
+			"eu-west: Setting FOO... OK",
+			"eu-west: Release: release2",
+			"",
+			"RACK     WAVE  STATUS  RESULT",
+			"us-east  1     ok      Release: release1",
+			"eu-west  1     ok      Release: release2",
+		})
+
+		me.AssertExpectations(t)
+	})
+}
+
+func TestFanoutWaves(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		exe, err := os.Executable()
+		require.NoError(t, err)
+
+		fanoutRacks(t, e, "us-east", "eu-west", "ap-south")
+
+		err = os.WriteFile(filepath.Join(e.Settings, "groups"), []byte(`[{"name":"prod","racks":["us-east","eu-west","ap-south"]}]`), 0600)
+		require.NoError(t, err)
+
+		me := &mockstdcli.Executor{}
+		me.On("Execute", exe, "rack", "update", "--rack=us-east", "--", "3.23.3").Return([]byte("Updating to 3.23.3... OK\n"), nil)
+		me.On("Execute", exe, "rack", "update", "--rack=eu-west", "--", "3.23.3").Return([]byte("Updating to 3.23.3... ERROR: err1\n"), fmt.Errorf("exit status 1"))
+		e.Executor = me
+
+		res, err := testExecute(e, "rack update 3.23.3 --racks prod --wave-size 1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: 1 of 3 racks failed"})
+		res.RequireStdout(t, []string{
+			"Wave 1: us-east",
+			"us-east: Updating to 3.23.3... OK",
+			"Wave 2: eu-west",
+			"eu-west: Updating to 3.23.3... ERROR: err1",
+			"",
+			"RACK      WAVE  STATUS   RESULT",
+			"us-east   1     ok       Updating to 3.23.3... OK",
+			"eu-west   2     failed   Updating to 3.23.3... ERROR: err1",
+			"ap-south  3     skipped  ",
+		})
+
+		me.AssertExpectations(t)
+	})
+}
+
+func TestFanoutRackConflict(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		res, err := testExecute(e, "scale web --count 2 --rack us-east --racks prod", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: --rack and --racks can not be used together"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestFanoutUnknownRack(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		fanoutRacks(t, e, "us-east", "eu-west")
+
+		me := &mockstdcli.Executor{}
+		e.Executor = me
+
+		res, err := testExecute(e, "env set FOO=bar -a app1 --racks us-east,eu-wset", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: could not find rack: eu-wset"})
+		res.RequireStdout(t, []string{""})
+
+		me.AssertNotCalled(t, "Execute")
+	})
+}
+
+func TestFanoutPlanRequiresYes(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		res, err := testExecute(e, "rack update 3.23.3 --plan --racks us-east,eu-west", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: --plan with --racks requires --yes"})
+		res.RequireStdout(t, []string{""})
+	})
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/convox/stdcli"
+	"sigs.k8s.io/yaml"
+)
+
+var flagFormat = stdcli.StringFlag("format", "", "output format: table, json or yaml")
+
+// formatted writes v, the value the api returned, as json or yaml when the
+// command was run with --format json or --format yaml and reports whether it
+// did. Commands print their table or info output when it did not.
+func formatted(c *stdcli.Context, v interface{}) (bool, error) {
+	var data []byte
+	var err error
+
+	// an empty list is [] rather than null
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.IsNil() {
+		v = reflect.MakeSlice(rv.Type(), 0, 0).Interface()
+	}
+
+	switch f := c.String("format"); f {
+	case "", "table":
+		return false, nil
+	case "json":
+		data, err = json.MarshalIndent(v, "", "  ")
+	case "yaml":
+		data, err = yaml.Marshal(v)
+	default:
+		return true, fmt.Errorf("unknown format: %s, must be one of table, json, yaml", f)
+	}
+	if err != nil {
+		return true, err
+	}
+
+	// write around the tag renderer so values are printed verbatim
+	if _, err := fmt.Fprintf(c.Writer().Stdout, "%s\n", bytes.TrimRight(data, "\n")); err != nil {
+		return true, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,287 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/sdk"
+	"github.com/convox/stdcli"
+)
+
+var (
+	ForwardRefreshInterval = 5 * time.Second
+	ForwardWaitTimeout     = 60 * time.Second
+)
+
+func init() {
+	register("forward", "forward local ports to the processes of a service", Forward, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagApp, flagRack},
+		Usage:    "<service> [[local:]remote]... [<service> [[local:]remote]...]...",
+		Validate: stdcli.ArgsMin(1),
+	})
+}
+
+type forwardPort struct {
+	Local  int
+	Remote int
+}
+
+type forwardTarget struct {
+	Service string
+	Ports   []forwardPort
+}
+
+// forwarder spreads connections across the running processes of a service
+// and follows them as they are replaced.
+type forwarder struct {
+	app     string
+	service string
+	rack    sdk.Interface
+
+	lock  sync.Mutex
+	next  int
+	procs structs.Processes
+}
+
+func Forward(rack sdk.Interface, c *stdcli.Context) error {
+	ts, err := forwardTargets(c.Args)
+	if err != nil {
+		return err
+	}
+
+	m, _, err := common.AppManifest(rack, app(c))
+	if err != nil {
+		return err
+	}
+
+	locals := map[int]bool{}
+
+	for i := range ts {
+		s, err := m.Service(ts[i].Service)
+		if err != nil {
+			return err
+		}
+
+		if len(ts[i].Ports) == 0 {
+			ts[i].Ports = forwardServicePorts(*s)
+		}
+
+		if len(ts[i].Ports) == 0 {
+			return fmt.Errorf("service %s has no ports, specify the port to forward", s.Name)
+		}
+
+		for _, p := range ts[i].Ports {
+			if locals[p.Local] {
+				return fmt.Errorf("local port %d used more than once", p.Local)
+			}
+
+			locals[p.Local] = true
+		}
+	}
+
+	for _, t := range ts {
+		f := &forwarder{app: app(c), service: t.Service, rack: rack}
+
+		go f.refreshEvery(c)
+
+		for _, p := range t.Ports {
+			go f.forward(c, p)
+		}
+	}
+
+	<-c.Done()
+
+	return nil
+}
+
+// forwardTargets parses arguments of the form service [[local:]remote]...
+// where a port list applies to the service named before it.
+func forwardTargets(args []string) ([]forwardTarget, error) {
+	ts := []forwardTarget{}
+
+	for _, arg := range args {
+		parts := strings.Split(arg, ":")
+
+		if _, err := strconv.Atoi(parts[0]); err != nil {
+			if len(parts) > 1 || !manifest.NameValidator.MatchString(arg) {
+				return nil, fmt.Errorf("invalid service: %s", arg)
+			}
+
+			ts = append(ts, forwardTarget{Service: arg})
+			continue
+		}
+
+		if len(ts) == 0 {
+			return nil, fmt.Errorf("port %s must follow a service", arg)
+		}
+
+		var p forwardPort
+
+		switch len(parts) {
+		case 1:
+			p.Remote, _ = strconv.Atoi(parts[0])
+			p.Local = p.Remote
+		case 2:
+			p.Local, _ = strconv.Atoi(parts[0])
+
+			r, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid port: %s", arg)
+			}
+
+			p.Remote = r
+		default:
+			return nil, fmt.Errorf("invalid port: %s", arg)
+		}
+
+		if p.Local < 1 || p.Local > 65535 || p.Remote < 1 || p.Remote > 65535 {
+			return nil, fmt.Errorf("invalid port: %s", arg)
+		}
+
+		ts[len(ts)-1].Ports = append(ts[len(ts)-1].Ports, p)
+	}
+
+	return ts, nil
+}
+
+// forwardServicePorts returns the tcp ports a service listens on.
+func forwardServicePorts(s manifest.Service) []forwardPort {
+	ps := []forwardPort{}
+
+	if s.Port.Port > 0 {
+		ps = append(ps, forwardPort{Local: s.Port.Port, Remote: s.Port.Port})
+	}
+
+	for _, p := range s.Ports {
+		if p.Port > 0 && p.Port != s.Port.Port && (p.Protocol == "" || p.Protocol == "tcp") {
+			ps = append(ps, forwardPort{Local: p.Port, Remote: p.Port})
+		}
+	}
+
+	return ps
+}
+
+func (f *forwarder) forward(c *stdcli.Context, p forwardPort) {
+	c.Writef("forwarding localhost:%d to <service>%s</service>:%d\n", p.Local, f.service, p.Remote)
+
+	lc := &net.ListenConfig{}
+
+	ln, err := lc.Listen(c.Context, "tcp", fmt.Sprintf("127.0.0.1:%d", p.Local))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer ln.Close()
+
+	ch := make(chan net.Conn)
+
+	go proxyAccept(c, ln, ch)
+
+	for {
+		select {
+		case <-c.Done():
+			return
+		case cn := <-ch:
+			go f.connection(c, cn, p)
+		}
+	}
+}
+
+func (f *forwarder) connection(c *stdcli.Context, cn net.Conn, p forwardPort) {
+	defer cn.Close()
+
+	ps, err := f.process(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Writef("connect: %d to <service>%s</service> process <id>%s</id>\n", p.Local, f.service, ps.Id)
+
+	if err := f.rack.WithContext(c.Context).Proxy(ps.Host, p.Remote, cn, structs.ProxyOptions{TLS: options.Bool(false)}); err != nil {
+		c.Error(err)
+
+		// the process may have been replaced, pick up its successor
+		f.refresh()
+	}
+}
+
+// process returns the next running process of the service, waiting for
+// one to start while the service rolls.
+func (f *forwarder) process(c *stdcli.Context) (*structs.Process, error) {
+	deadline := time.Now().Add(ForwardWaitTimeout)
+
+	for {
+		if ps := f.pick(); ps != nil {
+			return ps, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("no running processes for service: %s", f.service)
+		}
+
+		select {
+		case <-c.Done():
+			return nil, fmt.Errorf("canceled")
+		case <-time.After(time.Second):
+			f.refresh()
+		}
+	}
+}
+
+func (f *forwarder) pick() *structs.Process {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if len(f.procs) == 0 {
+		return nil
+	}
+
+	ps := f.procs[f.next%len(f.procs)]
+	f.next++
+
+	return &ps
+}
+
+func (f *forwarder) refresh() {
+	pss, err := f.rack.ProcessList(f.app, structs.ProcessListOptions{Service: options.String(f.service)})
+	if err != nil {
+		return
+	}
+
+	procs := structs.Processes{}
+
+	for _, ps := range pss {
+		if ps.Status == "running" && ps.Host != "" {
+			procs = append(procs, ps)
+		}
+	}
+
+	f.lock.Lock()
+	f.procs = procs
+	f.lock.Unlock()
+}
+
+func (f *forwarder) refreshEvery(c *stdcli.Context) {
+	f.refresh()
+
+	tick := time.NewTicker(ForwardRefreshInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-tick.C:
+			f.refresh()
+		}
+	}
+}
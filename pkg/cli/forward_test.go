@@ -0,0 +1,110 @@
+package cli_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/cli"
+	mocksdk "github.com/convox/convox/pkg/mock/sdk"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func fxForwardRelease() *structs.Release {
+	r := fxRelease()
+	r.Manifest = "services:\n  web:\n    build: .\n    port: 3000\n    ports:\n      - 3000\n      - 9090\n      - 5353/udp\n  worker:\n    build: .\n"
+	return r
+}
+
+func TestForward(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		i.On("AppGet", "app1").Return(fxApp(), nil)
+		i.On("ReleaseGet", "app1", "release1").Return(fxForwardRelease(), nil)
+		i.On("ProcessList", "app1", structs.ProcessListOptions{Service: options.String("web")}).Return(structs.Processes{
+			{Id: "web-1", Host: "10.0.0.1", Status: "running"},
+			{Id: "web-2", Host: "", Status: "pending"},
+			{Id: "web-3", Host: "10.0.0.3", Status: "running"},
+		}, nil)
+		i.On("WithContext", ctx).Return(i)
+
+		reply := func(out string) func(mock.Arguments) {
+			return func(args mock.Arguments) {
+				rwc := args.Get(2).(io.ReadWriteCloser)
+				rwc.Write([]byte(out))
+				rwc.Close()
+			}
+		}
+
+		i.On("Proxy", "10.0.0.1", 3000, mock.Anything, structs.ProxyOptions{TLS: options.Bool(false)}).Return(nil).Run(reply("one")).Once()
+		i.On("Proxy", "10.0.0.3", 3000, mock.Anything, structs.ProxyOptions{TLS: options.Bool(false)}).Return(nil).Run(reply("three")).Once()
+
+		port := rand.Intn(30000) + 10000
+
+		ch := make(chan *result)
+
+		go func() {
+			res, err := testExecuteContext(ctx, e, fmt.Sprintf("forward web %d:3000 -a app1", port), nil)
+			require.NoError(t, err)
+			ch <- res
+		}()
+
+		time.Sleep(500 * time.Millisecond)
+
+		for _, out := range []string{"one", "three"} {
+			cn, err := net.Dial("tcp", fmt.Sprintf("localhost:%d", port))
+			require.NoError(t, err)
+
+			data, err := io.ReadAll(cn)
+			require.NoError(t, err)
+			require.Equal(t, out, string(data))
+		}
+
+		cancel()
+
+		res := <-ch
+
+		require.NotNil(t, res)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			fmt.Sprintf("forwarding localhost:%d to web:3000", port),
+			fmt.Sprintf("connect: %d to web process web-1", port),
+			fmt.Sprintf("connect: %d to web process web-3", port),
+		})
+	})
+}
+
+func TestForwardErrors(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppGet", "app1").Return(fxApp(), nil)
+		i.On("ReleaseGet", "app1", "release1").Return(fxForwardRelease(), nil)
+
+		tests := map[string]string{
+			"forward 3000 -a app1":         "port 3000 must follow a service",
+			"forward web 3000:abc -a app1": "invalid port: 3000:abc",
+			"forward web 70000 -a app1":    "invalid port: 70000",
+			"forward web:3000 -a app1":     "invalid service: web:3000",
+			"forward api -a app1":          "no such service: api",
+			"forward worker -a app1":       "service worker has no ports, specify the port to forward",
+			"forward web web 9090 -a app1": "local port 9090 used more than once",
+		}
+
+		for cmd, msg := range tests {
+			res, err := testExecute(e, cmd, nil)
+			require.NoError(t, err)
+			require.Equal(t, 1, res.Code, cmd)
+			res.RequireStderr(t, []string{"ERROR: " + msg})
+			res.RequireStdout(t, []string{""})
+		}
+	})
+}
@@ -21,7 +21,7 @@ var (
 
 func init() {
 	register("instances", "list instances", watch(Instances), stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack, flagWatchInterval},
+		Flags:    []stdcli.Flag{flagRack, flagWatchInterval, flagFormat},
 		Validate: stdcli.Args(0),
 	})
 
@@ -47,6 +47,10 @@ func Instances(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, is); ok {
+		return err
+	}
+
 	t := c.Table("ID", "STATUS", "STARTED", "PS", "CPU", "MEM", "PUBLIC", "PRIVATE")
 
 	for _, i := range is {
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/sdk"
+	"github.com/convox/stdcli"
+)
+
+func init() {
+	registerWithoutProvider("lint", "validate a convox.yml", Lint, stdcli.CommandOptions{
+		Flags: []stdcli.Flag{
+			stdcli.StringFlag("file", "f", "manifest file"),
+			stdcli.StringFlag("env-file", "", "check required env against this env file"),
+			flagFormat,
+		},
+		Validate: stdcli.Args(0),
+	})
+}
+
+func Lint(rack sdk.Interface, c *stdcli.Context) error {
+	file := coalesce(c.String("file"), "convox.yml")
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	var env map[string]string
+
+	if ef := c.String("env-file"); ef != "" {
+		edata, err := os.ReadFile(ef)
+		if err != nil {
+			return err
+		}
+
+		env, err = structs.NewEnvironment(edata)
+		if err != nil {
+			return err
+		}
+	}
+
+	errs := manifest.Lint(data, env)
+
+	ok, err := formatted(c, errs)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		if len(errs) == 0 {
+			return c.OK()
+		}
+
+		for _, e := range errs {
+			// write around the tag renderer so messages are printed verbatim
+			if _, err := fmt.Fprintf(c.Writer().Stdout, "%s: %s\n", lintLocation(file, e), e.Message); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return stdcli.Exit(1)
+	}
+
+	return nil
+}
+
+func lintLocation(file string, e manifest.LintError) string {
+	switch {
+	case e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d", file, e.Line, e.Column)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d", file, e.Line)
+	default:
+		return file
+	}
+}
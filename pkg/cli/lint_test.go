@@ -0,0 +1,98 @@
+package cli_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/convox/pkg/cli"
+	mocksdk "github.com/convox/convox/pkg/mock/sdk"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLintManifest(t *testing.T, data string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "convox.yml")
+	require.NoError(t, os.WriteFile(file, []byte(data), 0600))
+
+	return file
+}
+
+func TestLint(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := writeLintManifest(t, "services:\n  web:\n    port: 3000\n")
+
+		res, err := testExecute(e, fmt.Sprintf("lint -f %s", file), nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"OK"})
+	})
+}
+
+func TestLintErrors(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := writeLintManifest(t, "services:\n  web:\n    prot: 3000\ntimers:\n  cleanup:\n    schedule: \"0 * * * *\"\n    service: worker\n    command: bin/cleanup\n")
+
+		res, err := testExecute(e, fmt.Sprintf("lint -f %s", file), nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			fmt.Sprintf("%s:3:5: unknown key: services.web.prot", file),
+			fmt.Sprintf("%s:7:5: timer cleanup references a service that does not exist: worker", file),
+		})
+	})
+}
+
+func TestLintEnvFile(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := writeLintManifest(t, "environment:\n  - SECRET\nservices:\n  web:\n    port: 3000\n")
+
+		env := filepath.Join(filepath.Dir(file), ".env")
+		require.NoError(t, os.WriteFile(env, []byte("OTHER=1\n"), 0600))
+
+		res, err := testExecute(e, fmt.Sprintf("lint -f %s --env-file %s", file, env), nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStdout(t, []string{
+			fmt.Sprintf("%s: required env: SECRET", file),
+		})
+
+		require.NoError(t, os.WriteFile(env, []byte("SECRET=1\n"), 0600))
+
+		res, err = testExecute(e, fmt.Sprintf("lint -f %s --env-file %s", file, env), nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStdout(t, []string{"OK"})
+	})
+}
+
+func TestLintFormat(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := writeLintManifest(t, "services:\n  web:\n    prot: 3000\n")
+
+		res, err := testExecute(e, fmt.Sprintf("lint -f %s --format json", file), nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStdout(t, []string{
+			"[",
+			"  {",
+			`    "line": 3,`,
+			`    "column": 5,`,
+			`    "path": "services.web.prot",`,
+			`    "message": "unknown key: services.web.prot"`,
+			"  }",
+			"]",
+		})
+
+		file = writeLintManifest(t, "services:\n  web:\n    port: 3000\n")
+
+		res, err = testExecute(e, fmt.Sprintf("lint -f %s --format json", file), nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStdout(t, []string{"[]"})
+	})
+}
@@ -9,12 +9,12 @@ import (
 
 func init() {
 	register("ps", "list app processes", watch(Ps), stdcli.CommandOptions{
-		Flags:    append(stdcli.OptionFlags(structs.ProcessListOptions{}), flagApp, flagRack, flagWatchInterval),
+		Flags:    append(stdcli.OptionFlags(structs.ProcessListOptions{}), flagApp, flagRack, flagWatchInterval, flagFormat),
 		Validate: stdcli.Args(0),
 	}, WithCloud())
 
 	register("ps info", "get information about a process", watch(PsInfo), stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagApp, flagRack, flagWatchInterval},
+		Flags:    []stdcli.Flag{flagApp, flagRack, flagWatchInterval, flagFormat},
 		Validate: stdcli.Args(1),
 	}, WithCloud())
 
@@ -36,6 +36,10 @@ func Ps(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, ps); ok {
+		return err
+	}
+
 	// budgetCapStatus is best-effort: errors are logged to stderr inside the
 	// helper so a budget-API hiccup never degrades the user-visible ps output.
 	cs, _ := budgetCapStatus(rack, app(c), c.Writer().Stderr)
@@ -50,13 +54,17 @@ func Ps(rack sdk.Interface, c *stdcli.Context) error {
 }
 
 func PsInfo(rack sdk.Interface, c *stdcli.Context) error {
-	i := c.Info()
-
 	ps, err := rack.ProcessGet(app(c), c.Arg(0))
 	if err != nil {
 		return err
 	}
 
+	if ok, err := formatted(c, ps); ok {
+		return err
+	}
+
+	i := c.Info()
+
 	i.Add("Id", ps.Id)
 	i.Add("App", ps.App)
 	i.Add("Command", ps.Command)
@@ -164,6 +164,28 @@ var localKnownParams = map[string]bool{
 	"webhook_signing_key": true,
 }
 
+// installOnlyParams define infrastructure that cannot be changed
+// after rack creation without catastrophic consequences (network recreation,
+// subnet destruction, cluster replacement). Block ANY post-install modification.
+//
+// MAINTENANCE: Keep in sync with immutableRackParams in console3
+// api/controller/cli.go. Add any new TF variable here if changing it
+// post-install would destroy or recreate core infrastructure.
+var installOnlyParams = map[string]bool{
+	"high_availability":   true,
+	"private":             true,
+	"cidr":                true,
+	"vpc_id":              true,
+	"internet_gateway_id": true,
+	"private_subnets_ids": true,
+	"public_subnets_ids":  true,
+	"availability_zones":  true,
+	"region":              true,
+	"access_id":           true,
+	"secret_key":          true,
+	"token":               true,
+}
+
 var managedParams = map[string]bool{
 	"image": true, "name": true, "rack_name": true, "release": true, "settings": true,
 	"nginx_image": true, "k8s_version": true, "aws_ebs_csi_driver_version": true,
@@ -349,6 +371,7 @@ var paramGroups = map[string]map[string]bool{
 		"private_eks_pass":                    true,
 		"private_eks_user":                    true,
 		"secret_key":                          true,
+		"secret_stores":                       true,
 		"ssl_ciphers":                         true,
 		"ssl_protocols":                       true,
 		"tags":                                true, // dual-listed in cost
@@ -664,6 +687,8 @@ var clearableParams = map[string]bool{
 	"ecr_additional_policy_arn": true,
 	// Feature gates — clear means "disable all"
 	"api_feature_gates": true,
+	// External secret stores — clear means "read no store"
+	"secret_stores": true,
 	// Private EKS — cleared by console during mode changes
 	"private_eks_host": true,
 	"private_eks_user": true,
@@ -818,7 +843,7 @@ func disambiguatingPrefix(group string) string {
 
 func init() {
 	register("rack", "get information about the rack", watch(Rack), stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack, flagWatchInterval},
+		Flags:    []stdcli.Flag{flagRack, flagWatchInterval, flagFormat},
 		Validate: stdcli.Args(0),
 	})
 
@@ -827,6 +852,7 @@ func init() {
 			flagRack,
 			stdcli.StringFlag("role", "", "access role: read, write, or admin"),
 			stdcli.IntFlag("duration-in-hours", "", "duration in hours"),
+			stdcli.StringFlag("policies", "", "comma separated access policy ids to restrict the credential to"),
 		},
 		Validate: stdcli.Args(0),
 	})
@@ -877,18 +903,18 @@ func init() {
 	})
 
 	registerWithoutProvider("rack params set", "set rack parameters", RackParamsSet, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack, flagForceParams},
+		Flags:    []stdcli.Flag{flagRack, flagForceParams, flagPlan, flagYes},
 		Usage:    "<Key=Value> [Key=Value]...",
 		Validate: stdcli.ArgsMin(1),
 	})
 
 	register("rack ps", "list rack processes", RackPs, stdcli.CommandOptions{
-		Flags:    append(stdcli.OptionFlags(structs.SystemProcessesOptions{}), flagRack),
+		Flags:    append(stdcli.OptionFlags(structs.SystemProcessesOptions{}), flagRack, flagFormat),
 		Validate: stdcli.Args(0),
 	})
 
 	register("rack releases", "list rack version history", RackReleases, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack},
+		Flags:    []stdcli.Flag{flagRack, flagFormat},
 		Validate: stdcli.Args(0),
 	})
 
@@ -922,10 +948,10 @@ func init() {
 	})
 
 	registerWithoutProvider("rack update", "update a rack", RackUpdate, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack, flagForce},
+		Flags:    []stdcli.Flag{flagRack, flagForce, flagPlan, flagYes},
 		Usage:    "[version]",
 		Validate: stdcli.ArgsMax(1),
-	})
+	}, WithFanout())
 }
 
 type NodeGroupConfigParam struct {
@@ -1212,28 +1238,6 @@ func suggestParam(key string, known map[string]bool) string {
 }
 
 func validateAndMutateParams(params map[string]string, provider string, currentParams map[string]string, force bool) error {
-	// Install-only params — these define infrastructure that cannot be changed
-	// after rack creation without catastrophic consequences (network recreation,
-	// subnet destruction, cluster replacement). Block ANY post-install modification.
-	//
-	// MAINTENANCE: Keep in sync with immutableRackParams in console3
-	// api/controller/cli.go. Add any new TF variable here if changing it
-	// post-install would destroy or recreate core infrastructure.
-	installOnlyParams := map[string]bool{
-		"high_availability":   true,
-		"private":             true,
-		"cidr":                true,
-		"vpc_id":              true,
-		"internet_gateway_id": true,
-		"private_subnets_ids": true,
-		"public_subnets_ids":  true,
-		"availability_zones":  true,
-		"region":              true,
-		"access_id":           true,
-		"secret_key":          true,
-		"token":               true,
-	}
-
 	for k := range params {
 		if installOnlyParams[k] {
 			return fmt.Errorf("param '%s' can only be set during rack installation", k)
@@ -2017,6 +2021,17 @@ func validateAndMutateParams(params map[string]string, provider string, currentP
 		}
 	}
 
+	// secret_stores: comma separated list of the known external secret stores.
+	if v, has := params["secret_stores"]; has && v != "" {
+		for _, s := range strings.Split(v, ",") {
+			switch strings.TrimSpace(s) {
+			case "aws-sm", "file", "sops", "vault":
+			default:
+				return fmt.Errorf("secret_stores: must be a comma separated list of aws-sm, file, sops and vault (got %q)", v)
+			}
+		}
+	}
+
 	// gpu_metrics_max_pods: positive integer, hard cap 500 to prevent operator from defeating the DoS bound.
 	if v, has := params["gpu_metrics_max_pods"]; has && v != "" {
 		n, err := strconv.Atoi(v)
@@ -2065,6 +2080,18 @@ func Rack(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if IsTerminalFn(c) {
+		for k, v := range s.Parameters {
+			if sensitiveParams[k] && v != "" {
+				s.Parameters[k] = "**********"
+			}
+		}
+	}
+
+	if ok, err := formatted(c, s); ok {
+		return err
+	}
+
 	i := c.Info()
 
 	i.Add("Name", s.Name)
@@ -2134,10 +2161,16 @@ func RackAccess(rack sdk.Interface, c *stdcli.Context) error {
 		return fmt.Errorf("duration is required")
 	}
 
-	jwtTk, err := rack.SystemJwtToken(structs.SystemJwtOptions{
+	opts := structs.SystemJwtOptions{
 		Role:           options.String(role),
 		DurationInHour: options.String(strconv.Itoa(duration)),
-	})
+	}
+
+	if ps := c.String("policies"); ps != "" {
+		opts.Policies = options.String(ps)
+	}
+
+	jwtTk, err := rack.SystemJwtToken(opts)
 	if err != nil {
 		return err
 	}
@@ -2423,7 +2456,9 @@ func RackParamsSet(_ sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
-	c.Startf("Updating parameters")
+	if !c.Bool("plan") {
+		c.Startf("Updating parameters")
+	}
 
 	currentParams, err := r.Parameters()
 	if err != nil {
@@ -2439,6 +2474,29 @@ func RackParamsSet(_ sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if c.Bool("plan") {
+		p, err := r.Plan(params, "", force)
+		if err != nil {
+			return err
+		}
+
+		if err := rackPlanPrint(c, p); err != nil {
+			return err
+		}
+
+		if err := rackPlanConfirm(c, p); err != nil {
+			return err
+		}
+
+		c.Startf("Updating parameters")
+
+		if err := p.Apply(); err != nil {
+			return err
+		}
+
+		return c.OK()
+	}
+
 	if err := r.UpdateParams(params); err != nil {
 		return err
 	}
@@ -2458,6 +2516,10 @@ func RackPs(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, ps); ok {
+		return err
+	}
+
 	t := c.Table("ID", "APP", "SERVICE", "STATUS", "RELEASE", "STARTED", "COMMAND")
 
 	for _, p := range ps {
@@ -2473,6 +2535,10 @@ func RackReleases(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, rs); ok {
+		return err
+	}
+
 	t := c.Table("VERSION", "UPDATED")
 
 	for _, r := range rs {
@@ -2661,6 +2727,36 @@ func RackUpdate(_ sdk.Interface, c *stdcli.Context) error {
 		}
 	}
 
+	if c.Bool("plan") {
+		// plan the version an update without one would move to
+		if newVersion == "" {
+			if newVersion, err = rack.LatestVersion(currentVersion); err != nil {
+				return err
+			}
+		}
+
+		p, err := r.Plan(nil, newVersion, force)
+		if err != nil {
+			return err
+		}
+
+		if err := rackPlanPrint(c, p); err != nil {
+			return err
+		}
+
+		if err := rackPlanConfirm(c, p); err != nil {
+			return err
+		}
+
+		c.Startf("Updating to <release>%s</release>", newVersion)
+
+		if err := p.Apply(); err != nil {
+			return err
+		}
+
+		return c.OK()
+	}
+
 	if newVersion != "" {
 		c.Startf("Updating to <release>%s</release>", newVersion)
 	} else {
@@ -0,0 +1,472 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/rack"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/sdk"
+	"github.com/convox/stdcli"
+)
+
+func init() {
+	registerWithoutProvider("rack export", "export the state of a rack", RackExport, stdcli.CommandOptions{
+		Flags: []stdcli.Flag{
+			flagRack,
+			stdcli.StringFlag("file", "f", "export to file"),
+		},
+		Validate: stdcli.Args(0),
+	})
+
+	registerWithoutProvider("rack import", "restore the state of a rack from an export", RackImport, stdcli.CommandOptions{
+		Flags: []stdcli.Flag{
+			flagRack,
+			stdcli.StringFlag("file", "f", "import from file"),
+			stdcli.BoolFlag("force", "", "override unknown-key and managed-param guards"),
+		},
+		Usage:    "[file]",
+		Validate: stdcli.ArgsMax(1),
+	})
+}
+
+// rackExport is the rack.json of a rack export. Apps are exported alongside
+// it as apps/<app>.tgz in the format of apps export.
+type rackExport struct {
+	Name         string                     `json:"name"`
+	Provider     string                     `json:"provider"`
+	Version      string                     `json:"version"`
+	Params       map[string]string          `json:"params"`
+	Registries   structs.Registries         `json:"registries"`
+	Certificates structs.Certificates       `json:"certificates"`
+	LetsEncrypt  *structs.LetsEncryptConfig `json:"letsencrypt"`
+	Resources    structs.Resources          `json:"resources"`
+	Apps         []string                   `json:"apps"`
+}
+
+func RackExport(_ sdk.Interface, c *stdcli.Context) error {
+	r, err := rack.Current(c)
+	if err != nil {
+		return err
+	}
+
+	cl, err := r.Client()
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer
+
+	if file := c.String("file"); file != "" {
+		f, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	} else {
+		if c.Writer().IsTerminal() {
+			return fmt.Errorf("pipe this command into a file or specify --file")
+		}
+		w = c.Writer().Stdout
+		c.Writer().Stdout = c.Writer().Stderr
+	}
+
+	return rackExportTo(c, r, cl, w)
+}
+
+func RackImport(_ sdk.Interface, c *stdcli.Context) error {
+	r, err := rack.Current(c)
+	if err != nil {
+		return err
+	}
+
+	cl, err := r.Client()
+	if err != nil {
+		return err
+	}
+
+	var rd io.ReadCloser
+
+	if file := coalesce(c.Arg(0), c.String("file")); file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		rd = f
+	} else {
+		if c.Reader().IsTerminal() {
+			return fmt.Errorf("pipe a file into this command or specify --file")
+		}
+		rd = io.NopCloser(c.Reader())
+	}
+
+	defer rd.Close()
+
+	return rackImportFrom(c, r, cl, rd)
+}
+
+func rackExportTo(c *stdcli.Context, r rack.Rack, cl sdk.Interface, w io.Writer) error {
+	tmp, err := os.MkdirTemp("", "")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	c.Startf("Exporting rack <rack>%s</rack>", r.Name())
+
+	s, err := cl.SystemGet()
+	if err != nil {
+		return err
+	}
+
+	params, err := r.Parameters()
+	if err != nil {
+		return err
+	}
+
+	ex := rackExport{
+		Name:     r.Name(),
+		Provider: r.Provider(),
+		Version:  s.Version,
+		Params:   map[string]string{},
+	}
+
+	// install-only params hold the cloud credentials of the rack and can not
+	// be set on another rack, managed params are set by rack update
+	for k, v := range params {
+		if !installOnlyParams[k] && !managedParams[k] {
+			ex.Params[k] = v
+		}
+	}
+
+	if ex.Registries, err = cl.RegistryList(); err != nil {
+		return err
+	}
+
+	sort.Sort(ex.Registries)
+
+	if ex.Certificates, err = cl.CertificateList(structs.CertificateListOptions{}); err != nil {
+		return err
+	}
+
+	if ex.LetsEncrypt, err = cl.LetsEncryptConfigGet(); err != nil {
+		return err
+	}
+
+	rs, err := cl.SystemResourceList()
+	if err != nil {
+		return err
+	}
+
+	// only the definition of a resource is exported, its url holds credentials
+	for _, sr := range rs {
+		ex.Resources = append(ex.Resources, structs.Resource{Name: sr.Name, Type: sr.Type, Parameters: sr.Parameters})
+	}
+
+	sort.Slice(ex.Resources, ex.Resources.Less)
+
+	as, err := cl.AppList()
+	if err != nil {
+		return err
+	}
+
+	for _, a := range as {
+		ex.Apps = append(ex.Apps, a.Name)
+	}
+
+	sort.Strings(ex.Apps)
+
+	data, err := json.MarshalIndent(ex, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := rackExportFile(tw, "rack.json", int64(len(data)), bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	c.OK()
+
+	for _, app := range ex.Apps {
+		if err := rackExportApp(c, cl, tw, tmp, app); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// rackExportApp exports app to a file and appends it to the archive, so that
+// only one app export is held on disk at a time.
+func rackExportApp(c *stdcli.Context, cl sdk.Interface, tw *tar.Writer, dir, app string) error {
+	fd, err := os.CreateTemp(dir, "")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(fd.Name())
+	defer fd.Close()
+
+	if err := appExport(cl, c, app, fd); err != nil {
+		return err
+	}
+
+	st, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+
+	if _, err := fd.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	return rackExportFile(tw, path.Join("apps", fmt.Sprintf("%s.tgz", app)), st.Size(), fd)
+}
+
+func rackExportFile(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	h := &tar.Header{
+		Name:     name,
+		Mode:     0600,
+		Size:     size,
+		ModTime:  time.Now(),
+		Typeflag: tar.TypeReg,
+	}
+
+	if err := tw.WriteHeader(h); err != nil {
+		return err
+	}
+
+	_, err := io.Copy(tw, r)
+	return err
+}
+
+// rackImportFrom restores a rack export in the order that its parts depend on
+// each other: params, registries, letsencrypt, resources and then apps. Parts
+// that are already in place are skipped so that an interrupted import can be
+// run again.
+func rackImportFrom(c *stdcli.Context, r rack.Rack, cl sdk.Interface, rd io.Reader) error {
+	tmp, err := os.MkdirTemp("", "")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	gz, err := gzip.NewReader(rd)
+	if err != nil {
+		return err
+	}
+
+	if err := common.Unarchive(gz, tmp); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmp, "rack.json"))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("invalid rack export: missing rack.json")
+	}
+	if err != nil {
+		return err
+	}
+
+	var ex rackExport
+
+	if err := json.Unmarshal(data, &ex); err != nil {
+		return fmt.Errorf("invalid rack export: %s", err)
+	}
+
+	s, err := cl.SystemGet()
+	if err != nil {
+		return err
+	}
+
+	if ex.Version != "" && ex.Version != s.Version {
+		c.Writef("<fail>WARNING: export is from version %s, rack is on version %s</fail>\n", ex.Version, s.Version)
+	}
+
+	if err := rackImportParams(c, r, ex.Params); err != nil {
+		return err
+	}
+
+	registries, err := cl.RegistryList()
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]structs.Registry{}
+
+	for _, reg := range registries {
+		existing[reg.Server] = reg
+	}
+
+	for _, reg := range ex.Registries {
+		if existing[reg.Server] == reg {
+			continue
+		}
+
+		c.Startf("Adding registry <id>%s</id>", reg.Server)
+
+		if _, err := cl.RegistryAdd(reg.Server, reg.Username, reg.Password); err != nil {
+			return err
+		}
+
+		c.OK()
+	}
+
+	if ex.LetsEncrypt != nil && len(ex.LetsEncrypt.Solvers) > 0 {
+		c.Startf("Updating letsencrypt config")
+
+		if err := cl.LetsEncryptConfigApply(*ex.LetsEncrypt); err != nil {
+			return err
+		}
+
+		c.OK()
+	}
+
+	rs, err := cl.SystemResourceList()
+	if err != nil {
+		return err
+	}
+
+	resources := map[string]bool{}
+
+	for _, sr := range rs {
+		resources[sr.Name] = true
+	}
+
+	for _, sr := range ex.Resources {
+		if resources[sr.Name] {
+			continue
+		}
+
+		c.Startf("Creating resource <resource>%s</resource>", sr.Name)
+
+		if _, err := cl.SystemResourceCreate(sr.Type, structs.ResourceCreateOptions{Name: options.String(sr.Name), Parameters: sr.Parameters}); err != nil {
+			return err
+		}
+
+		c.OK()
+	}
+
+	as, err := cl.AppList()
+	if err != nil {
+		return err
+	}
+
+	apps := map[string]bool{}
+
+	for _, a := range as {
+		apps[a.Name] = true
+	}
+
+	for _, app := range ex.Apps {
+		if apps[app] {
+			c.Writef("Skipping app <app>%s</app>, it already exists\n", app)
+			continue
+		}
+
+		if err := rackImportApp(c, cl, app, filepath.Join(tmp, "apps", fmt.Sprintf("%s.tgz", app))); err != nil {
+			return err
+		}
+	}
+
+	return rackImportCertificates(c, cl, ex.Certificates)
+}
+
+// rackImportParams sets the exported params that differ from the rack. They
+// are validated the same way as by rack params set.
+func rackImportParams(c *stdcli.Context, r rack.Rack, exported map[string]string) error {
+	if len(exported) == 0 {
+		return nil
+	}
+
+	current, err := r.Parameters()
+	if err != nil {
+		return err
+	}
+
+	params := map[string]string{}
+
+	for k, v := range exported {
+		if installOnlyParams[k] || managedParams[k] || current[k] == v {
+			continue
+		}
+
+		params[k] = v
+	}
+
+	if len(params) == 0 {
+		return nil
+	}
+
+	if err := validateAndMutateParams(params, r.Provider(), current, c.Bool("force")); err != nil {
+		return err
+	}
+
+	c.Startf("Updating parameters")
+
+	if err := r.UpdateParams(params); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
+func rackImportApp(c *stdcli.Context, cl sdk.Interface, app, file string) error {
+	fd, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	return appImport(cl, c, app, fd)
+}
+
+// rackImportCertificates warns about the uploaded certificates of the export
+// that the rack does not have. Their keys are not part of the export so they
+// have to be imported again with certs import. Certificates that are issued
+// for app domains are issued again once the apps are running.
+func rackImportCertificates(c *stdcli.Context, cl sdk.Interface, exported structs.Certificates) error {
+	if len(exported) == 0 {
+		return nil
+	}
+
+	cs, err := cl.CertificateList(structs.CertificateListOptions{})
+	if err != nil {
+		return err
+	}
+
+	domains := map[string]bool{}
+
+	for _, cert := range cs {
+		domains[cert.Domain] = true
+	}
+
+	for _, cert := range exported {
+		if !strings.HasPrefix(cert.Id, "cert-") || domains[cert.Domain] {
+			continue
+		}
+
+		c.Writef("<fail>WARNING: certificate %s for %s was not restored, import it with convox certs import</fail>\n", cert.Id, cert.Domain)
+	}
+
+	return nil
+}
@@ -0,0 +1,357 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/rack"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/sdk"
+	"github.com/convox/stdcli"
+)
+
+var flagsRackSpec = []stdcli.Flag{
+	flagRack,
+	stdcli.StringFlag("file", "f", "rack spec file (default rack.yml)"),
+	stdcli.BoolFlag("force", "", "override known-key and managed-param guards"),
+	stdcli.BoolFlag("prune", "", "delete registries and resources that are not in the spec"),
+}
+
+func init() {
+	registerWithoutProvider("rack apply", "converge the rack to a rack spec", RackApply, stdcli.CommandOptions{
+		Flags:    flagsRackSpec,
+		Validate: stdcli.Args(0),
+	})
+
+	registerWithoutProvider("rack plan", "show the changes a rack spec would make", RackPlan, stdcli.CommandOptions{
+		Flags:    append(flagsRackSpec, flagFormat),
+		Validate: stdcli.Args(0),
+	})
+}
+
+// rackSpecPlan is the difference between a rack spec and a rack along with
+// the terraform plan for the version and param changes when the rack is
+// installed from this machine.
+type rackSpecPlan struct {
+	Rack      string           `json:"rack"`
+	Changes   rack.SpecChanges `json:"changes"`
+	Terraform *rack.Plan       `json:"terraform"`
+
+	spec *rack.Spec
+}
+
+func RackPlan(_ sdk.Interface, c *stdcli.Context) error {
+	r, err := rack.Current(c)
+	if err != nil {
+		return err
+	}
+
+	cl, err := r.Client()
+	if err != nil {
+		return err
+	}
+
+	p, err := rackSpecDiff(c, r, cl)
+	if err != nil {
+		return err
+	}
+
+	if len(p.Changes.Filter(rack.SpecTypeVersion))+len(p.Changes.Filter(rack.SpecTypeParam)) > 0 {
+		force, _ := c.Value("force").(bool)
+
+		tp, err := r.Plan(rackSpecParams(p), p.spec.Version, force)
+		switch {
+		case errors.Is(err, rack.ErrPlanUnsupported):
+		case err != nil:
+			return err
+		default:
+			defer tp.Discard()
+			p.Terraform = tp
+		}
+	}
+
+	if ok, err := formatted(c, p); ok {
+		return err
+	}
+
+	if len(p.Changes) == 0 {
+		return c.Writef("no changes\n")
+	}
+
+	if err := rackSpecPrint(c, p); err != nil {
+		return err
+	}
+
+	if p.Terraform != nil {
+		c.Writef("\n")
+
+		return rackPlanPrint(c, p.Terraform)
+	}
+
+	return nil
+}
+
+func RackApply(_ sdk.Interface, c *stdcli.Context) error {
+	r, err := rack.Current(c)
+	if err != nil {
+		return err
+	}
+
+	cl, err := r.Client()
+	if err != nil {
+		return err
+	}
+
+	p, err := rackSpecDiff(c, r, cl)
+	if err != nil {
+		return err
+	}
+
+	if len(p.Changes) == 0 {
+		return c.Writef("no changes\n")
+	}
+
+	if err := rackSpecPrint(c, p); err != nil {
+		return err
+	}
+
+	c.Writef("\n")
+
+	// the version goes first so that params added by the new version can be set
+	if len(p.Changes.Filter(rack.SpecTypeVersion)) > 0 {
+		c.Startf("Updating to <release>%s</release>", p.spec.Version)
+
+		if err := r.UpdateVersion(p.spec.Version, false); err != nil {
+			return err
+		}
+
+		c.OK()
+	}
+
+	if params := rackSpecParams(p); len(params) > 0 {
+		c.Startf("Updating parameters")
+
+		if err := r.UpdateParams(params); err != nil {
+			return err
+		}
+
+		c.OK()
+	}
+
+	registries := map[string]rack.SpecRegistry{}
+
+	for _, sr := range p.spec.Registries {
+		registries[sr.Server] = sr
+	}
+
+	for _, ch := range p.Changes.Filter(rack.SpecTypeRegistry) {
+		if ch.Action == rack.SpecActionDelete {
+			c.Startf("Removing registry <id>%s</id>", ch.Name)
+
+			if err := cl.RegistryRemove(ch.Name); err != nil {
+				return err
+			}
+		} else {
+			c.Startf("Adding registry <id>%s</id>", ch.Name)
+
+			sr := registries[ch.Name]
+
+			if _, err := cl.RegistryAdd(sr.Server, sr.Username, sr.Password); err != nil {
+				return err
+			}
+		}
+
+		c.OK()
+	}
+
+	if len(p.Changes.Filter(rack.SpecTypeLetsEncrypt)) > 0 {
+		c.Startf("Updating letsencrypt config")
+
+		if err := cl.LetsEncryptConfigApply(p.spec.LetsEncryptConfig()); err != nil {
+			return err
+		}
+
+		c.OK()
+	}
+
+	for _, ch := range p.Changes.Filter(rack.SpecTypeResource) {
+		sr := p.spec.Resources[ch.Name]
+
+		switch ch.Action {
+		case rack.SpecActionCreate:
+			c.Startf("Creating resource <resource>%s</resource>", ch.Name)
+			_, err = cl.SystemResourceCreate(sr.Type, structs.ResourceCreateOptions{Name: options.String(ch.Name), Parameters: sr.Options})
+		case rack.SpecActionUpdate:
+			c.Startf("Updating resource <resource>%s</resource>", ch.Name)
+			_, err = cl.SystemResourceUpdate(ch.Name, structs.ResourceUpdateOptions{Parameters: sr.Options})
+		case rack.SpecActionDelete:
+			c.Startf("Deleting resource <resource>%s</resource>", ch.Name)
+			err = cl.SystemResourceDelete(ch.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		c.OK()
+	}
+
+	return nil
+}
+
+// rackSpecDiff loads the rack spec and compares it with the rack. Params are
+// validated and normalized the same way as by rack params set before they
+// are compared.
+func rackSpecDiff(c *stdcli.Context, r rack.Rack, cl sdk.Interface) (*rackSpecPlan, error) {
+	file := coalesce(c.String("file"), "rack.yml")
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{}
+
+	for _, e := range os.Environ() {
+		if parts := strings.SplitN(e, "=", 2); len(parts) == 2 {
+			env[parts[0]] = parts[1]
+		}
+	}
+
+	spec, err := rack.LoadSpec(data, env)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", file, err)
+	}
+
+	prune := c.Bool("prune")
+
+	s, err := cl.SystemGet()
+	if err != nil {
+		return nil, err
+	}
+
+	state := rack.SpecState{Version: s.Version}
+
+	if len(spec.Params) > 0 {
+		if state.Params, err = r.Parameters(); err != nil {
+			return nil, err
+		}
+
+		force, _ := c.Value("force").(bool)
+
+		if err := validateAndMutateParams(spec.Params, r.Provider(), state.Params, force); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(spec.Registries) > 0 || prune {
+		if state.Registries, err = cl.RegistryList(); err != nil {
+			return nil, err
+		}
+	}
+
+	if spec.LetsEncrypt != nil {
+		if state.LetsEncrypt, err = cl.LetsEncryptConfigGet(); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(spec.Resources) > 0 || prune {
+		if state.Resources, err = cl.SystemResourceList(); err != nil {
+			return nil, err
+		}
+	}
+
+	cs, err := spec.Diff(state, prune)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range cs {
+		if cs[i].Type == rack.SpecTypeParam && sensitiveParams[cs[i].Name] {
+			if cs[i].From != "" {
+				cs[i].From = "**********"
+			}
+			cs[i].To = "**********"
+		}
+	}
+
+	return &rackSpecPlan{Rack: r.Name(), Changes: cs, spec: spec}, nil
+}
+
+// rackSpecParams returns the params of the spec that differ from the rack.
+func rackSpecParams(p *rackSpecPlan) map[string]string {
+	params := map[string]string{}
+
+	for _, ch := range p.Changes.Filter(rack.SpecTypeParam) {
+		params[ch.Name] = p.spec.Params[ch.Name]
+	}
+
+	return params
+}
+
+func rackSpecPrint(c *stdcli.Context, p *rackSpecPlan) error {
+	t := c.Table("ACTION", "TYPE", "NAME", "FROM", "TO")
+
+	for _, ch := range p.Changes {
+		t.AddRow(ch.Action, ch.Type, ch.Name, ch.From, ch.To)
+	}
+
+	return t.Print()
+}
+
+// rackPlanPrint summarises a terraform plan and warns about the changes that
+// delete or replace a cluster, node group or database.
+func rackPlanPrint(c *stdcli.Context, p *rack.Plan) error {
+	c.Writef("terraform: %s\n", p.Summary())
+
+	if p.Empty() {
+		return nil
+	}
+
+	t := c.Table("ACTION", "RESOURCE")
+
+	for _, r := range p.Resources {
+		t.AddRow(r.Action, r.Address)
+	}
+
+	if err := t.Print(); err != nil {
+		return err
+	}
+
+	done := map[string]string{rack.PlanActionDelete: "deleted", rack.PlanActionReplace: "replaced"}
+
+	for _, r := range p.Destructive() {
+		c.Writef("<fail>WARNING: %s will be %s</fail>\n", r.Address, done[r.Action])
+	}
+
+	return nil
+}
+
+// rackPlanConfirm asks whether to apply a plan unless --yes was given. The
+// plan is discarded when it is not confirmed.
+func rackPlanConfirm(c *stdcli.Context, p *rack.Plan) error {
+	if c.Bool("yes") {
+		return nil
+	}
+
+	if !c.Reader().IsTerminal() {
+		p.Discard()
+		return fmt.Errorf("refusing to prompt for confirmation on non-interactive stdin; pass --yes to apply the plan")
+	}
+
+	fmt.Fprintf(c.Writer(), "Apply this plan? [y/N]: ")
+
+	scanner := bufio.NewScanner(c.Reader())
+	scanner.Scan()
+
+	if answer := strings.ToLower(strings.TrimSpace(scanner.Text())); answer != "y" && answer != "yes" {
+		p.Discard()
+		return fmt.Errorf("aborted")
+	}
+
+	return nil
+}
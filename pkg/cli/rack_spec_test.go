@@ -0,0 +1,201 @@
+package cli_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/convox/pkg/cli"
+	mocksdk "github.com/convox/convox/pkg/mock/sdk"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/rack"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+const fxRackSpec = `
+version: "21000101000001"
+params:
+  ParamFoo: value9
+  ParamNew: new
+registries:
+  - server: docker.io
+    username: user1
+    password: pw1
+resources:
+  cache:
+    type: redis
+    options:
+      version: "7"
+`
+
+func testRackSpec(t *testing.T, data string) string {
+	file := filepath.Join(t.TempDir(), "rack.yml")
+	require.NoError(t, os.WriteFile(file, []byte(data), 0600))
+	return file
+}
+
+func TestRackPlan(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := testRackSpec(t, fxRackSpec)
+
+		rack.TestPlan = &rack.Plan{
+			Replace:   1,
+			Resources: []rack.PlanResource{{Address: "module.system.aws_eks_node_group.cluster", Type: "aws_eks_node_group", Action: "replace", Destructive: true}},
+		}
+		defer func() { rack.TestPlan = nil }()
+
+		i.On("SystemGet").Return(fxSystem(), nil)
+		i.On("RegistryList").Return(structs.Registries{{Server: "docker.io", Username: "user1", Password: "pw1"}}, nil)
+		i.On("SystemResourceList").Return(structs.Resources{}, nil)
+
+		res, err := testExecute(e, "rack plan -f "+file, nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"ACTION  TYPE      NAME      FROM            TO",
+			"update  version   version   21000101000000  21000101000001",
+			"update  param     ParamFoo  value1          value9",
+			"create  param     ParamNew                  new",
+			"create  resource  cache                     redis version=7",
+			"",
+			"terraform: 0 to create, 0 to update, 1 to replace, 0 to delete",
+			"ACTION   RESOURCE",
+			"replace  module.system.aws_eks_node_group.cluster",
+			"WARNING: module.system.aws_eks_node_group.cluster will be replaced",
+		})
+	})
+}
+
+func TestRackPlanFormatJson(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := testRackSpec(t, "params:\n  ParamFoo: value9\n")
+
+		i.On("SystemGet").Return(fxSystem(), nil)
+
+		res, err := testExecute(e, "rack plan -f "+file+" --format json", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			`{`,
+			`  "rack": "rack1",`,
+			`  "changes": [`,
+			`    {`,
+			`      "type": "param",`,
+			`      "name": "ParamFoo",`,
+			`      "action": "update",`,
+			`      "from": "value1",`,
+			`      "to": "value9"`,
+			`    }`,
+			`  ],`,
+			`  "terraform": null`,
+			`}`,
+		})
+	})
+}
+
+func TestRackPlanNoChanges(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := testRackSpec(t, "version: \"21000101000000\"\nparams:\n  ParamFoo: value1\n")
+
+		i.On("SystemGet").Return(fxSystem(), nil)
+
+		res, err := testExecute(e, "rack plan -f "+file, nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"no changes"})
+	})
+}
+
+func TestRackPlanInvalid(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := testRackSpec(t, "resources:\n  cache: {}\n")
+
+		res, err := testExecute(e, "rack plan -f "+file, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{fmt.Sprintf("ERROR: %s: resource cache requires a type", file)})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestRackApply(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := testRackSpec(t, fxRackSpec)
+
+		i.On("SystemGet").Return(fxSystem(), nil)
+		i.On("RegistryList").Return(structs.Registries{}, nil)
+		i.On("SystemResourceList").Return(structs.Resources{}, nil)
+		i.On("SystemUpdate", structs.SystemUpdateOptions{Version: options.String("21000101000001"), Force: options.Bool(false)}).Return(nil)
+		i.On("SystemUpdate", structs.SystemUpdateOptions{Parameters: map[string]string{"ParamFoo": "value9", "ParamNew": "new"}}).Return(nil)
+		i.On("RegistryAdd", "docker.io", "user1", "pw1").Return(&structs.Registry{}, nil)
+		i.On("SystemResourceCreate", "redis", structs.ResourceCreateOptions{Name: options.String("cache"), Parameters: map[string]string{"version": "7"}}).Return(&structs.Resource{}, nil)
+
+		res, err := testExecute(e, "rack apply -f "+file, nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"ACTION  TYPE      NAME       FROM            TO",
+			"update  version   version    21000101000000  21000101000001",
+			"update  param     ParamFoo   value1          value9",
+			"create  param     ParamNew                   new",
+			"create  registry  docker.io                  user1",
+			"create  resource  cache                      redis version=7",
+			"",
+			"Updating to 21000101000001... OK",
+			"Updating parameters... OK",
+			"Adding registry docker.io... OK",
+			"Creating resource cache... OK",
+		})
+	})
+}
+
+func TestRackApplyPrune(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := testRackSpec(t, "registries: []\n")
+
+		i.On("SystemGet").Return(fxSystem(), nil)
+		i.On("RegistryList").Return(structs.Registries{{Server: "docker.io", Username: "user1"}}, nil)
+		i.On("SystemResourceList").Return(structs.Resources{{Name: "cache", Type: "redis"}}, nil)
+		i.On("RegistryRemove", "docker.io").Return(nil)
+		i.On("SystemResourceDelete", "cache").Return(nil)
+
+		res, err := testExecute(e, "rack apply -f "+file+" --prune", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"ACTION  TYPE      NAME       FROM   TO",
+			"delete  registry  docker.io  user1  ",
+			"delete  resource  cache      redis  ",
+			"",
+			"Removing registry docker.io... OK",
+			"Deleting resource cache... OK",
+		})
+	})
+}
+
+func TestRackApplyError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		file := testRackSpec(t, "params:\n  ParamFoo: value9\n")
+
+		i.On("SystemGet").Return(fxSystem(), nil)
+		i.On("SystemUpdate", structs.SystemUpdateOptions{Parameters: map[string]string{"ParamFoo": "value9"}}).Return(fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "rack apply -f "+file, nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{
+			"ACTION  TYPE   NAME      FROM    TO",
+			"update  param  ParamFoo  value1  value9",
+			"",
+			"Updating parameters... ",
+		})
+	})
+}
@@ -510,6 +510,58 @@ func TestRackParamsSet(t *testing.T) {
 	})
 }
 
+func TestRackParamsSetPlan(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		rack.TestPlan = &rack.Plan{
+			Replace:   1,
+			Resources: []rack.PlanResource{{Address: "module.system.aws_eks_node_group.cluster", Type: "aws_eks_node_group", Action: "replace", Destructive: true}},
+		}
+		defer func() { rack.TestPlan = nil }()
+
+		i.On("SystemGet").Return(fxSystem(), nil)
+		i.On("SystemUpdate", structs.SystemUpdateOptions{Parameters: map[string]string{"Foo": "bar"}}).Return(nil)
+
+		res, err := testExecute(e, "rack params set Foo=bar --plan --yes", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"terraform: 0 to create, 0 to update, 1 to replace, 0 to delete",
+			"ACTION   RESOURCE",
+			"replace  module.system.aws_eks_node_group.cluster",
+			"WARNING: module.system.aws_eks_node_group.cluster will be replaced",
+			"Updating parameters... OK",
+		})
+	})
+}
+
+func TestRackParamsSetPlanNoConfirm(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		rack.TestPlan = &rack.Plan{Resources: []rack.PlanResource{}}
+		defer func() { rack.TestPlan = nil }()
+
+		i.On("SystemGet").Return(fxSystem(), nil)
+
+		res, err := testExecute(e, "rack params set Foo=bar --plan", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: refusing to prompt for confirmation on non-interactive stdin; pass --yes to apply the plan"})
+		res.RequireStdout(t, []string{"terraform: no infrastructure changes"})
+	})
+}
+
+func TestRackParamsSetPlanUnsupported(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("SystemGet").Return(fxSystem(), nil)
+
+		res, err := testExecute(e, "rack params set Foo=bar --plan --yes", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: terraform plans are only available for racks installed from this machine"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
 func TestRackParamsSetError(t *testing.T) {
 	prev := cli.IsTerminalFn
 	cli.IsTerminalFn = func(_ *stdcli.Context) bool { return false }
@@ -920,6 +972,30 @@ func TestRackUpdateSpecific(t *testing.T) {
 	})
 }
 
+func TestRackUpdatePlan(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		rack.TestPlan = &rack.Plan{
+			Update:    1,
+			Resources: []rack.PlanResource{{Address: "module.system.module.rack.kubernetes_deployment.api", Type: "kubernetes_deployment", Action: "update"}},
+		}
+		defer func() { rack.TestPlan = nil }()
+
+		i.On("SystemGet").Return(fxSystem(), nil)
+		i.On("SystemUpdate", structs.SystemUpdateOptions{Version: options.String("21000101000001"), Force: options.Bool(false)}).Return(nil)
+
+		res, err := testExecute(e, "rack update 21000101000001 --plan --yes", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"terraform: 0 to create, 1 to update, 0 to replace, 0 to delete",
+			"ACTION  RESOURCE",
+			"update  module.system.module.rack.kubernetes_deployment.api",
+			"Updating to 21000101000001... OK",
+		})
+	})
+}
+
 func TestRackUpdateError(t *testing.T) {
 	prev := cli.IsTerminalFn
 	cli.IsTerminalFn = func(_ *stdcli.Context) bool { return false }
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"strings"
+
 	"github.com/convox/convox/pkg/rack"
 	"github.com/convox/convox/sdk"
 	"github.com/convox/stdcli"
@@ -11,6 +13,21 @@ func init() {
 		Flags:    []stdcli.Flag{flagWatchInterval},
 		Validate: stdcli.Args(0),
 	})
+
+	registerWithoutProvider("racks groups", "list rack groups", RacksGroups, stdcli.CommandOptions{
+		Validate: stdcli.Args(0),
+	})
+
+	registerWithoutProvider("racks group create", "create or replace a rack group", RacksGroupCreate, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{stdcli.StringFlag("racks", "", "comma separated rack names")},
+		Usage:    "<name>",
+		Validate: stdcli.Args(1),
+	})
+
+	registerWithoutProvider("racks group delete", "delete a rack group", RacksGroupDelete, stdcli.CommandOptions{
+		Usage:    "<name>",
+		Validate: stdcli.Args(1),
+	})
 }
 
 func Racks(_ sdk.Interface, c *stdcli.Context) error {
@@ -27,3 +44,47 @@ func Racks(_ sdk.Interface, c *stdcli.Context) error {
 
 	return t.Print()
 }
+
+func RacksGroups(_ sdk.Interface, c *stdcli.Context) error {
+	gs, err := rack.ListGroups(c)
+	if err != nil {
+		return err
+	}
+
+	t := c.Table("NAME", "RACKS")
+
+	for _, g := range gs {
+		t.AddRow(g.Name, strings.Join(g.Racks, ","))
+	}
+
+	return t.Print()
+}
+
+func RacksGroupCreate(_ sdk.Interface, c *stdcli.Context) error {
+	racks := []string{}
+
+	for _, rn := range strings.Split(c.String("racks"), ",") {
+		if rn = strings.TrimSpace(rn); rn != "" {
+			racks = append(racks, rn)
+		}
+	}
+
+	c.Startf("Creating group <id>%s</id>", c.Arg(0))
+
+	g, err := rack.GroupCreate(c, c.Arg(0), racks)
+	if err != nil {
+		return err
+	}
+
+	return c.OK(strings.Join(g.Racks, ","))
+}
+
+func RacksGroupDelete(_ sdk.Interface, c *stdcli.Context) error {
+	c.Startf("Deleting group <id>%s</id>", c.Arg(0))
+
+	if err := rack.GroupDelete(c, c.Arg(0)); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
@@ -138,3 +138,66 @@ func TestRacksError(t *testing.T) {
 		})
 	})
 }
+
+func TestRacksGroupCreate(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		require.NoError(t, testLocalRack(e, "us-east", "aws", "https://host1"))
+		require.NoError(t, testLocalRack(e, "eu-west", "aws", "https://host2"))
+
+		res, err := testExecute(e, "racks group create prod --racks us-east,eu", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Creating group prod... OK, us-east,eu-west"})
+
+		res, err = testExecute(e, "racks group create dev --racks eu-west", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+
+		res, err = testExecute(e, "racks groups", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"NAME  RACKS",
+			"dev   eu-west",
+			"prod  us-east,eu-west",
+		})
+
+		res, err = testExecute(e, "racks group delete dev", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Deleting group dev... OK"})
+
+		res, err = testExecute(e, "racks groups", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStdout(t, []string{
+			"NAME  RACKS",
+			"prod  us-east,eu-west",
+		})
+	})
+}
+
+func TestRacksGroupCreateError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		require.NoError(t, testLocalRack(e, "us-east", "aws", "https://host1"))
+
+		res, err := testExecute(e, "racks group create prod --racks us-east,ap-south", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: could not find rack: ap-south"})
+		res.RequireStdout(t, []string{"Creating group prod... "})
+
+		res, err = testExecute(e, "racks group create prod", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: a group requires at least one rack"})
+
+		res, err = testExecute(e, "racks group delete prod", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: no such group: prod"})
+	})
+}
@@ -7,7 +7,7 @@ import (
 
 func init() {
 	register("registries", "list private registries", watch(Registries), stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack, flagWatchInterval},
+		Flags:    []stdcli.Flag{flagRack, flagWatchInterval, flagFormat},
 		Validate: stdcli.Args(0),
 	})
 
@@ -29,6 +29,15 @@ func Registries(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	// passwords are write-only from the cli
+	for i := range rs {
+		rs[i].Password = ""
+	}
+
+	if ok, err := formatted(c, rs); ok {
+		return err
+	}
+
 	t := c.Table("SERVER", "USERNAME")
 
 	for _, r := range rs {
@@ -1,6 +1,7 @@
 package cli_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -26,6 +27,21 @@ func TestRegistries(t *testing.T) {
 	})
 }
 
+func TestRegistriesFormat(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("RegistryList").Return(structs.Registries{*fxRegistry()}, nil)
+
+		res, err := testExecute(e, "registries --format json", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+
+		var rs structs.Registries
+		require.NoError(t, json.Unmarshal([]byte(res.Stdout), &rs))
+		require.Equal(t, structs.Registries{{Server: "registry1", Username: "username"}}, rs)
+	})
+}
+
 func TestRegistriesError(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("RegistryList").Return(nil, fmt.Errorf("err1"))
@@ -14,19 +14,44 @@ import (
 	"github.com/convox/stdcli"
 )
 
+var flagsPromoteStrategy = []stdcli.Flag{
+	stdcli.IntFlag("error-threshold", "", "roll back when the error rate rises by more than this many percentage points (default 1)"),
+	stdcli.DurationFlag("interval", "", "how long to hold each traffic step (default 5m)"),
+	stdcli.IntFlag("latency-threshold", "", "roll back when p95 latency rises by more than this percent (default 20)"),
+	stdcli.StringFlag("steps", "", "comma separated traffic percentages for a canary promote (default 10,50,100)"),
+	stdcli.StringFlag("strategy", "", "rolling, canary or blue-green (default rolling)"),
+	stdcli.DurationFlag("wait-timeout", "", "give up on a canary or blue-green promote that does not advance for this long (default 50m)"),
+}
+
+const (
+	// promotionLookups is how many polls a promote gets to show up on the
+	// app before the wait stops expecting it.
+	promotionLookups = 3
+
+	// promotionWaitTimeout matches the rack's default promote timeout.
+	promotionWaitTimeout = 3000 * time.Second
+)
+
+var flagOverrideFreeze = stdcli.StringFlag("override-freeze", "", "promote during a deploy freeze, giving the reason (admin only)")
+
 func init() {
 	register("releases", "list releases for an app", watch(Releases), stdcli.CommandOptions{
-		Flags:    append(stdcli.OptionFlags(structs.ReleaseListOptions{}), flagRack, flagApp, flagWatchInterval),
+		Flags:    append(stdcli.OptionFlags(structs.ReleaseListOptions{}), flagRack, flagApp, flagWatchInterval, flagFormat),
 		Validate: stdcli.Args(0),
 	}, WithCloud())
 
+	register("releases approve", "approve a release for promotion", ReleasesApprove, stdcli.CommandOptions{
+		Flags:    append(stdcli.OptionFlags(structs.ReleaseApproveOptions{}), flagApp, flagRack),
+		Validate: stdcli.Args(1),
+	}, WithCloud())
+
 	register("releases create-from", "create a new release using the build from one release and the environment from another for an app", ReleasesCreateFrom, stdcli.CommandOptions{
 		Flags:    append(stdcli.OptionFlags(structs.ReleaseCreateFromOptions{}), flagRack, flagApp),
 		Validate: stdcli.Args(0),
 	}, WithCloud())
 
 	register("releases info", "get information about a release", ReleasesInfo, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagApp, flagRack, stdcli.BoolFlag("reveal", "", "show unmasked env values")},
+		Flags:    []stdcli.Flag{flagApp, flagRack, flagFormat, stdcli.BoolFlag("reveal", "", "show unmasked env values")},
 		Validate: stdcli.Args(1),
 	}, WithCloud())
 
@@ -35,13 +60,30 @@ func init() {
 		Validate: stdcli.Args(1),
 	}, WithCloud())
 
+	register("releases pause", "hold the rollout in progress of an app", ReleasesPause, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagApp, flagRack},
+		Usage:    "[app]",
+		Validate: stdcli.ArgsMax(1),
+	}, WithCloud())
+
 	register("releases promote", "promote a release", ReleasesPromote, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagApp, flagRack, flagForce},
+		Flags:    append([]stdcli.Flag{flagApp, flagRack, flagForce, flagOverrideFreeze}, flagsPromoteStrategy...),
+		Validate: stdcli.ArgsMax(1),
+	}, WithCloud(), WithFanout())
+
+	register("releases resume", "continue a paused rollout of an app", ReleasesResume, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagApp, flagRack},
+		Usage:    "[app]",
 		Validate: stdcli.ArgsMax(1),
 	}, WithCloud())
 
 	register("releases rollback", "copy an old release forward and promote it", ReleasesRollback, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagApp, flagId, flagRack, flagForce},
+		Flags:    []stdcli.Flag{flagApp, flagId, flagRack, flagForce, flagOverrideFreeze},
+		Validate: stdcli.Args(1),
+	}, WithCloud())
+
+	register("releases timeline", "show the recorded timeline of the promotes of a release", ReleasesTimeline, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagApp, flagRack, flagFormat},
 		Validate: stdcli.Args(1),
 	}, WithCloud())
 }
@@ -63,6 +105,18 @@ func Releases(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if f := c.String("format"); f == "json" || f == "yaml" {
+		masked := releaseMaskedKeys(rack, c)
+
+		for i := range rs {
+			rs[i].Env = releaseEnvDisplay(rs[i].Env, masked)
+		}
+	}
+
+	if ok, err := formatted(c, rs); ok {
+		return err
+	}
+
 	t := c.Table("ID", "STATUS", "BUILD", "CREATED", "DESCRIPTION")
 
 	for _, r := range rs {
@@ -78,6 +132,23 @@ func Releases(rack sdk.Interface, c *stdcli.Context) error {
 	return t.Print()
 }
 
+func ReleasesApprove(rack sdk.Interface, c *stdcli.Context) error {
+	var opts structs.ReleaseApproveOptions
+
+	if err := c.Options(&opts); err != nil {
+		return err
+	}
+
+	c.Startf("Approving <release>%s</release>", c.Arg(0))
+
+	r, err := rack.ReleaseApprove(app(c), c.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+
+	return c.OK(fmt.Sprintf("%d approvals", len(r.Approvals)))
+}
+
 func ReleasesCreateFrom(rack sdk.Interface, c *stdcli.Context) error {
 	var opts structs.ReleaseCreateFromOptions
 
@@ -145,6 +216,12 @@ func ReleasesInfo(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	r.Env = releaseEnvDisplay(r.Env, releaseMaskedKeys(rack, c))
+
+	if ok, err := formatted(c, r); ok {
+		return err
+	}
+
 	i := c.Info()
 
 	i.Add("Id", r.Id)
@@ -152,22 +229,61 @@ func ReleasesInfo(rack sdk.Interface, c *stdcli.Context) error {
 	i.Add("Created", r.Created.Format(time.RFC3339))
 	i.Add("Description", r.Description)
 
-	envDisplay := r.Env
-	if !c.Bool("reveal") && c.Writer().IsTerminal() {
-		if masked := maskedKeysSet(rack, app(c)); masked != nil {
-			env := structs.Environment{}
-			if err := env.Load([]byte(r.Env)); err == nil {
-				envDisplay = env.StringMasked(masked)
+	if len(r.Approvals) > 0 {
+		approvals := []string{}
+
+		for _, a := range r.Approvals {
+			line := fmt.Sprintf("%s %s", a.Actor, common.Ago(a.Created))
+			if a.Comment != "" {
+				line = fmt.Sprintf("%s: %s", line, a.Comment)
 			}
-			// env.Load failure: fall through with raw r.Env (no masking, but no crash)
+			approvals = append(approvals, line)
 		}
+
+		i.Add("Approvals", strings.Join(approvals, "\n"))
 	}
 
-	i.Add("Env", envDisplay)
+	i.Add("Env", r.Env)
 
 	return i.Print()
 }
 
+// releaseMaskedKeys returns the app's masked env keys when printing to a
+// terminal without --reveal. The set is empty rather than nil when masking
+// applies so that secret references are still masked.
+func releaseMaskedKeys(rack sdk.Interface, c *stdcli.Context) map[string]bool {
+	if c.Bool("reveal") || !c.Writer().IsTerminal() {
+		return nil
+	}
+
+	if masked := maskedKeysSet(rack, app(c)); masked != nil {
+		return masked
+	}
+
+	return map[string]bool{}
+}
+
+// releaseEnvDisplay masks the values of the masked keys and of the secret
+// references in env.
+func releaseEnvDisplay(env string, masked map[string]bool) string {
+	if masked == nil {
+		return env
+	}
+
+	e := structs.Environment{}
+	if err := e.Load([]byte(env)); err != nil {
+		// fall through with the raw env (no masking, but no crash)
+		return env
+	}
+
+	masked = withSecretRefs(masked, e)
+	if len(masked) == 0 {
+		return env
+	}
+
+	return e.StringMasked(masked)
+}
+
 func ReleasesManifest(rack sdk.Interface, c *stdcli.Context) error {
 	release := c.Arg(0)
 
@@ -190,6 +306,18 @@ func ReleasesManifest(rack sdk.Interface, c *stdcli.Context) error {
 	return nil
 }
 
+func ReleasesPause(rack sdk.Interface, c *stdcli.Context) error {
+	app := coalesce(c.Arg(0), app(c))
+
+	c.Startf("Pausing rollout of <app>%s</app>", app)
+
+	if err := rack.ReleasePause(app); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
 func ReleasesPromote(rack sdk.Interface, c *stdcli.Context) error {
 	release := c.Arg(0)
 
@@ -236,20 +364,120 @@ func releasePromote(rack sdk.Interface, c *stdcli.Context, app, id string, force
 
 	go printPromotingInProgress(ctx, c)
 
-	if err := rack.ReleasePromote(app, id, structs.ReleasePromoteOptions{
-		Force: &force,
-	}); err != nil {
+	opts := promoteStrategyOptions(c)
+	opts.Force = &force
+
+	if v := c.String("override-freeze"); v != "" {
+		opts.OverrideFreeze = options.String(v)
+	}
+
+	if err := rack.ReleasePromote(app, id, opts); err != nil {
 		cancel()
 		return err
 	}
 
 	cancel()
 
+	if opts.Strategy != nil && *opts.Strategy != structs.ReleasePromoteStrategyRolling {
+		return waitForPromotion(rack, c, app, id)
+	}
+
+	return waitForRollout(rack, c, app, id)
+}
+
+func promoteStrategyOptions(c *stdcli.Context) structs.ReleasePromoteOptions {
+	var opts structs.ReleasePromoteOptions
+
+	if v, ok := c.Value("error-threshold").(int); ok && v >= 0 {
+		opts.ErrorThreshold = options.Int(v)
+	}
+
+	if v, ok := c.Value("interval").(time.Duration); ok && v > 0 {
+		opts.Interval = options.Duration(v)
+	}
+
+	if v := c.Int("latency-threshold"); v > 0 {
+		opts.LatencyThreshold = options.Int(v)
+	}
+
+	if v := c.String("steps"); v != "" {
+		opts.Steps = options.String(v)
+	}
+
+	if v := c.String("strategy"); v != "" {
+		opts.Strategy = options.String(v)
+	}
+
+	return opts
+}
+
+// waitForPromotion follows a canary or blue-green promote, which shifts
+// traffic in the background, until it is promoted or rolled back. It gives
+// up when the promote does not advance within --wait-timeout, and follows a
+// plain rollout instead when the rack never records the promote, as racks
+// that predate promote strategies do.
+func waitForPromotion(rack sdk.Interface, c *stdcli.Context, app, id string) error {
+	timeout := promotionWaitTimeout
+	if v, ok := c.Value("wait-timeout").(time.Duration); ok && v > 0 {
+		timeout = v
+	}
+
+	deadline := time.Now().Add(timeout)
+	seen := false
+	status := ""
+	weight := -1
+
+	for polls := 1; ; polls++ {
+		a, err := rack.AppGet(app)
+		if err != nil {
+			return err
+		}
+
+		switch pr := a.Promotion; {
+		case pr != nil && pr.Release == id:
+			seen = true
+
+			if pr.Weight != weight || pr.Status != status {
+				deadline = time.Now().Add(timeout)
+				status = pr.Status
+			}
+
+			if pr.Weight != weight && pr.Status == structs.ReleasePromotionRunning {
+				weight = pr.Weight
+				c.Writef("Serving %d%% of traffic from <release>%s</release>\n", weight, id)
+			}
+
+			switch pr.Status {
+			case structs.ReleasePromotionPromoted:
+				return c.OK()
+			case structs.ReleasePromotionRolledBack:
+				return fmt.Errorf("rolled back to %s: %s", pr.Base, pr.Reason)
+			case structs.ReleasePromotionCancelled:
+				return fmt.Errorf("promote cancelled: %s", pr.Reason)
+			}
+		case pr != nil && (seen || polls >= promotionLookups):
+			return fmt.Errorf("promote of %s was superseded by a promote of %s", id, pr.Release)
+		case pr == nil && seen:
+			return fmt.Errorf("promote of %s is no longer recorded on the app", id)
+		case pr == nil && polls >= promotionLookups:
+			return waitForRollout(rack, c, app, id)
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("promote of %s did not advance for %s", id, timeout)
+		}
+
+		time.Sleep(WaitDuration)
+	}
+}
+
+// waitForRollout waits for a plain rolling promote of id to finish.
+func waitForRollout(rack sdk.Interface, c *stdcli.Context, app, id string) error {
 	if err := common.WaitForAppWithLogs(rack, c, app); err != nil {
 		return err
 	}
 
-	a, err = rack.AppGet(app)
+	a, err := rack.AppGet(app)
 	if err != nil {
 		return err
 	}
@@ -261,6 +489,18 @@ func releasePromote(rack sdk.Interface, c *stdcli.Context, app, id string, force
 	return c.OK()
 }
 
+func ReleasesResume(rack sdk.Interface, c *stdcli.Context) error {
+	app := coalesce(c.Arg(0), app(c))
+
+	c.Startf("Resuming rollout of <app>%s</app>", app)
+
+	if err := rack.ReleaseResume(app); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
 func ReleasesRollback(rack sdk.Interface, c *stdcli.Context) error {
 	var stdout io.Writer
 
@@ -290,10 +530,15 @@ func ReleasesRollback(rack sdk.Interface, c *stdcli.Context) error {
 
 	c.Startf("Promoting <release>%s</release>", rn.Id)
 
-	force := c.Bool("force")
-	if err := rack.ReleasePromote(app(c), rn.Id, structs.ReleasePromoteOptions{
-		Force: &force,
-	}); err != nil {
+	popts := structs.ReleasePromoteOptions{
+		Force: options.Bool(c.Bool("force")),
+	}
+
+	if v := c.String("override-freeze"); v != "" {
+		popts.OverrideFreeze = options.String(v)
+	}
+
+	if err := rack.ReleasePromote(app(c), rn.Id, popts); err != nil {
 		return err
 	}
 
@@ -318,3 +563,71 @@ func ReleasesRollback(rack sdk.Interface, c *stdcli.Context) error {
 
 	return c.OK()
 }
+
+func ReleasesTimeline(rack sdk.Interface, c *stdcli.Context) error {
+	t, err := rack.ReleaseTimeline(app(c), c.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if ok, err := formatted(c, t); ok {
+		return err
+	}
+
+	i := c.Info()
+
+	i.Add("Release", t.Release)
+	i.Add("Status", t.Status)
+	i.Add("Started", t.Started.Format(time.RFC3339))
+	i.Add("Duration", timelineDuration(t.Started, t.Ended))
+
+	if err := i.Print(); err != nil {
+		return err
+	}
+
+	if len(t.Phases) > 0 {
+		c.Writef("\n")
+
+		pt := c.Table("PHASE", "STARTED", "DURATION")
+
+		for _, p := range t.Phases {
+			pt.AddRow(p.Name, timelineOffset(t.Started, p.Started), timelineDuration(p.Started, p.Ended))
+		}
+
+		if err := pt.Print(); err != nil {
+			return err
+		}
+	}
+
+	c.Writef("\n")
+
+	et := c.Table("TIME", "SOURCE", "OBJECT", "REASON", "MESSAGE")
+
+	for _, e := range t.Events {
+		et.AddRow(timelineOffset(t.Started, e.Time), e.Source, timelineObject(e, t.Release), e.Reason, e.Message)
+	}
+
+	return et.Print()
+}
+
+func timelineDuration(start, end time.Time) string {
+	if end.IsZero() {
+		return "in progress"
+	}
+
+	return common.Duration(start, end)
+}
+
+// timelineObject marks the pods of other releases, such as the replicas
+// being drained.
+func timelineObject(e structs.ReleaseTimelineEvent, release string) string {
+	if e.Release != "" && e.Release != release {
+		return fmt.Sprintf("%s (%s)", e.Object, e.Release)
+	}
+
+	return e.Object
+}
+
+func timelineOffset(start, t time.Time) string {
+	return fmt.Sprintf("+%s", common.Duration(start, t))
+}
@@ -43,6 +43,32 @@ func TestReleasesError(t *testing.T) {
 	})
 }
 
+func TestReleasesApprove(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		r := fxRelease()
+		r.Approvals = []structs.ReleaseApproval{{Actor: "alice@example.com"}, {Actor: "bob@example.com", Comment: "lgtm"}}
+		i.On("ReleaseApprove", "app1", "release1", structs.ReleaseApproveOptions{Comment: options.String("lgtm")}).Return(r, nil)
+
+		res, err := testExecute(e, "releases approve release1 -a app1 -m lgtm", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Approving release1... OK, 2 approvals"})
+	})
+}
+
+func TestReleasesApproveError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ReleaseApprove", "app1", "release1", structs.ReleaseApproveOptions{}).Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "releases approve release1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Approving release1... "})
+	})
+}
+
 func TestReleasesInfo(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
@@ -62,6 +88,20 @@ func TestReleasesInfo(t *testing.T) {
 	})
 }
 
+func TestReleasesInfoFormat(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ReleaseGet", "app1", "release1").Return(fxRelease(), nil)
+
+		res, err := testExecute(e, "releases info release1 -a app1 --format yaml", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		require.Contains(t, res.Stdout, "id: release1\n")
+		require.Contains(t, res.Stdout, "build: build1\n")
+		require.Contains(t, res.Stdout, "env: |-\n  FOO=bar\n  BAZ=quux\n")
+	})
+}
+
 func TestReleasesInfoError(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("ReleaseGet", "app1", "release1").Return(nil, fmt.Errorf("err1"))
@@ -102,6 +142,30 @@ func TestReleasesManifestError(t *testing.T) {
 	})
 }
 
+func TestReleasesPause(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ReleasePause", "app1").Return(nil)
+
+		res, err := testExecute(e, "releases pause app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Pausing rollout of app1... OK"})
+	})
+}
+
+func TestReleasesPauseError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ReleasePause", "app1").Return(fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "releases pause -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Pausing rollout of app1... "})
+	})
+}
+
 func TestReleasesPromote(t *testing.T) {
 	testClientWait(t, 100*time.Millisecond, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("AppGet", "app1").Return(fxApp(), nil).Once()
@@ -125,6 +189,164 @@ func TestReleasesPromote(t *testing.T) {
 	})
 }
 
+func TestReleasesPromoteOverrideFreeze(t *testing.T) {
+	testClientWait(t, 100*time.Millisecond, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppGet", "app1").Return(fxApp(), nil).Once()
+		i.On("ReleasePromote", "app1", "release1", structs.ReleasePromoteOptions{
+			Force:          options.Bool(false),
+			OverrideFreeze: options.String("hotfix for checkout outage"),
+		}).Return(nil)
+		i.On("AppGet", "app1").Return(fxApp(), nil)
+		i.On("AppLogs", "app1", mock.Anything).Return(testLogs(fxLogsSystem()), nil)
+
+		res, err := testExecute(e, "releases promote release1 -a app1 --override-freeze 'hotfix for checkout outage'", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+	})
+}
+
+func TestReleasesPromoteFrozen(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppGet", "app1").Return(fxApp(), nil).Once()
+		i.On("ReleasePromote", "app1", "release1", structs.ReleasePromoteOptions{
+			Force: options.Bool(false),
+		}).Return(fmt.Errorf("release release1 has 0 of 2 required approvals"))
+
+		res, err := testExecute(e, "releases promote release1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: release release1 has 0 of 2 required approvals"})
+	})
+}
+
+func TestReleasesPromoteCanary(t *testing.T) {
+	testClientWait(t, 10*time.Millisecond, func(e *cli.Engine, i *mocksdk.Interface) {
+		promoting := func(status string, weight int) *structs.App {
+			a := fxApp()
+			a.Promotion = &structs.ReleasePromotion{Release: "release2", Base: "release1", Status: status, Weight: weight}
+			return a
+		}
+
+		i.On("AppGet", "app1").Return(fxApp(), nil).Once()
+		i.On("ReleasePromote", "app1", "release2", structs.ReleasePromoteOptions{
+			Force:    options.Bool(false),
+			Interval: options.Duration(time.Minute),
+			Steps:    options.String("10,100"),
+			Strategy: options.String("canary"),
+		}).Return(nil)
+		i.On("AppGet", "app1").Return(promoting(structs.ReleasePromotionRunning, 10), nil).Twice()
+		i.On("AppGet", "app1").Return(promoting(structs.ReleasePromotionRunning, 100), nil).Once()
+		i.On("AppGet", "app1").Return(promoting(structs.ReleasePromotionPromoted, 100), nil)
+
+		res, err := testExecute(e, "releases promote release2 -a app1 --strategy canary --steps 10,100 --interval 1m", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Promoting release2... ",
+			"Serving 10% of traffic from release2",
+			"Serving 100% of traffic from release2",
+			"OK",
+		})
+	})
+}
+
+func TestReleasesPromoteCanaryRolledBack(t *testing.T) {
+	testClientWait(t, 10*time.Millisecond, func(e *cli.Engine, i *mocksdk.Interface) {
+		a := fxApp()
+		a.Promotion = &structs.ReleasePromotion{
+			Release: "release2",
+			Base:    "release1",
+			Status:  structs.ReleasePromotionRolledBack,
+			Reason:  "web error rate 5.00% exceeds 1.00% on the current release",
+		}
+
+		i.On("AppGet", "app1").Return(fxApp(), nil).Once()
+		i.On("ReleasePromote", "app1", "release2", structs.ReleasePromoteOptions{
+			Force:    options.Bool(false),
+			Strategy: options.String("blue-green"),
+		}).Return(nil)
+		i.On("AppGet", "app1").Return(a, nil)
+
+		res, err := testExecute(e, "releases promote release2 -a app1 --strategy blue-green", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: rolled back to release1: web error rate 5.00% exceeds 1.00% on the current release"})
+		res.RequireStdout(t, []string{"Promoting release2... "})
+	})
+}
+
+func TestReleasesPromoteCanaryUnrecorded(t *testing.T) {
+	testClientWait(t, 10*time.Millisecond, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("AppGet", "app1").Return(fxApp(), nil).Once()
+		i.On("ReleasePromote", "app1", "release1", structs.ReleasePromoteOptions{
+			ErrorThreshold: options.Int(0),
+			Force:          options.Bool(false),
+			Strategy:       options.String("canary"),
+		}).Return(nil)
+		i.On("AppGet", "app1").Return(fxApp(), nil)
+		i.On("AppLogs", "app1", mock.Anything).Return(testLogs(fxLogsSystem()), nil)
+
+		res, err := testExecute(e, "releases promote release1 -a app1 --strategy canary --error-threshold 0", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Promoting release1... ",
+			"TIME system/aws/component log1",
+			"TIME system/aws/component log2",
+			"OK",
+		})
+	})
+}
+
+func TestReleasesPromoteCanarySuperseded(t *testing.T) {
+	testClientWait(t, 10*time.Millisecond, func(e *cli.Engine, i *mocksdk.Interface) {
+		promoting := func(release string) *structs.App {
+			a := fxApp()
+			a.Promotion = &structs.ReleasePromotion{Release: release, Base: "release1", Status: structs.ReleasePromotionRunning, Weight: 10}
+			return a
+		}
+
+		i.On("AppGet", "app1").Return(fxApp(), nil).Once()
+		i.On("ReleasePromote", "app1", "release2", structs.ReleasePromoteOptions{
+			Force:    options.Bool(false),
+			Strategy: options.String("canary"),
+		}).Return(nil)
+		i.On("AppGet", "app1").Return(promoting("release2"), nil).Once()
+		i.On("AppGet", "app1").Return(promoting("release3"), nil)
+
+		res, err := testExecute(e, "releases promote release2 -a app1 --strategy canary", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: promote of release2 was superseded by a promote of release3"})
+		res.RequireStdout(t, []string{
+			"Promoting release2... ",
+			"Serving 10% of traffic from release2",
+		})
+	})
+}
+
+func TestReleasesPromoteCanaryStalled(t *testing.T) {
+	testClientWait(t, 10*time.Millisecond, func(e *cli.Engine, i *mocksdk.Interface) {
+		a := fxApp()
+		a.Promotion = &structs.ReleasePromotion{Release: "release2", Base: "release1", Status: structs.ReleasePromotionRunning, Weight: 10}
+
+		i.On("AppGet", "app1").Return(fxApp(), nil).Once()
+		i.On("ReleasePromote", "app1", "release2", structs.ReleasePromoteOptions{
+			Force:    options.Bool(false),
+			Strategy: options.String("canary"),
+		}).Return(nil)
+		i.On("AppGet", "app1").Return(a, nil)
+
+		res, err := testExecute(e, "releases promote release2 -a app1 --strategy canary --wait-timeout 50ms", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: promote of release2 did not advance for 50ms"})
+	})
+}
+
 func TestReleasesPromoteError(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("AppGet", "app1").Return(fxApp(), nil)
@@ -166,6 +388,30 @@ func TestReleasesPromoteAlreadyUpdating(t *testing.T) {
 	})
 }
 
+func TestReleasesResume(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ReleaseResume", "app1").Return(nil)
+
+		res, err := testExecute(e, "releases resume app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Resuming rollout of app1... OK"})
+	})
+}
+
+func TestReleasesResumeError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ReleaseResume", "app1").Return(fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "releases resume -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{"Resuming rollout of app1... "})
+	})
+}
+
 func TestReleasesRollback(t *testing.T) {
 	testClientWait(t, 50*time.Millisecond, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("ReleaseGet", "app1", "release2").Return(fxRelease2(), nil)
@@ -241,3 +487,61 @@ func TestReleasesInfoRevealFlag(t *testing.T) {
 		})
 	})
 }
+
+func TestReleasesTimeline(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+		i.On("ReleaseTimeline", "app1", "release1").Return(&structs.ReleaseTimeline{
+			App:     "app1",
+			Release: "release1",
+			Status:  "completed",
+			Started: start,
+			Ended:   at(95),
+			Events: []structs.ReleaseTimelineEvent{
+				{Time: at(0), Source: "promote", Reason: "Promote", Message: "rolling promote from release0 by user1"},
+				{Time: at(2), Source: "pod", Object: "web-1", Release: "release1", Reason: "Pulling", Message: "Pulling image"},
+				{Time: at(30), Source: "pod", Object: "web-0", Release: "release0", Reason: "Killing"},
+				{Time: at(95), Source: "watcher", Reason: "completed"},
+			},
+			Phases: []structs.ReleaseTimelinePhase{
+				{Name: "image-pull", Started: at(2), Ended: at(20), Duration: 18 * time.Second},
+				{Name: "drain", Started: at(30)},
+			},
+		}, nil)
+
+		res, err := testExecute(e, "releases timeline release1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Release   release1",
+			"Status    completed",
+			"Started   2026-01-02T03:04:05Z",
+			"Duration  1m35s",
+			"",
+			"PHASE       STARTED  DURATION",
+			"image-pull  +2s      18s",
+			"drain       +30s     in progress",
+			"",
+			"TIME    SOURCE   OBJECT            REASON     MESSAGE",
+			"+0s     promote                    Promote    rolling promote from release0 by user1",
+			"+2s     pod      web-1             Pulling    Pulling image",
+			"+30s    pod      web-0 (release0)  Killing    ",
+			"+1m35s  watcher                    completed  ",
+		})
+	})
+}
+
+func TestReleasesTimelineError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ReleaseTimeline", "app1", "release1").Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "releases timeline release1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{""})
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/convox/convox/pkg/common"
 	"github.com/convox/convox/pkg/options"
 	"github.com/convox/convox/pkg/structs"
 	"github.com/convox/convox/sdk"
@@ -17,10 +18,16 @@ import (
 
 func init() {
 	register("resources", "list resources", watch(Resources), stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack, flagApp, flagWatchInterval},
+		Flags:    []stdcli.Flag{flagRack, flagApp, flagWatchInterval, flagFormat},
 		Validate: stdcli.Args(0),
 	}, WithCloud())
 
+	register("resources backups", "list backups of a resource", ResourcesBackups, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack, flagApp, flagFormat},
+		Usage:    "<resource>",
+		Validate: stdcli.Args(1),
+	}, WithCloud())
+
 	register("resources console", "start a console for a resource", ResourcesConsole, stdcli.CommandOptions{
 		Flags:    []stdcli.Flag{flagRack, flagApp},
 		Usage:    "<resource>",
@@ -47,7 +54,7 @@ func init() {
 	}, WithCloud())
 
 	register("resources info", "get information about a resource", ResourcesInfo, stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagRack, flagApp},
+		Flags:    []stdcli.Flag{flagRack, flagApp, flagFormat},
 		Usage:    "<resource>",
 		Validate: stdcli.Args(1),
 	}, WithCloud())
@@ -63,6 +70,16 @@ func init() {
 		Validate: stdcli.Args(1),
 	}, WithCloud())
 
+	register("resources restore", "restore a resource from a backup", ResourcesRestore, stdcli.CommandOptions{
+		Flags: []stdcli.Flag{
+			flagRack,
+			flagApp,
+			stdcli.StringFlag("backup", "b", "backup id"),
+		},
+		Usage:    "<resource>",
+		Validate: stdcli.Args(1),
+	}, WithCloud())
+
 	register("resources url", "get url for a resource", ResourcesUrl, stdcli.CommandOptions{
 		Flags:    []stdcli.Flag{flagRack, flagApp},
 		Usage:    "<resource>",
@@ -158,6 +175,10 @@ func Resources(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, rs); ok {
+		return err
+	}
+
 	t := c.Table("NAME", "TYPE", "URL")
 
 	for _, r := range rs {
@@ -167,6 +188,25 @@ func Resources(rack sdk.Interface, c *stdcli.Context) error {
 	return t.Print()
 }
 
+func ResourcesBackups(rack sdk.Interface, c *stdcli.Context) error {
+	bs, err := rack.ResourceBackupList(app(c), c.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if ok, err := formatted(c, bs); ok {
+		return err
+	}
+
+	t := c.Table("ID", "CREATED")
+
+	for _, b := range bs {
+		t.AddRow(b.Id, common.Ago(b.Created))
+	}
+
+	return t.Print()
+}
+
 func ResourcesConsole(rack sdk.Interface, c *stdcli.Context) error {
 	opts := structs.ResourceConsoleOptions{}
 
@@ -243,6 +283,10 @@ func ResourcesInfo(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, r); ok {
+		return err
+	}
+
 	i := c.Info()
 
 	i.Add("Name", r.Name)
@@ -315,6 +359,22 @@ func ResourcesProxy(rack sdk.Interface, c *stdcli.Context) error {
 	return nil
 }
 
+func ResourcesRestore(rack sdk.Interface, c *stdcli.Context) error {
+	id := c.String("backup")
+
+	if id == "" {
+		return fmt.Errorf("--backup is required")
+	}
+
+	c.Startf("Restoring <resource>%s</resource> from backup <id>%s</id>", c.Arg(0), id)
+
+	if err := rack.ResourceBackupRestore(app(c), c.Arg(0), id); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
 func ResourcesUrl(rack sdk.Interface, c *stdcli.Context) error {
 	r, err := rack.ResourceGet(app(c), c.Arg(0))
 	if err != nil {
@@ -45,6 +45,59 @@ func TestResourcesError(t *testing.T) {
 	})
 }
 
+func TestResourcesBackups(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ResourceBackupList", "app1", "resource1").Return(structs.ResourceBackups{
+			{Id: "20261018030000", Resource: "resource1", Created: fxStarted},
+			{Id: "20261017030000", Resource: "resource1", Created: fxStarted},
+		}, nil)
+
+		res, err := testExecute(e, "resources backups resource1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"ID              CREATED",
+			"20261018030000  2 days ago",
+			"20261017030000  2 days ago",
+		})
+	})
+}
+
+func TestResourcesBackupsError(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ResourceBackupList", "app1", "resource1").Return(nil, fmt.Errorf("err1"))
+
+		res, err := testExecute(e, "resources backups resource1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: err1"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
+func TestResourcesRestore(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("ResourceBackupRestore", "app1", "resource1", "20261018030000").Return(nil)
+
+		res, err := testExecute(e, "resources restore resource1 -a app1 --backup 20261018030000", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Restoring resource1 from backup 20261018030000... OK"})
+	})
+}
+
+func TestResourcesRestoreNoBackup(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		res, err := testExecute(e, "resources restore resource1 -a app1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1, res.Code)
+		res.RequireStderr(t, []string{"ERROR: --backup is required"})
+		res.RequireStdout(t, []string{""})
+	})
+}
+
 func TestResourcesInfo(t *testing.T) {
 	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
 		i.On("ResourceGet", "app1", "resource1").Return(fxResource(), nil)
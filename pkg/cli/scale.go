@@ -25,7 +25,7 @@ func init() {
 			}
 			return stdcli.ArgsMax(1)(c)
 		},
-	}, WithCloud())
+	}, WithCloud(), WithFanout())
 }
 
 func scaleHasImperativeFlag(c *stdcli.Context) bool {
@@ -13,7 +13,7 @@ import (
 
 func init() {
 	register("services", "list services for an app", watch(Services), stdcli.CommandOptions{
-		Flags:    []stdcli.Flag{flagApp, flagRack, flagWatchInterval},
+		Flags:    []stdcli.Flag{flagApp, flagRack, flagWatchInterval, flagFormat},
 		Validate: stdcli.Args(0),
 	}, WithCloud())
 
@@ -66,6 +66,10 @@ func Services(rack sdk.Interface, c *stdcli.Context) error {
 		return err
 	}
 
+	if ok, err := formatted(c, ss); ok {
+		return err
+	}
+
 	// NLB PORTS column only renders when at least one service declares nlb: ports.
 	// v3 racks never populate Nlb; v2 racks populate it from the release manifest.
 	// Index-based iteration avoids copying the full Service struct per scan step.
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/sdk"
+	"github.com/convox/stdcli"
+)
+
+func init() {
+	register("rack tokens", "list api tokens", RackTokens, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack},
+		Validate: stdcli.Args(0),
+	})
+
+	register("rack tokens create", "create a named api token", RackTokensCreate, stdcli.CommandOptions{
+		Flags: []stdcli.Flag{
+			flagRack,
+			stdcli.StringFlag("apps", "", "comma separated list of apps the token is limited to"),
+			stdcli.StringFlag("expires", "e", "token lifetime (default 30d)"),
+			stdcli.StringFlag("policies", "p", "comma separated list of access policies to attach"),
+			stdcli.StringFlag("role", "", "token role: read, write or admin (default write)"),
+		},
+		Usage:    "<name>",
+		Validate: stdcli.Args(1),
+	})
+
+	register("rack tokens revoke", "revoke an api token", RackTokensRevoke, stdcli.CommandOptions{
+		Flags:    []stdcli.Flag{flagRack},
+		Usage:    "<id>",
+		Validate: stdcli.Args(1),
+	})
+}
+
+func RackTokens(rack sdk.Interface, c *stdcli.Context) error {
+	ts, err := rack.SystemTokenList()
+	if err != nil {
+		return err
+	}
+
+	t := c.Table("ID", "NAME", "ROLE", "APPS", "CREATED BY", "EXPIRES", "LAST USED", "STATUS")
+
+	for _, tk := range ts {
+		t.AddRow(tk.Id, tk.Name, systemTokenRoleName(tk.Role), strings.Join(tk.Apps, ","), tk.CreatedBy, tk.Expires.Format("2006-01-02"), common.Ago(tk.LastUsed), systemTokenStatus(tk))
+	}
+
+	return t.Print()
+}
+
+func RackTokensCreate(rack sdk.Interface, c *stdcli.Context) error {
+	opts := structs.SystemTokenCreateOptions{
+		Name: options.String(c.Arg(0)),
+	}
+
+	if v := c.String("apps"); v != "" {
+		opts.Apps = strings.Split(v, ",")
+	}
+
+	if v := c.String("expires"); v != "" {
+		opts.Expires = options.String(v)
+	}
+
+	if v := c.String("policies"); v != "" {
+		opts.Policies = strings.Split(v, ",")
+	}
+
+	if v := c.String("role"); v != "" {
+		opts.Role = options.String(v)
+	}
+
+	s, err := rack.SystemGet()
+	if err != nil {
+		return err
+	}
+
+	tk, err := rack.SystemTokenCreate(opts)
+	if err != nil {
+		return err
+	}
+
+	c.Writef("Created token <id>%s</id> expiring %s\n", tk.Id, tk.Expires.Format(time.RFC3339))
+
+	return c.Writef("RACK_URL=https://jwt:%s@%s\n", tk.Token, s.RackDomain)
+}
+
+func RackTokensRevoke(rack sdk.Interface, c *stdcli.Context) error {
+	c.Startf("Revoking token <id>%s</id>", c.Arg(0))
+
+	if err := rack.SystemTokenRevoke(c.Arg(0)); err != nil {
+		return err
+	}
+
+	return c.OK()
+}
+
+func systemTokenRoleName(role string) string {
+	switch role {
+	case structs.ConvoxRoleRead:
+		return "read"
+	case structs.ConvoxRoleReadWrite:
+		return "write"
+	case structs.ConvoxRoleAdmin:
+		return "admin"
+	}
+
+	return role
+}
+
+func systemTokenStatus(t structs.SystemToken) string {
+	switch {
+	case !t.Revoked.IsZero():
+		return fmt.Sprintf("revoked %s", t.Revoked.Format("2006-01-02"))
+	case time.Now().After(t.Expires):
+		return "expired"
+	}
+
+	return "active"
+}
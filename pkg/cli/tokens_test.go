@@ -0,0 +1,71 @@
+package cli_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/cli"
+	mocksdk "github.com/convox/convox/pkg/mock/sdk"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRackTokens(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		expires := time.Now().Add(24 * time.Hour)
+
+		i.On("SystemTokenList").Return(structs.SystemTokens{
+			{Id: "T1", Name: "ci", Role: "rw", Apps: []string{"app1", "app2"}, CreatedBy: "admin", Expires: expires},
+			{Id: "T2", Name: "old", Role: "r", Expires: expires, Revoked: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		}, nil)
+
+		day := expires.Format("2006-01-02")
+
+		res, err := testExecute(e, "rack tokens", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"ID  NAME  ROLE   APPS       CREATED BY  EXPIRES     LAST USED  STATUS",
+			"T1  ci    write  app1,app2  admin       " + day + "             active",
+			"T2  old   read                          " + day + "             revoked 2026-01-02",
+		})
+	})
+}
+
+func TestRackTokensCreate(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		opts := structs.SystemTokenCreateOptions{
+			Apps:    []string{"app1", "app2"},
+			Expires: options.String("7d"),
+			Name:    options.String("ci"),
+			Role:    options.String("write"),
+		}
+		expires := time.Date(2026, 1, 8, 0, 0, 0, 0, time.UTC)
+
+		i.On("SystemGet").Return(&structs.System{RackDomain: "rack.example.org"}, nil)
+		i.On("SystemTokenCreate", opts).Return(&structs.SystemToken{Id: "T1", Name: "ci", Expires: expires, Token: "tk"}, nil)
+
+		res, err := testExecute(e, "rack tokens create ci --apps app1,app2 --expires 7d --role write", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{
+			"Created token T1 expiring 2026-01-08T00:00:00Z",
+			"RACK_URL=https://jwt:tk@rack.example.org",
+		})
+	})
+}
+
+func TestRackTokensRevoke(t *testing.T) {
+	testClient(t, func(e *cli.Engine, i *mocksdk.Interface) {
+		i.On("SystemTokenRevoke", "T1").Return(nil)
+
+		res, err := testExecute(e, "rack tokens revoke T1", nil)
+		require.NoError(t, err)
+		require.Equal(t, 0, res.Code)
+		res.RequireStderr(t, []string{""})
+		res.RequireStdout(t, []string{"Revoking token T1... OK"})
+	})
+}
@@ -9,13 +9,17 @@ import (
 )
 
 type TokenData struct {
+	Id        string
 	User      string
 	Role      string
+	Apps      []string
+	Policies  []string
 	ExpiresAt time.Time
 }
 
 type JwtManager struct {
 	signKey []byte
+	revoked func(id string) bool
 }
 
 func NewJwtManager(signKey string) *JwtManager {
@@ -24,42 +28,63 @@ func NewJwtManager(signKey string) *JwtManager {
 	}
 }
 
-func (j *JwtManager) ReadToken(duration time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user":      "system-read",
-		"role":      structs.ConvoxRoleRead,
-		"expiresAt": time.Now().UTC().Add(duration).Unix(),
-	})
+// SetRevocationCheck registers the lookup Verify uses to reject rack issued
+// tokens by their jti once they have been revoked.
+func (j *JwtManager) SetRevocationCheck(fn func(id string) bool) {
+	j.revoked = fn
+}
 
-	// Sign and get the complete encoded token as a string using the secret
-	tokenString, err := token.SignedString(j.signKey)
-	if err != nil {
-		return "", err
+func (j *JwtManager) ReadToken(duration time.Duration, policies ...string) (string, error) {
+	return j.sign("system-read", structs.ConvoxRoleRead, duration, policies)
+}
+
+func (j *JwtManager) WriteToken(duration time.Duration, policies ...string) (string, error) {
+	return j.sign("system-write", structs.ConvoxRoleReadWrite, duration, policies)
+}
+
+func (j *JwtManager) AdminToken(duration time.Duration, policies ...string) (string, error) {
+	return j.sign("system-admin", structs.ConvoxRoleAdmin, duration, policies)
+}
+
+// SystemToken signs a named token issued by the rack. Its id is carried as
+// the jti claim so the token can be revoked individually.
+func (j *JwtManager) SystemToken(t *structs.SystemToken) (string, error) {
+	claims := jwt.MapClaims{
+		"jti":       t.Id,
+		"user":      fmt.Sprintf("token:%s", t.Name),
+		"role":      t.Role,
+		"expiresAt": t.Expires.UTC().Unix(),
 	}
-	return tokenString, nil
+
+	if len(t.Apps) > 0 {
+		claims["apps"] = t.Apps
+	}
+
+	if len(t.Policies) > 0 {
+		claims["policies"] = t.Policies
+	}
+
+	return j.signClaims(claims)
 }
 
-func (j *JwtManager) WriteToken(duration time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user":      "system-write",
-		"role":      structs.ConvoxRoleReadWrite,
+func (j *JwtManager) sign(user, role string, duration time.Duration, policies []string) (string, error) {
+	claims := jwt.MapClaims{
+		"user":      user,
+		"role":      role,
 		"expiresAt": time.Now().UTC().Add(duration).Unix(),
-	})
+	}
 
-	// Sign and get the complete encoded token as a string using the secret
-	tokenString, err := token.SignedString(j.signKey)
-	if err != nil {
-		return "", err
+	// policies are only carried when present so unrestricted tokens stay
+	// byte-compatible with racks that predate access policies
+	if len(policies) > 0 {
+		claims["policies"] = policies
 	}
-	return tokenString, nil
+
+	return j.signClaims(claims)
 }
 
-func (j *JwtManager) AdminToken(duration time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user":      "system-admin",
-		"role":      structs.ConvoxRoleAdmin,
-		"expiresAt": time.Now().UTC().Add(duration).Unix(),
-	})
+func (j *JwtManager) signClaims(claims jwt.MapClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Sign and get the complete encoded token as a string using the secret
 	tokenString, err := token.SignedString(j.signKey)
@@ -107,11 +132,56 @@ func (j *JwtManager) Verify(token string) (*TokenData, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid token: expiresAt claim missing or wrong type")
 	}
+	policies, err := stringsClaim(claims, "policies")
+	if err != nil {
+		return nil, err
+	}
+	apps, err := stringsClaim(claims, "apps")
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := claims["jti"]; ok {
+		id, ok := raw.(string)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid token: jti claim wrong type")
+		}
+		d.Id = id
+	}
+	d.Apps = apps
+	d.Policies = policies
 	d.User = user
 	d.Role = role
 	d.ExpiresAt = time.Unix(int64(expiresAtRaw), 0)
 	if d.ExpiresAt.UTC().Before(time.Now().UTC()) {
 		return nil, fmt.Errorf("token is expired")
 	}
+	if d.Id != "" && j.revoked != nil && j.revoked(d.Id) {
+		return nil, fmt.Errorf("token is revoked")
+	}
 	return d, nil
 }
+
+// stringsClaim reads an optional list-of-strings claim.
+func stringsClaim(claims jwt.MapClaims, name string) ([]string, error) {
+	raw, ok := claims[name]
+	if !ok {
+		return nil, nil
+	}
+
+	vs, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid token: %s claim wrong type", name)
+	}
+
+	ss := []string{}
+
+	for _, v := range vs {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid token: %s claim wrong type", name)
+		}
+		ss = append(ss, s)
+	}
+
+	return ss, nil
+}
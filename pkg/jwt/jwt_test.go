@@ -164,3 +164,69 @@ func TestVerify_ClaimsWrongType_ReturnsErr(t *testing.T) {
 	assert.Error(t, verr3, "wrong-type expiresAt claim must surface error")
 	assert.Nil(t, data3)
 }
+
+func TestJwtTokenPolicies(t *testing.T) {
+	jm := jwt.NewJwtManager("TEST")
+
+	tk, err := jm.WriteToken(time.Hour, "payments", "on-call")
+	assert.NoError(t, err)
+
+	data, err := jm.Verify(tk)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"payments", "on-call"}, data.Policies)
+
+	tk, err = jm.WriteToken(time.Hour)
+	assert.NoError(t, err)
+
+	data, err = jm.Verify(tk)
+	assert.NoError(t, err)
+	assert.Nil(t, data.Policies)
+}
+
+func TestVerify_PoliciesWrongType_ReturnsErr(t *testing.T) {
+	jm := jwt.NewJwtManager("TEST")
+
+	tok := golangjwt.NewWithClaims(golangjwt.SigningMethodHS256, golangjwt.MapClaims{
+		"user":      "user@example.com",
+		"role":      "rw",
+		"policies":  "payments",
+		"expiresAt": float64(time.Now().Add(time.Hour).Unix()),
+	})
+	signed, err := tok.SignedString([]byte("TEST"))
+	assert.NoError(t, err)
+
+	data, verr := jm.Verify(signed)
+	assert.Error(t, verr, "non-list policies claim must surface error")
+	assert.Nil(t, data)
+}
+
+func TestJwtSystemToken(t *testing.T) {
+	jm := jwt.NewJwtManager("TEST")
+
+	revoked := map[string]bool{}
+	jm.SetRevocationCheck(func(id string) bool { return revoked[id] })
+
+	tk, err := jm.SystemToken(&structs.SystemToken{
+		Id:       "TABCDEFGHIJ",
+		Name:     "ci",
+		Role:     structs.ConvoxRoleReadWrite,
+		Apps:     []string{"app1"},
+		Policies: []string{"payments"},
+		Expires:  time.Now().Add(time.Hour),
+	})
+	assert.NoError(t, err)
+
+	data, err := jm.Verify(tk)
+	assert.NoError(t, err)
+	assert.Equal(t, "TABCDEFGHIJ", data.Id)
+	assert.Equal(t, "token:ci", data.User)
+	assert.Equal(t, structs.ConvoxRoleReadWrite, data.Role)
+	assert.Equal(t, []string{"app1"}, data.Apps)
+	assert.Equal(t, []string{"payments"}, data.Policies)
+
+	revoked["TABCDEFGHIJ"] = true
+
+	data, err = jm.Verify(tk)
+	assert.EqualError(t, err, "token is revoked")
+	assert.Nil(t, data)
+}
@@ -0,0 +1,53 @@
+package logstorage
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Backend stores the logs for each stream. Query results are ordered by
+// timestamp, oldest first.
+type Backend interface {
+	Append(stream string, l Log) error
+	Prune(now time.Time) error
+	Query(stream string, q Query) ([]Log, error)
+}
+
+// Query selects logs by time range and content. Zero values do not filter.
+type Query struct {
+	Start   time.Time
+	End     time.Time
+	Match   string
+	Pattern *regexp.Regexp
+	Limit   int
+}
+
+func (q Query) matches(l Log) bool {
+	if !q.Start.IsZero() && l.Timestamp.Before(q.Start) {
+		return false
+	}
+
+	if !q.End.IsZero() && !l.Timestamp.Before(q.End) {
+		return false
+	}
+
+	if q.Match != "" && !strings.Contains(l.Message, q.Match) {
+		return false
+	}
+
+	if q.Pattern != nil && !q.Pattern.MatchString(l.Message) {
+		return false
+	}
+
+	return true
+}
+
+// limit keeps the most recent logs when a query has a limit.
+func (q Query) limit(ls []Log) []Log {
+	if q.Limit > 0 && len(ls) > q.Limit {
+		return ls[len(ls)-q.Limit:]
+	}
+
+	return ls
+}
@@ -0,0 +1,451 @@
+package logstorage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultSegmentAge   = time.Hour
+	defaultSegmentBytes = 8 * 1024 * 1024
+	maxLineBytes        = 1024 * 1024
+	maxMessageBytes     = 256 * 1024
+)
+
+type DiskOptions struct {
+	// MaxBytes caps the total size of all streams; the oldest segments are
+	// removed first. Zero means no cap.
+	MaxBytes int64
+
+	// Retention is how long logs are kept. Zero keeps logs until MaxBytes
+	// forces them out.
+	Retention time.Duration
+
+	// SegmentAge and SegmentBytes control when a stream starts a new segment
+	// file, which is the unit retention and size caps remove.
+	SegmentAge   time.Duration
+	SegmentBytes int64
+}
+
+// Disk stores each stream as a directory of append-only segment files of
+// json lines. The time range of every segment is kept in an in memory index,
+// rebuilt from disk on startup, so queries only read the segments that overlap
+// the requested range.
+type Disk struct {
+	dir     string
+	opts    DiskOptions
+	lock    sync.Mutex
+	streams map[string]*diskStream
+}
+
+type diskStream struct {
+	dir      string
+	segments []*diskSegment
+
+	// active is the open file of the last segment
+	active *os.File
+	path   string
+}
+
+type diskSegment struct {
+	path    string
+	created time.Time
+	first   time.Time
+	last    time.Time
+	size    int64
+}
+
+type diskLine struct {
+	Timestamp time.Time `json:"t"`
+	Prefix    string    `json:"p,omitempty"`
+	Message   string    `json:"m"`
+	Seq       uint64    `json:"s,omitempty"`
+}
+
+// NewDisk opens (or creates) the log store in dir and rebuilds its index from
+// the segments already on disk.
+func NewDisk(dir string, opts DiskOptions) (*Disk, error) {
+	if opts.SegmentAge <= 0 {
+		opts.SegmentAge = defaultSegmentAge
+	}
+
+	if opts.SegmentBytes <= 0 {
+		opts.SegmentBytes = defaultSegmentBytes
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	d := &Disk{dir: dir, opts: opts, streams: map[string]*diskStream{}}
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *Disk) Append(stream string, l Log) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	ds, err := d.stream(stream)
+	if err != nil {
+		return err
+	}
+
+	if len(l.Message) > maxMessageBytes {
+		l.Message = l.Message[:maxMessageBytes]
+	}
+
+	data, err := json.Marshal(diskLine{Timestamp: l.Timestamp, Prefix: l.Prefix, Message: l.Message, Seq: l.Seq})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	data = append(data, '\n')
+
+	seg, err := d.activeSegment(ds, int64(len(data)))
+	if err != nil {
+		return err
+	}
+
+	fd, err := ds.file(seg)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fd.Write(data); err != nil {
+		return errors.WithStack(err)
+	}
+
+	seg.size += int64(len(data))
+
+	if seg.first.IsZero() || l.Timestamp.Before(seg.first) {
+		seg.first = l.Timestamp
+	}
+
+	if l.Timestamp.After(seg.last) {
+		seg.last = l.Timestamp
+	}
+
+	return nil
+}
+
+func (d *Disk) Prune(now time.Time) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.opts.Retention > 0 {
+		cutoff := now.Add(-d.opts.Retention)
+
+		for _, ds := range d.streams {
+			keep := []*diskSegment{}
+
+			for _, seg := range ds.segments {
+				if seg.last.Before(cutoff) && seg.created.Before(cutoff) {
+					if err := removeSegment(seg); err != nil {
+						return err
+					}
+					continue
+				}
+				keep = append(keep, seg)
+			}
+
+			ds.segments = keep
+		}
+	}
+
+	if d.opts.MaxBytes > 0 {
+		all := []*diskSegment{}
+		total := int64(0)
+
+		for _, ds := range d.streams {
+			all = append(all, ds.segments...)
+
+			for _, seg := range ds.segments {
+				total += seg.size
+			}
+		}
+
+		sort.Slice(all, func(i, j int) bool { return all[i].last.Before(all[j].last) })
+
+		removed := map[*diskSegment]bool{}
+
+		for _, seg := range all {
+			if total <= d.opts.MaxBytes {
+				break
+			}
+
+			if err := removeSegment(seg); err != nil {
+				return err
+			}
+
+			total -= seg.size
+			removed[seg] = true
+		}
+
+		for _, ds := range d.streams {
+			keep := []*diskSegment{}
+
+			for _, seg := range ds.segments {
+				if !removed[seg] {
+					keep = append(keep, seg)
+				}
+			}
+
+			ds.segments = keep
+		}
+	}
+
+	for name, ds := range d.streams {
+		if n := len(ds.segments); n == 0 || ds.segments[n-1].path != ds.path {
+			ds.close()
+		}
+
+		if len(ds.segments) == 0 {
+			os.Remove(ds.dir)
+			delete(d.streams, name)
+		}
+	}
+
+	return nil
+}
+
+// Query reads the segments that overlap the time range of the query. The
+// segments are read without holding the index so that appends are not held
+// up by a large query; a line that is being appended is skipped as torn.
+func (d *Disk) Query(stream string, q Query) ([]Log, error) {
+	ls := []Log{}
+
+	paths := []string{}
+
+	d.lock.Lock()
+
+	if ds, ok := d.streams[stream]; ok {
+		for _, seg := range ds.segments {
+			if !q.Start.IsZero() && seg.last.Before(q.Start) {
+				continue
+			}
+
+			if !q.End.IsZero() && !seg.first.Before(q.End) {
+				continue
+			}
+
+			paths = append(paths, seg.path)
+		}
+	}
+
+	d.lock.Unlock()
+
+	for _, path := range paths {
+		err := readSegment(path, func(l Log) {
+			if q.matches(l) {
+				ls = append(ls, l)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(ls, func(i, j int) bool { return ls[i].Timestamp.Before(ls[j].Timestamp) })
+
+	return q.limit(ls), nil
+}
+
+// activeSegment returns the segment to append size bytes to, starting a new
+// segment when the current one is too old or too large.
+func (d *Disk) activeSegment(ds *diskStream, size int64) (*diskSegment, error) {
+	now := time.Now().UTC()
+
+	if n := len(ds.segments); n > 0 {
+		seg := ds.segments[n-1]
+
+		if now.Sub(seg.created) < d.opts.SegmentAge && seg.size+size <= d.opts.SegmentBytes {
+			return seg, nil
+		}
+	}
+
+	if err := os.MkdirAll(ds.dir, 0700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	seg := &diskSegment{
+		path:    filepath.Join(ds.dir, fmt.Sprintf("%020d.log", now.UnixNano())),
+		created: now,
+	}
+
+	ds.segments = append(ds.segments, seg)
+
+	return seg, nil
+}
+
+func (d *Disk) stream(name string) (*diskStream, error) {
+	if ds, ok := d.streams[name]; ok {
+		return ds, nil
+	}
+
+	escaped := url.PathEscape(name)
+
+	if escaped == "" || escaped == "." || escaped == ".." {
+		return nil, errors.WithStack(fmt.Errorf("invalid stream name: %q", name))
+	}
+
+	ds := &diskStream{dir: filepath.Join(d.dir, escaped)}
+
+	d.streams[name] = ds
+
+	return ds, nil
+}
+
+func (d *Disk) load() error {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		name, err := url.PathUnescape(e.Name())
+		if err != nil {
+			continue
+		}
+
+		ds := &diskStream{dir: filepath.Join(d.dir, e.Name())}
+
+		files, err := os.ReadDir(ds.dir)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		for _, f := range files {
+			seg, err := loadSegment(filepath.Join(ds.dir, f.Name()))
+			if err != nil {
+				return err
+			}
+			if seg != nil {
+				ds.segments = append(ds.segments, seg)
+			}
+		}
+
+		sort.Slice(ds.segments, func(i, j int) bool { return ds.segments[i].created.Before(ds.segments[j].created) })
+
+		d.streams[name] = ds
+	}
+
+	return nil
+}
+
+func loadSegment(path string) (*diskSegment, error) {
+	base := filepath.Base(path)
+
+	if !strings.HasSuffix(base, ".log") {
+		return nil, nil
+	}
+
+	ns, err := strconv.ParseInt(strings.TrimSuffix(base, ".log"), 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	seg := &diskSegment{path: path, created: time.Unix(0, ns).UTC(), size: fi.Size()}
+
+	err = readSegment(path, func(l Log) {
+		if seg.first.IsZero() || l.Timestamp.Before(seg.first) {
+			seg.first = l.Timestamp
+		}
+		if l.Timestamp.After(seg.last) {
+			seg.last = l.Timestamp
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return seg, nil
+}
+
+func readSegment(path string, fn func(Log)) error {
+	fd, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fd.Close()
+
+	s := bufio.NewScanner(fd)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	for s.Scan() {
+		var dl diskLine
+
+		// a torn write at the end of a segment is skipped rather than
+		// making the whole segment unreadable
+		if err := json.Unmarshal(s.Bytes(), &dl); err != nil {
+			continue
+		}
+
+		fn(Log{Timestamp: dl.Timestamp, Prefix: dl.Prefix, Message: dl.Message, Seq: dl.Seq})
+	}
+
+	return errors.WithStack(s.Err())
+}
+
+// file returns the open file of a segment, closing the file of the previous
+// segment when the stream has moved on.
+func (ds *diskStream) file(seg *diskSegment) (*os.File, error) {
+	if ds.active != nil && ds.path == seg.path {
+		return ds.active, nil
+	}
+
+	ds.close()
+
+	fd, err := os.OpenFile(seg.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	ds.active = fd
+	ds.path = seg.path
+
+	return fd, nil
+}
+
+func (ds *diskStream) close() {
+	if ds.active != nil {
+		ds.active.Close()
+		ds.active = nil
+		ds.path = ""
+	}
+}
+
+func removeSegment(seg *diskSegment) error {
+	if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package logstorage_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/logstorage"
+	"github.com/stretchr/testify/require"
+)
+
+func messages(ls []logstorage.Log) []string {
+	ms := []string{}
+
+	for _, l := range ls {
+		ms = append(ms, l.Message)
+	}
+
+	return ms
+}
+
+func TestDiskQuery(t *testing.T) {
+	d, err := logstorage.NewDisk(t.TempDir(), logstorage.DiskOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, d.Append("app1/web", logstorage.Log{Timestamp: time2, Prefix: "p2", Message: "GET /health 200"}))
+	require.NoError(t, d.Append("app1/web", logstorage.Log{Timestamp: time1, Prefix: "p1", Message: "starting"}))
+	require.NoError(t, d.Append("app1/web", logstorage.Log{Timestamp: time3, Prefix: "p3", Message: "GET /users 500"}))
+	require.NoError(t, d.Append("app1/worker", logstorage.Log{Timestamp: time2, Message: "job done"}))
+
+	ls, err := d.Query("app1/web", logstorage.Query{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"starting", "GET /health 200", "GET /users 500"}, messages(ls))
+	require.Equal(t, "p1", ls[0].Prefix)
+	require.True(t, time1.Equal(ls[0].Timestamp))
+
+	ls, err = d.Query("app1/web", logstorage.Query{Start: time2, End: time3})
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /health 200"}, messages(ls))
+
+	ls, err = d.Query("app1/web", logstorage.Query{Match: "GET"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /health 200", "GET /users 500"}, messages(ls))
+
+	ls, err = d.Query("app1/web", logstorage.Query{Pattern: regexp.MustCompile(` 5\d\d$`)})
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /users 500"}, messages(ls))
+
+	ls, err = d.Query("app1/web", logstorage.Query{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"GET /health 200", "GET /users 500"}, messages(ls))
+
+	ls, err = d.Query("missing", logstorage.Query{})
+	require.NoError(t, err)
+	require.Empty(t, ls)
+}
+
+func TestDiskReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	d, err := logstorage.NewDisk(dir, logstorage.DiskOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, d.Append("app1/service/web/pid1", logstorage.Log{Timestamp: time1, Message: "one"}))
+	require.NoError(t, d.Append("app1/service/web/pid1", logstorage.Log{Timestamp: time2, Message: "two"}))
+
+	d, err = logstorage.NewDisk(dir, logstorage.DiskOptions{})
+	require.NoError(t, err)
+
+	ls, err := d.Query("app1/service/web/pid1", logstorage.Query{Start: time2})
+	require.NoError(t, err)
+	require.Equal(t, []string{"two"}, messages(ls))
+}
+
+func TestDiskInvalidStream(t *testing.T) {
+	d, err := logstorage.NewDisk(t.TempDir(), logstorage.DiskOptions{})
+	require.NoError(t, err)
+
+	require.EqualError(t, d.Append("..", logstorage.Log{Timestamp: time1, Message: "one"}), `invalid stream name: ".."`)
+}
+
+func TestDiskPruneRetention(t *testing.T) {
+	dir := t.TempDir()
+
+	d, err := logstorage.NewDisk(dir, logstorage.DiskOptions{Retention: time.Hour, SegmentAge: time.Nanosecond})
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+
+	require.NoError(t, d.Append("app1", logstorage.Log{Timestamp: now.Add(-2 * time.Hour), Message: "old"}))
+	time.Sleep(time.Millisecond)
+	require.NoError(t, d.Append("app1", logstorage.Log{Timestamp: now, Message: "new"}))
+
+	// segments are only removed once both their logs and the segment itself
+	// have aged out, so late arriving logs are kept for the full retention
+	require.NoError(t, d.Prune(now))
+
+	ls, err := d.Query("app1", logstorage.Query{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"old", "new"}, messages(ls))
+
+	require.NoError(t, d.Prune(now.Add(2*time.Hour)))
+
+	ls, err = d.Query("app1", logstorage.Query{})
+	require.NoError(t, err)
+	require.Empty(t, ls)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestDiskPruneSize(t *testing.T) {
+	dir := t.TempDir()
+
+	d, err := logstorage.NewDisk(dir, logstorage.DiskOptions{MaxBytes: 300, SegmentBytes: 100})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		ts := time1.Add(time.Duration(i) * time.Second)
+		require.NoError(t, d.Append("app1", logstorage.Log{Timestamp: ts, Message: fmt.Sprintf("message %d", i)}))
+	}
+
+	require.NoError(t, d.Prune(time.Now()))
+
+	ls, err := d.Query("app1", logstorage.Query{})
+	require.NoError(t, err)
+	require.NotEmpty(t, ls)
+	require.Equal(t, "message 9", ls[len(ls)-1].Message)
+	require.NotEqual(t, "message 0", ls[0].Message)
+
+	size := int64(0)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(path, ".log") {
+			size += info.Size()
+		}
+		return err
+	})
+	require.NoError(t, err)
+	require.LessOrEqual(t, size, int64(300))
+}
+
+func TestStoreDiskSubscribe(t *testing.T) {
+	d, err := logstorage.NewDisk(t.TempDir(), logstorage.DiskOptions{})
+	require.NoError(t, err)
+
+	s := logstorage.NewWithBackend(d)
+
+	s.Append("foo", time1, "p1", "one")
+	s.Append("foo", time2, "p2", "two")
+
+	ch := make(chan logstorage.Log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.Subscribe(ctx, ch, "foo", time2, false)
+
+	log, ok := <-ch
+	require.True(t, ok)
+	require.Equal(t, "two", log.Message)
+
+	_, ok = <-ch
+	require.False(t, ok)
+
+	ch = make(chan logstorage.Log)
+
+	s.SubscribeQuery(ctx, ch, "foo", logstorage.Query{Pattern: regexp.MustCompile(`^o`)}, false)
+
+	log, ok = <-ch
+	require.True(t, ok)
+	require.Equal(t, "one", log.Message)
+
+	_, ok = <-ch
+	require.False(t, ok)
+}
+
+func TestStoreDiskSubscribeFollow(t *testing.T) {
+	d, err := logstorage.NewDisk(t.TempDir(), logstorage.DiskOptions{})
+	require.NoError(t, err)
+
+	s := logstorage.NewWithBackend(d)
+
+	s.Append("foo", time1, "p1", "one")
+
+	ch := make(chan logstorage.Log, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.SubscribeQuery(ctx, ch, "foo", logstorage.Query{Start: time1, Match: "o"}, true)
+
+	s.Append("foo", time2, "p2", "two")
+	s.Append("foo", time3, "p3", "three")
+
+	// history first, then each live log once
+	require.Equal(t, "one", (<-ch).Message)
+	require.Equal(t, "two", (<-ch).Message)
+
+	select {
+	case l := <-ch:
+		t.Fatalf("unexpected log: %s", l.Message)
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func TestStoreDiskRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	d, err := logstorage.NewDisk(dir, logstorage.DiskOptions{})
+	require.NoError(t, err)
+
+	logstorage.NewWithBackend(d).Append("foo", time1, "p1", "one")
+
+	d, err = logstorage.NewDisk(dir, logstorage.DiskOptions{})
+	require.NoError(t, err)
+
+	s := logstorage.NewWithBackend(d)
+
+	ch := make(chan logstorage.Log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.Subscribe(ctx, ch, "foo", time1, false)
+
+	log, ok := <-ch
+	require.True(t, ok)
+	require.Equal(t, "one", log.Message)
+}
@@ -4,21 +4,32 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
-	"sort"
 	"sync"
 	"time"
 )
 
+const (
+	cleanInterval   = 30 * time.Second
+	memoryRetention = 30 * time.Second
+)
+
+// Store fans logs out to live subscribers and keeps them in a Backend so
+// that subscribers can also replay history from a start time.
 type Store struct {
+	backend       Backend
 	lock          sync.Mutex
-	streams       map[string][]Log
+	seq           uint64
 	subscriptions subscriptions
 }
 
+// Log is a single line of a stream. Seq orders the logs appended to a Store
+// so that a subscriber can tell the history it read from the live logs it
+// was sent.
 type Log struct {
 	Prefix    string
 	Message   string
 	Timestamp time.Time
+	Seq       uint64
 }
 
 type Receiver chan Log
@@ -27,8 +38,16 @@ func init() {
 	rand.Seed(time.Now().UTC().UnixNano())
 }
 
-func New() Store {
-	s := Store{streams: map[string][]Log{}}
+// New returns a Store that keeps recent logs in memory, suitable only for
+// live tailing.
+func New() *Store {
+	return NewWithBackend(NewMemory(memoryRetention))
+}
+
+func NewWithBackend(b Backend) *Store {
+	// sequences start from the clock so they stay above those a backend kept
+	// from before a restart
+	s := &Store{backend: b, seq: uint64(time.Now().UnixNano())}
 
 	go s.startCleaner()
 
@@ -39,56 +58,64 @@ func (s *Store) Append(stream string, ts time.Time, prefix, message string) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	log := Log{Message: message, Prefix: prefix, Timestamp: ts}
-
-	ls, ok := s.streams[stream]
-	if !ok {
-		ls = []Log{}
-	}
+	s.seq++
 
-	n := sort.Search(len(ls), func(i int) bool { return ls[i].Timestamp.After(ts) })
+	log := Log{Message: message, Prefix: prefix, Timestamp: ts, Seq: s.seq}
 
-	ls = append(ls, Log{})
-	copy(ls[n+1:], ls[n:])
-	ls[n] = log
-
-	s.streams[stream] = ls
+	if err := s.backend.Append(stream, log); err != nil {
+		fmt.Printf("ns=logstorage at=append stream=%q error=%q\n", stream, err)
+	}
 
 	s.subscriptions.send(stream, log)
 }
 
 func (s *Store) Subscribe(ctx context.Context, ch Receiver, stream string, start time.Time, follow bool) {
+	s.SubscribeQuery(ctx, ch, stream, Query{Start: start}, follow)
+}
+
+// SubscribeQuery sends the stored logs of a stream that match the query and,
+// when following, the matching logs appended after them. The history is read
+// without holding up appends; logs appended while it is read are sent once,
+// as live logs.
+func (s *Store) SubscribeQuery(ctx context.Context, ch Receiver, stream string, q Query, follow bool) {
 	s.lock.Lock()
-	defer s.lock.Unlock()
 
-	if ls, ok := s.streams[stream]; ok {
-		n := sort.Search(len(ls), func(i int) bool { return !ls[i].Timestamp.Before(start) })
-		go sendMultiple(ch, ls[n:], func() {
-			if !follow {
-				close(ch)
-			}
-		})
-	}
+	seq := s.seq
+
+	var sub *subscription
 
 	if follow {
-		s.subscriptions.Subscribe(ctx, ch, stream, start)
+		sub = s.subscriptions.add(ctx, ch, stream, q)
 	}
-}
 
-func (s *Store) cleanupLogs() {
-	s.lock.Lock()
-	defer s.lock.Unlock()
+	s.lock.Unlock()
 
-	for name := range s.streams {
-		ls := s.streams[name]
-		n := sort.Search(len(ls), func(i int) bool { return ls[i].Timestamp.After(time.Now().Add(30 * time.Second)) })
-		s.streams[name] = ls[n:]
+	ls, err := s.backend.Query(stream, q)
+	if err != nil {
+		fmt.Printf("ns=logstorage at=subscribe stream=%q error=%q\n", stream, err)
 	}
+
+	history := []Log{}
+
+	for _, l := range ls {
+		if l.Seq <= seq {
+			history = append(history, l)
+		}
+	}
+
+	if follow {
+		sub.start(history)
+		return
+	}
+
+	go sendMultiple(ch, history, func() { close(ch) })
 }
 
 func (s *Store) startCleaner() {
-	for range time.Tick(30 * time.Second) {
-		s.cleanupLogs()
+	for range time.Tick(cleanInterval) {
+		if err := s.backend.Prune(time.Now().UTC()); err != nil {
+			fmt.Printf("ns=logstorage at=prune error=%q\n", err)
+		}
 	}
 }
 
@@ -97,18 +124,17 @@ type subscriptions struct {
 	subscriptions map[string]map[string]*subscription
 }
 
+// subscription queues the live logs for a follower. It is held until the
+// history has been read so that the history is sent first.
 type subscription struct {
 	ch    Receiver
 	lock  sync.Mutex
+	held  bool
 	queue []Log
-	start time.Time
+	query Query
 }
 
-func (s *subscriptions) Subscribe(ctx context.Context, ch Receiver, stream string, start time.Time) {
-	s.add(ctx, ch, stream, start)
-}
-
-func (s *subscriptions) add(ctx context.Context, ch Receiver, stream string, start time.Time) {
+func (s *subscriptions) add(ctx context.Context, ch Receiver, stream string, q Query) *subscription {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -122,9 +148,13 @@ func (s *subscriptions) add(ctx context.Context, ch Receiver, stream string, sta
 
 	handle := fmt.Sprintf("%v:%d", ch, rand.Int63())
 
-	s.subscriptions[stream][handle] = &subscription{ch: ch, start: start}
+	sub := &subscription{ch: ch, held: true, query: q}
+
+	s.subscriptions[stream][handle] = sub
+
+	go s.watch(ctx, stream, handle, sub)
 
-	go s.watch(ctx, stream, handle)
+	return sub
 }
 
 func (s *subscriptions) remove(stream, handle string) {
@@ -147,13 +177,13 @@ func (s *subscriptions) send(stream string, l Log) {
 	}
 
 	for _, sub := range s.subscriptions[stream] {
-		if !sub.start.After(l.Timestamp) {
+		if sub.query.matches(l) {
 			sub.add(l)
 		}
 	}
 }
 
-func (s *subscriptions) watch(ctx context.Context, stream, handle string) {
+func (s *subscriptions) watch(ctx context.Context, stream, handle string, sub *subscription) {
 	defer s.remove(stream, handle)
 
 	tick := time.NewTicker(100 * time.Millisecond)
@@ -164,11 +194,7 @@ func (s *subscriptions) watch(ctx context.Context, stream, handle string) {
 		case <-ctx.Done():
 			return
 		case <-tick.C:
-			if ss, ok := s.subscriptions[stream]; ok {
-				if sub, ok := ss[handle]; ok && len(sub.queue) > 0 {
-					sub.flush()
-				}
-			}
+			sub.flush(ctx)
 		}
 	}
 }
@@ -179,15 +205,38 @@ func (s *subscription) add(l Log) {
 	s.queue = append(s.queue, l)
 }
 
-func (s *subscription) flush() {
+// start queues the history ahead of the live logs and releases the
+// subscription.
+func (s *subscription) start(history []Log) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	for _, l := range s.queue {
-		s.ch <- l
+	s.queue = append(history, s.queue...)
+	s.held = false
+}
+
+// flush sends the queued logs without holding the queue so that a slow
+// reader does not hold up appends.
+func (s *subscription) flush(ctx context.Context) {
+	s.lock.Lock()
+
+	if s.held {
+		s.lock.Unlock()
+		return
 	}
 
-	s.queue = s.queue[:0]
+	queue := s.queue
+	s.queue = nil
+
+	s.lock.Unlock()
+
+	for _, l := range queue {
+		select {
+		case <-ctx.Done():
+			return
+		case s.ch <- l:
+		}
+	}
 }
 
 func sendMultiple(ch Receiver, ls []Log, done func()) {
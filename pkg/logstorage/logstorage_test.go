@@ -86,3 +86,31 @@ func TestFollow(t *testing.T) {
 	require.Equal(t, "p1", log.Prefix)
 	require.Equal(t, "one", log.Message)
 }
+
+func TestNoFollowEmpty(t *testing.T) {
+	s := logstorage.New()
+
+	ch := make(chan logstorage.Log)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.Subscribe(ctx, ch, "missing", time1, false)
+
+	_, ok := <-ch
+	require.False(t, ok)
+}
+
+func TestMemoryPrune(t *testing.T) {
+	m := logstorage.NewMemory(time.Minute)
+
+	require.NoError(t, m.Append("foo", logstorage.Log{Timestamp: time1, Message: "one"}))
+	require.NoError(t, m.Append("foo", logstorage.Log{Timestamp: time3, Message: "three"}))
+
+	require.NoError(t, m.Prune(time2.Add(30*time.Second)))
+
+	ls, err := m.Query("foo", logstorage.Query{})
+	require.NoError(t, err)
+	require.Len(t, ls, 1)
+	require.Equal(t, "three", ls[0].Message)
+}
@@ -0,0 +1,77 @@
+package logstorage
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory keeps logs in memory for a fixed retention.
+type Memory struct {
+	lock      sync.Mutex
+	retention time.Duration
+	streams   map[string][]Log
+}
+
+func NewMemory(retention time.Duration) *Memory {
+	return &Memory{retention: retention, streams: map[string][]Log{}}
+}
+
+func (m *Memory) Append(stream string, l Log) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	ls := m.streams[stream]
+
+	n := sort.Search(len(ls), func(i int) bool { return ls[i].Timestamp.After(l.Timestamp) })
+
+	ls = append(ls, Log{})
+	copy(ls[n+1:], ls[n:])
+	ls[n] = l
+
+	m.streams[stream] = ls
+
+	return nil
+}
+
+func (m *Memory) Prune(now time.Time) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	cutoff := now.Add(-m.retention)
+
+	for name, ls := range m.streams {
+		n := sort.Search(len(ls), func(i int) bool { return !ls[i].Timestamp.Before(cutoff) })
+
+		if n == len(ls) {
+			delete(m.streams, name)
+			continue
+		}
+
+		m.streams[name] = ls[n:]
+	}
+
+	return nil
+}
+
+func (m *Memory) Query(stream string, q Query) ([]Log, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	ls := m.streams[stream]
+
+	n := sort.Search(len(ls), func(i int) bool { return !ls[i].Timestamp.Before(q.Start) })
+
+	out := []Log{}
+
+	for _, l := range ls[n:] {
+		if !q.End.IsZero() && !l.Timestamp.Before(q.End) {
+			break
+		}
+		if q.matches(l) {
+			out = append(out, l)
+		}
+	}
+
+	return q.limit(out), nil
+}
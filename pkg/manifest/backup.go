@@ -0,0 +1,156 @@
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBackupRetention is the number of backups kept for a resource when
+// its backup section sets no retention.
+const DefaultBackupRetention = 7
+
+// BackupResourceTypes are the resource types that can be backed up. They
+// run in containers on the rack, managed databases have their own backups.
+var BackupResourceTypes = []string{"mariadb", "mysql", "postgis", "postgres"}
+
+// ResourceBackup schedules backups of a resource to the rack's object
+// storage. The Retention newest backups are kept.
+type ResourceBackup struct {
+	Schedule  string `yaml:"schedule"`
+	Retention int    `yaml:"retention,omitempty"`
+}
+
+// CronSchedule is a five field cron schedule: minute, hour, day of month,
+// month and day of week. Fields take *, numbers, ranges, lists and steps.
+type CronSchedule struct {
+	fields [5]map[int]bool
+	any    [5]bool
+}
+
+var cronFieldRanges = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+
+var cronAliases = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseCronSchedule parses s, a five field cron schedule or one of @hourly,
+// @daily, @midnight, @weekly and @monthly.
+func ParseCronSchedule(s string) (*CronSchedule, error) {
+	spec := strings.TrimSpace(s)
+
+	if a, ok := cronAliases[spec]; ok {
+		spec = a
+	}
+
+	parts := strings.Fields(spec)
+
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("must have 5 fields: minute hour day-of-month month day-of-week")
+	}
+
+	cs := &CronSchedule{}
+
+	for i, p := range parts {
+		values, err := parseCronField(p, cronFieldRanges[i][0], cronFieldRanges[i][1])
+		if err != nil {
+			return nil, err
+		}
+
+		cs.fields[i] = values
+		cs.any[i] = p == "*"
+	}
+
+	// 0 and 7 are both sunday
+	if cs.fields[4][7] {
+		cs.fields[4][0] = true
+	}
+
+	return cs, nil
+}
+
+// Matches reports whether the schedule fires in the minute of t. As in
+// cron, when both day fields are restricted either one matching is enough.
+func (cs CronSchedule) Matches(t time.Time) bool {
+	if !cs.fields[0][t.Minute()] || !cs.fields[1][t.Hour()] || !cs.fields[3][int(t.Month())] {
+		return false
+	}
+
+	dom := cs.fields[2][t.Day()]
+	dow := cs.fields[4][int(t.Weekday())]
+
+	switch {
+	case cs.any[2] && cs.any[4]:
+		return true
+	case cs.any[2]:
+		return dow
+	case cs.any[4]:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := map[int]bool{}
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step: %s", part)
+			}
+			step = n
+			part = part[:i]
+		}
+
+		lo, hi := min, max
+
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+
+			l, err := strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid range: %s", part)
+			}
+
+			h, err := strconv.Atoi(bounds[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid range: %s", part)
+			}
+
+			lo, hi = l, h
+		default:
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value: %s", part)
+			}
+
+			lo, hi = n, n
+
+			// a step on a single value runs from it to the end of the range
+			if step > 1 {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			return nil, fmt.Errorf("%s out of range %d-%d", part, min, max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			values[v] = true
+		}
+	}
+
+	return values, nil
+}
@@ -0,0 +1,48 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// InternalDomain is the domain of the names the rack resolver serves for the
+// internal services of every app: <service>.<app>.convox.internal. The names
+// do not depend on the rack or on namespace naming.
+const InternalDomain = "convox.internal"
+
+var reEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Dependency injects the url of an internal service of another app on the
+// rack into the environment of the app as As.
+type Dependency struct {
+	App     string `yaml:"app"`
+	Service string `yaml:"service"`
+	As      string `yaml:"as,omitempty"`
+}
+
+type Dependencies []Dependency
+
+// Env returns the name of the env var the url is injected as, As or
+// <APP>_<SERVICE>_URL.
+func (d Dependency) Env() string {
+	if d.As != "" {
+		return d.As
+	}
+
+	return strings.ToUpper(strings.ReplaceAll(fmt.Sprintf("%s_%s_URL", d.App, d.Service), "-", "_"))
+}
+
+// Host returns the internal name of the service of the dependency.
+func (d Dependency) Host() string {
+	return InternalHost(d.App, d.Service)
+}
+
+func (d Dependency) String() string {
+	return fmt.Sprintf("%s/%s", d.App, d.Service)
+}
+
+// InternalHost returns the internal name of a service of an app.
+func InternalHost(app, service string) string {
+	return fmt.Sprintf("%s.%s.%s", service, app, InternalDomain)
+}
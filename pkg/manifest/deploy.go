@@ -0,0 +1,154 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	// freeze windows name IANA timezones, embed the zone database so they
+	// resolve the same on the cli and in minimal rack images
+	_ "time/tzdata"
+)
+
+// Deploy is the promote policy of an app. A release needs Approvals
+// approvals from distinct users before it can be promoted, and promotes are
+// refused while any of the Freeze windows is open.
+type Deploy struct {
+	Approvals int      `yaml:"approvals,omitempty"`
+	Freeze    []string `yaml:"freeze,omitempty"`
+}
+
+const week = 7 * 24 * time.Hour
+
+var freezeWindowR = regexp.MustCompile(`^([A-Za-z]+)\s+(\d{1,2}):(\d{2})\s*-\s*([A-Za-z]+)\s+(\d{1,2}):(\d{2})(?:\s+(\S+))?$`)
+
+// FreezeWindow is a weekly window such as "Fri 16:00-Mon 08:00
+// America/New_York" during which promotes are refused. Times are read in
+// Location, which is UTC when the window names no timezone.
+type FreezeWindow struct {
+	Start    time.Duration
+	End      time.Duration
+	Location *time.Location
+
+	spec string
+}
+
+// ParseFreezeWindow parses a window of the form "<day> <hh:mm>-<day> <hh:mm>
+// [timezone]". Days are English weekday names or their three letter
+// abbreviations.
+func ParseFreezeWindow(s string) (*FreezeWindow, error) {
+	spec := strings.TrimSpace(s)
+
+	m := freezeWindowR.FindStringSubmatch(spec)
+	if m == nil {
+		return nil, fmt.Errorf("must be of the form \"Fri 16:00-Mon 08:00 [timezone]\"")
+	}
+
+	start, err := freezeWindowOffset(m[1], m[2], m[3])
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := freezeWindowOffset(m[4], m[5], m[6])
+	if err != nil {
+		return nil, err
+	}
+
+	if start == end {
+		return nil, fmt.Errorf("must not start and end at the same time")
+	}
+
+	loc := time.UTC
+
+	if m[7] != "" {
+		l, err := time.LoadLocation(m[7])
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone: %s", m[7])
+		}
+		loc = l
+	}
+
+	return &FreezeWindow{Start: start, End: end, Location: loc, spec: spec}, nil
+}
+
+// ParseFreezeWindows parses every window in ss.
+func ParseFreezeWindows(ss []string) ([]FreezeWindow, error) {
+	ws := []FreezeWindow{}
+
+	for _, s := range ss {
+		w, err := ParseFreezeWindow(s)
+		if err != nil {
+			return nil, fmt.Errorf("freeze window %q invalid, %s", s, err)
+		}
+
+		ws = append(ws, *w)
+	}
+
+	return ws, nil
+}
+
+// Contains reports whether t falls inside the window.
+func (w FreezeWindow) Contains(t time.Time) bool {
+	off := w.offset(t)
+
+	if w.Start < w.End {
+		return off >= w.Start && off < w.End
+	}
+
+	// the window wraps around the end of the week
+	return off >= w.Start || off < w.End
+}
+
+// Until returns when the window next closes after t.
+func (w FreezeWindow) Until(t time.Time) time.Time {
+	d := w.End - w.offset(t)
+
+	if d <= 0 {
+		d += week
+	}
+
+	return t.Add(d).Truncate(time.Minute)
+}
+
+func (w FreezeWindow) String() string {
+	return w.spec
+}
+
+// offset is the time elapsed since the start of the week (Sunday 00:00) of
+// t in the window's timezone.
+func (w FreezeWindow) offset(t time.Time) time.Duration {
+	lt := t.In(w.Location)
+
+	return time.Duration(lt.Weekday())*24*time.Hour +
+		time.Duration(lt.Hour())*time.Hour +
+		time.Duration(lt.Minute())*time.Minute +
+		time.Duration(lt.Second())*time.Second
+}
+
+func freezeWindowOffset(day, hour, minute string) (time.Duration, error) {
+	wd := -1
+
+	for i := time.Sunday; i <= time.Saturday; i++ {
+		name := strings.ToLower(i.String())
+		d := strings.ToLower(day)
+
+		if d == name || d == name[0:3] {
+			wd = int(i)
+		}
+	}
+
+	if wd < 0 {
+		return 0, fmt.Errorf("unknown day: %s", day)
+	}
+
+	h, _ := strconv.Atoi(hour)
+	m, _ := strconv.Atoi(minute)
+
+	if h > 23 || m > 59 {
+		return 0, fmt.Errorf("invalid time: %s:%s", hour, minute)
+	}
+
+	return time.Duration(wd)*24*time.Hour + time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
@@ -0,0 +1,77 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var reSignal = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// ServiceDevelopment configures how convox start syncs local changes to the
+// processes of a service. When Sync is empty the sync paths are read from
+// the COPY and ADD lines of the service's Dockerfile.
+type ServiceDevelopment struct {
+	Sync     []ServiceDevelopmentSync `yaml:"sync,omitempty"`
+	OnChange OnChange                 `yaml:"onChange,omitempty"`
+}
+
+// ServiceDevelopmentSync copies changes below the local From path to the To
+// path in the container. Ignore patterns are relative to From. Download
+// lists paths relative to From that are written in the container, such as
+// lockfiles, and are copied back to the host when they change there.
+type ServiceDevelopmentSync struct {
+	From     string   `yaml:"from,omitempty"`
+	To       string   `yaml:"to,omitempty"`
+	Ignore   []string `yaml:"ignore,omitempty"`
+	Download []string `yaml:"download,omitempty"`
+}
+
+// OnChange is the action run on each process of a service after files are
+// synced to it: restart, signal:<signal> or command:<command>.
+type OnChange string
+
+const (
+	OnChangeCommand = "command"
+	OnChangeRestart = "restart"
+	OnChangeSignal  = "signal"
+)
+
+// Action returns the kind of the action and its signal or command.
+func (oc OnChange) Action() (string, string, error) {
+	s := strings.TrimSpace(string(oc))
+
+	if s == "" || s == OnChangeRestart {
+		return s, "", nil
+	}
+
+	parts := strings.SplitN(s, ":", 2)
+
+	if len(parts) == 2 {
+		arg := strings.TrimSpace(parts[1])
+
+		switch strings.TrimSpace(parts[0]) {
+		case OnChangeCommand:
+			if arg != "" {
+				return OnChangeCommand, arg, nil
+			}
+		case OnChangeSignal:
+			sig := strings.TrimPrefix(strings.ToUpper(arg), "SIG")
+
+			if reSignal.MatchString(sig) {
+				return OnChangeSignal, sig, nil
+			}
+		}
+	}
+
+	return "", "", fmt.Errorf("must be restart, signal:<signal> or command:<command>")
+}
+
+// SyncFrom returns the local path of the sync, relative to the manifest.
+func (s ServiceDevelopmentSync) SyncFrom() string {
+	if s.From == "" {
+		return "."
+	}
+
+	return s.From
+}
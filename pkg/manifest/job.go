@@ -0,0 +1,58 @@
+package manifest
+
+import "strings"
+
+const (
+	JobHookAfterPromote  = "after-promote"
+	JobHookBeforePromote = "before-promote"
+)
+
+type Job struct {
+	Name        string             `yaml:"-"`
+	Annotations ServiceAnnotations `yaml:"annotations,omitempty"`
+
+	BackoffLimit            int    `yaml:"backoffLimit,omitempty"`
+	Command                 string `yaml:"command,omitempty"`
+	Completions             int    `yaml:"completions,omitempty"`
+	Hook                    string `yaml:"hook,omitempty"`
+	Parallelism             int    `yaml:"parallelism,omitempty"`
+	Service                 string `yaml:"service"`
+	TtlSecondsAfterFinished int    `yaml:"ttlSecondsAfterFinished,omitempty"`
+}
+
+type Jobs []Job
+
+// skipcq
+func (j Job) AnnotationsMap() map[string]string {
+	annotations := map[string]string{}
+
+	for _, a := range j.Annotations {
+		parts := strings.SplitN(a, "=", 2)
+		annotations[parts[0]] = parts[1]
+	}
+
+	return annotations
+}
+
+func (j Job) GetName() string {
+	return j.Name
+}
+
+func (j *Job) SetName(name string) error {
+	j.Name = name
+	return nil
+}
+
+// Hooked returns the jobs that run at the given promote hook, in manifest
+// order. An empty hook returns the jobs that run alongside the rollout.
+func (jj Jobs) Hooked(hook string) Jobs {
+	js := Jobs{}
+
+	for _, j := range jj {
+		if j.Hook == hook {
+			js = append(js, j)
+		}
+	}
+
+	return js
+}
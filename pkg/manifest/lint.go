@@ -0,0 +1,438 @@
+package manifest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// LintError is a problem found in a manifest. Line and Column point at the
+// manifest source when the problem could be located, and are 0 otherwise.
+type LintError struct {
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e LintError) Error() string {
+	return e.Message
+}
+
+type LintErrors []LintError
+
+var (
+	lintLineR       = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	lintValueR      = regexp.MustCompile("`([^`]*)`")
+	lintEntityR     = regexp.MustCompile(`^(balancer|job|resource|service|timer)(?: name)? (\S+)`)
+	lintEntityPaths = map[string]string{
+		"balancer": "balancers",
+		"job":      "jobs",
+		"resource": "resources",
+		"service":  "services",
+		"timer":    "timers",
+	}
+)
+
+// Lint loads data like Load does and reports every problem it finds at
+// once: yaml syntax and type errors, keys the manifest does not know, and
+// validation errors. Required environment variables are only checked when
+// env is not nil.
+func Lint(data []byte, env map[string]string) LintErrors {
+	p, err := interpolate(data, env)
+	if err != nil {
+		return LintErrors{{Message: err.Error()}}
+	}
+
+	var root yamlv3.Node
+
+	if err := yamlv3.Unmarshal(p, &root); err != nil {
+		return lintYamlErrors(err)
+	}
+
+	positions := map[string]*yamlv3.Node{}
+	lintPositions(&root, "", positions)
+
+	errs := LintErrors{}
+
+	if len(root.Content) > 0 {
+		errs = append(errs, lintUnknownKeys(root.Content[0], reflect.TypeOf(Manifest{}), "")...)
+	}
+
+	var m Manifest
+
+	if err := yaml.Unmarshal(p, &m); err != nil {
+		return append(errs, lintTypeErrors(err, &root)...)
+	}
+
+	m.attributes, err = yamlAttributes(p)
+	if err != nil {
+		return append(errs, LintError{Message: err.Error()})
+	}
+
+	m.env = map[string]string{}
+
+	for k, v := range env {
+		m.env[k] = v
+	}
+
+	// keep checking with missing variables set so that one missing variable
+	// does not hide the rest of the problems
+	if missing := m.lintMissingEnv(); len(missing) > 0 {
+		if env != nil {
+			errs = append(errs, LintError{Message: fmt.Sprintf("required env: %s", strings.Join(missing, ", "))})
+		}
+
+		for _, k := range missing {
+			m.env[k] = ""
+		}
+	}
+
+	for _, fn := range []func() error{m.ApplyCompatibility, m.ApplyDefaults, m.CombineEnv, m.CombineLabels} {
+		if err := fn(); err != nil {
+			return append(errs, LintError{Message: err.Error()})
+		}
+	}
+
+	for _, err := range m.validate() {
+		errs = append(errs, m.lintLocate(err.Error(), positions))
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Line == 0 || errs[j].Line == 0 {
+			return errs[i].Line != 0 && errs[j].Line == 0
+		}
+		return errs[i].Line < errs[j].Line
+	})
+
+	return errs
+}
+
+// lintMissingEnv returns the variables the app and its services require
+// that the manifest env does not set.
+func (m *Manifest) lintMissingEnv() []string {
+	missing := map[string]bool{}
+
+	envs := [][]string{m.Environment}
+
+	for _, s := range m.Services {
+		envs = append(envs, s.Environment)
+	}
+
+	for _, e := range envs {
+		for _, k := range e {
+			if strings.Contains(k, "=") || k == "*" {
+				continue
+			}
+
+			if _, ok := m.env[k]; !ok {
+				missing[k] = true
+			}
+		}
+	}
+
+	ks := []string{}
+
+	for k := range missing {
+		ks = append(ks, k)
+	}
+
+	sort.Strings(ks)
+
+	return ks
+}
+
+// lintLocate finds the position of a validation error from the entity its
+// message starts with, narrowed to the deepest attribute of that entity
+// whose key, or its singular, appears in the message.
+func (m *Manifest) lintLocate(msg string, positions map[string]*yamlv3.Node) LintError {
+	le := LintError{Message: msg}
+
+	path := ""
+
+	if match := lintEntityR.FindStringSubmatch(msg); match != nil {
+		path = fmt.Sprintf("%s.%s", lintEntityPaths[match[1]], strings.TrimRight(match[2], ",:"))
+	} else if strings.HasPrefix(msg, "deploy ") {
+		path = "deploy"
+	} else if strings.HasPrefix(msg, "budget") {
+		path = "budget"
+	}
+
+	if _, ok := positions[path]; !ok {
+		return le
+	}
+
+	words := map[string]bool{}
+
+	for _, w := range strings.FieldsFunc(msg, func(r rune) bool { return r == ' ' || r == ',' || r == ':' || r == '.' }) {
+		words[w] = true
+	}
+
+	best := path
+
+	for _, a := range m.AttributesByPrefix(path + ".") {
+		parts := strings.Split(strings.TrimPrefix(a, path+"."), ".")
+
+		found := true
+
+		for _, part := range parts {
+			if !words[part] && !words[strings.TrimSuffix(part, "s")] {
+				found = false
+				break
+			}
+		}
+
+		if found && strings.Count(a, ".") > strings.Count(best, ".") {
+			best = a
+		}
+	}
+
+	le.Path = best
+
+	if n := positions[best]; n != nil {
+		le.Line = n.Line
+		le.Column = n.Column
+	}
+
+	return le
+}
+
+// lintPositions records the key node of every attribute of n, keyed like
+// the attributes of a Manifest.
+func lintPositions(n *yamlv3.Node, prefix string, positions map[string]*yamlv3.Node) {
+	switch n.Kind {
+	case yamlv3.DocumentNode:
+		for _, c := range n.Content {
+			lintPositions(c, prefix, positions)
+		}
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if lintMergeKey(n.Content[i]) {
+				continue
+			}
+
+			path := lintJoin(prefix, n.Content[i].Value)
+			positions[path] = n.Content[i]
+			lintPositions(n.Content[i+1], path, positions)
+		}
+	}
+}
+
+// lintUnknownKeys walks n alongside t, the type it unmarshals into, and
+// reports mapping keys that t has no field for. Values a type parses on its
+// own, such as scalars and sequences of strings, are not checked.
+func lintUnknownKeys(n *yamlv3.Node, t reflect.Type, prefix string) LintErrors {
+	errs := LintErrors{}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if n.Kind == yamlv3.AliasNode {
+		n = n.Alias
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yamlv3.MappingNode {
+			return errs
+		}
+
+		fields := lintFields(t)
+
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			path := lintJoin(prefix, k.Value)
+
+			// merged mappings are checked against the type they are merged into
+			if lintMergeKey(k) {
+				errs = append(errs, lintUnknownKeys(v, t, prefix)...)
+				continue
+			}
+
+			ft, ok := fields[k.Value]
+			if !ok {
+				errs = append(errs, LintError{Line: k.Line, Column: k.Column, Path: path, Message: fmt.Sprintf("unknown key: %s", path)})
+				continue
+			}
+
+			errs = append(errs, lintUnknownKeys(v, ft, path)...)
+		}
+	case reflect.Map, reflect.Slice, reflect.Array:
+		switch n.Kind {
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				errs = append(errs, lintUnknownKeys(n.Content[i+1], t.Elem(), lintJoin(prefix, n.Content[i].Value))...)
+			}
+		case yamlv3.SequenceNode:
+			for i, c := range n.Content {
+				errs = append(errs, lintUnknownKeys(c, t.Elem(), lintJoin(prefix, strconv.Itoa(i)))...)
+			}
+		}
+	}
+
+	return errs
+}
+
+// lintFields returns the keys a struct accepts. Fields without a yaml tag
+// are known by their json name, as kubernetes types are, and by their
+// lowercased name, as yaml names them.
+func lintFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		yname, yopts := lintTag(f.Tag.Get("yaml"))
+		jname, jopts := lintTag(f.Tag.Get("json"))
+
+		if yname == "-" {
+			continue
+		}
+
+		if strings.Contains(yopts, "inline") || strings.Contains(jopts, "inline") || (f.Anonymous && yname == "" && jname == "") {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for k, v := range lintFields(ft) {
+					fields[k] = v
+				}
+			}
+			continue
+		}
+
+		if yname != "" {
+			fields[yname] = f.Type
+			continue
+		}
+
+		if jname != "" && jname != "-" {
+			fields[jname] = f.Type
+		}
+
+		fields[strings.ToLower(f.Name)] = f.Type
+	}
+
+	return fields
+}
+
+// lintMergeKey reports whether k merges another mapping in. The yaml parser
+// manifests are loaded with treats a quoted << as a merge key too.
+func lintMergeKey(k *yamlv3.Node) bool {
+	return k.Tag == "!!merge" || k.Value == "<<"
+}
+
+func lintTag(tag string) (string, string) {
+	parts := strings.SplitN(tag, ",", 2)
+
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+func lintJoin(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", prefix, key)
+}
+
+// lintTypeErrors splits a yaml error from unmarshaling a Manifest. Custom
+// unmarshalers parse parts of the manifest on their own so the lines yaml
+// reports are not reliable, and each error is located by its value instead
+// when only one value in the manifest matches.
+func lintTypeErrors(err error, root *yamlv3.Node) LintErrors {
+	errs := lintYamlErrors(err)
+
+	for i := range errs {
+		errs[i].Line = 0
+
+		m := lintValueR.FindStringSubmatch(errs[i].Message)
+		if m == nil {
+			continue
+		}
+
+		found := map[string]*yamlv3.Node{}
+		lintValues(root, "", m[1], found)
+
+		if len(found) != 1 {
+			continue
+		}
+
+		for path, n := range found {
+			errs[i].Path = path
+			errs[i].Line = n.Line
+			errs[i].Column = n.Column
+		}
+	}
+
+	return errs
+}
+
+// lintValues records the scalar values of n that equal value by path.
+func lintValues(n *yamlv3.Node, path, value string, found map[string]*yamlv3.Node) {
+	switch n.Kind {
+	case yamlv3.DocumentNode:
+		for _, c := range n.Content {
+			lintValues(c, path, value, found)
+		}
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			lintValues(n.Content[i+1], lintJoin(path, n.Content[i].Value), value, found)
+		}
+	case yamlv3.SequenceNode:
+		for i, c := range n.Content {
+			lintValues(c, lintJoin(path, strconv.Itoa(i)), value, found)
+		}
+	case yamlv3.ScalarNode:
+		if n.Value == value {
+			found[path] = n
+		}
+	}
+}
+
+// lintYamlErrors splits a yaml error into one error per problem, with the
+// line yaml reports for it.
+func lintYamlErrors(err error) LintErrors {
+	errs := LintErrors{}
+
+	msg := err.Error()
+
+	lines := []string{msg}
+
+	if te, ok := err.(*yaml.TypeError); ok {
+		lines = te.Errors
+	}
+
+	if te, ok := err.(*yamlv3.TypeError); ok {
+		lines = te.Errors
+	}
+
+	for _, l := range lines {
+		le := LintError{Message: strings.TrimSpace(l)}
+
+		if m := lintLineR.FindStringSubmatch(le.Message); m != nil {
+			le.Line, _ = strconv.Atoi(m[1])
+			le.Message = m[2]
+		}
+
+		errs = append(errs, le)
+	}
+
+	return errs
+}
@@ -0,0 +1,89 @@
+package manifest_test
+
+import (
+	"testing"
+
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	errs := manifest.Lint([]byte(`
+environment:
+  - SECRET
+balancers:
+  main:
+    service: missing
+    ports:
+      80: 3000
+services:
+  web:
+    port: 3000
+    helthcheck: /check
+    resources:
+      - database
+  worker:
+    command: work
+timers:
+  cleanup:
+    schedule: "0 * * * *"
+    service: cleaner
+    command: bin/cleanup
+`), nil)
+
+	require.Equal(t, manifest.LintErrors{
+		{Line: 6, Column: 5, Path: "balancers.main.service", Message: "balancer main refers to unknown service missing"},
+		{Line: 12, Column: 5, Path: "services.web.helthcheck", Message: "unknown key: services.web.helthcheck"},
+		{Line: 13, Column: 5, Path: "services.web.resources", Message: "service web references a resource that does not exist: database"},
+		{Line: 20, Column: 5, Path: "timers.cleanup.service", Message: "timer cleanup references a service that does not exist: cleaner"},
+	}, errs)
+}
+
+func TestLintClean(t *testing.T) {
+	errs := manifest.Lint([]byte(`
+services:
+  web:
+    port: 3000
+`), map[string]string{})
+
+	require.Empty(t, errs)
+}
+
+func TestLintRequiredEnv(t *testing.T) {
+	data := []byte(`
+environment:
+  - SECRET
+services:
+  web:
+    environment:
+      - TOKEN
+      - PORT=3000
+`)
+
+	require.Empty(t, manifest.Lint(data, nil))
+
+	require.Equal(t, manifest.LintErrors{
+		{Message: "required env: SECRET, TOKEN"},
+	}, manifest.Lint(data, map[string]string{}))
+
+	require.Empty(t, manifest.Lint(data, map[string]string{"SECRET": "1", "TOKEN": "2"}))
+}
+
+func TestLintSyntax(t *testing.T) {
+	errs := manifest.Lint([]byte("services:\n  web:\n    port: [3000\n"), nil)
+
+	require.Len(t, errs, 1)
+	require.NotZero(t, errs[0].Line)
+}
+
+func TestLintTypes(t *testing.T) {
+	errs := manifest.Lint([]byte(`
+services:
+  web:
+    internal: maybe
+`), nil)
+
+	require.Equal(t, manifest.LintErrors{
+		{Line: 4, Column: 15, Path: "services.web.internal", Message: "cannot unmarshal !!str `maybe` into bool"},
+	}, errs)
+}
@@ -27,16 +27,20 @@ var (
 )
 
 type Manifest struct {
-	AppSettings AppSettings    `yaml:"appSettings,omitempty"`
-	Balancers   Balancers      `yaml:"balancers,omitempty"`
-	Budget      BudgetSettings `yaml:"budget,omitempty"`
-	Configs     AppConfigs     `yaml:"configs,omitempty"`
-	Environment Environment `yaml:"environment,omitempty"`
-	Labels      Labels      `yaml:"labels,omitempty"`
-	Params      Params      `yaml:"params,omitempty"`
-	Resources   Resources   `yaml:"resources,omitempty"`
-	Services    Services    `yaml:"services,omitempty"`
-	Timers      Timers      `yaml:"timers,omitempty"`
+	AppSettings  AppSettings    `yaml:"appSettings,omitempty"`
+	Balancers    Balancers      `yaml:"balancers,omitempty"`
+	Budget       BudgetSettings `yaml:"budget,omitempty"`
+	Configs      AppConfigs     `yaml:"configs,omitempty"`
+	Deploy       Deploy         `yaml:"deploy,omitempty"`
+	Dependencies Dependencies   `yaml:"dependencies,omitempty"`
+	Environment  Environment    `yaml:"environment,omitempty"`
+	Jobs         Jobs           `yaml:"jobs,omitempty"`
+	Labels       Labels         `yaml:"labels,omitempty"`
+	Network      Network        `yaml:"network,omitempty"`
+	Params       Params         `yaml:"params,omitempty"`
+	Resources    Resources      `yaml:"resources,omitempty"`
+	Services     Services       `yaml:"services,omitempty"`
+	Timers       Timers         `yaml:"timers,omitempty"`
 
 	attributes map[string]bool
 	env        map[string]string
@@ -123,6 +127,11 @@ func (m *Manifest) ApplyCompatibility() error {
 }
 
 func (m *Manifest) ApplyDefaults() error {
+	for i, r := range m.Resources {
+		if r.Backup != nil && r.Backup.Retention == 0 {
+			m.Resources[i].Backup.Retention = DefaultBackupRetention
+		}
+	}
 
 	for i, s := range m.Services {
 		if s.Build.Path == "" && s.Image == "" {
@@ -383,6 +392,20 @@ func (m *Manifest) ApplyDefaults() error {
 		}
 	}
 
+	for i, j := range m.Jobs {
+		if j.Completions == 0 {
+			m.Jobs[i].Completions = 1
+		}
+
+		if j.Parallelism == 0 {
+			m.Jobs[i].Parallelism = 1
+		}
+
+		if !m.AttributeExists(fmt.Sprintf("jobs.%s.ttlSecondsAfterFinished", j.Name)) {
+			m.Jobs[i].TtlSecondsAfterFinished = 86400
+		}
+	}
+
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/convox/convox/pkg/common"
 	"github.com/convox/convox/pkg/manifest"
@@ -783,11 +784,43 @@ func TestManifestValidate(t *testing.T) {
 		"balancer alpha has blank service",
 		"balancer alpha whitelist 1.1.1.1 is not a valid cidr range",
 		"balancer bravo refers to unknown service nosuch",
+		"dependency billing/api as BILLING_API_URL is already used by another dependency",
+		"dependency Billing/worker app Billing invalid, must contain only lowercase alphanumeric and dashes",
+		"dependency Billing/worker as 1URL is not a valid environment variable name",
+		"dependency /api requires app and service",
+		"deploy approvals must not be negative",
+		`deploy freeze window "Friday 17:00-Funday 08:00" invalid, unknown day: Funday`,
+		`deploy freeze window "Sat 10:00-Sun 10:00 Mars/Olympus_Mons" invalid, unknown timezone: Mars/Olympus_Mons`,
+		"service development-invalid development onChange must be restart, signal:<signal> or command:<command>",
+		"service development-invalid development sync from src has blank to",
+		"service development-invalid development sync download ../secrets must be a path below from",
+		"service development-invalid development sync download /etc/passwd must be a path below from",
+		"job name job_1 invalid, must contain only lowercase alphanumeric and dashes",
+		"job job_1 references a service that does not exist: someservice",
+		"job job_1 hook must be one of before-promote, after-promote",
+		"job name nightly-customer-ledger-backfill invalid, must be 30 characters or less",
+		"job nightly-customer-ledger-backfill backoffLimit must not be negative",
+		"service network-invalid network ingress from nosuch is not a service or resource of this app",
+		"service network-invalid network ingress from 1resource must not be a resource",
+		"service network-invalid network ingress from 10.0.0.0/33 is not a valid cidr range",
+		"service network-invalid network ingress from app:Billing does not name an app",
+		"service network-invalid network egress deny metadata must be a cidr range or metadata-endpoint",
 		"resource name 1resource invalid, must contain only lowercase alphanumeric and dashes",
+		"resource cache backup is not available for resources of type redis, must be one of mariadb, mysql, postgis, postgres",
+		"resource cache backup schedule invalid, 25 out of range 0-23",
+		"resource cache backup retention can not be less than 0",
 		"service deployment-invalid-low deployment minimum can not be less than 0",
 		"service deployment-invalid-low deployment maximum can not be less than 100",
 		"service deployment-invalid-high deployment minimum can not be greater than 100",
 		"service deployment-invalid-high deployment maximum can not be greater than 200",
+		"service deployment-invalid-rollout deployment strategy must be rolling or recreate",
+		"service deployment-invalid-rollout deployment maxUnavailable must be a count or a percentage up to 100%",
+		"service deployment-invalid-rollout deployment minReadySeconds can not be less than 0",
+		"service deployment-invalid-rollout deployment batch 0 must be a count or a percentage between 1% and 100%",
+		"service deployment-invalid-rollout deployment batch 200% must be a count or a percentage between 1% and 100%",
+		"service deployment-invalid-rollout deployment pause must be a duration",
+		"service deployment-invalid-recreate deployment strategy recreate can not be combined with maxSurge, maxUnavailable or batches",
+		"service deployment-invalid-recreate deployment pause requires batches",
 		"service internal-router-invalid can not have both internal and internalRouter set as true",
 		"service name serviceF invalid, must contain only lowercase alphanumeric and dashes",
 		"service serviceF references a resource that does not exist: foo",
@@ -798,6 +831,251 @@ func TestManifestValidate(t *testing.T) {
 	require.EqualError(t, err, fmt.Sprintf("validation errors:\n%s", strings.Join(errors, "\n")))
 }
 
+func TestCronSchedule(t *testing.T) {
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	cs, err := manifest.ParseCronSchedule("*/15 2 * * *")
+	require.NoError(t, err)
+	require.True(t, cs.Matches(at(10, 18, 2, 0)))
+	require.True(t, cs.Matches(at(10, 18, 2, 45)))
+	require.False(t, cs.Matches(at(10, 18, 2, 10)))
+	require.False(t, cs.Matches(at(10, 18, 3, 0)))
+
+	cs, err = manifest.ParseCronSchedule("@daily")
+	require.NoError(t, err)
+	require.True(t, cs.Matches(at(10, 18, 0, 0)))
+	require.False(t, cs.Matches(at(10, 18, 0, 1)))
+
+	// 2026-10-18 is a sunday, 7 and 0 both name it
+	cs, err = manifest.ParseCronSchedule("30 4 * * 1-5,7")
+	require.NoError(t, err)
+	require.True(t, cs.Matches(at(10, 18, 4, 30)))
+	require.True(t, cs.Matches(at(10, 19, 4, 30)))
+	require.False(t, cs.Matches(at(10, 24, 4, 30)))
+
+	// both day fields restricted matches either
+	cs, err = manifest.ParseCronSchedule("0 0 1 * 1")
+	require.NoError(t, err)
+	require.True(t, cs.Matches(at(10, 1, 0, 0)))
+	require.True(t, cs.Matches(at(10, 19, 0, 0)))
+	require.False(t, cs.Matches(at(10, 20, 0, 0)))
+
+	for _, s := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := manifest.ParseCronSchedule(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestManifestResourceBackup(t *testing.T) {
+	m, err := manifest.Load([]byte(`
+resources:
+  database:
+    type: postgres
+    backup:
+      schedule: "0 3 * * *"
+  other:
+    type: mysql
+    backup:
+      schedule: "@hourly"
+      retention: 24
+`), map[string]string{})
+	require.NoError(t, err)
+
+	r, err := m.Resource("database")
+	require.NoError(t, err)
+	require.Equal(t, &manifest.ResourceBackup{Schedule: "0 3 * * *", Retention: manifest.DefaultBackupRetention}, r.Backup)
+
+	r, err = m.Resource("other")
+	require.NoError(t, err)
+	require.Equal(t, &manifest.ResourceBackup{Schedule: "@hourly", Retention: 24}, r.Backup)
+}
+
+func TestManifestDependencies(t *testing.T) {
+	m, err := manifest.Load([]byte(`
+dependencies:
+  - app: billing
+    service: api
+    as: BILLING_URL
+  - app: user-auth
+    service: token-api
+services:
+  web:
+    build: .
+`), map[string]string{})
+	require.NoError(t, err)
+	require.NoError(t, m.Validate())
+
+	require.Equal(t, manifest.Dependencies{
+		{App: "billing", Service: "api", As: "BILLING_URL"},
+		{App: "user-auth", Service: "token-api"},
+	}, m.Dependencies)
+
+	require.Equal(t, "BILLING_URL", m.Dependencies[0].Env())
+	require.Equal(t, "api.billing.convox.internal", m.Dependencies[0].Host())
+	require.Equal(t, "USER_AUTH_TOKEN_API_URL", m.Dependencies[1].Env())
+	require.Equal(t, "token-api.user-auth.convox.internal", m.Dependencies[1].Host())
+}
+
+func TestManifestServiceDevelopment(t *testing.T) {
+	m, err := manifest.Load([]byte(`
+services:
+  web:
+    build: .
+    development:
+      onChange: restart
+      sync:
+        - from: app
+          to: /usr/src/app
+          ignore:
+            - tmp
+          download:
+            - Gemfile.lock
+  worker:
+    build: .
+    development:
+      onChange:
+        command: bundle install
+  other:
+    build: .
+`), map[string]string{})
+	require.NoError(t, err)
+	require.NoError(t, m.Validate())
+
+	s, err := m.Service("web")
+	require.NoError(t, err)
+	require.Equal(t, &manifest.ServiceDevelopment{
+		OnChange: "restart",
+		Sync: []manifest.ServiceDevelopmentSync{
+			{From: "app", To: "/usr/src/app", Ignore: []string{"tmp"}, Download: []string{"Gemfile.lock"}},
+		},
+	}, s.Development)
+
+	s, err = m.Service("worker")
+	require.NoError(t, err)
+	require.Equal(t, manifest.OnChange("command:bundle install"), s.Development.OnChange)
+
+	s, err = m.Service("other")
+	require.NoError(t, err)
+	require.Nil(t, s.Development)
+}
+
+func TestOnChangeAction(t *testing.T) {
+	tests := []struct {
+		onChange string
+		action   string
+		arg      string
+	}{
+		{"", "", ""},
+		{"restart", "restart", ""},
+		{"signal:HUP", "signal", "HUP"},
+		{"signal: sigusr1", "signal", "USR1"},
+		{"command: bundle install", "command", "bundle install"},
+		{"command:rm -f tmp/pids/server.pid", "command", "rm -f tmp/pids/server.pid"},
+	}
+
+	for _, tt := range tests {
+		action, arg, err := manifest.OnChange(tt.onChange).Action()
+		require.NoError(t, err, tt.onChange)
+		require.Equal(t, tt.action, action, tt.onChange)
+		require.Equal(t, tt.arg, arg, tt.onChange)
+	}
+
+	for _, oc := range []string{"reload", "signal:", "signal:HUP now", "command:", "command:  "} {
+		_, _, err := manifest.OnChange(oc).Action()
+		require.EqualError(t, err, "must be restart, signal:<signal> or command:<command>", oc)
+	}
+}
+
+func TestFreezeWindow(t *testing.T) {
+	w, err := manifest.ParseFreezeWindow("Fri 16:00-Mon 08:00 America/New_York")
+	require.NoError(t, err)
+	require.Equal(t, "Fri 16:00-Mon 08:00 America/New_York", w.String())
+
+	ny, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	require.False(t, w.Contains(time.Date(2026, 10, 16, 15, 59, 0, 0, ny)))
+	require.True(t, w.Contains(time.Date(2026, 10, 16, 16, 0, 0, 0, ny)))
+	require.True(t, w.Contains(time.Date(2026, 10, 18, 12, 0, 0, 0, ny)))
+	require.True(t, w.Contains(time.Date(2026, 10, 19, 7, 59, 0, 0, ny)))
+	require.False(t, w.Contains(time.Date(2026, 10, 19, 8, 0, 0, 0, ny)))
+	require.False(t, w.Contains(time.Date(2026, 10, 21, 12, 0, 0, 0, ny)))
+
+	// 20:00 utc on friday is 16:00 in new york
+	require.True(t, w.Contains(time.Date(2026, 10, 16, 20, 0, 0, 0, time.UTC)))
+	require.False(t, w.Contains(time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC)))
+
+	require.Equal(t, time.Date(2026, 10, 19, 8, 0, 0, 0, ny).UTC(), w.Until(time.Date(2026, 10, 17, 9, 30, 0, 0, ny)).UTC())
+
+	w, err = manifest.ParseFreezeWindow("saturday 00:00 - sunday 23:59")
+	require.NoError(t, err)
+	require.Equal(t, time.UTC, w.Location)
+	require.True(t, w.Contains(time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)))
+	require.False(t, w.Contains(time.Date(2026, 10, 16, 23, 59, 0, 0, time.UTC)))
+
+	for spec, msg := range map[string]string{
+		"weekends":                     `must be of the form "Fri 16:00-Mon 08:00 [timezone]"`,
+		"Fri 16:00-Fri 16:00":          "must not start and end at the same time",
+		"Fri 25:00-Mon 08:00":          "invalid time: 25:00",
+		"Fri 16:00-Mon 08:00 Nowhere1": "unknown timezone: Nowhere1",
+	} {
+		_, err := manifest.ParseFreezeWindow(spec)
+		require.EqualError(t, err, msg, spec)
+	}
+}
+
+func TestManifestJobs(t *testing.T) {
+	m, err := testdataManifest("jobs", map[string]string{})
+	require.NoError(t, err)
+
+	require.Equal(t, manifest.Jobs{
+		{
+			Name:                    "migrate",
+			Command:                 "bin/migrate",
+			Completions:             1,
+			Hook:                    "before-promote",
+			Parallelism:             1,
+			Service:                 "web",
+			TtlSecondsAfterFinished: 86400,
+		},
+		{
+			Name:                    "backfill",
+			Annotations:             manifest.ServiceAnnotations{"example.com/owner=data"},
+			BackoffLimit:            3,
+			Command:                 "bin/backfill --all",
+			Completions:             4,
+			Hook:                    "after-promote",
+			Parallelism:             2,
+			Service:                 "web",
+			TtlSecondsAfterFinished: 0,
+		},
+		{
+			Name:                    "warm",
+			Command:                 "bin/warm-cache",
+			Completions:             1,
+			Parallelism:             1,
+			Service:                 "web",
+			TtlSecondsAfterFinished: 600,
+		},
+	}, m.Jobs)
+
+	require.Equal(t, []string{"migrate"}, jobNames(m.Jobs.Hooked(manifest.JobHookBeforePromote)))
+	require.Equal(t, []string{"backfill"}, jobNames(m.Jobs.Hooked(manifest.JobHookAfterPromote)))
+	require.Equal(t, []string{"warm"}, jobNames(m.Jobs.Hooked("")))
+}
+
+func jobNames(jj manifest.Jobs) []string {
+	names := []string{}
+
+	for _, j := range jj {
+		names = append(names, j.Name)
+	}
+
+	return names
+}
+
 func TestManifestStartupProbe(t *testing.T) {
 	m, err := testdataManifest("startup-probe", map[string]string{})
 	require.NoError(t, err)
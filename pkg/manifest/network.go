@@ -0,0 +1,127 @@
+package manifest
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// MetadataEndpoint names the cloud instance metadata endpoint in a service's
+// egress deny list.
+const MetadataEndpoint = "metadata-endpoint"
+
+// MetadataEndpointCIDR is the address MetadataEndpoint stands for.
+const MetadataEndpointCIDR = "169.254.169.254/32"
+
+// Network is the app wide network policy. When DefaultDeny is set every
+// service only accepts traffic from the rack router and from the peers its
+// network section allows.
+type Network struct {
+	DefaultDeny bool `yaml:"defaultDeny,omitempty"`
+}
+
+// ServiceNetwork limits the traffic a service accepts and sends.
+//
+// Peers are the name of a service or resource of this app, app:<app> or
+// app:<app>/<service> for another app on the rack, or a cidr range.
+type ServiceNetwork struct {
+	Ingress ServiceNetworkIngress `yaml:"ingress,omitempty"`
+	Egress  ServiceNetworkEgress  `yaml:"egress,omitempty"`
+}
+
+type ServiceNetworkIngress struct {
+	From []string `yaml:"from,omitempty"`
+}
+
+// ServiceNetworkEgress limits outbound traffic to the To peers when any are
+// listed. Deny takes cidr ranges or metadata-endpoint and blocks them even
+// when a broader To range would allow them.
+type ServiceNetworkEgress struct {
+	To   []string `yaml:"to,omitempty"`
+	Deny []string `yaml:"deny,omitempty"`
+}
+
+// NetworkPeer is a parsed network peer. Exactly one of Service, Resource
+// and CIDR is set unless App is set alone, which matches every pod of the
+// app.
+type NetworkPeer struct {
+	App      string
+	Service  string
+	Resource string
+	CIDR     string
+}
+
+// Isolated reports whether the service only accepts traffic from the peers
+// it allows.
+func (sn ServiceNetwork) Isolated(n Network) bool {
+	return n.DefaultDeny || len(sn.Ingress.From) > 0
+}
+
+// Restricted reports whether the outbound traffic of the service is limited.
+func (sn ServiceNetwork) Restricted() bool {
+	return len(sn.Egress.To) > 0 || len(sn.Egress.Deny) > 0
+}
+
+// DenyCIDRs returns the cidr ranges of the egress deny list.
+func (sn ServiceNetwork) DenyCIDRs() ([]string, error) {
+	cs := []string{}
+
+	for _, d := range sn.Egress.Deny {
+		if d == MetadataEndpoint {
+			cs = append(cs, MetadataEndpointCIDR)
+			continue
+		}
+
+		_, n, err := net.ParseCIDR(d)
+		if err != nil {
+			return nil, fmt.Errorf("deny %s must be a cidr range or %s", d, MetadataEndpoint)
+		}
+
+		cs = append(cs, n.String())
+	}
+
+	return cs, nil
+}
+
+// NetworkPeer parses s, a peer in a network section. Names without a prefix
+// must be a service or resource of this manifest.
+func (m *Manifest) NetworkPeer(s string) (*NetworkPeer, error) {
+	if strings.Contains(s, "/") && !strings.HasPrefix(s, "app:") {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not a valid cidr range", s)
+		}
+
+		return &NetworkPeer{CIDR: n.String()}, nil
+	}
+
+	if strings.HasPrefix(s, "app:") {
+		parts := strings.SplitN(strings.TrimPrefix(s, "app:"), "/", 2)
+
+		if !NameValidator.MatchString(parts[0]) {
+			return nil, fmt.Errorf("%s does not name an app", s)
+		}
+
+		p := &NetworkPeer{App: parts[0]}
+
+		if len(parts) == 2 {
+			if !NameValidator.MatchString(parts[1]) {
+				return nil, fmt.Errorf("%s does not name a service", s)
+			}
+
+			p.Service = parts[1]
+		}
+
+		return p, nil
+	}
+
+	if _, err := m.Service(s); err == nil {
+		return &NetworkPeer{Service: s}, nil
+	}
+
+	if _, err := m.Resource(s); err == nil {
+		return &NetworkPeer{Resource: s}, nil
+	}
+
+	return nil, fmt.Errorf("%s is not a service or resource of this app", s)
+}
@@ -28,6 +28,7 @@ type Resource struct {
 	Type    string            `yaml:"type"`
 	Image   string            `yaml:"image,omitempty"`
 	Options map[string]string `yaml:"options"`
+	Backup  *ResourceBackup   `yaml:"backup,omitempty"`
 }
 
 type Resources []Resource
@@ -86,7 +87,7 @@ func (r Resource) ElastiCacheNameValidate() error {
 
 func (r Resource) IsContainerizedResource() bool {
 	switch r.Type {
-	case "postgres", "mysql", "mariadb", "redis", "memcached", "postgis":
+	case "postgres", "mysql", "mariadb", "redis", "memcached", "postgis", "rabbitmq", "mongodb", "kafka", "minio":
 		return true
 	default:
 		return false
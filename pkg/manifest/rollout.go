@@ -0,0 +1,129 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DeploymentStrategyRecreate = "recreate"
+	DeploymentStrategyRolling  = "rolling"
+)
+
+var reCountOrPercent = regexp.MustCompile(`^[0-9]+%?$`)
+
+// BatchCounts resolves the batches of a deployment against the desired
+// count of processes. Each batch is at least one process and at least the
+// batch before it.
+func (d ServiceDeployment) BatchCounts(replicas int) []int {
+	counts := []int{}
+
+	for _, b := range d.Batches {
+		n := countOrPercent(b, replicas, true)
+
+		if n > replicas {
+			n = replicas
+		}
+
+		if n < 1 {
+			n = 1
+		}
+
+		if len(counts) > 0 && n < counts[len(counts)-1] {
+			n = counts[len(counts)-1]
+		}
+
+		counts = append(counts, n)
+	}
+
+	return counts
+}
+
+// PauseDuration is how long a rollout waits after each batch is ready.
+func (d ServiceDeployment) PauseDuration() time.Duration {
+	p, err := time.ParseDuration(d.Pause)
+	if err != nil {
+		return 0
+	}
+
+	return p
+}
+
+// countOrPercent resolves a count such as 3 or a percentage such as 25% of
+// total, rounding percentages up or down.
+func countOrPercent(v string, total int, up bool) int {
+	if strings.HasSuffix(v, "%") {
+		pct, _ := strconv.Atoi(strings.TrimSuffix(v, "%"))
+
+		n := total * pct / 100
+
+		if up && total*pct%100 != 0 {
+			n++
+		}
+
+		return n
+	}
+
+	n, _ := strconv.Atoi(v)
+
+	return n
+}
+
+// overPercent is true for percentages above 100%.
+func overPercent(v string) bool {
+	return strings.HasSuffix(v, "%") && countOrPercent(v, 100, false) > 100
+}
+
+func (s Service) validateRollout() []error {
+	errs := []error{}
+	d := s.Deployment
+
+	switch d.Strategy {
+	case "", DeploymentStrategyRolling:
+	case DeploymentStrategyRecreate:
+		if d.MaxSurge != "" || d.MaxUnavailable != "" || len(d.Batches) > 0 {
+			errs = append(errs, fmt.Errorf("service %s deployment strategy recreate can not be combined with maxSurge, maxUnavailable or batches", s.Name))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("service %s deployment strategy must be rolling or recreate", s.Name))
+	}
+
+	if s.Agent.Enabled && (d.Strategy != "" || len(d.Batches) > 0) {
+		errs = append(errs, fmt.Errorf("service %s deployment strategy and batches are not supported for agents", s.Name))
+	}
+
+	if d.MaxSurge != "" && !reCountOrPercent.MatchString(d.MaxSurge) {
+		errs = append(errs, fmt.Errorf("service %s deployment maxSurge must be a count or a percentage", s.Name))
+	}
+
+	if d.MaxUnavailable != "" && (!reCountOrPercent.MatchString(d.MaxUnavailable) || overPercent(d.MaxUnavailable)) {
+		errs = append(errs, fmt.Errorf("service %s deployment maxUnavailable must be a count or a percentage up to 100%%", s.Name))
+	}
+
+	if d.MaxSurge != "" && d.MaxUnavailable != "" && countOrPercent(d.MaxSurge, 100, false) == 0 && countOrPercent(d.MaxUnavailable, 100, false) == 0 {
+		errs = append(errs, fmt.Errorf("service %s deployment maxSurge and maxUnavailable can not both be 0", s.Name))
+	}
+
+	if d.MinReadySeconds != nil && *d.MinReadySeconds < 0 {
+		errs = append(errs, fmt.Errorf("service %s deployment minReadySeconds can not be less than 0", s.Name))
+	}
+
+	for _, b := range d.Batches {
+		if !reCountOrPercent.MatchString(b) || countOrPercent(b, 100, false) == 0 || overPercent(b) {
+			errs = append(errs, fmt.Errorf("service %s deployment batch %s must be a count or a percentage between 1%% and 100%%", s.Name, b))
+		}
+	}
+
+	if d.Pause != "" {
+		if p, err := time.ParseDuration(d.Pause); err != nil || p < 0 {
+			errs = append(errs, fmt.Errorf("service %s deployment pause must be a duration", s.Name))
+		} else if len(d.Batches) == 0 {
+			errs = append(errs, fmt.Errorf("service %s deployment pause requires batches", s.Name))
+		}
+	}
+
+	return errs
+}
@@ -34,6 +34,7 @@ type Service struct {
 	Command            string                   `yaml:"command,omitempty"`
 	ConfigMounts       ConfigMounts             `yaml:"configMounts,omitempty"`
 	Deployment         ServiceDeployment        `yaml:"deployment,omitempty"`
+	Development        *ServiceDevelopment      `yaml:"development,omitempty"`
 	DnsConfig          ServiceDnsConfig         `yaml:"dnsConfig,omitempty"`
 	Domains            ServiceDomains           `yaml:"domain,omitempty"`
 	Drain              int                      `yaml:"drain,omitempty"`
@@ -53,6 +54,7 @@ type Service struct {
 	Labels             Labels                   `yaml:"labels,omitempty"`
 	NodeAffinityLabels Affinities               `yaml:"nodeAffinityLabels,omitempty"`
 	NodeSelectorLabels Labels                   `yaml:"nodeSelectorLabels,omitempty"`
+	Network            ServiceNetwork           `yaml:"network,omitempty"`
 	Lifecycle          ServiceLifecycle         `yaml:"lifecycle,omitempty"`
 	Port               ServicePortScheme        `yaml:"port,omitempty"`
 	Ports              []ServicePortProtocol    `yaml:"ports,omitempty"`
@@ -273,9 +275,20 @@ type ServiceBuild struct {
 	Path     string   `yaml:"path,omitempty"`
 }
 
+// ServiceDeployment controls how a promote replaces the processes of a
+// service. Minimum and Maximum are percentages of the desired count;
+// MaxSurge and MaxUnavailable take a count or a percentage and win over
+// them when set. Batches stop the rollout after each step until its
+// processes are ready and Pause has passed.
 type ServiceDeployment struct {
-	Maximum int `yaml:"maximum,omitempty"`
-	Minimum int `yaml:"minimum,omitempty"`
+	Batches         []string `yaml:"batches,omitempty"`
+	Maximum         int      `yaml:"maximum,omitempty"`
+	MaxSurge        string   `yaml:"maxSurge,omitempty"`
+	MaxUnavailable  string   `yaml:"maxUnavailable,omitempty"`
+	Minimum         int      `yaml:"minimum,omitempty"`
+	MinReadySeconds *int     `yaml:"minReadySeconds,omitempty"`
+	Pause           string   `yaml:"pause,omitempty"`
+	Strategy        string   `yaml:"strategy,omitempty"`
 }
 
 type ServiceDomains []string
@@ -404,3 +404,37 @@ func TestAnnotationsMapReadsAnnotations(t *testing.T) {
 	require.NotContains(t, got, "ingress-key",
 		"AnnotationsMap must not return ingress annotations")
 }
+
+func TestManifestLoadServiceDeploymentRollout(t *testing.T) {
+	y := []byte(`services:
+  web:
+    deployment:
+      maxSurge: 2
+      maxUnavailable: 0
+      minReadySeconds: 10
+      batches: [1, 25%, 100%]
+      pause: 5m
+  db:
+    deployment:
+      strategy: recreate
+`)
+	m, err := manifest.Load(y, map[string]string{})
+	require.NoError(t, err)
+	require.NoError(t, m.Validate())
+
+	web, err := m.Service("web")
+	require.NoError(t, err)
+	require.Equal(t, "2", web.Deployment.MaxSurge)
+	require.Equal(t, "0", web.Deployment.MaxUnavailable)
+	require.Equal(t, 10, *web.Deployment.MinReadySeconds)
+	require.Equal(t, []string{"1", "25%", "100%"}, web.Deployment.Batches)
+	require.Equal(t, "5m0s", web.Deployment.PauseDuration().String())
+	require.Equal(t, []int{1, 3, 10}, web.Deployment.BatchCounts(10))
+	require.Equal(t, []int{1, 1, 2}, web.Deployment.BatchCounts(2))
+
+	db, err := m.Service("db")
+	require.NoError(t, err)
+	require.Equal(t, manifest.DeploymentStrategyRecreate, db.Deployment.Strategy)
+	require.Nil(t, db.Deployment.MinReadySeconds)
+	require.Empty(t, db.Deployment.BatchCounts(3))
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -32,7 +33,12 @@ func (m *Manifest) validate() []error {
 
 	errs = append(errs, m.validateBalancers()...)
 	errs = append(errs, m.validateBudget()...)
+	errs = append(errs, m.validateDependencies()...)
+	errs = append(errs, m.validateDeploy()...)
+	errs = append(errs, m.validateDevelopment()...)
 	errs = append(errs, m.validateEnv()...)
+	errs = append(errs, m.validateJobs()...)
+	errs = append(errs, m.validateNetwork()...)
 	errs = append(errs, m.validateResources()...)
 	errs = append(errs, m.validateServices()...)
 	errs = append(errs, m.validateTimers()...)
@@ -75,6 +81,84 @@ func (m *Manifest) validateBalancers() []error {
 	return errs
 }
 
+func (m *Manifest) validateDependencies() []error {
+	errs := []error{}
+
+	envs := map[string]bool{}
+
+	for _, d := range m.Dependencies {
+		if d.App == "" || d.Service == "" {
+			errs = append(errs, fmt.Errorf("dependency %s requires app and service", d))
+			continue
+		}
+
+		if !NameValidator.MatchString(d.App) {
+			errs = append(errs, fmt.Errorf("dependency %s app %s invalid, %s", d, d.App, ValidNameDescription))
+		}
+
+		if !NameValidator.MatchString(d.Service) {
+			errs = append(errs, fmt.Errorf("dependency %s service %s invalid, %s", d, d.Service, ValidNameDescription))
+		}
+
+		if !reEnvName.MatchString(d.Env()) {
+			errs = append(errs, fmt.Errorf("dependency %s as %s is not a valid environment variable name", d, d.Env()))
+			continue
+		}
+
+		if envs[d.Env()] {
+			errs = append(errs, fmt.Errorf("dependency %s as %s is already used by another dependency", d, d.Env()))
+		}
+
+		envs[d.Env()] = true
+	}
+
+	return errs
+}
+
+func (m *Manifest) validateDeploy() []error {
+	errs := []error{}
+
+	if m.Deploy.Approvals < 0 {
+		errs = append(errs, fmt.Errorf("deploy approvals must not be negative"))
+	}
+
+	for _, f := range m.Deploy.Freeze {
+		if _, err := ParseFreezeWindow(f); err != nil {
+			errs = append(errs, fmt.Errorf("deploy freeze window %q invalid, %s", f, err))
+		}
+	}
+
+	return errs
+}
+
+func (m *Manifest) validateDevelopment() []error {
+	errs := []error{}
+
+	for _, s := range m.Services {
+		if s.Development == nil {
+			continue
+		}
+
+		if _, _, err := s.Development.OnChange.Action(); err != nil {
+			errs = append(errs, fmt.Errorf("service %s development onChange %s", s.Name, err))
+		}
+
+		for _, sy := range s.Development.Sync {
+			if strings.TrimSpace(sy.To) == "" {
+				errs = append(errs, fmt.Errorf("service %s development sync from %s has blank to", s.Name, sy.SyncFrom()))
+			}
+
+			for _, d := range sy.Download {
+				if c := path.Clean(d); path.IsAbs(c) || c == "." || c == ".." || strings.HasPrefix(c, "../") {
+					errs = append(errs, fmt.Errorf("service %s development sync download %s must be a path below from", s.Name, d))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
 func (m *Manifest) validateEnv() []error {
 	errs := []error{}
 
@@ -87,6 +171,36 @@ func (m *Manifest) validateEnv() []error {
 	return errs
 }
 
+func (m *Manifest) validateNetwork() []error {
+	errs := []error{}
+
+	for _, s := range m.Services {
+		for _, f := range s.Network.Ingress.From {
+			p, err := m.NetworkPeer(f)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("service %s network ingress from %s", s.Name, err))
+				continue
+			}
+
+			if p.Resource != "" {
+				errs = append(errs, fmt.Errorf("service %s network ingress from %s must not be a resource", s.Name, f))
+			}
+		}
+
+		for _, t := range s.Network.Egress.To {
+			if _, err := m.NetworkPeer(t); err != nil {
+				errs = append(errs, fmt.Errorf("service %s network egress to %s", s.Name, err))
+			}
+		}
+
+		if _, err := s.Network.DenyCIDRs(); err != nil {
+			errs = append(errs, fmt.Errorf("service %s network egress %s", s.Name, err))
+		}
+	}
+
+	return errs
+}
+
 func (m *Manifest) validateResources() []error {
 	errs := []error{}
 
@@ -98,6 +212,28 @@ func (m *Manifest) validateResources() []error {
 		if strings.TrimSpace(r.Type) == "" {
 			errs = append(errs, fmt.Errorf("resource %q has blank type", r.Name))
 		}
+
+		if r.Backup != nil {
+			errs = append(errs, r.validateBackup()...)
+		}
+	}
+
+	return errs
+}
+
+func (r Resource) validateBackup() []error {
+	errs := []error{}
+
+	if !containsInStringSlice(BackupResourceTypes, r.Type) {
+		errs = append(errs, fmt.Errorf("resource %s backup is not available for resources of type %s, must be one of %s", r.Name, r.Type, strings.Join(BackupResourceTypes, ", ")))
+	}
+
+	if _, err := ParseCronSchedule(r.Backup.Schedule); err != nil {
+		errs = append(errs, fmt.Errorf("resource %s backup schedule invalid, %s", r.Name, err))
+	}
+
+	if r.Backup.Retention < 0 {
+		errs = append(errs, fmt.Errorf("resource %s backup retention can not be less than 0", r.Name))
 	}
 
 	return errs
@@ -132,6 +268,8 @@ func (m *Manifest) validateServices() []error {
 			errs = append(errs, fmt.Errorf("service %s deployment maximum can not be greater than 200", s.Name))
 		}
 
+		errs = append(errs, s.validateRollout()...)
+
 		if s.Internal && s.InternalRouter {
 			errs = append(errs, fmt.Errorf("service %s can not have both internal and internalRouter set as true", s.Name))
 		}
@@ -336,6 +474,47 @@ func validatePrometheusURL(raw string) error {
 	return nil
 }
 
+func (m *Manifest) validateJobs() []error {
+	errs := []error{}
+
+	for _, j := range m.Jobs {
+		if !NameValidator.MatchString(j.Name) {
+			errs = append(errs, fmt.Errorf("job name %s invalid, %s", j.Name, ValidNameDescription))
+		}
+
+		// job-<name>-<release> must stay a valid label value once the pod suffix is added
+		if len(j.Name) > 30 {
+			errs = append(errs, fmt.Errorf("job name %s invalid, must be 30 characters or less", j.Name))
+		}
+
+		if _, err := m.Service(j.Service); err != nil {
+			if strings.HasPrefix(err.Error(), "no such service") {
+				errs = append(errs, fmt.Errorf("job %s references a service that does not exist: %s", j.Name, j.Service))
+			}
+		}
+
+		switch j.Hook {
+		case "", JobHookBeforePromote, JobHookAfterPromote:
+		default:
+			errs = append(errs, fmt.Errorf("job %s hook must be one of %s, %s", j.Name, JobHookBeforePromote, JobHookAfterPromote))
+		}
+
+		if j.BackoffLimit < 0 {
+			errs = append(errs, fmt.Errorf("job %s backoffLimit must not be negative", j.Name))
+		}
+
+		if j.Completions < 1 || j.Parallelism < 1 {
+			errs = append(errs, fmt.Errorf("job %s completions and parallelism must be at least 1", j.Name))
+		}
+
+		if j.TtlSecondsAfterFinished < 0 {
+			errs = append(errs, fmt.Errorf("job %s ttlSecondsAfterFinished must not be negative", j.Name))
+		}
+	}
+
+	return errs
+}
+
 func (m *Manifest) validateTimers() []error {
 	errs := []error{}
 
@@ -261,6 +261,31 @@ func (v ServiceBuild) MarshalYAML() (interface{}, error) {
 	return v, nil
 }
 
+func (v *OnChange) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var w interface{}
+
+	if err := unmarshal(&w); err != nil {
+		return err
+	}
+
+	switch t := w.(type) {
+	case map[interface{}]interface{}:
+		if len(t) != 1 {
+			return fmt.Errorf("onChange must have one of signal or command")
+		}
+
+		for k, a := range t {
+			*v = OnChange(fmt.Sprintf("%v:%v", k, a))
+		}
+	case string:
+		*v = OnChange(t)
+	default:
+		return fmt.Errorf("unknown type for onChange: %T", t)
+	}
+
+	return nil
+}
+
 func (v *ServiceDomains) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	var w interface{}
 
@@ -646,6 +671,14 @@ func (v ServiceScaleCount) MarshalYAML() (interface{}, error) {
 	return v, nil
 }
 
+func (v Jobs) MarshalYAML() (interface{}, error) {
+	return marshalMapSlice(v)
+}
+
+func (v *Jobs) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	return unmarshalMapSlice(unmarshal, v)
+}
+
 func (v Timers) MarshalYAML() (interface{}, error) {
 	return marshalMapSlice(v)
 }
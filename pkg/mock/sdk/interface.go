@@ -22,6 +22,89 @@ type Interface struct {
 	mock.Mock
 }
 
+// AccessPolicyDelete provides a mock function with given fields: id
+func (_m *Interface) AccessPolicyDelete(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AccessPolicyGet provides a mock function with given fields: id
+func (_m *Interface) AccessPolicyGet(id string) (*structs.AccessPolicy, error) {
+	ret := _m.Called(id)
+
+	var r0 *structs.AccessPolicy
+	if rf, ok := ret.Get(0).(func(string) *structs.AccessPolicy); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.AccessPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccessPolicyList provides a mock function with given fields:
+func (_m *Interface) AccessPolicyList() (structs.AccessPolicies, error) {
+	ret := _m.Called()
+
+	var r0 structs.AccessPolicies
+	if rf, ok := ret.Get(0).(func() structs.AccessPolicies); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(structs.AccessPolicies)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccessPolicySet provides a mock function with given fields: id, opts
+func (_m *Interface) AccessPolicySet(id string, opts structs.AccessPolicyOptions) (*structs.AccessPolicy, error) {
+	ret := _m.Called(id, opts)
+
+	var r0 *structs.AccessPolicy
+	if rf, ok := ret.Get(0).(func(string, structs.AccessPolicyOptions) *structs.AccessPolicy); ok {
+		r0 = rf(id, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.AccessPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, structs.AccessPolicyOptions) error); ok {
+		r1 = rf(id, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AppBudgetClear provides a mock function with given fields: app, ackBy
 func (_m *Interface) AppBudgetClear(app string, ackBy string) error {
 	ret := _m.Called(app, ackBy)
@@ -350,6 +433,29 @@ func (_m *Interface) AppDiagnose(app string, opts structs.AppDiagnoseOptions) (*
 	return r0, r1
 }
 
+// AppDrift provides a mock function with given fields: name
+func (_m *Interface) AppDrift(name string) (structs.Drifts, error) {
+	ret := _m.Called(name)
+
+	var r0 structs.Drifts
+	if rf, ok := ret.Get(0).(func(string) structs.Drifts); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(structs.Drifts)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AppGet provides a mock function with given fields: name
 func (_m *Interface) AppGet(name string) (*structs.App, error) {
 	ret := _m.Called(name)
@@ -502,6 +608,29 @@ func (_m *Interface) AppParametersSet(_a0 string, _a1 map[string]string) error {
 	return r0
 }
 
+// AppPreviewCreate provides a mock function with given fields: base, name, opts
+func (_m *Interface) AppPreviewCreate(base string, name string, opts structs.AppPreviewCreateOptions) (*structs.App, error) {
+	ret := _m.Called(base, name, opts)
+
+	var r0 *structs.App
+	if rf, ok := ret.Get(0).(func(string, string, structs.AppPreviewCreateOptions) *structs.App); ok {
+		r0 = rf(base, name, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.App)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, structs.AppPreviewCreateOptions) error); ok {
+		r1 = rf(base, name, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AppUpdate provides a mock function with given fields: name, opts
 func (_m *Interface) AppUpdate(name string, opts structs.AppUpdateOptions) error {
 	ret := _m.Called(name, opts)
@@ -516,6 +645,43 @@ func (_m *Interface) AppUpdate(name string, opts structs.AppUpdateOptions) error
 	return r0
 }
 
+// AuditList provides a mock function with given fields: opts
+func (_m *Interface) AuditList(opts structs.AuditListOptions) (structs.AuditEntries, error) {
+	ret := _m.Called(opts)
+
+	var r0 structs.AuditEntries
+	if rf, ok := ret.Get(0).(func(structs.AuditListOptions) structs.AuditEntries); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(structs.AuditEntries)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(structs.AuditListOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuditRecord provides a mock function with given fields: e
+func (_m *Interface) AuditRecord(e structs.AuditEntry) error {
+	ret := _m.Called(e)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(structs.AuditEntry) error); ok {
+		r0 = rf(e)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BalancerList provides a mock function with given fields: app
 func (_m *Interface) BalancerList(app string) (structs.Balancers, error) {
 	ret := _m.Called(app)
@@ -1739,6 +1905,29 @@ func (_m *Interface) RegistryRemoveClassic(_a0 string) error {
 	return r0
 }
 
+// ReleaseApprove provides a mock function with given fields: app, id, opts
+func (_m *Interface) ReleaseApprove(app string, id string, opts structs.ReleaseApproveOptions) (*structs.Release, error) {
+	ret := _m.Called(app, id, opts)
+
+	var r0 *structs.Release
+	if rf, ok := ret.Get(0).(func(string, string, structs.ReleaseApproveOptions) *structs.Release); ok {
+		r0 = rf(app, id, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.Release)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, structs.ReleaseApproveOptions) error); ok {
+		r1 = rf(app, id, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReleaseCreate provides a mock function with given fields: app, opts
 func (_m *Interface) ReleaseCreate(app string, opts structs.ReleaseCreateOptions) (*structs.Release, error) {
 	ret := _m.Called(app, opts)
@@ -1808,6 +1997,20 @@ func (_m *Interface) ReleaseList(app string, opts structs.ReleaseListOptions) (s
 	return r0, r1
 }
 
+// ReleasePause provides a mock function with given fields: app
+func (_m *Interface) ReleasePause(app string) error {
+	ret := _m.Called(app)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(app)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ReleasePromote provides a mock function with given fields: app, id, opts
 func (_m *Interface) ReleasePromote(app string, id string, opts structs.ReleasePromoteOptions) error {
 	ret := _m.Called(app, id, opts)
@@ -1822,6 +2025,80 @@ func (_m *Interface) ReleasePromote(app string, id string, opts structs.ReleaseP
 	return r0
 }
 
+// ReleaseResume provides a mock function with given fields: app
+func (_m *Interface) ReleaseResume(app string) error {
+	ret := _m.Called(app)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(app)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReleaseTimeline provides a mock function with given fields: app, id
+func (_m *Interface) ReleaseTimeline(app string, id string) (*structs.ReleaseTimeline, error) {
+	ret := _m.Called(app, id)
+
+	var r0 *structs.ReleaseTimeline
+	if rf, ok := ret.Get(0).(func(string, string) *structs.ReleaseTimeline); ok {
+		r0 = rf(app, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.ReleaseTimeline)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(app, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResourceBackupList provides a mock function with given fields: app, name
+func (_m *Interface) ResourceBackupList(app string, name string) (structs.ResourceBackups, error) {
+	ret := _m.Called(app, name)
+
+	var r0 structs.ResourceBackups
+	if rf, ok := ret.Get(0).(func(string, string) structs.ResourceBackups); ok {
+		r0 = rf(app, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(structs.ResourceBackups)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(app, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResourceBackupRestore provides a mock function with given fields: app, name, id
+func (_m *Interface) ResourceBackupRestore(app string, name string, id string) error {
+	ret := _m.Called(app, name, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(app, name, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResourceConsole provides a mock function with given fields: app, name, rw, opts
 func (_m *Interface) ResourceConsole(app string, name string, rw io.ReadWriter, opts structs.ResourceConsoleOptions) error {
 	ret := _m.Called(app, name, rw, opts)
@@ -2720,6 +2997,80 @@ func (_m *Interface) SystemResourceUpdateClassic(_a0 string, _a1 structs.Resourc
 	return r0, r1
 }
 
+// SystemTokenCreate provides a mock function with given fields: opts
+func (_m *Interface) SystemTokenCreate(opts structs.SystemTokenCreateOptions) (*structs.SystemToken, error) {
+	ret := _m.Called(opts)
+
+	var r0 *structs.SystemToken
+	if rf, ok := ret.Get(0).(func(structs.SystemTokenCreateOptions) *structs.SystemToken); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*structs.SystemToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(structs.SystemTokenCreateOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SystemTokenList provides a mock function with given fields:
+func (_m *Interface) SystemTokenList() (structs.SystemTokens, error) {
+	ret := _m.Called()
+
+	var r0 structs.SystemTokens
+	if rf, ok := ret.Get(0).(func() structs.SystemTokens); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(structs.SystemTokens)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SystemTokenRevoke provides a mock function with given fields: id
+func (_m *Interface) SystemTokenRevoke(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SystemTokenUsed provides a mock function with given fields: id
+func (_m *Interface) SystemTokenUsed(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SystemUninstall provides a mock function with given fields: name, w, opts
 func (_m *Interface) SystemUninstall(name string, w io.Writer, opts structs.SystemUninstallOptions) error {
 	ret := _m.Called(name, w, opts)
@@ -246,6 +246,10 @@ func (c Console) Parameters() (map[string]string, error) {
 	return s.Parameters, nil
 }
 
+func (c Console) Plan(params map[string]string, version string, force bool) (*Plan, error) {
+	return nil, ErrPlanUnsupported
+}
+
 func (c Console) Provider() string {
 	return c.provider
 }
@@ -123,6 +123,10 @@ func (d Direct) Parameters() (map[string]string, error) {
 	return s.Parameters, nil
 }
 
+func (d Direct) Plan(params map[string]string, version string, force bool) (*Plan, error) {
+	return nil, ErrPlanUnsupported
+}
+
 func (d Direct) Provider() string {
 	return d.provider
 }
@@ -0,0 +1,157 @@
+package rack
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/convox/stdcli"
+)
+
+// Group is a named set of racks that commands can be run against together.
+type Group struct {
+	Name  string   `json:"name"`
+	Racks []string `json:"racks"`
+}
+
+type Groups []Group
+
+func (gs Groups) Less(i, j int) bool { return gs[i].Name < gs[j].Name }
+
+func (gs Groups) Find(name string) (*Group, bool) {
+	for i := range gs {
+		if gs[i].Name == name {
+			return &gs[i], true
+		}
+	}
+
+	return nil, false
+}
+
+// GroupCreate creates or replaces a group. The racks are matched the same way
+// as by Switch and stored by their full name.
+func GroupCreate(c *stdcli.Context, name string, racks []string) (*Group, error) {
+	if strings.TrimSpace(name) == "" || strings.Contains(name, ",") {
+		return nil, fmt.Errorf("invalid group name: %s", name)
+	}
+
+	if len(racks) == 0 {
+		return nil, fmt.Errorf("a group requires at least one rack")
+	}
+
+	g := Group{Name: name}
+	seen := map[string]bool{}
+
+	for _, rn := range racks {
+		r, err := Match(c, rn)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[r.Name()] {
+			return nil, fmt.Errorf("rack %s is listed more than once", r.Name())
+		}
+
+		seen[r.Name()] = true
+
+		g.Racks = append(g.Racks, r.Name())
+	}
+
+	gs, err := ListGroups(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if eg, ok := gs.Find(name); ok {
+		eg.Racks = g.Racks
+	} else {
+		gs = append(gs, g)
+	}
+
+	if err := saveGroups(c, gs); err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+func GroupDelete(c *stdcli.Context, name string) error {
+	gs, err := ListGroups(c)
+	if err != nil {
+		return err
+	}
+
+	ngs := Groups{}
+
+	for _, g := range gs {
+		if g.Name != name {
+			ngs = append(ngs, g)
+		}
+	}
+
+	if len(ngs) == len(gs) {
+		return fmt.Errorf("no such group: %s", name)
+	}
+
+	return saveGroups(c, ngs)
+}
+
+func ListGroups(c *stdcli.Context) (Groups, error) {
+	data, err := c.SettingRead("groups")
+	if err != nil {
+		return nil, err
+	}
+
+	gs := Groups{}
+
+	if data == "" {
+		return gs, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), &gs); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(gs, gs.Less)
+
+	return gs, nil
+}
+
+// Select returns the racks for a --racks selector which is either the name of
+// a group or a comma separated list of racks.
+func Select(c *stdcli.Context, selector string) ([]string, error) {
+	gs, err := ListGroups(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if g, ok := gs.Find(selector); ok {
+		return g.Racks, nil
+	}
+
+	racks := []string{}
+
+	for _, rn := range strings.Split(selector, ",") {
+		if rn = strings.TrimSpace(rn); rn != "" {
+			racks = append(racks, rn)
+		}
+	}
+
+	if len(racks) == 0 {
+		return nil, fmt.Errorf("no racks selected")
+	}
+
+	return racks, nil
+}
+
+func saveGroups(c *stdcli.Context, gs Groups) error {
+	sort.Slice(gs, gs.Less)
+
+	data, err := json.Marshal(gs)
+	if err != nil {
+		return err
+	}
+
+	return c.SettingWrite("groups", string(data))
+}
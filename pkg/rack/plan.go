@@ -0,0 +1,341 @@
+package rack
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/convox/stdcli"
+)
+
+const (
+	PlanActionCreate  = "create"
+	PlanActionDelete  = "delete"
+	PlanActionReplace = "replace"
+	PlanActionUpdate  = "update"
+
+	planFile = "convox.tfplan"
+)
+
+var (
+	ErrPlanUnsupported = fmt.Errorf("terraform plans are only available for racks installed from this machine")
+
+	// planDestructiveTypes are the terraform resources whose deletion or
+	// replacement takes down a cluster, its nodes or a database.
+	planDestructiveTypes = map[string]bool{
+		"aws_db_instance":                      true,
+		"aws_eks_cluster":                      true,
+		"aws_eks_node_group":                   true,
+		"aws_elasticache_cluster":              true,
+		"aws_elasticache_replication_group":    true,
+		"aws_rds_cluster":                      true,
+		"aws_rds_cluster_instance":             true,
+		"azurerm_kubernetes_cluster":           true,
+		"azurerm_kubernetes_cluster_node_pool": true,
+		"azurerm_mysql_flexible_server":        true,
+		"azurerm_postgresql_flexible_server":   true,
+		"digitalocean_database_cluster":        true,
+		"digitalocean_kubernetes_cluster":      true,
+		"digitalocean_kubernetes_node_pool":    true,
+		"google_container_cluster":             true,
+		"google_container_node_pool":           true,
+		"google_sql_database_instance":         true,
+	}
+
+	// planFiles are the settings a plan was made from, they replace the
+	// settings of the rack when the plan is applied.
+	planFiles = []string{".terraform.lock.hcl", "backend.tf", "main.tf", "vars.json"}
+)
+
+// Plan is a saved terraform plan for a change to a rack. Apply applies
+// exactly what was planned. Discard drops the plan, the rack settings are
+// left as they were.
+type Plan struct {
+	Create    int            `json:"create"`
+	Update    int            `json:"update"`
+	Delete    int            `json:"delete"`
+	Replace   int            `json:"replace"`
+	Resources []PlanResource `json:"resources"`
+
+	apply   func() error
+	discard func() error
+}
+
+// PlanResource is a terraform resource that a plan would change.
+// Destructive is set when a cluster, node group or database would be
+// deleted or replaced.
+type PlanResource struct {
+	Address     string `json:"address"`
+	Type        string `json:"type"`
+	Action      string `json:"action"`
+	Destructive bool   `json:"destructive"`
+}
+
+func (p *Plan) Apply() error {
+	if p.apply == nil {
+		return nil
+	}
+
+	return p.apply()
+}
+
+func (p *Plan) Discard() error {
+	if p.discard == nil {
+		return nil
+	}
+
+	return p.discard()
+}
+
+// Destructive returns the resources of the plan that are destructive.
+func (p *Plan) Destructive() []PlanResource {
+	rs := []PlanResource{}
+
+	for _, r := range p.Resources {
+		if r.Destructive {
+			rs = append(rs, r)
+		}
+	}
+
+	return rs
+}
+
+func (p *Plan) Empty() bool {
+	return len(p.Resources) == 0
+}
+
+func (p *Plan) Summary() string {
+	if p.Empty() {
+		return "no infrastructure changes"
+	}
+
+	return fmt.Sprintf("%d to create, %d to update, %d to replace, %d to delete", p.Create, p.Update, p.Replace, p.Delete)
+}
+
+// Plan saves a terraform plan for the rack settings for params and version,
+// the current release when empty. It works in a copy of the settings of the
+// rack, which are only replaced when the plan is applied.
+func (t Terraform) Plan(params map[string]string, version string, force bool) (*Plan, error) {
+	dir, err := t.settingsDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := t.vars()
+	if err != nil {
+		return nil, err
+	}
+
+	release := vars["release"]
+
+	if version != "" && version != release {
+		if !force {
+			if err := t.checkVersion(version); err != nil {
+				return nil, err
+			}
+		}
+
+		release = version
+	}
+
+	if release == "" {
+		return nil, fmt.Errorf("could not determine current release")
+	}
+
+	tmp, err := os.MkdirTemp("", "convox-plan-")
+	if err != nil {
+		return nil, err
+	}
+
+	discard := func() error {
+		return os.RemoveAll(tmp)
+	}
+
+	if err := copyDirectory(dir, tmp); err != nil {
+		discard()
+		return nil, err
+	}
+
+	pt := t
+	pt.dir = tmp
+
+	p, err := pt.plan(tmp, release, vars, params)
+	if err != nil {
+		discard()
+		return nil, err
+	}
+
+	p.apply = func() error {
+		defer discard()
+		defer os.Remove(filepath.Join(dir, planFile))
+
+		if err := planCommit(tmp, dir); err != nil {
+			return err
+		}
+
+		if data, err := terraformOutput(t.ctx, dir, "init", "-input=false", "-no-color"); err != nil {
+			return fmt.Errorf("terraform init failed: %s", strings.TrimSpace(string(data)))
+		}
+
+		return terraform(t.ctx, dir, "apply", "-no-color", planFile)
+	}
+
+	p.discard = discard
+
+	return p, nil
+}
+
+func (t Terraform) plan(dir, release string, vars, params map[string]string) (*Plan, error) {
+	for k, v := range params {
+		vars[k] = v
+	}
+
+	if err := t.update(release, vars); err != nil {
+		return nil, err
+	}
+
+	// providers stay on the versions the rack was installed with
+	if data, err := terraformOutput(t.ctx, dir, "init", "-input=false", "-no-color"); err != nil {
+		return nil, fmt.Errorf("terraform init failed: %s", strings.TrimSpace(string(data)))
+	}
+
+	if err := t.reconcileVarsWithModule(release); err != nil {
+		return nil, err
+	}
+
+	if data, err := terraformOutput(t.ctx, dir, "plan", "-input=false", "-no-color", "-out="+planFile); err != nil {
+		return nil, fmt.Errorf("terraform plan failed: %s", strings.TrimSpace(string(data)))
+	}
+
+	data, err := terraformOutput(t.ctx, dir, "show", "-json", planFile)
+	if err != nil {
+		return nil, fmt.Errorf("terraform show failed: %s", strings.TrimSpace(string(data)))
+	}
+
+	return parseTerraformPlan(data)
+}
+
+// planCommit copies a saved plan and the settings it was made from out of
+// the plan directory from into the rack settings directory to.
+func planCommit(from, to string) error {
+	for _, name := range append([]string{planFile}, planFiles...) {
+		data, err := os.ReadFile(filepath.Join(from, name))
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return err
+		}
+
+		if err := os.WriteFile(filepath.Join(to, name), data, 0600); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyDirectory copies the files, directories and links under src to dst.
+func copyDirectory(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+
+			return os.Symlink(link, target)
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm()|0700)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		return os.WriteFile(target, data, info.Mode().Perm())
+	})
+}
+
+// parseTerraformPlan summarises the resource changes of the json output of
+// terraform show.
+func parseTerraformPlan(data []byte) (*Plan, error) {
+	var tp struct {
+		ResourceChanges []struct {
+			Address string `json:"address"`
+			Type    string `json:"type"`
+			Change  struct {
+				Actions []string `json:"actions"`
+			} `json:"change"`
+		} `json:"resource_changes"`
+	}
+
+	if err := json.Unmarshal(data, &tp); err != nil {
+		return nil, fmt.Errorf("could not parse terraform plan: %s", err)
+	}
+
+	p := &Plan{Resources: []PlanResource{}}
+
+	for _, rc := range tp.ResourceChanges {
+		r := PlanResource{Address: rc.Address, Type: rc.Type}
+
+		switch strings.Join(rc.Change.Actions, ",") {
+		case "create":
+			r.Action = PlanActionCreate
+			p.Create++
+		case "update":
+			r.Action = PlanActionUpdate
+			p.Update++
+		case "delete":
+			r.Action = PlanActionDelete
+			p.Delete++
+		case "delete,create", "create,delete":
+			r.Action = PlanActionReplace
+			p.Replace++
+		default:
+			continue
+		}
+
+		r.Destructive = planDestructiveTypes[r.Type] && (r.Action == PlanActionDelete || r.Action == PlanActionReplace)
+
+		p.Resources = append(p.Resources, r)
+	}
+
+	sort.SliceStable(p.Resources, func(i, j int) bool {
+		return p.Resources[i].Address < p.Resources[j].Address
+	})
+
+	return p, nil
+}
+
+func terraformOutput(c *stdcli.Context, dir string, args ...string) ([]byte, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	defer os.Chdir(wd)
+
+	if err := os.Chdir(dir); err != nil {
+		return nil, err
+	}
+
+	os.Setenv("AWS_STS_REGIONAL_ENDPOINTS", "regional")
+
+	return c.Execute("terraform", args...)
+}
@@ -0,0 +1,79 @@
+package rack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTerraformPlan(t *testing.T) {
+	data := []byte(`{
+  "resource_changes": [
+    {"address": "module.system.module.rack.kubernetes_deployment.api", "type": "kubernetes_deployment", "change": {"actions": ["update"]}},
+    {"address": "module.system.module.cluster.aws_eks_node_group.cluster[0]", "type": "aws_eks_node_group", "change": {"actions": ["delete", "create"]}},
+    {"address": "module.system.module.cluster.aws_iam_role.nodes", "type": "aws_iam_role", "change": {"actions": ["no-op"]}},
+    {"address": "module.system.module.cluster.aws_iam_role.extra", "type": "aws_iam_role", "change": {"actions": ["delete"]}},
+    {"address": "module.system.module.rack.kubernetes_config_map.new", "type": "kubernetes_config_map", "change": {"actions": ["create"]}}
+  ]
+}`)
+
+	p, err := parseTerraformPlan(data)
+	require.NoError(t, err)
+	require.Equal(t, 1, p.Create)
+	require.Equal(t, 1, p.Update)
+	require.Equal(t, 1, p.Delete)
+	require.Equal(t, 1, p.Replace)
+	require.Equal(t, []PlanResource{
+		{Address: "module.system.module.cluster.aws_eks_node_group.cluster[0]", Type: "aws_eks_node_group", Action: "replace", Destructive: true},
+		{Address: "module.system.module.cluster.aws_iam_role.extra", Type: "aws_iam_role", Action: "delete"},
+		{Address: "module.system.module.rack.kubernetes_config_map.new", Type: "kubernetes_config_map", Action: "create"},
+		{Address: "module.system.module.rack.kubernetes_deployment.api", Type: "kubernetes_deployment", Action: "update"},
+	}, p.Resources)
+	require.Equal(t, "1 to create, 1 to update, 1 to replace, 1 to delete", p.Summary())
+	require.Len(t, p.Destructive(), 1)
+
+	p, err = parseTerraformPlan([]byte(`{"resource_changes":[]}`))
+	require.NoError(t, err)
+	require.True(t, p.Empty())
+	require.Equal(t, "no infrastructure changes", p.Summary())
+
+	_, err = parseTerraformPlan([]byte("Error: bad"))
+	require.EqualError(t, err, "could not parse terraform plan: invalid character 'E' looking for beginning of value")
+}
+
+func TestPlanCommit(t *testing.T) {
+	live, tmp := t.TempDir(), t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(live, ".terraform", "modules"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(live, ".terraform", "modules", "modules.json"), []byte("{}"), 0600))
+	require.NoError(t, os.Symlink("modules.json", filepath.Join(live, ".terraform", "modules", "link.json")))
+	require.NoError(t, os.WriteFile(filepath.Join(live, "main.tf"), []byte("old"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(live, "vars.json"), []byte(`{"release":"3.23.2"}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(live, "terraform.tfstate"), []byte("state"), 0600))
+
+	require.NoError(t, copyDirectory(live, tmp))
+
+	data, err := os.ReadFile(filepath.Join(tmp, ".terraform", "modules", "link.json"))
+	require.NoError(t, err)
+	require.Equal(t, "{}", string(data))
+
+	// the plan changes its copy only
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "main.tf"), []byte("new"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "vars.json"), []byte(`{"release":"3.23.3"}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, planFile), []byte("plan"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(tmp, "terraform.tfstate"), []byte("other"), 0600))
+
+	data, err = os.ReadFile(filepath.Join(live, "main.tf"))
+	require.NoError(t, err)
+	require.Equal(t, "old", string(data))
+
+	require.NoError(t, planCommit(tmp, live))
+
+	for name, content := range map[string]string{"main.tf": "new", "vars.json": `{"release":"3.23.3"}`, planFile: "plan", "terraform.tfstate": "state"} {
+		data, err := os.ReadFile(filepath.Join(live, name))
+		require.NoError(t, err)
+		require.Equal(t, content, string(data), name)
+	}
+}
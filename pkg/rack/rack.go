@@ -32,6 +32,7 @@ type Rack interface {
 	Metadata() (*Metadata, error)
 	Name() string
 	Parameters() (map[string]string, error)
+	Plan(map[string]string, string, bool) (*Plan, error)
 	Provider() string
 	Remote() bool
 	Status() string
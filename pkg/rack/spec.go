@@ -0,0 +1,438 @@
+package rack
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	SpecActionCreate = "create"
+	SpecActionDelete = "delete"
+	SpecActionUpdate = "update"
+
+	SpecTypeLetsEncrypt = "letsencrypt"
+	SpecTypeParam       = "param"
+	SpecTypeRegistry    = "registry"
+	SpecTypeResource    = "resource"
+	SpecTypeVersion     = "version"
+)
+
+var (
+	reSpecInterpolation = regexp.MustCompile(`\$\{([^}]*?)\}`)
+
+	specTypeOrder = map[string]int{
+		SpecTypeVersion:     0,
+		SpecTypeParam:       1,
+		SpecTypeRegistry:    2,
+		SpecTypeLetsEncrypt: 3,
+		SpecTypeResource:    4,
+	}
+)
+
+// Spec is the declarative configuration of a rack kept in a rack.yml. Only
+// what is listed is managed: params that are not listed keep their current
+// value, and registries and resources that are not listed are only removed
+// when pruning. A letsencrypt section replaces all of the dns solvers.
+type Spec struct {
+	Version     string                  `yaml:"version,omitempty"`
+	Params      map[string]string       `yaml:"params,omitempty"`
+	Registries  []SpecRegistry          `yaml:"registries,omitempty"`
+	LetsEncrypt *SpecLetsEncrypt        `yaml:"letsencrypt,omitempty"`
+	Resources   map[string]SpecResource `yaml:"resources,omitempty"`
+}
+
+type SpecRegistry struct {
+	Server   string `yaml:"server"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+type SpecLetsEncrypt struct {
+	Solvers []SpecSolver `yaml:"solvers"`
+}
+
+// SpecSolver is a route53 dns solver for letsencrypt certificates.
+type SpecSolver struct {
+	Id           int      `yaml:"id"`
+	Zones        []string `yaml:"zones"`
+	Role         string   `yaml:"role"`
+	Region       string   `yaml:"region,omitempty"`
+	HostedZoneId string   `yaml:"hostedZoneId,omitempty"`
+}
+
+type SpecResource struct {
+	Type    string            `yaml:"type"`
+	Options map[string]string `yaml:"options,omitempty"`
+}
+
+// SpecState is the current configuration of a rack that a Spec is compared
+// against.
+type SpecState struct {
+	Version     string
+	Params      map[string]string
+	Registries  structs.Registries
+	LetsEncrypt *structs.LetsEncryptConfig
+	Resources   structs.Resources
+}
+
+// SpecChange is one difference between a Spec and the current state of a
+// rack. Registry passwords are never included in From and To.
+type SpecChange struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	From   string `json:"from,omitempty"`
+	To     string `json:"to,omitempty"`
+}
+
+type SpecChanges []SpecChange
+
+func (cs SpecChanges) Len() int      { return len(cs) }
+func (cs SpecChanges) Swap(i, j int) { cs[i], cs[j] = cs[j], cs[i] }
+func (cs SpecChanges) Less(i, j int) bool {
+	if cs[i].Type != cs[j].Type {
+		return specTypeOrder[cs[i].Type] < specTypeOrder[cs[j].Type]
+	}
+
+	return cs[i].Name < cs[j].Name
+}
+
+// Filter returns the changes of type t.
+func (cs SpecChanges) Filter(t string) SpecChanges {
+	fcs := SpecChanges{}
+
+	for _, c := range cs {
+		if c.Type == t {
+			fcs = append(fcs, c)
+		}
+	}
+
+	return fcs
+}
+
+// LoadSpec parses a rack spec, replacing ${NAME} with values from env so
+// that secrets such as registry passwords can be kept out of the file.
+func LoadSpec(data []byte, env map[string]string) (*Spec, error) {
+	missing := []string{}
+
+	p := reSpecInterpolation.ReplaceAllFunc(data, func(m []byte) []byte {
+		name := string(m[2 : len(m)-1])
+
+		v, ok := env[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+
+		return []byte(v)
+	})
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("required env: %s", strings.Join(missing, ", "))
+	}
+
+	var s Spec
+
+	if err := yaml.UnmarshalStrict(p, &s); err != nil {
+		return nil, err
+	}
+
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (s *Spec) Validate() error {
+	servers := map[string]bool{}
+
+	for _, r := range s.Registries {
+		if r.Server == "" {
+			return fmt.Errorf("registries require a server")
+		}
+
+		if servers[r.Server] {
+			return fmt.Errorf("registry %s is listed more than once", r.Server)
+		}
+
+		servers[r.Server] = true
+	}
+
+	if s.LetsEncrypt != nil {
+		ids := map[int]bool{}
+
+		for _, ss := range s.LetsEncrypt.Solvers {
+			if ss.Id <= 0 {
+				return fmt.Errorf("letsencrypt solvers require an id greater than 0")
+			}
+
+			if ids[ss.Id] {
+				return fmt.Errorf("letsencrypt solver %d is listed more than once", ss.Id)
+			}
+
+			ids[ss.Id] = true
+
+			if err := ss.solver().Validate(); err != nil {
+				return fmt.Errorf("letsencrypt solver %d: %s", ss.Id, err)
+			}
+		}
+	}
+
+	for name, r := range s.Resources {
+		if r.Type == "" {
+			return fmt.Errorf("resource %s requires a type", name)
+		}
+	}
+
+	return nil
+}
+
+// LetsEncryptConfig returns the letsencrypt configuration of the spec.
+func (s *Spec) LetsEncryptConfig() structs.LetsEncryptConfig {
+	config := structs.LetsEncryptConfig{Solvers: []*structs.Dns01Solver{}}
+
+	if s.LetsEncrypt != nil {
+		for _, ss := range s.LetsEncrypt.Solvers {
+			config.Solvers = append(config.Solvers, ss.solver())
+		}
+	}
+
+	return config
+}
+
+// Diff compares the spec with the current state of a rack. Registries and
+// resources missing from the spec are deleted only when prune is set.
+func (s *Spec) Diff(current SpecState, prune bool) (SpecChanges, error) {
+	cs := SpecChanges{}
+
+	if s.Version != "" && s.Version != current.Version {
+		cs = append(cs, SpecChange{Type: SpecTypeVersion, Name: "version", Action: SpecActionUpdate, From: current.Version, To: s.Version})
+	}
+
+	for k, v := range s.Params {
+		cv, ok := current.Params[k]
+
+		switch {
+		case !ok:
+			cs = append(cs, SpecChange{Type: SpecTypeParam, Name: k, Action: SpecActionCreate, To: v})
+		case cv != v:
+			cs = append(cs, SpecChange{Type: SpecTypeParam, Name: k, Action: SpecActionUpdate, From: cv, To: v})
+		}
+	}
+
+	cs = append(cs, s.diffRegistries(current.Registries, prune)...)
+
+	if s.LetsEncrypt != nil {
+		cs = append(cs, s.diffLetsEncrypt(current.LetsEncrypt)...)
+	}
+
+	rcs, err := s.diffResources(current.Resources, prune)
+	if err != nil {
+		return nil, err
+	}
+
+	cs = append(cs, rcs...)
+
+	sort.Sort(cs)
+
+	return cs, nil
+}
+
+func (s *Spec) diffRegistries(current structs.Registries, prune bool) SpecChanges {
+	cs := SpecChanges{}
+	existing := map[string]structs.Registry{}
+
+	for _, r := range current {
+		existing[r.Server] = r
+	}
+
+	for _, r := range s.Registries {
+		cr, ok := existing[r.Server]
+
+		switch {
+		case !ok:
+			cs = append(cs, SpecChange{Type: SpecTypeRegistry, Name: r.Server, Action: SpecActionCreate, To: r.Username})
+		case cr.Username != r.Username:
+			cs = append(cs, SpecChange{Type: SpecTypeRegistry, Name: r.Server, Action: SpecActionUpdate, From: cr.Username, To: r.Username})
+		case cr.Password != r.Password:
+			cs = append(cs, SpecChange{Type: SpecTypeRegistry, Name: r.Server, Action: SpecActionUpdate, From: cr.Username, To: r.Username + " (new password)"})
+		}
+
+		delete(existing, r.Server)
+	}
+
+	if prune {
+		for server, r := range existing {
+			cs = append(cs, SpecChange{Type: SpecTypeRegistry, Name: server, Action: SpecActionDelete, From: r.Username})
+		}
+	}
+
+	return cs
+}
+
+func (s *Spec) diffLetsEncrypt(current *structs.LetsEncryptConfig) SpecChanges {
+	cs := SpecChanges{}
+	existing := map[int]*structs.Dns01Solver{}
+
+	if current != nil {
+		for _, solver := range current.Solvers {
+			if solver != nil {
+				existing[solver.Id] = solver
+			}
+		}
+	}
+
+	for _, ss := range s.LetsEncrypt.Solvers {
+		name := strconv.Itoa(ss.Id)
+		v := describeSolver(ss.solver())
+		cs0, ok := existing[ss.Id]
+		delete(existing, ss.Id)
+
+		if !ok {
+			cs = append(cs, SpecChange{Type: SpecTypeLetsEncrypt, Name: name, Action: SpecActionCreate, To: v})
+			continue
+		}
+
+		// the rack fills in a default region, only compare it when it is set
+		c := *cs0
+		if c.Route53 != nil {
+			r := *c.Route53
+			if ss.Region == "" {
+				r.Region = nil
+			}
+			if ss.HostedZoneId == "" {
+				r.HostedZoneID = nil
+			}
+			c.Route53 = &r
+		}
+
+		if cv := describeSolver(&c); cv != v {
+			cs = append(cs, SpecChange{Type: SpecTypeLetsEncrypt, Name: name, Action: SpecActionUpdate, From: cv, To: v})
+		}
+	}
+
+	for id, solver := range existing {
+		cs = append(cs, SpecChange{Type: SpecTypeLetsEncrypt, Name: strconv.Itoa(id), Action: SpecActionDelete, From: describeSolver(solver)})
+	}
+
+	return cs
+}
+
+func (s *Spec) diffResources(current structs.Resources, prune bool) (SpecChanges, error) {
+	cs := SpecChanges{}
+	existing := map[string]structs.Resource{}
+
+	for _, r := range current {
+		existing[r.Name] = r
+	}
+
+	for name, r := range s.Resources {
+		cr, ok := existing[name]
+		delete(existing, name)
+
+		if !ok {
+			cs = append(cs, SpecChange{Type: SpecTypeResource, Name: name, Action: SpecActionCreate, To: describeResource(r.Type, r.Options)})
+			continue
+		}
+
+		if cr.Type != r.Type {
+			return nil, fmt.Errorf("resource %s is a %s and can not be changed to a %s", name, cr.Type, r.Type)
+		}
+
+		from := map[string]string{}
+		to := map[string]string{}
+
+		for k, v := range r.Options {
+			if cr.Parameters[k] != v {
+				from[k] = cr.Parameters[k]
+				to[k] = v
+			}
+		}
+
+		if len(to) > 0 {
+			cs = append(cs, SpecChange{Type: SpecTypeResource, Name: name, Action: SpecActionUpdate, From: describeOptions(from), To: describeOptions(to)})
+		}
+	}
+
+	if prune {
+		for name, r := range existing {
+			cs = append(cs, SpecChange{Type: SpecTypeResource, Name: name, Action: SpecActionDelete, From: r.Type})
+		}
+	}
+
+	return cs, nil
+}
+
+func (ss SpecSolver) solver() *structs.Dns01Solver {
+	s := &structs.Dns01Solver{
+		Id:       ss.Id,
+		DnsZones: ss.Zones,
+		Route53: &structs.Route53{
+			Role: options.String(ss.Role),
+		},
+	}
+
+	if ss.Region != "" {
+		s.Route53.Region = options.String(ss.Region)
+	}
+
+	if ss.HostedZoneId != "" {
+		s.Route53.HostedZoneID = options.String(ss.HostedZoneId)
+	}
+
+	return s
+}
+
+func describeSolver(s *structs.Dns01Solver) string {
+	parts := []string{fmt.Sprintf("zones=%s", strings.Join(s.DnsZones, ","))}
+
+	if s.Route53 != nil {
+		for _, kv := range []struct {
+			key   string
+			value *string
+		}{
+			{"role", s.Route53.Role},
+			{"region", s.Route53.Region},
+			{"hosted-zone-id", s.Route53.HostedZoneID},
+		} {
+			if kv.value != nil && *kv.value != "" {
+				parts = append(parts, fmt.Sprintf("%s=%s", kv.key, *kv.value))
+			}
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func describeResource(kind string, opts map[string]string) string {
+	if len(opts) == 0 {
+		return kind
+	}
+
+	return fmt.Sprintf("%s %s", kind, describeOptions(opts))
+}
+
+func describeOptions(opts map[string]string) string {
+	keys := []string{}
+
+	for k := range opts {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%s", k, opts[k])
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,132 @@
+package rack
+
+import (
+	"testing"
+
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSpec(t *testing.T) {
+	data := []byte(`
+version: 3.22.1
+params:
+  node_type: t3.large
+  node_disk: 50
+registries:
+  - server: docker.io
+    username: user1
+    password: ${REGISTRY_PASSWORD}
+letsencrypt:
+  solvers:
+    - id: 1
+      zones: [example.org]
+      role: arn:aws:iam::1:role/dns
+resources:
+  cache:
+    type: redis
+    options:
+      version: "7"
+`)
+
+	s, err := LoadSpec(data, map[string]string{"REGISTRY_PASSWORD": "secret"})
+	require.NoError(t, err)
+	require.Equal(t, "3.22.1", s.Version)
+	require.Equal(t, map[string]string{"node_disk": "50", "node_type": "t3.large"}, s.Params)
+	require.Equal(t, []SpecRegistry{{Server: "docker.io", Username: "user1", Password: "secret"}}, s.Registries)
+	require.Equal(t, map[string]SpecResource{"cache": {Type: "redis", Options: map[string]string{"version": "7"}}}, s.Resources)
+
+	config := s.LetsEncryptConfig()
+	require.Len(t, config.Solvers, 1)
+	require.Equal(t, []string{"example.org"}, config.Solvers[0].DnsZones)
+	require.Equal(t, "arn:aws:iam::1:role/dns", *config.Solvers[0].Route53.Role)
+	require.Nil(t, config.Solvers[0].Route53.Region)
+
+	_, err = LoadSpec(data, map[string]string{})
+	require.EqualError(t, err, "required env: REGISTRY_PASSWORD")
+}
+
+func TestLoadSpecInvalid(t *testing.T) {
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{"paramz:\n  a: b\n", "yaml: unmarshal errors:\n  line 1: field paramz not found in type rack.Spec"},
+		{"registries:\n  - username: user1\n", "registries require a server"},
+		{"registries:\n  - server: a\n  - server: a\n", "registry a is listed more than once"},
+		{"letsencrypt:\n  solvers:\n    - zones: [a]\n      role: r\n", "letsencrypt solvers require an id greater than 0"},
+		{"letsencrypt:\n  solvers:\n    - id: 1\n      role: r\n", "letsencrypt solver 1: dns zones are required"},
+		{"resources:\n  cache: {}\n", "resource cache requires a type"},
+	}
+
+	for _, tt := range tests {
+		_, err := LoadSpec([]byte(tt.data), nil)
+		require.EqualError(t, err, tt.err, tt.data)
+	}
+}
+
+func TestSpecDiff(t *testing.T) {
+	s := &Spec{
+		Version: "3.22.1",
+		Params:  map[string]string{"node_disk": "50", "node_type": "t3.large", "tags": "a=b"},
+		Registries: []SpecRegistry{
+			{Server: "docker.io", Username: "user1", Password: "new"},
+			{Server: "ghcr.io", Username: "user2", Password: "pw"},
+			{Server: "quay.io", Username: "user3", Password: "pw"},
+		},
+		LetsEncrypt: &SpecLetsEncrypt{Solvers: []SpecSolver{
+			{Id: 1, Zones: []string{"example.org"}, Role: "role1"},
+			{Id: 2, Zones: []string{"example.com"}, Role: "role2"},
+		}},
+		Resources: map[string]SpecResource{
+			"cache":    {Type: "redis", Options: map[string]string{"version": "7"}},
+			"database": {Type: "postgres", Options: map[string]string{"storage": "20"}},
+		},
+	}
+
+	current := SpecState{
+		Version: "3.21.0",
+		Params:  map[string]string{"node_disk": "50", "node_type": "t3.medium"},
+		Registries: structs.Registries{
+			{Server: "docker.io", Username: "user1", Password: "old"},
+			{Server: "ecr.example.org", Username: "user4", Password: "pw"},
+			{Server: "ghcr.io", Username: "other", Password: "pw"},
+		},
+		LetsEncrypt: &structs.LetsEncryptConfig{Solvers: []*structs.Dns01Solver{
+			{Id: 1, DnsZones: []string{"example.org"}, Route53: &structs.Route53{Role: options.String("role1"), Region: options.String("us-east-1")}},
+			{Id: 3, DnsZones: []string{"example.net"}, Route53: &structs.Route53{Role: options.String("role3")}},
+		}},
+		Resources: structs.Resources{
+			{Name: "database", Type: "postgres", Parameters: map[string]string{"storage": "10", "version": "13"}},
+			{Name: "queue", Type: "rabbitmq"},
+		},
+	}
+
+	cs, err := s.Diff(current, false)
+	require.NoError(t, err)
+	require.Equal(t, SpecChanges{
+		{Type: "version", Name: "version", Action: "update", From: "3.21.0", To: "3.22.1"},
+		{Type: "param", Name: "node_type", Action: "update", From: "t3.medium", To: "t3.large"},
+		{Type: "param", Name: "tags", Action: "create", To: "a=b"},
+		{Type: "registry", Name: "docker.io", Action: "update", From: "user1", To: "user1 (new password)"},
+		{Type: "registry", Name: "ghcr.io", Action: "update", From: "other", To: "user2"},
+		{Type: "registry", Name: "quay.io", Action: "create", To: "user3"},
+		{Type: "letsencrypt", Name: "2", Action: "create", To: "zones=example.com role=role2"},
+		{Type: "letsencrypt", Name: "3", Action: "delete", From: "zones=example.net role=role3"},
+		{Type: "resource", Name: "cache", Action: "create", To: "redis version=7"},
+		{Type: "resource", Name: "database", Action: "update", From: "storage=10", To: "storage=20"},
+	}, cs)
+
+	cs, err = s.Diff(current, true)
+	require.NoError(t, err)
+	require.Contains(t, cs, SpecChange{Type: "registry", Name: "ecr.example.org", Action: "delete", From: "user4"})
+	require.Contains(t, cs, SpecChange{Type: "resource", Name: "queue", Action: "delete", From: "rabbitmq"})
+
+	cs, err = (&Spec{Version: "3.21.0"}).Diff(current, false)
+	require.NoError(t, err)
+	require.Empty(t, cs)
+
+	_, err = (&Spec{Resources: map[string]SpecResource{"database": {Type: "mysql"}}}).Diff(current, false)
+	require.EqualError(t, err, "resource database is a postgres and can not be changed to a mysql")
+}
@@ -87,6 +87,7 @@ var preserveEmpty = map[string]bool{
 	"contour_memory_request":          true,
 	"envoy_cpu_request":               true,
 	"envoy_memory_request":            true,
+	"secret_stores":                   true,
 }
 
 // PreserveEmptyParams returns a copy of the writeVars empty-preservation
@@ -103,6 +104,7 @@ func PreserveEmptyParams() map[string]bool {
 
 type Terraform struct {
 	ctx      *stdcli.Context
+	dir      string
 	endpoint string
 	name     string
 	provider string
@@ -265,6 +267,12 @@ func (Terraform) Latest() (string, error) {
 	return getTheLatestRelease()
 }
 
+// LatestVersion returns the version a rack on version current is updated
+// to when no version is given.
+func LatestVersion(current string) (string, error) {
+	return terraformLatestVersion(current)
+}
+
 func (t Terraform) Metadata() (*Metadata, error) {
 	dir, err := t.settingsDirectory()
 	if err != nil {
@@ -401,24 +409,9 @@ func (t Terraform) UpdateParams(params map[string]string) error {
 
 func (t Terraform) UpdateVersion(version string, force bool) error {
 	if version != "" && !force {
-		r, err := t.Client()
-		if err != nil {
-			return err
-		}
-		s, err := r.SystemGet()
-		if err != nil {
-			return err
-		}
-		if err := isSkippingMinor(s.Version, version); err != nil {
+		if err := t.checkVersion(version); err != nil {
 			return err
 		}
-
-		if versionLessThan(version, s.Version) {
-			vars, vErr := t.vars()
-			if vErr == nil && vars["router_type"] == "contour" {
-				return fmt.Errorf("cannot downgrade to %s while router_type=contour is set", version)
-			}
-		}
 	}
 
 	vars, err := t.vars()
@@ -445,6 +438,29 @@ func (t Terraform) UpdateVersion(version string, force bool) error {
 	return nil
 }
 
+func (t Terraform) checkVersion(version string) error {
+	r, err := t.Client()
+	if err != nil {
+		return err
+	}
+	s, err := r.SystemGet()
+	if err != nil {
+		return err
+	}
+	if err := isSkippingMinor(s.Version, version); err != nil {
+		return err
+	}
+
+	if versionLessThan(version, s.Version) {
+		vars, vErr := t.vars()
+		if vErr == nil && vars["router_type"] == "contour" {
+			return fmt.Errorf("cannot downgrade to %s while router_type=contour is set", version)
+		}
+	}
+
+	return nil
+}
+
 func (t Terraform) apply() error {
 	dir, err := t.settingsDirectory()
 	if err != nil {
@@ -605,7 +621,13 @@ func (t Terraform) init() error {
 	return nil
 }
 
+// settingsDirectory is where the terraform of the rack lives, or the copy of
+// it that a plan works in.
 func (t Terraform) settingsDirectory() (string, error) {
+	if t.dir != "" {
+		return t.dir, nil
+	}
+
 	return t.ctx.SettingDirectory(fmt.Sprintf("racks/%s", t.name))
 }
 
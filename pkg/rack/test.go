@@ -11,6 +11,7 @@ import (
 
 var (
 	TestClient sdk.Interface
+	TestPlan   *Plan
 )
 
 type Test struct {
@@ -72,6 +73,30 @@ func (t Test) Parameters() (map[string]string, error) {
 	return s.Parameters, nil
 }
 
+func (t Test) Plan(params map[string]string, version string, force bool) (*Plan, error) {
+	if TestPlan == nil {
+		return nil, ErrPlanUnsupported
+	}
+
+	p := *TestPlan
+
+	p.apply = func() error {
+		if version != "" {
+			if err := t.UpdateVersion(version, force); err != nil {
+				return err
+			}
+		}
+
+		if len(params) > 0 {
+			return t.UpdateParams(params)
+		}
+
+		return nil
+	}
+
+	return &p, nil
+}
+
 func (t Test) Provider() string {
 	return "provider1"
 }
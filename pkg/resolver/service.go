@@ -10,6 +10,11 @@ import (
 	"k8s.io/client-go/tools/cache"
 )
 
+// serviceAliases are the annotations holding the names a service resolves
+// by. Internal services of apps also resolve by
+// <service>.<app>.convox.internal, which does not depend on the rack name.
+var serviceAliases = []string{"convox.com/alias", "convox.com/internal-alias"}
+
 type Service struct {
 	errch    chan error
 	hosts    map[string]string
@@ -108,14 +113,18 @@ func (s *Service) update(prev, cur interface{}) {
 }
 
 func (s *Service) addService(svc *ac.Service) {
-	if host := svc.ObjectMeta.Annotations["convox.com/alias"]; host != "" {
-		s.hosts[host] = svc.Spec.ClusterIP
+	for _, a := range serviceAliases {
+		if host := svc.ObjectMeta.Annotations[a]; host != "" {
+			s.hosts[host] = svc.Spec.ClusterIP
+		}
 	}
 }
 
 func (s *Service) deleteService(svc *ac.Service) {
-	if host := svc.ObjectMeta.Annotations["convox.com/alias"]; host != "" {
-		delete(s.hosts, host)
+	for _, a := range serviceAliases {
+		if host := svc.ObjectMeta.Annotations[a]; host != "" {
+			delete(s.hosts, host)
+		}
 	}
 }
 
@@ -0,0 +1,48 @@
+package resolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func serviceObj(ip string, annotations map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "rack1-billing", Annotations: annotations},
+		Spec:       corev1.ServiceSpec{ClusterIP: ip},
+	}
+}
+
+func TestServiceInternalAlias(t *testing.T) {
+	s, err := NewService(fake.NewSimpleClientset())
+	require.NoError(t, err)
+
+	internal := serviceObj("10.0.0.5", map[string]string{
+		"convox.com/alias":          "api.billing.rack1.local",
+		"convox.com/internal-alias": "api.billing.convox.internal",
+	})
+
+	s.add(internal)
+
+	ip, ok := s.IP("api.billing.convox.internal")
+	require.True(t, ok)
+	require.Equal(t, "10.0.0.5", ip)
+
+	ip, ok = s.IP("api.billing.rack1.local")
+	require.True(t, ok)
+	require.Equal(t, "10.0.0.5", ip)
+
+	// the service stops being internal
+	s.update(internal, serviceObj("10.0.0.5", map[string]string{"convox.com/alias": "api.billing.rack1.local"}))
+
+	_, ok = s.IP("api.billing.convox.internal")
+	require.False(t, ok)
+
+	s.delete(serviceObj("10.0.0.5", map[string]string{"convox.com/alias": "api.billing.rack1.local"}))
+
+	_, ok = s.IP("api.billing.rack1.local")
+	require.False(t, ok)
+}
@@ -0,0 +1,98 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/pkg/errors"
+)
+
+// Aws reads secrets from AWS Secrets Manager. Paths are secret names or
+// arns; secrets stored as a JSON object expose its keys.
+type Aws struct {
+	Credentials *credentials.Credentials
+	Endpoint    string
+	Region      string
+
+	Client *http.Client
+}
+
+func (a *Aws) Read(path string) (map[string]string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	endpoint := a.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", a.Region)
+	}
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	if _, err := v4.NewSigner(a.Credentials).Sign(req, bytes.NewReader(body), "secretsmanager", a.Region, time.Now()); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	res, err := a.client().Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var e struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+
+		_ = json.Unmarshal(data, &e)
+
+		if e.Type == "ResourceNotFoundException" {
+			return nil, fmt.Errorf("no such secret: %s", path)
+		}
+
+		return nil, fmt.Errorf("secrets manager returned %d for %s: %s", res.StatusCode, path, e.Message)
+	}
+
+	var sv struct {
+		SecretString string `json:"SecretString"`
+	}
+
+	if err := json.Unmarshal(data, &sv); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var doc map[string]interface{}
+
+	if err := json.Unmarshal([]byte(sv.SecretString), &doc); err != nil || len(doc) == 0 {
+		return map[string]string{"": sv.SecretString}, nil
+	}
+
+	return stringValues(doc), nil
+}
+
+func (a *Aws) client() *http.Client {
+	if a.Client != nil {
+		return a.Client
+	}
+
+	return &http.Client{Timeout: 10 * time.Second}
+}
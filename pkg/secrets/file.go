@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// SopsCommand is the sops binary used to decrypt secret files.
+var SopsCommand = "sops"
+
+// File reads secrets from files below Root. Files holding a JSON or YAML
+// document of keys expose those keys, other files are a single value.
+type File struct {
+	Root string
+}
+
+func (f *File) Read(path string) (map[string]string, error) {
+	file, err := filePath(f.Root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no such secret: %s", path)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return parseDocument(data), nil
+}
+
+// Sops reads secret files below Root that are encrypted with sops. The sops
+// binary decrypts them with the keys available to the rack.
+type Sops struct {
+	Root string
+}
+
+func (s *Sops) Read(path string) (map[string]string, error) {
+	file, err := filePath(s.Root, path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return nil, fmt.Errorf("no such secret: %s", path)
+	}
+
+	data, err := exec.Command(SopsCommand, "--decrypt", file).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("could not decrypt %s: %s", path, strings.TrimSpace(string(ee.Stderr)))
+		}
+
+		return nil, errors.WithStack(err)
+	}
+
+	return parseDocument(data), nil
+}
+
+// filePath keeps the secret path inside root.
+func filePath(root, path string) (string, error) {
+	if root == "" {
+		return "", fmt.Errorf("no secret directory configured")
+	}
+
+	clean := filepath.Clean("/" + path)
+
+	if clean != "/"+strings.Trim(path, "/") {
+		return "", fmt.Errorf("invalid secret path: %s", path)
+	}
+
+	return filepath.Join(root, clean), nil
+}
+
+func parseDocument(data []byte) map[string]string {
+	var doc map[string]interface{}
+
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc) == 0 {
+		return map[string]string{"": strings.TrimRight(string(data), "\r\n")}
+	}
+
+	return stringValues(doc)
+}
+
+func stringValues(doc map[string]interface{}) map[string]string {
+	values := map[string]string{}
+
+	for k, v := range doc {
+		switch t := v.(type) {
+		case nil:
+			values[k] = ""
+		case string:
+			values[k] = t
+		default:
+			values[k] = fmt.Sprint(t)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,292 @@
+// Package secrets resolves env values that reference secrets held in an
+// external store, such as DB_PASS=secretref://vault/secret/data/db#password.
+// Releases keep the reference only; the rack resolves it when it renders the
+// environment of a service.
+package secrets
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+const (
+	StoreAws   = "aws-sm"
+	StoreFile  = "file"
+	StoreSops  = "sops"
+	StoreVault = "vault"
+
+	schemeRef = "secretref"
+)
+
+// Ref points to a key of a secret in a store. An empty Key selects the whole
+// secret when it is not a document of keys.
+type Ref struct {
+	Store string
+	Path  string
+	Key   string
+}
+
+// Store reads secrets. Secrets that are documents of keys are returned as a
+// map of their keys, other secrets as a map with the single key "".
+type Store interface {
+	Read(path string) (map[string]string, error)
+}
+
+// IsRef returns true if v is a secret reference.
+func IsRef(v string) bool {
+	for _, scheme := range []string{schemeRef, StoreAws, StoreSops} {
+		if strings.HasPrefix(v, scheme+"://") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseRef parses secretref://<store>/<path>#<key> and its shorthands
+// aws-sm://<path>#<key> and sops://<path>#<key>.
+func ParseRef(v string) (*Ref, error) {
+	parts := strings.SplitN(v, "://", 2)
+	if len(parts) != 2 || !IsRef(v) {
+		return nil, fmt.Errorf("invalid secret reference: %s", v)
+	}
+
+	r := &Ref{Store: parts[0]}
+	rest := parts[1]
+
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		rest, r.Key = rest[:i], rest[i+1:]
+	}
+
+	if r.Store == schemeRef {
+		sp := strings.SplitN(rest, "/", 2)
+		if len(sp) != 2 {
+			return nil, fmt.Errorf("invalid secret reference: %s", v)
+		}
+
+		r.Store, rest = sp[0], sp[1]
+	}
+
+	r.Path = strings.Trim(rest, "/")
+
+	if r.Store == "" || r.Path == "" {
+		return nil, fmt.Errorf("invalid secret reference: %s", v)
+	}
+
+	return r, nil
+}
+
+func (r Ref) String() string {
+	s := fmt.Sprintf("%s://%s/%s", schemeRef, r.Store, r.Path)
+
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+
+	return s
+}
+
+// Refs returns the sorted keys of env whose values are secret references.
+func Refs(env map[string]string) []string {
+	ks := []string{}
+
+	for k, v := range env {
+		if IsRef(v) {
+			ks = append(ks, k)
+		}
+	}
+
+	sort.Strings(ks)
+
+	return ks
+}
+
+// Resolver resolves references against the stores configured by name.
+type Resolver struct {
+	Stores map[string]Store
+}
+
+// FromEnv configures the stores named in SECRET_STORES, a comma separated
+// list. Each store takes its settings from the environment: SECRETS_FILE_DIR,
+// SECRETS_SOPS_DIR, VAULT_ADDR and VAULT_TOKEN, and AWS_REGION with the
+// default aws credentials. A store that is not listed is never read, even
+// when its settings are present.
+func FromEnv() (*Resolver, error) {
+	r := &Resolver{Stores: map[string]Store{}}
+
+	for _, name := range strings.Split(os.Getenv("SECRET_STORES"), ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case StoreAws:
+			region := os.Getenv("AWS_REGION")
+			if region == "" {
+				return nil, fmt.Errorf("secret store %s requires AWS_REGION", name)
+			}
+
+			s, err := session.NewSession(aws.NewConfig().WithRegion(region))
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			r.Stores[StoreAws] = &Aws{Credentials: s.Config.Credentials, Region: region}
+		case StoreFile:
+			dir := os.Getenv("SECRETS_FILE_DIR")
+			if dir == "" {
+				return nil, fmt.Errorf("secret store %s requires SECRETS_FILE_DIR", name)
+			}
+
+			r.Stores[StoreFile] = &File{Root: dir}
+		case StoreSops:
+			dir := os.Getenv("SECRETS_SOPS_DIR")
+			if dir == "" {
+				return nil, fmt.Errorf("secret store %s requires SECRETS_SOPS_DIR", name)
+			}
+
+			r.Stores[StoreSops] = &Sops{Root: dir}
+		case StoreVault:
+			addr := os.Getenv("VAULT_ADDR")
+			if addr == "" {
+				return nil, fmt.Errorf("secret store %s requires VAULT_ADDR", name)
+			}
+
+			r.Stores[StoreVault] = &Vault{Address: addr, Token: os.Getenv("VAULT_TOKEN")}
+		default:
+			return nil, fmt.Errorf("unknown secret store: %s", name)
+		}
+	}
+
+	return r, nil
+}
+
+// Prefix allows references to the paths under Path in Store.
+type Prefix struct {
+	Store string
+	Path  string
+}
+
+// ParsePrefixes parses a comma separated list of <store>/<path prefix>.
+func ParsePrefixes(v string) ([]Prefix, error) {
+	ps := []Prefix{}
+
+	for _, s := range strings.Split(v, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+
+		parts := strings.SplitN(s, "/", 2)
+
+		switch parts[0] {
+		case StoreAws, StoreFile, StoreSops, StoreVault:
+		default:
+			return nil, fmt.Errorf("invalid secret path: %s", s)
+		}
+
+		p := Prefix{Store: parts[0]}
+
+		if len(parts) == 2 {
+			p.Path = strings.Trim(parts[1], "/")
+		}
+
+		if p.Path != "" && path.Clean(p.Path) != p.Path {
+			return nil, fmt.Errorf("invalid secret path: %s", s)
+		}
+
+		ps = append(ps, p)
+	}
+
+	return ps, nil
+}
+
+// Allows returns true if ref is in the store of p and under its path.
+func (p Prefix) Allows(ref *Ref) bool {
+	if ref.Store != p.Store || path.Clean(ref.Path) != ref.Path {
+		return false
+	}
+
+	return p.Path == "" || ref.Path == p.Path || strings.HasPrefix(ref.Path, p.Path+"/")
+}
+
+// Check returns an error for the first reference in env that none of
+// prefixes allows.
+func Check(env map[string]string, prefixes []Prefix) error {
+	for _, k := range Refs(env) {
+		ref, err := ParseRef(env[k])
+		if err != nil {
+			return fmt.Errorf("env %s: %s", k, err)
+		}
+
+		allowed := false
+
+		for _, p := range prefixes {
+			if p.Allows(ref) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return fmt.Errorf("env %s: secret reference not allowed: %s", k, env[k])
+		}
+	}
+
+	return nil
+}
+
+// Resolve returns a copy of env with every secret reference replaced by the
+// value of the secret. Each secret is read once per call.
+func (r *Resolver) Resolve(env map[string]string) (map[string]string, error) {
+	out := map[string]string{}
+	docs := map[string]map[string]string{}
+
+	for k, v := range env {
+		out[k] = v
+	}
+
+	for _, k := range Refs(env) {
+		ref, err := ParseRef(env[k])
+		if err != nil {
+			return nil, errors.WithStack(fmt.Errorf("env %s: %s", k, err))
+		}
+
+		id := ref.Store + "/" + ref.Path
+
+		doc, ok := docs[id]
+		if !ok {
+			doc, err = r.read(ref)
+			if err != nil {
+				return nil, errors.WithStack(fmt.Errorf("env %s: %s", k, err))
+			}
+
+			docs[id] = doc
+		}
+
+		sv, ok := doc[ref.Key]
+		if !ok {
+			if ref.Key == "" {
+				return nil, errors.WithStack(fmt.Errorf("env %s: secret %s has keys, specify one with #<key>", k, ref.Path))
+			}
+
+			return nil, errors.WithStack(fmt.Errorf("env %s: no such key in secret %s: %s", k, ref.Path, ref.Key))
+		}
+
+		out[k] = sv
+	}
+
+	return out, nil
+}
+
+func (r *Resolver) read(ref *Ref) (map[string]string, error) {
+	if r == nil || r.Stores[ref.Store] == nil {
+		return nil, fmt.Errorf("secret store not configured: %s", ref.Store)
+	}
+
+	return r.Stores[ref.Store].Read(ref.Path)
+}
@@ -0,0 +1,162 @@
+package secrets_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/convox/pkg/secrets"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := map[string]secrets.Ref{
+		"secretref://vault/secret/data/db#password": {Store: "vault", Path: "secret/data/db", Key: "password"},
+		"secretref://file/db.yml#url":               {Store: "file", Path: "db.yml", Key: "url"},
+		"aws-sm://prod/db#password":                 {Store: "aws-sm", Path: "prod/db", Key: "password"},
+		"sops://db.enc.yaml":                        {Store: "sops", Path: "db.enc.yaml"},
+	}
+
+	for v, ref := range tests {
+		r, err := secrets.ParseRef(v)
+		require.NoError(t, err, v)
+		require.Equal(t, ref, *r, v)
+	}
+
+	for _, v := range []string{"secretref://vault", "secretref:///db#key", "aws-sm://#key", "https://example.org"} {
+		_, err := secrets.ParseRef(v)
+		require.EqualError(t, err, "invalid secret reference: "+v)
+	}
+
+	require.True(t, secrets.IsRef("aws-sm://prod/db"))
+	require.False(t, secrets.IsRef("postgres://db:5432"))
+	require.Equal(t, []string{"A", "C"}, secrets.Refs(map[string]string{"A": "sops://a#b", "B": "plain", "C": "secretref://file/c"}))
+}
+
+func TestResolveFile(t *testing.T) {
+	dir := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.yml"), []byte("password: hunter2\nport: 5432\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("abc123\n"), 0600))
+
+	r := &secrets.Resolver{Stores: map[string]secrets.Store{secrets.StoreFile: &secrets.File{Root: dir}}}
+
+	env, err := r.Resolve(map[string]string{
+		"DB_PASS": "secretref://file/db.yml#password",
+		"DB_PORT": "secretref://file/db.yml#port",
+		"PLAIN":   "value",
+		"TOKEN":   "secretref://file/token",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"DB_PASS": "hunter2", "DB_PORT": "5432", "PLAIN": "value", "TOKEN": "abc123"}, env)
+
+	tests := map[string]string{
+		"secretref://file/db.yml":        "env KEY: secret db.yml has keys, specify one with #<key>",
+		"secretref://file/db.yml#user":   "env KEY: no such key in secret db.yml: user",
+		"secretref://file/missing#key":   "env KEY: no such secret: missing",
+		"secretref://file/../etc/passwd": "env KEY: invalid secret path: ../etc/passwd",
+		"secretref://vault/secret/db#k":  "env KEY: secret store not configured: vault",
+	}
+
+	for v, msg := range tests {
+		_, err := r.Resolve(map[string]string{"KEY": v})
+		require.EqualError(t, err, msg, v)
+	}
+}
+
+func TestResolveVault(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token1" {
+			w.WriteHeader(403)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			w.Write([]byte(`{"data":{"data":{"password":"hunter2"},"metadata":{"version":3}}}`))
+		case "/v1/kv/db":
+			w.Write([]byte(`{"data":{"password":"swordfish"}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer ts.Close()
+
+	r := &secrets.Resolver{Stores: map[string]secrets.Store{secrets.StoreVault: &secrets.Vault{Address: ts.URL, Token: "token1"}}}
+
+	env, err := r.Resolve(map[string]string{
+		"V1": "secretref://vault/kv/db#password",
+		"V2": "secretref://vault/secret/data/db#password",
+	})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"V1": "swordfish", "V2": "hunter2"}, env)
+
+	_, err = r.Resolve(map[string]string{"KEY": "secretref://vault/secret/data/other#password"})
+	require.EqualError(t, err, "env KEY: no such secret: secret/data/other")
+}
+
+func TestCheck(t *testing.T) {
+	ps, err := secrets.ParsePrefixes("aws-sm/prod/app1, vault/secret/data/app1/,file")
+	require.NoError(t, err)
+	require.Equal(t, []secrets.Prefix{{Store: "aws-sm", Path: "prod/app1"}, {Store: "vault", Path: "secret/data/app1"}, {Store: "file"}}, ps)
+
+	require.NoError(t, secrets.Check(map[string]string{
+		"A": "aws-sm://prod/app1/db#password",
+		"B": "secretref://vault/secret/data/app1#key",
+		"C": "secretref://file/any/where",
+		"D": "plain",
+	}, ps))
+
+	tests := map[string]string{
+		"aws-sm://prod/app10/db#password":            "env KEY: secret reference not allowed: aws-sm://prod/app10/db#password",
+		"aws-sm://prod/app1/../app2/db#password":     "env KEY: secret reference not allowed: aws-sm://prod/app1/../app2/db#password",
+		"secretref://vault/secret/data/app2#key":     "env KEY: secret reference not allowed: secretref://vault/secret/data/app2#key",
+		"sops://app1/db.enc.yaml":                    "env KEY: secret reference not allowed: sops://app1/db.enc.yaml",
+		"secretref://aws-sm/prod/app1/db#password/x": "",
+	}
+
+	for v, msg := range tests {
+		err := secrets.Check(map[string]string{"KEY": v}, ps)
+		if msg == "" {
+			require.NoError(t, err, v)
+		} else {
+			require.EqualError(t, err, msg, v)
+		}
+	}
+
+	require.EqualError(t, secrets.Check(map[string]string{"KEY": "aws-sm://prod/db"}, nil), "env KEY: secret reference not allowed: aws-sm://prod/db")
+
+	for _, v := range []string{"s3/bucket", "vault/a/../b"} {
+		_, err := secrets.ParsePrefixes(v)
+		require.EqualError(t, err, "invalid secret path: "+v)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("SECRETS_FILE_DIR", t.TempDir())
+	t.Setenv("SECRET_STORES", "")
+
+	r, err := secrets.FromEnv()
+	require.NoError(t, err)
+	require.Empty(t, r.Stores)
+
+	t.Setenv("SECRET_STORES", "file")
+
+	r, err = secrets.FromEnv()
+	require.NoError(t, err)
+	require.Len(t, r.Stores, 1)
+	require.NotNil(t, r.Stores[secrets.StoreFile])
+
+	t.Setenv("SECRET_STORES", "file,vault")
+
+	_, err = secrets.FromEnv()
+	require.EqualError(t, err, "secret store vault requires VAULT_ADDR")
+
+	t.Setenv("SECRET_STORES", "s3")
+
+	_, err = secrets.FromEnv()
+	require.EqualError(t, err, "unknown secret store: s3")
+}
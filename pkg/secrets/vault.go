@@ -0,0 +1,74 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Vault reads secrets from the HTTP API of a Vault server. Paths are api
+// paths below /v1 such as secret/data/db for a kv version 2 engine.
+type Vault struct {
+	Address string
+	Token   string
+
+	Client *http.Client
+}
+
+func (v *Vault) Read(path string) (map[string]string, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/%s", strings.TrimRight(v.Address, "/"), path), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	req.Header.Set("X-Vault-Token", v.Token)
+
+	res, err := v.client().Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("no such secret: %s", path)
+	default:
+		return nil, fmt.Errorf("vault returned %d for %s", res.StatusCode, path)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// kv version 2 nests the secret below data with its metadata alongside
+	if inner, ok := body.Data["data"].(map[string]interface{}); ok {
+		if _, ok := body.Data["metadata"]; ok {
+			return stringValues(inner), nil
+		}
+	}
+
+	return stringValues(body.Data), nil
+}
+
+func (v *Vault) client() *http.Client {
+	if v.Client != nil {
+		return v.Client
+	}
+
+	return &http.Client{Timeout: 10 * time.Second}
+}
@@ -5,13 +5,16 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -25,12 +28,19 @@ import (
 	"github.com/convox/convox/pkg/options"
 	"github.com/convox/convox/pkg/prefix"
 	"github.com/convox/convox/pkg/structs"
+	shellquote "github.com/kballard/go-shellquote"
 	"github.com/moby/buildkit/frontend/dockerfile/dockerignore"
 )
 
 const (
 	ScannerStartSize = 4096
 	ScannerMaxSize   = 20 * 1024 * 1024
+
+	syncDownloadInterval = 2 * time.Second
+
+	// syncReplayTimeout is how long a restarted process is waited on for a
+	// replacement to replay the synced changes to
+	syncReplayTimeout = 2 * time.Minute
 )
 
 var (
@@ -51,8 +61,17 @@ type Options2 struct {
 }
 
 type buildSource struct {
-	Local  string
-	Remote string
+	Local    string
+	Remote   string
+	Ignores  []string
+	Download []string
+}
+
+// syncDownloads remembers the files that downloads wrote to the host so
+// that they are not synced straight back to the container.
+type syncDownloads struct {
+	lock sync.Mutex
+	sums map[string][sha256.Size]byte
 }
 
 func (*Start) Start2(ctx context.Context, w io.Writer, opts Options2) error {
@@ -161,7 +180,7 @@ func (*Start) Start2(ctx context.Context, w io.Writer, opts Options2) error {
 			continue
 		}
 
-		if m.Services[i].Build.Path != "" {
+		if m.Services[i].Build.Path != "" || developmentSync(m.Services[i]) {
 			go opts.watchChanges(ctx, pw, m, m.Services[i].Name, wd, errch)
 		}
 	}
@@ -325,16 +344,9 @@ func (opts Options2) handleAdds(pid, remote string, adds []changes.Change) error
 		return nil
 	}
 
-	if !filepath.IsAbs(remote) {
-		var buf bytes.Buffer
-
-		if _, err := opts.Provider.ProcessExec(opts.App, pid, "pwd", &buf, structs.ProcessExecOptions{}); err != nil {
-			return errors.WithStack(fmt.Errorf("%s pwd: %s", pid, err))
-		}
-
-		wd := strings.TrimSpace(buf.String())
-
-		remote = filepath.Join(wd, remote)
+	remote, err := opts.remotePath(pid, remote)
+	if err != nil {
+		return err
 	}
 
 	rp, wp := io.Pipe()
@@ -401,6 +413,193 @@ func (opts Options2) handleRemoves(pid string, removes []changes.Change) error {
 	return opts.Provider.FilesDelete(opts.App, pid, changes.Files(removes))
 }
 
+// handleChange runs the onChange action of a service on process pid after
+// files were synced to it.
+func (opts Options2) handleChange(pid string, oc manifest.OnChange) error {
+	action, arg, err := oc.Action()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	switch action {
+	case manifest.OnChangeCommand:
+		return opts.processCommand(pid, shellquote.Join("sh", "-c", arg))
+	case manifest.OnChangeRestart:
+		return opts.Provider.ProcessStop(opts.App, pid)
+	case manifest.OnChangeSignal:
+		return opts.processSignal(pid, arg)
+	}
+
+	return nil
+}
+
+// processSignal sends signal to the containers of process pid through the
+// container runtime, which delivers it to the container's main process
+// whatever its pid. Runtimes that docker can not reach fall back to kill in
+// the process.
+func (opts Options2) processSignal(pid, signal string) error {
+	data, err := Exec.Execute("docker", "ps", "-q", "--filter", fmt.Sprintf("label=io.kubernetes.pod.name=%s", pid), "--filter", "label=io.kubernetes.docker.type=container")
+	if err != nil || strings.TrimSpace(string(data)) == "" {
+		return opts.processCommand(pid, fmt.Sprintf("kill -%s 1", signal))
+	}
+
+	args := append([]string{"kill", "--signal", signal}, strings.Fields(string(data))...)
+
+	if out, err := Exec.Execute("docker", args...); err != nil {
+		return errors.WithStack(fmt.Errorf("%s docker kill: %s: %s", pid, err, strings.TrimSpace(string(out))))
+	}
+
+	return nil
+}
+
+// handleDownloads copies the download paths of bs from process pid to the
+// host and returns the paths it wrote, relative to bs.Local.
+func (opts Options2) handleDownloads(pid string, bs buildSource, sd *syncDownloads) ([]string, error) {
+	remote, err := opts.remotePath(pid, bs.Remote)
+	if err != nil {
+		return nil, err
+	}
+
+	written := []string{}
+
+	for _, d := range bs.Download {
+		files, err := opts.downloadFiles(pid, path.Join(remote, d))
+		if err != nil {
+			return written, err
+		}
+
+		for rel, data := range files {
+			file := filepath.Join(d, rel)
+
+			ok, err := sd.write(bs.Local, file, data)
+			if err != nil {
+				return written, err
+			}
+
+			if ok {
+				written = append(written, file)
+			}
+		}
+	}
+
+	sort.Strings(written)
+
+	return written, nil
+}
+
+// downloadFiles returns the regular files at remote in process pid by
+// their path relative to remote. A missing remote returns no files.
+func (opts Options2) downloadFiles(pid, remote string) (map[string][]byte, error) {
+	r, err := opts.Provider.FilesDownload(opts.App, pid, remote)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	files := map[string][]byte{}
+	base := strings.TrimPrefix(remote, "/")
+
+	tr := tar.NewReader(r)
+
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		rel, err := filepath.Rel(base, strings.TrimPrefix(h.Name, "/"))
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		files[rel] = data
+	}
+
+	return files, nil
+}
+
+func (opts Options2) processCommand(pid, command string) error {
+	var buf bytes.Buffer
+
+	code, err := opts.Provider.ProcessExec(opts.App, pid, command, &buf, structs.ProcessExecOptions{})
+	if err != nil {
+		return errors.WithStack(fmt.Errorf("%s %s: %s", pid, command, err))
+	}
+
+	if code != 0 {
+		return errors.WithStack(fmt.Errorf("%s %s: exit %d: %s", pid, command, code, strings.TrimSpace(buf.String())))
+	}
+
+	return nil
+}
+
+// remotePath resolves a relative remote against the working directory of
+// process pid.
+func (opts Options2) remotePath(pid, remote string) (string, error) {
+	if filepath.IsAbs(remote) {
+		return remote, nil
+	}
+
+	var buf bytes.Buffer
+
+	if _, err := opts.Provider.ProcessExec(opts.App, pid, "pwd", &buf, structs.ProcessExecOptions{}); err != nil {
+		return "", errors.WithStack(fmt.Errorf("%s pwd: %s", pid, err))
+	}
+
+	wd := strings.TrimSpace(buf.String())
+
+	return filepath.Join(wd, remote), nil
+}
+
+// replayChanges copies the changes synced earlier in the session to running
+// processes that have not received them yet, such as the replacements of
+// processes restarted by onChange, and returns how many it copied them to.
+func (opts Options2) replayChanges(pw prefix.Writer, service string, bs buildSource, pss structs.Processes, synced map[string]changes.Change, seen map[string]bool) int {
+	cs := []changes.Change{}
+
+	for _, c := range synced {
+		cs = append(cs, c)
+	}
+
+	adds, removes := changes.Partition(cs)
+
+	replayed := 0
+
+	for _, ps := range pss {
+		if seen[ps.Id] || ps.Status != "running" {
+			continue
+		}
+
+		pw.Writef("convox", "sync: %d changes to <dir>%s</dir> on <service>%s</service> process %s\n", len(cs), common.CoalesceString(bs.Remote, "."), service, ps.Id)
+
+		if err := opts.handleAdds(ps.Id, bs.Remote, adds); err != nil {
+			pw.Writef("convox", "sync add error: %s\n", err)
+			continue
+		}
+
+		if err := opts.handleRemoves(ps.Id, removes); err != nil {
+			pw.Writef("convox", "sync remove error: %s\n", err)
+			continue
+		}
+
+		seen[ps.Id] = true
+		replayed++
+	}
+
+	return replayed
+}
+
 func (opts Options2) stopProcess(pid string, wg *sync.WaitGroup) {
 	defer wg.Done()
 	opts.Provider.ProcessStop(opts.App, pid)
@@ -455,24 +654,80 @@ func (opts Options2) waitForBuild(ctx context.Context, id string) error {
 }
 
 func (opts Options2) watchChanges(ctx context.Context, pw prefix.Writer, m *manifest.Manifest, service, root string, ch chan error) {
-	bss, err := buildSources(m, root, service)
+	s, err := m.Service(service)
 	if err != nil {
 		ch <- fmt.Errorf("sync error: %s", err)
 		return
 	}
 
-	ignores, err := buildIgnores(root, service)
+	var oc manifest.OnChange
+
+	if s.Development != nil {
+		oc = s.Development.OnChange
+	}
+
+	bss := []buildSource{}
+	ignores := []string{}
+
+	if developmentSync(*s) {
+		bss, err = developmentSources(root, *s)
+	} else {
+		bss, err = buildSources(m, root, service)
+		if err == nil {
+			ignores, err = buildIgnores(root, service)
+		}
+	}
 	if err != nil {
 		ch <- fmt.Errorf("sync error: %s", err)
 		return
 	}
 
 	for _, bs := range bss {
-		go opts.watchPath(ctx, pw, service, root, bs, ignores, ch)
+		sd := &syncDownloads{sums: map[string][sha256.Size]byte{}}
+
+		go opts.watchPath(ctx, pw, service, root, bs, append(append([]string{}, ignores...), bs.Ignores...), oc, sd, ch)
+
+		if len(bs.Download) > 0 {
+			go opts.watchDownloads(ctx, pw, service, bs, sd)
+		}
+	}
+}
+
+func (opts Options2) watchDownloads(ctx context.Context, pw prefix.Writer, service string, bs buildSource, sd *syncDownloads) {
+	tick := time.Tick(syncDownloadInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			pss, err := opts.Provider.ProcessList(opts.App, structs.ProcessListOptions{Service: options.String(service)})
+			if err != nil {
+				pw.Writef("convox", "download error: %s\n", err)
+				continue
+			}
+
+			// every process runs the same code, the first running one is the source
+			for _, ps := range pss {
+				if ps.Status != "running" {
+					continue
+				}
+
+				written, err := opts.handleDownloads(ps.Id, bs, sd)
+				for _, w := range written {
+					pw.Writef("convox", "download: <dir>%s</dir> from <service>%s</service>\n", w, service)
+				}
+				if err != nil {
+					pw.Writef("convox", "download error: %s\n", err)
+				}
+
+				break
+			}
+		}
 	}
 }
 
-func (opts Options2) watchPath(ctx context.Context, pw prefix.Writer, service, root string, bs buildSource, ignores []string, ch chan error) {
+func (opts Options2) watchPath(ctx context.Context, pw prefix.Writer, service, root string, bs buildSource, ignores []string, oc manifest.OnChange, sd *syncDownloads, ch chan error) {
 	cch := make(chan changes.Change, 1)
 
 	abs, err := filepath.Abs(bs.Local)
@@ -493,6 +748,12 @@ func (opts Options2) watchPath(ctx context.Context, pw prefix.Writer, service, r
 		return
 	}
 
+	action, _, err := oc.Action()
+	if err != nil {
+		ch <- fmt.Errorf("sync error: %s", err)
+		return
+	}
+
 	pw.Writef("convox", "starting sync from <dir>%s</dir> to <dir>%s</dir> on <service>%s</service>\n", rel, common.CoalesceString(bs.Remote, "."), service)
 
 	go changes.Watch(abs, cch, changes.WatchOptions{
@@ -502,6 +763,14 @@ func (opts Options2) watchPath(ctx context.Context, pw prefix.Writer, service, r
 	tick := time.Tick(1000 * time.Millisecond)
 	var chgs []changes.Change
 
+	// restarted processes come back with the files of their image, the
+	// changes synced so far are replayed to them. Processes are only listed
+	// for a replay while a restart is waiting for its replacement.
+	synced := map[string]changes.Change{}
+	seen := map[string]bool{}
+	restarting := 0
+	replayUntil := time.Time{}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -509,7 +778,11 @@ func (opts Options2) watchPath(ctx context.Context, pw prefix.Writer, service, r
 		case c := <-cch:
 			chgs = append(chgs, c)
 		case <-tick:
-			if len(chgs) == 0 {
+			if restarting > 0 && time.Now().After(replayUntil) {
+				restarting = 0
+			}
+
+			if len(chgs) == 0 && restarting == 0 {
 				continue
 			}
 
@@ -519,7 +792,11 @@ func (opts Options2) watchPath(ctx context.Context, pw prefix.Writer, service, r
 				continue
 			}
 
-			adds, removes := changes.Partition(chgs)
+			if restarting > 0 {
+				restarting = max(restarting-opts.replayChanges(pw, service, bs, pss, synced, seen), 0)
+			}
+
+			adds, removes := changes.Partition(sd.fresh(chgs))
 
 			for _, ps := range pss {
 				switch {
@@ -531,8 +808,9 @@ func (opts Options2) watchPath(ctx context.Context, pw prefix.Writer, service, r
 					}
 				}
 
-				if err := opts.handleAdds(ps.Id, bs.Remote, adds); err != nil {
-					pw.Writef("convox", "sync add error: %s\n", err)
+				aerr := opts.handleAdds(ps.Id, bs.Remote, adds)
+				if aerr != nil {
+					pw.Writef("convox", "sync add error: %s\n", aerr)
 				}
 
 				switch {
@@ -544,8 +822,36 @@ func (opts Options2) watchPath(ctx context.Context, pw prefix.Writer, service, r
 					}
 				}
 
-				if err := opts.handleRemoves(ps.Id, removes); err != nil {
-					pw.Writef("convox", "sync remove error: %s\n", err)
+				rerr := opts.handleRemoves(ps.Id, removes)
+				if rerr != nil {
+					pw.Writef("convox", "sync remove error: %s\n", rerr)
+				}
+
+				if action == "" || (len(adds) == 0 && len(removes) == 0) || aerr != nil || rerr != nil {
+					continue
+				}
+
+				pw.Writef("convox", "%s: <service>%s</service> process %s\n", action, service, ps.Id)
+
+				if err := opts.handleChange(ps.Id, oc); err != nil {
+					pw.Writef("convox", "%s error: %s\n", action, err)
+					continue
+				}
+
+				if action == manifest.OnChangeRestart {
+					seen[ps.Id] = true
+					restarting++
+					replayUntil = time.Now().Add(syncReplayTimeout)
+				}
+			}
+
+			if action == manifest.OnChangeRestart {
+				for _, c := range append(adds, removes...) {
+					synced[c.Path] = c
+				}
+
+				for _, ps := range pss {
+					seen[ps.Id] = true
 				}
 			}
 
@@ -751,6 +1057,92 @@ lines:
 	return bss, nil
 }
 
+// developmentSync reports whether the sync paths of a service are set in
+// its development section rather than read from its Dockerfile.
+func developmentSync(s manifest.Service) bool {
+	return s.Development != nil && len(s.Development.Sync) > 0
+}
+
+func developmentSources(root string, s manifest.Service) ([]buildSource, error) {
+	bss := []buildSource{}
+
+	for _, sy := range s.Development.Sync {
+		abs, err := filepath.Abs(filepath.Join(root, sy.SyncFrom()))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		stat, err := os.Stat(abs)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if stat.IsDir() && !strings.HasSuffix(abs, "/") {
+			abs = abs + "/"
+		}
+
+		bss = append(bss, buildSource{Local: abs, Remote: sy.To, Ignores: sy.Ignore, Download: sy.Download})
+	}
+
+	return bss, nil
+}
+
+// fresh returns the changes that were not written by a download.
+func (sd *syncDownloads) fresh(cs []changes.Change) []changes.Change {
+	sd.lock.Lock()
+	defer sd.lock.Unlock()
+
+	fcs := []changes.Change{}
+
+	for _, c := range cs {
+		if sum, ok := sd.sums[c.Path]; ok && c.Operation == "add" {
+			delete(sd.sums, c.Path)
+
+			if data, err := os.ReadFile(filepath.Join(c.Base, c.Path)); err == nil && sha256.Sum256(data) == sum {
+				continue
+			}
+		}
+
+		fcs = append(fcs, c)
+	}
+
+	return fcs
+}
+
+// write writes data to file below local unless it already holds it.
+func (sd *syncDownloads) write(local, file string, data []byte) (bool, error) {
+	target := filepath.Join(local, file)
+
+	mode := os.FileMode(0644)
+
+	if stat, err := os.Stat(target); err == nil {
+		cur, err := os.ReadFile(target)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+
+		if bytes.Equal(cur, data) {
+			return false, nil
+		}
+
+		mode = stat.Mode()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	sd.lock.Lock()
+	sd.sums[file] = sha256.Sum256(data)
+	sd.lock.Unlock()
+
+	if err := os.WriteFile(target, data, mode); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return true, nil
+}
+
 type stackTracer interface {
 	StackTrace() errors.StackTrace
 }
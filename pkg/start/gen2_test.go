@@ -1,10 +1,12 @@
 package start_test
 
 import (
+	"archive/tar"
 	"bytes"
 	"context"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -139,3 +141,104 @@ func TestStart2Options(t *testing.T) {
 	p.AssertExpectations(t)
 	e.AssertExpectations(t)
 }
+
+func TestStart2Development(t *testing.T) {
+	common.ProviderWaitDuration = 1
+
+	p := &structs.MockProvider{}
+
+	uploads := []string{}
+
+	p.On("AppGet", "app1").Return(&structs.App{Name: "app1", Generation: "2", Status: "running"}, nil)
+	p.On("ReleaseList", "app1", structs.ReleaseListOptions{Limit: options.Int(1)}).Return(structs.Releases{{Id: "release1"}}, nil)
+	p.On("ReleaseGet", "app1", "release1").Return(&structs.Release{}, nil)
+	p.On("AppLogs", "app1", structs.LogsOptions{Prefix: options.Bool(true), Since: options.Duration(1 * time.Second)}).Return(io.NopCloser(strings.NewReader("")), nil)
+	p.On("ProcessList", "app1", structs.ProcessListOptions{Service: options.String("web")}).Return(structs.Processes{{Id: "pid1", Status: "running"}}, nil)
+	p.On("FilesDownload", "app1", "pid1", "/app/Gemfile.lock").Return(func(app, pid, file string) io.Reader {
+		return testTarball(t, map[string]string{"app/Gemfile.lock": "GEM\n  remote: https://rubygems.org/\n"})
+	}, nil)
+	p.On("FilesUpload", "app1", "pid1", mock.Anything, structs.FileTransterOptions{}).Return(func(app, pid string, r io.Reader, opts structs.FileTransterOptions) error {
+		tr := tar.NewReader(r)
+		for {
+			h, err := tr.Next()
+			if err != nil {
+				return nil
+			}
+			uploads = append(uploads, h.Name)
+		}
+	})
+	p.On("ProcessExec", "app1", "pid1", "sh -c 'bundle install'", mock.Anything, structs.ProcessExecOptions{}).Return(0, nil)
+
+	dir := t.TempDir()
+
+	manifest := `
+services:
+  web:
+    image: ruby
+    port: 3000
+    development:
+      onChange: "command: bundle install"
+      sync:
+        - from: src
+          to: /app
+          ignore:
+            - tmp
+          download:
+            - Gemfile.lock
+`
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "convox.yml"), []byte(manifest), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src", "tmp"), 0755))
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+	os.Chdir(dir)
+	defer os.Chdir(cwd)
+
+	s := start.New()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3500*time.Millisecond)
+	defer cancel()
+
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		os.WriteFile(filepath.Join(dir, "src", "Gemfile"), []byte("source 'https://rubygems.org'\n"), 0644)
+		os.WriteFile(filepath.Join(dir, "src", "tmp", "cache"), []byte("cache"), 0644)
+	}()
+
+	buf := bytes.Buffer{}
+
+	err = s.Start2(ctx, &buf, start.Options2{App: "app1", Provider: p, Sync: true, Test: true})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "src", "Gemfile.lock"))
+	require.NoError(t, err)
+	require.Equal(t, "GEM\n  remote: https://rubygems.org/\n", string(data))
+
+	// the downloaded lockfile is not synced back to the container
+	require.Equal(t, []string{"/app/Gemfile"}, uploads)
+
+	out := buf.String()
+	require.Contains(t, out, "<system>convox</system> | starting sync from <dir>src</dir> to <dir>/app</dir> on <service>web</service>\n")
+	require.Contains(t, out, "<system>convox</system> | sync: <dir>Gemfile</dir> to <dir>/app</dir> on <service>web</service>\n")
+	require.Contains(t, out, "<system>convox</system> | command: <service>web</service> process pid1\n")
+	require.Contains(t, out, "<system>convox</system> | download: <dir>Gemfile.lock</dir> from <service>web</service>\n")
+
+	p.AssertExpectations(t)
+}
+
+func testTarball(t *testing.T, files map[string]string) io.Reader {
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(data))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+
+	return &buf
+}
@@ -0,0 +1,143 @@
+package structs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+const (
+	// AccessActionRead matches every read-only (GET) route.
+	AccessActionRead = "read"
+	// AccessActionWrite matches every mutating (non-GET) route.
+	AccessActionWrite = "write"
+)
+
+// AccessPolicy is a named allow-list evaluated against the api route name
+// and the app a request targets. A token carrying policies is allowed a
+// request when any rule of any of its policies matches.
+type AccessPolicy struct {
+	Id          string       `json:"id"`
+	Description string       `json:"description,omitempty"`
+	Rules       []AccessRule `json:"rules"`
+}
+
+type AccessPolicies []AccessPolicy
+
+// AccessRule grants Actions on Apps. Apps are glob patterns ("payments-*");
+// "*" also matches rack-level routes that do not target an app. Actions are
+// route names ("ReleasePromote"), route globs ("Service*"), or the
+// AccessActionRead/AccessActionWrite shorthands.
+type AccessRule struct {
+	Apps    []string `json:"apps"`
+	Actions []string `json:"actions"`
+}
+
+type AccessPolicyOptions struct {
+	Description *string `param:"description"`
+	Rules       *string `param:"rules"`
+}
+
+func (ps AccessPolicies) Less(i, j int) bool { return ps[i].Id < ps[j].Id }
+
+// Allows reports whether the policy permits route against app. write is true
+// for mutating requests.
+func (p *AccessPolicy) Allows(route, app string, write bool) bool {
+	for _, r := range p.Rules {
+		if r.matchApp(app) && r.matchAction(route, write) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r AccessRule) String() string {
+	return fmt.Sprintf("%s=%s", strings.Join(r.Apps, ","), strings.Join(r.Actions, ","))
+}
+
+func (r AccessRule) matchAction(route string, write bool) bool {
+	for _, a := range r.Actions {
+		switch a {
+		case "*":
+			return true
+		case AccessActionRead:
+			if !write {
+				return true
+			}
+		case AccessActionWrite:
+			if write {
+				return true
+			}
+		default:
+			if g, err := glob.Compile(a); err == nil && g.Match(route) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (r AccessRule) matchApp(app string) bool {
+	for _, a := range r.Apps {
+		if a == "*" {
+			return true
+		}
+
+		if app == "" {
+			continue
+		}
+
+		if g, err := glob.Compile(a); err == nil && g.Match(app) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseAccessRules parses space separated rules of the form
+// apps=actions, for example "payments-*=write *=read".
+func ParseAccessRules(s string) ([]AccessRule, error) {
+	rs := []AccessRule{}
+
+	for _, f := range strings.Fields(s) {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, ErrBadRequest("invalid access rule: %s (expected apps=actions)", f)
+		}
+
+		r := AccessRule{
+			Apps:    splitAccessList(parts[0]),
+			Actions: splitAccessList(parts[1]),
+		}
+
+		for _, p := range append(append([]string{}, r.Apps...), r.Actions...) {
+			if _, err := glob.Compile(p); err != nil {
+				return nil, ErrBadRequest("invalid access rule pattern: %s", p)
+			}
+		}
+
+		rs = append(rs, r)
+	}
+
+	if len(rs) == 0 {
+		return nil, ErrBadRequest("at least one access rule is required")
+	}
+
+	return rs, nil
+}
+
+func splitAccessList(s string) []string {
+	out := []string{}
+
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,54 @@
+package structs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAccessRules(t *testing.T) {
+	rs, err := ParseAccessRules("payments-*,billing=write *=read,ProcessStop")
+	require.NoError(t, err)
+	require.Equal(t, []AccessRule{
+		{Apps: []string{"payments-*", "billing"}, Actions: []string{"write"}},
+		{Apps: []string{"*"}, Actions: []string{"read", "ProcessStop"}},
+	}, rs)
+
+	_, err = ParseAccessRules("payments-*")
+	require.EqualError(t, err, "invalid access rule: payments-* (expected apps=actions)")
+
+	_, err = ParseAccessRules("  ")
+	require.EqualError(t, err, "at least one access rule is required")
+}
+
+func TestAccessPolicyAllows(t *testing.T) {
+	ap := AccessPolicy{
+		Id: "team",
+		Rules: []AccessRule{
+			{Apps: []string{"payments-*"}, Actions: []string{"write"}},
+			{Apps: []string{"*"}, Actions: []string{"read", "ProcessStop", "Service*"}},
+		},
+	}
+
+	tests := []struct {
+		route string
+		app   string
+		write bool
+		allow bool
+	}{
+		{"ReleasePromote", "payments-api", true, true},
+		{"ReleasePromote", "marketing", true, false},
+		{"AppList", "", false, true},
+		{"ProcessStop", "marketing", true, true},
+		{"ServiceRestart", "marketing", true, true},
+		{"ReleaseCreate", "marketing", true, false},
+		{"SystemUpdate", "", true, false},
+		{"CertificateCreate", "", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route+"/"+tt.app, func(t *testing.T) {
+			require.Equal(t, tt.allow, ap.Allows(tt.route, tt.app, tt.write))
+		})
+	}
+}
@@ -1,10 +1,29 @@
 package structs
 
+import "time"
+
 const (
 	AppParamBuildLabels = "BuildLabels"
 	AppParamBuildCpu    = "BuildCpu"
 	AppParamBuildMem    = "BuildMem"
 	AppParamBuildArch   = "BuildArch"
+
+	// AppParamDeployApprovals and AppParamDeployFreeze set the deploy policy
+	// of an app alongside the deploy section of its manifest. Freeze windows
+	// are separated by semicolons.
+	AppParamDeployApprovals = "DeployApprovals"
+	AppParamDeployFreeze    = "DeployFreeze"
+
+	// AppParamSecretRefresh is how often env values that reference external
+	// secrets are resolved again, 0 turns it off. AppParamSecretRestart set
+	// to true restarts the services whose secrets changed.
+	AppParamSecretRefresh = "SecretRefresh"
+	AppParamSecretRestart = "SecretRestart"
+
+	// AppParamSecretPaths is the comma separated list of <store>/<path>
+	// prefixes that the secret references in the env of an app must fall
+	// under. Only admins can set it.
+	AppParamSecretPaths = "SecretPaths"
 )
 
 type App struct {
@@ -15,7 +34,9 @@ type App struct {
 	Router     string `json:"router"`
 	Status     string `json:"status"`
 
-	Budget *AppBudget `json:"budget,omitempty"`
+	Budget    *AppBudget        `json:"budget,omitempty"`
+	Preview   *AppPreview       `json:"preview,omitempty"`
+	Promotion *ReleasePromotion `json:"promotion,omitempty"`
 
 	Outputs    map[string]string `json:"-"`
 	Parameters map[string]string `json:"parameters"`
@@ -29,6 +50,23 @@ type AppCreateOptions struct {
 	Timeout    *int    `flag:"timeout" param:"timeout"`
 }
 
+// AppPreview marks an app as a preview environment cloned from the Base
+// app. The rack deletes the app once it Expires.
+type AppPreview struct {
+	Base    string    `json:"base"`
+	Expires time.Time `json:"expires"`
+}
+
+// AppPreviewCreateOptions configure a preview app. Environment overrides
+// the env of the base app's active release, Resources are the resources of
+// the base app to share instead of provisioning fresh copies.
+type AppPreviewCreateOptions struct {
+	Build       *string           `param:"build"`
+	Environment map[string]string `param:"environment"`
+	Resources   []string          `param:"resources"`
+	Ttl         *time.Duration    `param:"ttl"`
+}
+
 type AppUpdateOptions struct {
 	Lock       *bool             `param:"lock"`
 	Parameters map[string]string `param:"parameters"`
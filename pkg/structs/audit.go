@@ -0,0 +1,35 @@
+package structs
+
+import "time"
+
+type AuditEntry struct {
+	Id     string            `json:"id"`
+	Time   time.Time         `json:"time"`
+	Actor  string            `json:"actor"`
+	Route  string            `json:"route"`
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	App    string            `json:"app,omitempty"`
+	Params map[string]string `json:"params,omitempty"`
+	Status int               `json:"status"`
+	Error  string            `json:"error,omitempty"`
+}
+
+type AuditEntries []AuditEntry
+
+type AuditListOptions struct {
+	Actor *string        `query:"actor"`
+	App   *string        `query:"app"`
+	Limit *int           `query:"limit"`
+	Route *string        `query:"route"`
+	Since *time.Duration `default:"24h" query:"since"`
+}
+
+func NewAuditEntry() *AuditEntry {
+	return &AuditEntry{
+		Id:   id("E", 10),
+		Time: time.Now().UTC(),
+	}
+}
+
+func (es AuditEntries) Less(i, j int) bool { return es[i].Time.Before(es[j].Time) }
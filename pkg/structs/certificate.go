@@ -1,6 +1,7 @@
 package structs
 
 import (
+	"math"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ type Certificate struct {
 	Domains    []string  `json:"domains"`
 	Expiration time.Time `json:"expiration"`
 	Status     string    `json:"status"`
+	RenewError string    `json:"renew-error,omitempty"`
 }
 
 type Certificates []Certificate
@@ -33,6 +35,12 @@ type CertificateListOptions struct {
 
 func (c Certificates) Less(i, j int) bool { return strings.ToUpper(c[i].Id) < strings.ToUpper(c[j].Id) }
 
+// DaysToExpiry is the number of whole days from now until the certificate
+// expires. It is negative once the certificate has expired.
+func (c *Certificate) DaysToExpiry(now time.Time) int {
+	return int(math.Floor(c.Expiration.Sub(now).Hours() / 24))
+}
+
 func (c *Certificate) Match(domain string) (bool, error) {
 	for _, d := range c.Domains {
 		g, err := glob.Compile(d, '.')
@@ -9,6 +9,12 @@ const (
 	// ConvoxJwtUserParam is the audit-trail actor identity from JWT claims.
 	ConvoxJwtUserParam = "CONVOX_JWT_USER"
 
+	// ConvoxPoliciesParam carries the access policy ids from JWT claims.
+	ConvoxPoliciesParam = "CONVOX_POLICIES"
+
+	// ConvoxAppsParam carries the apps a rack issued token is scoped to.
+	ConvoxAppsParam = "CONVOX_APPS"
+
 	// SunsetDate3250 is the RFC 7231 Sunset header for AppBudget deprecation; update at 3.25.0.
 	SunsetDate3250 = "Thu, 01 Oct 2026 00:00:00 GMT"
 )
@@ -0,0 +1,21 @@
+package structs
+
+// Drift is an object of the active release of an app whose live state no
+// longer matches what the release renders. Missing objects have been
+// deleted, otherwise Changes lists the fields that differ.
+type Drift struct {
+	Kind    string        `json:"kind"`
+	Name    string        `json:"name"`
+	Missing bool          `json:"missing,omitempty"`
+	Changes []DriftChange `json:"changes,omitempty"`
+}
+
+type Drifts []Drift
+
+// DriftChange is a field of a drifted object. Values of secrets are not
+// included.
+type DriftChange struct {
+	Path     string `json:"path"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
@@ -16,6 +16,89 @@ type MockProvider struct {
 	mock.Mock
 }
 
+// AccessPolicyDelete provides a mock function with given fields: id
+func (_m *MockProvider) AccessPolicyDelete(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AccessPolicyGet provides a mock function with given fields: id
+func (_m *MockProvider) AccessPolicyGet(id string) (*AccessPolicy, error) {
+	ret := _m.Called(id)
+
+	var r0 *AccessPolicy
+	if rf, ok := ret.Get(0).(func(string) *AccessPolicy); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*AccessPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccessPolicyList provides a mock function with given fields:
+func (_m *MockProvider) AccessPolicyList() (AccessPolicies, error) {
+	ret := _m.Called()
+
+	var r0 AccessPolicies
+	if rf, ok := ret.Get(0).(func() AccessPolicies); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(AccessPolicies)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccessPolicySet provides a mock function with given fields: id, opts
+func (_m *MockProvider) AccessPolicySet(id string, opts AccessPolicyOptions) (*AccessPolicy, error) {
+	ret := _m.Called(id, opts)
+
+	var r0 *AccessPolicy
+	if rf, ok := ret.Get(0).(func(string, AccessPolicyOptions) *AccessPolicy); ok {
+		r0 = rf(id, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*AccessPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, AccessPolicyOptions) error); ok {
+		r1 = rf(id, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AppBudgetClear provides a mock function with given fields: app, ackBy
 func (_m *MockProvider) AppBudgetClear(app string, ackBy string) error {
 	ret := _m.Called(app, ackBy)
@@ -344,6 +427,29 @@ func (_m *MockProvider) AppDiagnose(app string, opts AppDiagnoseOptions) (*AppDi
 	return r0, r1
 }
 
+// AppDrift provides a mock function with given fields: name
+func (_m *MockProvider) AppDrift(name string) (Drifts, error) {
+	ret := _m.Called(name)
+
+	var r0 Drifts
+	if rf, ok := ret.Get(0).(func(string) Drifts); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(Drifts)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AppGet provides a mock function with given fields: name
 func (_m *MockProvider) AppGet(name string) (*App, error) {
 	ret := _m.Called(name)
@@ -459,6 +565,29 @@ func (_m *MockProvider) AppMetrics(name string, opts MetricsOptions) (Metrics, e
 	return r0, r1
 }
 
+// AppPreviewCreate provides a mock function with given fields: base, name, opts
+func (_m *MockProvider) AppPreviewCreate(base string, name string, opts AppPreviewCreateOptions) (*App, error) {
+	ret := _m.Called(base, name, opts)
+
+	var r0 *App
+	if rf, ok := ret.Get(0).(func(string, string, AppPreviewCreateOptions) *App); ok {
+		r0 = rf(base, name, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*App)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, AppPreviewCreateOptions) error); ok {
+		r1 = rf(base, name, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // AppUpdate provides a mock function with given fields: name, opts
 func (_m *MockProvider) AppUpdate(name string, opts AppUpdateOptions) error {
 	ret := _m.Called(name, opts)
@@ -473,6 +602,43 @@ func (_m *MockProvider) AppUpdate(name string, opts AppUpdateOptions) error {
 	return r0
 }
 
+// AuditList provides a mock function with given fields: opts
+func (_m *MockProvider) AuditList(opts AuditListOptions) (AuditEntries, error) {
+	ret := _m.Called(opts)
+
+	var r0 AuditEntries
+	if rf, ok := ret.Get(0).(func(AuditListOptions) AuditEntries); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(AuditEntries)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(AuditListOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AuditRecord provides a mock function with given fields: e
+func (_m *MockProvider) AuditRecord(e AuditEntry) error {
+	ret := _m.Called(e)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(AuditEntry) error); ok {
+		r0 = rf(e)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BalancerList provides a mock function with given fields: app
 func (_m *MockProvider) BalancerList(app string) (Balancers, error) {
 	ret := _m.Called(app)
@@ -1349,6 +1515,29 @@ func (_m *MockProvider) RegistryRemove(server string) error {
 	return r0
 }
 
+// ReleaseApprove provides a mock function with given fields: app, id, opts
+func (_m *MockProvider) ReleaseApprove(app string, id string, opts ReleaseApproveOptions) (*Release, error) {
+	ret := _m.Called(app, id, opts)
+
+	var r0 *Release
+	if rf, ok := ret.Get(0).(func(string, string, ReleaseApproveOptions) *Release); ok {
+		r0 = rf(app, id, opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*Release)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string, ReleaseApproveOptions) error); ok {
+		r1 = rf(app, id, opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReleaseCreate provides a mock function with given fields: app, opts
 func (_m *MockProvider) ReleaseCreate(app string, opts ReleaseCreateOptions) (*Release, error) {
 	ret := _m.Called(app, opts)
@@ -1418,6 +1607,20 @@ func (_m *MockProvider) ReleaseList(app string, opts ReleaseListOptions) (Releas
 	return r0, r1
 }
 
+// ReleasePause provides a mock function with given fields: app
+func (_m *MockProvider) ReleasePause(app string) error {
+	ret := _m.Called(app)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(app)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ReleasePromote provides a mock function with given fields: app, id, opts
 func (_m *MockProvider) ReleasePromote(app string, id string, opts ReleasePromoteOptions) error {
 	ret := _m.Called(app, id, opts)
@@ -1432,6 +1635,80 @@ func (_m *MockProvider) ReleasePromote(app string, id string, opts ReleasePromot
 	return r0
 }
 
+// ReleaseResume provides a mock function with given fields: app
+func (_m *MockProvider) ReleaseResume(app string) error {
+	ret := _m.Called(app)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(app)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReleaseTimeline provides a mock function with given fields: app, id
+func (_m *MockProvider) ReleaseTimeline(app string, id string) (*ReleaseTimeline, error) {
+	ret := _m.Called(app, id)
+
+	var r0 *ReleaseTimeline
+	if rf, ok := ret.Get(0).(func(string, string) *ReleaseTimeline); ok {
+		r0 = rf(app, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ReleaseTimeline)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(app, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResourceBackupList provides a mock function with given fields: app, name
+func (_m *MockProvider) ResourceBackupList(app string, name string) (ResourceBackups, error) {
+	ret := _m.Called(app, name)
+
+	var r0 ResourceBackups
+	if rf, ok := ret.Get(0).(func(string, string) ResourceBackups); ok {
+		r0 = rf(app, name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(ResourceBackups)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(app, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResourceBackupRestore provides a mock function with given fields: app, name, id
+func (_m *MockProvider) ResourceBackupRestore(app string, name string, id string) error {
+	ret := _m.Called(app, name, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(app, name, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ResourceConsole provides a mock function with given fields: app, name, rw, opts
 func (_m *MockProvider) ResourceConsole(app string, name string, rw io.ReadWriter, opts ResourceConsoleOptions) error {
 	ret := _m.Called(app, name, rw, opts)
@@ -2049,6 +2326,80 @@ func (_m *MockProvider) SystemResourceUpdate(name string, opts ResourceUpdateOpt
 	return r0, r1
 }
 
+// SystemTokenCreate provides a mock function with given fields: opts
+func (_m *MockProvider) SystemTokenCreate(opts SystemTokenCreateOptions) (*SystemToken, error) {
+	ret := _m.Called(opts)
+
+	var r0 *SystemToken
+	if rf, ok := ret.Get(0).(func(SystemTokenCreateOptions) *SystemToken); ok {
+		r0 = rf(opts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*SystemToken)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(SystemTokenCreateOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SystemTokenList provides a mock function with given fields:
+func (_m *MockProvider) SystemTokenList() (SystemTokens, error) {
+	ret := _m.Called()
+
+	var r0 SystemTokens
+	if rf, ok := ret.Get(0).(func() SystemTokens); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(SystemTokens)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SystemTokenRevoke provides a mock function with given fields: id
+func (_m *MockProvider) SystemTokenRevoke(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SystemTokenUsed provides a mock function with given fields: id
+func (_m *MockProvider) SystemTokenUsed(id string) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SystemUninstall provides a mock function with given fields: name, w, opts
 func (_m *MockProvider) SystemUninstall(name string, w io.Writer, opts SystemUninstallOptions) error {
 	ret := _m.Called(name, w, opts)
@@ -11,6 +11,11 @@ type Provider interface {
 	Initialize(opts ProviderOptions) error
 	Start() error
 
+	AccessPolicyDelete(id string) error
+	AccessPolicyGet(id string) (*AccessPolicy, error)
+	AccessPolicyList() (AccessPolicies, error)
+	AccessPolicySet(id string, opts AccessPolicyOptions) (*AccessPolicy, error)
+
 	AppCancel(name string) error
 	AppCreate(name string, opts AppCreateOptions) (*App, error)
 	AppConfigGet(app, name string) (*AppConfig, error)
@@ -19,10 +24,12 @@ type Provider interface {
 	AppGet(name string) (*App, error)
 	AppDelete(name string) error
 	AppDiagnose(app string, opts AppDiagnoseOptions) (*AppDiagnosticReport, error)
+	AppDrift(name string) (Drifts, error)
 	AppList() (Apps, error)
 	AppLogs(name string, opts LogsOptions) (io.ReadCloser, error)
 	AppManifestService(app, service string) (*ManifestService, error)
 	AppMetrics(name string, opts MetricsOptions) (Metrics, error)
+	AppPreviewCreate(base, name string, opts AppPreviewCreateOptions) (*App, error)
 	MetricsByService(app string, services []string, opts MetricsOptions) ([]ServiceMetricsRow, error)
 	AppUpdate(name string, opts AppUpdateOptions) error
 
@@ -37,6 +44,9 @@ type Provider interface {
 	AppBudgetDismissRecoveryWithResult(app string, ackBy string) (*AppBudgetDismissRecoveryResult, error)
 	AppCost(app string) (*AppCost, error)
 
+	AuditList(opts AuditListOptions) (AuditEntries, error)
+	AuditRecord(e AuditEntry) error
+
 	BalancerList(app string) (Balancers, error)
 
 	BuildCreate(app, url string, opts BuildCreateOptions) (*Build, error)
@@ -91,11 +101,17 @@ type Provider interface {
 	RegistryProxy(ctx *stdapi.Context) error
 	RegistryRemove(server string) error
 
+	ReleaseApprove(app, id string, opts ReleaseApproveOptions) (*Release, error)
 	ReleaseCreate(app string, opts ReleaseCreateOptions) (*Release, error)
 	ReleaseGet(app, id string) (*Release, error)
 	ReleaseList(app string, opts ReleaseListOptions) (Releases, error)
+	ReleasePause(app string) error
 	ReleasePromote(app, id string, opts ReleasePromoteOptions) error
+	ReleaseResume(app string) error
+	ReleaseTimeline(app, id string) (*ReleaseTimeline, error)
 
+	ResourceBackupList(app, name string) (ResourceBackups, error)
+	ResourceBackupRestore(app, name, id string) error
 	ResourceConsole(app, name string, rw io.ReadWriter, opts ResourceConsoleOptions) error
 	ResourceExport(app, name string) (io.ReadCloser, error)
 	ResourceGet(app, name string) (*Resource, error)
@@ -120,6 +136,10 @@ type Provider interface {
 	SystemMetrics(opts MetricsOptions) (Metrics, error)
 	SystemProcesses(opts SystemProcessesOptions) (Processes, error)
 	SystemReleases() (Releases, error)
+	SystemTokenCreate(opts SystemTokenCreateOptions) (*SystemToken, error)
+	SystemTokenList() (SystemTokens, error)
+	SystemTokenRevoke(id string) error
+	SystemTokenUsed(id string) error
 	SystemUninstall(name string, w io.Writer, opts SystemUninstallOptions) error
 	SystemUpdate(opts SystemUpdateOptions) error
 
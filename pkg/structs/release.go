@@ -11,9 +11,32 @@ type Release struct {
 	Manifest    string `json:"manifest"`
 	Description string `json:"description"`
 
+	Approvals []ReleaseApproval `json:"approvals,omitempty"`
+	Creator   string            `json:"creator,omitempty"`
+
 	Created time.Time `json:"created"`
 }
 
+// ReleaseApproval records a user signing off on a release before it can be
+// promoted under an app's deploy policy.
+type ReleaseApproval struct {
+	Actor   string    `json:"actor"`
+	Comment string    `json:"comment,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// ReleaseApprovalsAnnotation is the release annotation key holding its
+// approvals.
+const ReleaseApprovalsAnnotation = "convox.com/approvals"
+
+// ReleaseCreatorAnnotation is the release annotation key holding the user
+// that created it, who can not approve it.
+const ReleaseCreatorAnnotation = "convox.com/creator"
+
+type ReleaseApproveOptions struct {
+	Comment *string `flag:"comment,m" param:"comment"`
+}
+
 type Releases []Release
 
 type ReleaseCreateOptions struct {
@@ -42,6 +65,68 @@ type ReleasePromoteOptions struct {
 	Min         *int  `param:"min"`
 	Max         *int  `param:"max"`
 	Timeout     *int  `param:"timeout"`
+
+	// Strategy selects how traffic moves to the new release: rolling (the
+	// default), canary or blue-green. Canary and blue-green shift traffic in
+	// Steps (percentages), holding each step for Interval and rolling back
+	// when the new release's error rate exceeds the current release's by more
+	// than ErrorThreshold percentage points or its p95 latency is more than
+	// LatencyThreshold percent higher.
+	Strategy         *string        `param:"strategy"`
+	Steps            *string        `param:"steps"`
+	Interval         *time.Duration `param:"interval"`
+	ErrorThreshold   *int           `param:"error-threshold"`
+	LatencyThreshold *int           `param:"latency-threshold"`
+
+	// OverrideFreeze is the justification an admin gives for promoting
+	// while a deploy freeze window is open.
+	OverrideFreeze *string `param:"override-freeze"`
+}
+
+const (
+	ReleasePromoteStrategyBlueGreen = "blue-green"
+	ReleasePromoteStrategyCanary    = "canary"
+	ReleasePromoteStrategyRolling   = "rolling"
+)
+
+// ReleasePromotionAnnotation is the namespace annotation key holding the
+// progress of the most recent canary or blue-green promote.
+const ReleasePromotionAnnotation = "convox.com/release-promotion"
+
+const (
+	ReleasePromotionCancelled  = "cancelled"
+	ReleasePromotionPromoted   = "promoted"
+	ReleasePromotionRolledBack = "rolled-back"
+	ReleasePromotionRunning    = "running"
+)
+
+// ReleasePromotion is the progress of a canary or blue-green promote of
+// Release over the app's current release, Base.
+type ReleasePromotion struct {
+	Release  string `json:"release"`
+	Base     string `json:"base"`
+	Strategy string `json:"strategy"`
+	Steps    []int  `json:"steps"`
+	Step     int    `json:"step"`
+	Weight   int    `json:"weight"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	Actor    string `json:"actor,omitempty"`
+
+	Interval         time.Duration `json:"interval"`
+	ErrorThreshold   int           `json:"errorThreshold"`
+	LatencyThreshold int           `json:"latencyThreshold"`
+
+	// Services, Idle, Min, Max and Timeout are what a runner needs to pick
+	// up an interrupted promote where it left off.
+	Services []string `json:"services,omitempty"`
+	Idle     *bool    `json:"idle,omitempty"`
+	Min      *int     `json:"min,omitempty"`
+	Max      *int     `json:"max,omitempty"`
+	Timeout  *int     `json:"timeout,omitempty"`
+
+	Started time.Time `json:"started"`
+	Updated time.Time `json:"updated"`
 }
 
 // ReleasePromoteWatchAnnotation is the namespace annotation key for rollout-watcher state persistence.
@@ -1,5 +1,7 @@
 package structs
 
+import "time"
+
 type Resource struct {
 	Name       string            `json:"name"`
 	Parameters map[string]string `json:"parameters,omitempty"`
@@ -16,6 +18,20 @@ func (rs Resources) Less(i, j int) bool {
 	return rs[i].Name < rs[j].Name
 }
 
+// ResourceBackup is a scheduled backup of a resource, stored in the rack's
+// object storage.
+type ResourceBackup struct {
+	Id       string    `json:"id"`
+	Resource string    `json:"resource"`
+	Created  time.Time `json:"created"`
+}
+
+type ResourceBackups []ResourceBackup
+
+func (bs ResourceBackups) Less(i, j int) bool {
+	return bs[i].Created.After(bs[j].Created)
+}
+
 type ResourceType struct {
 	Name       string             `json:"name"`
 	Parameters ResourceParameters `json:"parameters"`
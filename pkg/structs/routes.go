@@ -9,6 +9,7 @@ func init() {
 	routes["AppCreate"] = "POST /apps"
 	routes["AppDelete"] = "DELETE /apps/{name}"
 	routes["AppDiagnose"] = "GET /apps/{app}/diagnose"
+	routes["AppDrift"] = "GET /apps/{name}/drift"
 	routes["AppGet"] = "GET /apps/{name}"
 	routes["AppList"] = "GET /apps"
 	routes["AppLogs"] = "SOCKET /apps/{name}/logs"
@@ -62,7 +63,10 @@ func init() {
 	routes["ReleaseCreate"] = "POST /apps/{app}/releases"
 	routes["ReleaseGet"] = "GET /apps/{app}/releases/{id}"
 	routes["ReleaseList"] = "GET /apps/{app}/releases"
+	routes["ReleasePause"] = "POST /apps/{app}/releases/pause"
 	routes["ReleasePromote"] = "POST /apps/{app}/releases/{id}/promote"
+	routes["ReleaseResume"] = "POST /apps/{app}/releases/resume"
+	routes["ReleaseTimeline"] = "GET /apps/{app}/releases/{id}/timeline"
 	routes["RegistryAdd"] = "POST /registries"
 	routes["RegistryList"] = "GET /registries"
 	routes["RegistryProxy"] = "ANY /v2/{path:.*}"
@@ -1,6 +1,9 @@
 package structs
 
-import "io"
+import (
+	"io"
+	"time"
+)
 
 type System struct {
 	Count          int               `json:"count"`
@@ -45,12 +48,64 @@ type SystemUpdateOptions struct {
 type SystemJwtOptions struct {
 	Role           *string `param:"role"`
 	DurationInHour *string `param:"durationInHour"`
+	Policies       *string `param:"policies"`
 }
 
 type SystemJwt struct {
 	Token string `json:"token"`
 }
 
+// SystemToken is a named, revocable api credential issued by the rack. The
+// signed Token is only returned when the credential is created.
+type SystemToken struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Apps      []string  `json:"apps,omitempty"`
+	Policies  []string  `json:"policies,omitempty"`
+	CreatedBy string    `json:"created-by,omitempty"`
+	Created   time.Time `json:"created"`
+	Expires   time.Time `json:"expires"`
+	LastUsed  time.Time `json:"last-used"`
+	Revoked   time.Time `json:"revoked"`
+	Token     string    `json:"token,omitempty"`
+}
+
+type SystemTokens []SystemToken
+
+type SystemTokenCreateOptions struct {
+	Apps     []string `param:"apps"`
+	Expires  *string  `param:"expires"`
+	Name     *string  `param:"name"`
+	Policies []string `param:"policies"`
+	Role     *string  `param:"role"`
+}
+
+func NewSystemToken(name string) *SystemToken {
+	return &SystemToken{
+		Id:      id("T", 10),
+		Name:    name,
+		Created: time.Now().UTC(),
+	}
+}
+
+func (ts SystemTokens) Less(i, j int) bool { return ts[i].Created.Before(ts[j].Created) }
+
+// SystemTokenRole maps a role name (read, write, admin) or role string
+// (r, rw, rwa) to the role string carried in the token.
+func SystemTokenRole(role string) (string, error) {
+	switch role {
+	case "read", ConvoxRoleRead:
+		return ConvoxRoleRead, nil
+	case "write", ConvoxRoleReadWrite:
+		return ConvoxRoleReadWrite, nil
+	case "admin", ConvoxRoleAdmin:
+		return ConvoxRoleAdmin, nil
+	}
+
+	return "", ErrBadRequest("invalid role: %s (must be read, write, or admin)", role)
+}
+
 type RackData struct {
 	Host string `json:"host"`
 }
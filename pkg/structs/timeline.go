@@ -0,0 +1,45 @@
+package structs
+
+import "time"
+
+const (
+	TimelineSourceAtom    = "atom"
+	TimelineSourcePod     = "pod"
+	TimelineSourcePromote = "promote"
+	TimelineSourceRollout = "rollout"
+	TimelineSourceWatcher = "watcher"
+)
+
+// ReleaseTimeline is the recorded history of the promotes of a release:
+// atom status transitions, pod events, probe failures and the result seen
+// by the release watcher, with the duration of each phase derived from them.
+type ReleaseTimeline struct {
+	App     string                 `json:"app"`
+	Release string                 `json:"release"`
+	Status  string                 `json:"status"`
+	Started time.Time              `json:"started"`
+	Ended   time.Time              `json:"ended"`
+	Events  []ReleaseTimelineEvent `json:"events"`
+	Phases  []ReleaseTimelinePhase `json:"phases"`
+}
+
+// ReleaseTimelineEvent is one entry of a timeline. Release is the release
+// of the pod for pod events, which is not the timeline release when an old
+// replica is being drained.
+type ReleaseTimelineEvent struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Object  string    `json:"object,omitempty"`
+	Release string    `json:"release,omitempty"`
+	Reason  string    `json:"reason"`
+	Message string    `json:"message,omitempty"`
+}
+
+// ReleaseTimelinePhase is a span of a promote such as the image pull or the
+// drain of old replicas. Phases that have not ended have a zero Ended.
+type ReleaseTimelinePhase struct {
+	Name     string        `json:"name"`
+	Started  time.Time     `json:"started"`
+	Ended    time.Time     `json:"ended"`
+	Duration time.Duration `json:"duration"`
+}
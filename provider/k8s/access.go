@@ -0,0 +1,132 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"sort"
+
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	ac "k8s.io/api/core/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const accessPolicyConfigMap = "access-policies"
+
+var accessPolicyIdPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+func (p *Provider) AccessPolicyDelete(id string) error {
+	cm, err := p.accessPolicyConfigMap()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, ok := cm.Data[id]; !ok {
+		return errors.WithStack(structs.ErrNotFound("no such access policy: %s", id))
+	}
+
+	delete(cm.Data, id)
+
+	if _, err := p.Cluster.CoreV1().ConfigMaps(p.Namespace).Update(context.TODO(), cm, am.UpdateOptions{}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (p *Provider) AccessPolicyGet(id string) (*structs.AccessPolicy, error) {
+	cm, err := p.accessPolicyConfigMap()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	data, ok := cm.Data[id]
+	if !ok {
+		return nil, errors.WithStack(structs.ErrNotFound("no such access policy: %s", id))
+	}
+
+	var ap structs.AccessPolicy
+
+	if err := json.Unmarshal([]byte(data), &ap); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &ap, nil
+}
+
+func (p *Provider) AccessPolicyList() (structs.AccessPolicies, error) {
+	cm, err := p.accessPolicyConfigMap()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	aps := structs.AccessPolicies{}
+
+	for id, data := range cm.Data {
+		var ap structs.AccessPolicy
+
+		if err := json.Unmarshal([]byte(data), &ap); err != nil {
+			p.logger.Errorf("ns=access at=list policy=%s error=%q", id, err)
+			continue
+		}
+
+		aps = append(aps, ap)
+	}
+
+	sort.Slice(aps, aps.Less)
+
+	return aps, nil
+}
+
+func (p *Provider) AccessPolicySet(id string, opts structs.AccessPolicyOptions) (*structs.AccessPolicy, error) {
+	if !accessPolicyIdPattern.MatchString(id) {
+		return nil, errors.WithStack(structs.ErrBadRequest("invalid access policy id: %s", id))
+	}
+
+	cm, err := p.accessPolicyConfigMap()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	ap := structs.AccessPolicy{Id: id}
+
+	if data, ok := cm.Data[id]; ok {
+		if err := json.Unmarshal([]byte(data), &ap); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	if opts.Description != nil {
+		ap.Description = *opts.Description
+	}
+
+	if opts.Rules != nil {
+		rs, err := structs.ParseAccessRules(*opts.Rules)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ap.Rules = rs
+	}
+
+	if len(ap.Rules) == 0 {
+		return nil, errors.WithStack(structs.ErrBadRequest("at least one access rule is required"))
+	}
+
+	data, err := json.Marshal(ap)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cm.Data[id] = string(data)
+
+	if _, err := p.Cluster.CoreV1().ConfigMaps(p.Namespace).Update(context.TODO(), cm, am.UpdateOptions{}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &ap, nil
+}
+
+func (p *Provider) accessPolicyConfigMap() (*ac.ConfigMap, error) {
+	return p.systemConfigMap(accessPolicyConfigMap)
+}
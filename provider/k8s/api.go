@@ -62,11 +62,17 @@ func (p *Provider) apiProxyAuthenticate(handler http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		username, password, _ := r.BasicAuth()
 		if username == "jwt" && p.JwtMngr != nil {
-			_, err := p.JwtMngr.Verify(password)
+			data, err := p.JwtMngr.Verify(password)
 			if err != nil {
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
+			// the raw cluster api cannot be scoped by access policies or apps and
+			// does not track revocation of rack issued tokens
+			if len(data.Policies) > 0 || len(data.Apps) > 0 || data.Id != "" {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
 		} else {
 			if password != p.Password {
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
@@ -363,6 +363,13 @@ func (p *Provider) AppParameters() map[string]string {
 		structs.AppParamBuildCpu:    "",
 		structs.AppParamBuildMem:    "",
 		structs.AppParamBuildLabels: "",
+
+		structs.AppParamDeployApprovals: "",
+		structs.AppParamDeployFreeze:    "",
+
+		structs.AppParamSecretRefresh: "",
+		structs.AppParamSecretRestart: "",
+		structs.AppParamSecretPaths:   "",
 	}
 }
 
@@ -421,6 +428,8 @@ func (p *Provider) appFromNamespace(ns ac.Namespace) (*structs.App, error) {
 		Tags: map[string]string{
 			"namespace": ns.Name,
 		},
+		Preview:   appPreviewFromAnnotations(ns.Annotations),
+		Promotion: releasePromotionFromAnnotations(ns.Annotations),
 	}
 
 	var params map[string]string
@@ -480,6 +489,8 @@ func (p *Provider) appFromNamespaceOnly(ns ac.Namespace) (*structs.App, error) {
 		Tags: map[string]string{
 			"namespace": ns.Name,
 		},
+		Preview:   appPreviewFromAnnotations(ns.Annotations),
+		Promotion: releasePromotionFromAnnotations(ns.Annotations),
 	}
 
 	var params map[string]string
@@ -541,6 +552,14 @@ func (p *Provider) appParametersUpdate(a *structs.App, params map[string]string)
 		if _, ok := defs[k]; !ok {
 			invalidParameters = append(invalidParameters, k)
 		} else {
+			if err := validateDeployParameter(k, v); err != nil {
+				return err
+			}
+
+			if err := validateSecretParameter(k, v); err != nil {
+				return err
+			}
+
 			a.Parameters[k] = v
 		}
 	}
@@ -0,0 +1,386 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	appPreviewBaseAnnotation    = "convox.com/preview-base"
+	appPreviewExpiresAnnotation = "convox.com/preview-expires"
+	appPreviewDefaultTtl        = 72 * time.Hour
+	appPreviewLeaseName         = "convox-app-previews"
+	appPreviewTickInterval      = time.Minute
+)
+
+// AppPreviewCreate creates app name as a preview of the base app. The preview
+// runs a copy of the build of the base app's active release, or opts.Build,
+// with the env of that release. Its resources are provisioned fresh unless
+// they are listed in opts.Resources, which link the preview to the resources
+// of the base app instead.
+func (p *Provider) AppPreviewCreate(base, name string, opts structs.AppPreviewCreateOptions) (*structs.App, error) {
+	if name == "" {
+		return nil, errors.WithStack(structs.ErrBadRequest("preview name required"))
+	}
+
+	ttl := common.DefaultDuration(opts.Ttl, appPreviewDefaultTtl)
+	if ttl <= 0 {
+		return nil, errors.WithStack(structs.ErrBadRequest("ttl must be positive"))
+	}
+
+	a, err := p.AppGet(base)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if a.Preview != nil {
+		return nil, errors.WithStack(structs.ErrBadRequest("%s is a preview app, create previews of %s instead", base, a.Preview.Base))
+	}
+
+	if a.Release == "" {
+		return nil, errors.WithStack(structs.ErrBadRequest("app has no release to preview: %s", base))
+	}
+
+	r, err := p.ReleaseGet(base, a.Release)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	b, err := p.BuildGet(base, common.DefaultString(opts.Build, r.Build))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if b.Status != "complete" {
+		return nil, errors.WithStack(structs.ErrBadRequest("build %s is %s", b.Id, b.Status))
+	}
+
+	env, err := p.appPreviewEnv(base, r, b, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	expires := time.Now().UTC().Add(ttl).Truncate(time.Second)
+
+	if _, err := p.AppCreate(name, structs.AppCreateOptions{}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := p.appPreviewPromote(base, name, expires, b, env); err != nil {
+		// a half created preview would only wait for its ttl
+		if derr := p.AppDelete(name); derr != nil {
+			fmt.Printf("ns=app_previews at=cleanup app=%s err=%q\n", name, derr)
+		}
+		return nil, err
+	}
+
+	p.EventSend("app:preview:create", structs.EventSendOptions{Data: map[string]string{"name": name, "base": base, "expires": expires.Format(time.RFC3339)}})
+
+	return p.AppGet(name)
+}
+
+func (p *Provider) appPreviewPromote(base, name string, expires time.Time, b *structs.Build, env structs.Environment) error {
+	if err := p.appPreviewAnnotate(name, base, expires); err != nil {
+		return err
+	}
+
+	if err := p.appPreviewSecretPaths(base, name); err != nil {
+		return err
+	}
+
+	nb, err := p.appPreviewBuild(base, name, b)
+	if err != nil {
+		return err
+	}
+
+	r, err := p.ReleaseCreate(name, structs.ReleaseCreateOptions{
+		Build:       options.String(nb.Id),
+		Env:         options.String(env.String()),
+		Description: options.String(fmt.Sprintf("preview of %s build %s", base, b.Id)),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := p.ReleasePromote(name, r.Id, structs.ReleasePromoteOptions{}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// appPreviewSecretPaths gives the preview the SecretPaths of its base app so
+// the secret references of the base env resolve, and no others.
+func (p *Provider) appPreviewSecretPaths(base, name string) error {
+	ba, err := p.AppGet(base)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	sp := ba.Parameters[structs.AppParamSecretPaths]
+	if sp == "" {
+		return nil
+	}
+
+	a, err := p.AppGet(name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := p.appParametersUpdate(a, map[string]string{structs.AppParamSecretPaths: sp}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(p.appUpdate(a))
+}
+
+// appPreviewEnv returns the env of a preview: the env of the base release
+// with the urls of the shared resources and the overrides applied.
+func (p *Provider) appPreviewEnv(base string, r *structs.Release, b *structs.Build, opts structs.AppPreviewCreateOptions) (structs.Environment, error) {
+	env := structs.Environment{}
+
+	if err := env.Load([]byte(r.Env)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	m, err := manifest.Load([]byte(b.Manifest), env)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, name := range opts.Resources {
+		mr, err := m.Resource(name)
+		if err != nil {
+			return nil, errors.WithStack(structs.ErrBadRequest("%s", err))
+		}
+
+		u, err := p.resourceUrl(base, name)
+		if err != nil {
+			return nil, err
+		}
+
+		// a resource with its url set in env is linked rather than provisioned
+		env[mr.DefaultEnv()] = u
+	}
+
+	for k, v := range opts.Environment {
+		env[k] = v
+	}
+
+	return env, nil
+}
+
+// appPreviewBuild copies the images of build b of the base app to a new
+// build of the preview app.
+func (p *Provider) appPreviewBuild(base, name string, b *structs.Build) (*structs.Build, error) {
+	m, err := manifest.Load([]byte(b.Manifest), map[string]string{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	data, err := appPreviewManifest(b.Manifest)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	nb := structs.NewBuild(name)
+	nb.Description = fmt.Sprintf("preview of %s build %s", base, b.Id)
+	nb.Manifest = data
+	nb.Started = time.Now().UTC()
+
+	src, _, err := p.Engine.RepositoryHost(base)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	srcUser, srcPass, err := p.Engine.RepositoryAuth(base)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	dst, _, err := p.Engine.RepositoryHost(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	dstUser, dstPass, err := p.Engine.RepositoryAuth(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	authPath, err := writeImportAuthFile(src, srcUser, srcPass, dst, dstUser, dstPass)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer func() { _ = os.Remove(authPath) }()
+
+	for _, s := range m.Services {
+		args := []string{
+			"copy",
+			"--authfile", authPath,
+			"--",
+			fmt.Sprintf("docker://%s:%s.%s", src, s.Name, b.Id),
+			fmt.Sprintf("docker://%s:%s.%s", dst, s.Name, nb.Id),
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), skopeoCopyTimeout)
+		out, err := skopeoExec(ctx, args...)
+		cancel()
+		if err != nil {
+			return nil, errors.WithStack(fmt.Errorf("image copy failed for service %s: %s (output: %s)", s.Name, err, strings.TrimSpace(string(out))))
+		}
+	}
+
+	nb.Ended = time.Now().UTC()
+	nb.Status = "complete"
+
+	if _, err := p.buildCreate(nb); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return nb, nil
+}
+
+// appPreviewManifest removes the custom domains of the services in manifest
+// data so that a preview does not route the hosts of its base app or request
+// certificates for them. Previews are reached on their rack hostnames.
+func appPreviewManifest(data string) (string, error) {
+	var m yaml.MapSlice
+
+	if err := yaml.Unmarshal([]byte(data), &m); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	for i := range m {
+		if m[i].Key != "services" {
+			continue
+		}
+
+		services, ok := m[i].Value.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+
+		for j := range services {
+			s, ok := services[j].Value.(yaml.MapSlice)
+			if !ok {
+				continue
+			}
+
+			ns := yaml.MapSlice{}
+
+			for _, item := range s {
+				if item.Key != "domain" {
+					ns = append(ns, item)
+				}
+			}
+
+			services[j].Value = ns
+		}
+	}
+
+	out, err := yaml.Marshal(m)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return string(out), nil
+}
+
+func (p *Provider) appPreviewAnnotate(name, base string, expires time.Time) error {
+	patches := []Patch{
+		{Op: "add", Path: "/metadata/annotations/convox.com~1preview-base", Value: base},
+		{Op: "add", Path: "/metadata/annotations/convox.com~1preview-expires", Value: expires.Format(time.RFC3339)},
+	}
+
+	patch, err := json.Marshal(patches)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := p.Cluster.CoreV1().Namespaces().Patch(context.TODO(), p.AppNamespace(name), types.JSONPatchType, patch, am.PatchOptions{}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func appPreviewFromAnnotations(annotations map[string]string) *structs.AppPreview {
+	base := annotations[appPreviewBaseAnnotation]
+	if base == "" {
+		return nil
+	}
+
+	expires, err := time.Parse(time.RFC3339, annotations[appPreviewExpiresAnnotation])
+	if err != nil {
+		return nil
+	}
+
+	return &structs.AppPreview{Base: base, Expires: expires}
+}
+
+// runAppPreviews deletes preview apps once they expire. It runs on the
+// elected leader only.
+func (p *Provider) runAppPreviews(ctx context.Context) {
+	fmt.Printf("ns=app_previews at=start\n")
+
+	tick := time.NewTicker(appPreviewTickInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("ns=app_previews at=stop\n")
+			return
+		case now := <-tick.C:
+			p.appPreviewsExpire(now)
+		}
+	}
+}
+
+func (p *Provider) appPreviewsExpire(now time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("ns=app_previews at=error kind=panic_recovered recovered=%v\n", r)
+		}
+	}()
+
+	as, err := p.AppList()
+	if err != nil {
+		fmt.Printf("ns=app_previews at=error kind=app_list err=%q\n", err)
+		return
+	}
+
+	for _, a := range as {
+		if a.Preview == nil || now.Before(a.Preview.Expires) || a.Status == "deleting" {
+			continue
+		}
+
+		// locked previews are kept past their ttl until they are unlocked
+		if a.Locked {
+			continue
+		}
+
+		if err := p.AppDelete(a.Name); err != nil {
+			fmt.Printf("ns=app_previews at=error kind=delete app=%s err=%q\n", a.Name, err)
+			continue
+		}
+
+		fmt.Printf("ns=app_previews at=expire app=%s base=%s\n", a.Name, a.Preview.Base)
+
+		p.EventSend("app:preview:expire", structs.EventSendOptions{Data: map[string]string{"name": a.Name, "base": a.Preview.Base}})
+	}
+}
@@ -0,0 +1,128 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/mock"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/logger"
+	"github.com/stretchr/testify/require"
+	ac "k8s.io/api/core/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func appPreviewTestProvider(t *testing.T) *Provider {
+	t.Helper()
+
+	p := &Provider{Engine: &mock.TestEngine{}, Name: "rack1", Cluster: fake.NewSimpleClientset()}
+	p.logger = logger.New("ns=test")
+
+	return p
+}
+
+func appPreviewTestNamespace(t *testing.T, p *Provider, name string, annotations map[string]string) {
+	t.Helper()
+
+	ns := &ac.Namespace{
+		ObjectMeta: am.ObjectMeta{
+			Name: p.AppNamespace(name),
+			Annotations: map[string]string{
+				"convox.com/app-release": "R1",
+				"convox.com/app-status":  "running",
+				"convox.com/lock":        "false",
+			},
+			Labels: map[string]string{"name": name, "rack": "rack1", "system": "convox", "type": "app"},
+		},
+	}
+
+	for k, v := range annotations {
+		ns.Annotations[k] = v
+	}
+
+	_, err := p.Cluster.CoreV1().Namespaces().Create(context.TODO(), ns, am.CreateOptions{})
+	require.NoError(t, err)
+}
+
+func TestAppPreviewFromAnnotations(t *testing.T) {
+	require.Nil(t, appPreviewFromAnnotations(map[string]string{}))
+	require.Nil(t, appPreviewFromAnnotations(map[string]string{appPreviewBaseAnnotation: "app1", appPreviewExpiresAnnotation: "tomorrow"}))
+
+	require.Equal(t, &structs.AppPreview{Base: "app1", Expires: time.Date(2026, 10, 21, 12, 0, 0, 0, time.UTC)}, appPreviewFromAnnotations(map[string]string{
+		appPreviewBaseAnnotation:    "app1",
+		appPreviewExpiresAnnotation: "2026-10-21T12:00:00Z",
+	}))
+}
+
+func TestAppPreviewManifest(t *testing.T) {
+	data, err := appPreviewManifest(`services:
+  web:
+    domain: ${HOST},www.example.org
+    port: 3000
+  api:
+    domain:
+      - api.example.org
+    port: 4000
+  worker:
+    command: bin/work
+`)
+	require.NoError(t, err)
+
+	m, err := manifest.Load([]byte(data), map[string]string{"HOST": "example.org"})
+	require.NoError(t, err)
+	require.Len(t, m.Services, 3)
+
+	for _, s := range m.Services {
+		require.Empty(t, s.Domains, s.Name)
+	}
+
+	web, err := m.Service("web")
+	require.NoError(t, err)
+	require.Equal(t, 3000, web.Port.Port)
+
+	worker, err := m.Service("worker")
+	require.NoError(t, err)
+	require.Equal(t, "bin/work", worker.Command)
+}
+
+func TestAppPreviewsExpire(t *testing.T) {
+	p := appPreviewTestProvider(t)
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	appPreviewTestNamespace(t, p, "app1", nil)
+	appPreviewTestNamespace(t, p, "pr-1", map[string]string{appPreviewBaseAnnotation: "app1", appPreviewExpiresAnnotation: "2026-10-18T11:00:00Z"})
+	appPreviewTestNamespace(t, p, "pr-2", map[string]string{appPreviewBaseAnnotation: "app1", appPreviewExpiresAnnotation: "2026-10-18T13:00:00Z"})
+	appPreviewTestNamespace(t, p, "pr-3", map[string]string{appPreviewBaseAnnotation: "app1", appPreviewExpiresAnnotation: "2026-10-18T11:00:00Z", "convox.com/lock": "true"})
+
+	p.appPreviewsExpire(now)
+
+	as, err := p.AppList()
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, a := range as {
+		names = append(names, a.Name)
+	}
+
+	require.ElementsMatch(t, []string{"app1", "pr-2", "pr-3"}, names)
+}
+
+func TestAppPreviewCreateValidation(t *testing.T) {
+	p := appPreviewTestProvider(t)
+
+	appPreviewTestNamespace(t, p, "pr-1", map[string]string{appPreviewBaseAnnotation: "app1", appPreviewExpiresAnnotation: "2026-10-18T11:00:00Z"})
+
+	_, err := p.AppPreviewCreate("app1", "", structs.AppPreviewCreateOptions{})
+	require.EqualError(t, err, "preview name required")
+
+	_, err = p.AppPreviewCreate("app1", "pr-2", structs.AppPreviewCreateOptions{Ttl: options.Duration(-time.Hour)})
+	require.EqualError(t, err, "ttl must be positive")
+
+	_, err = p.AppPreviewCreate("pr-1", "pr-2", structs.AppPreviewCreateOptions{})
+	require.EqualError(t, err, "pr-1 is a preview app, create previews of app1 instead")
+}
@@ -0,0 +1,190 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	ac "k8s.io/api/core/v1"
+	ae "k8s.io/apimachinery/pkg/api/errors"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// audit entries are appended as json lines to one configmap segment per day,
+// rolling over to a new segment for the same day when a segment fills up
+const (
+	auditDayLabel     = "audit-day"
+	auditEntriesKey   = "entries"
+	auditRetention    = 365 * 24 * time.Hour
+	auditSegmentBytes = 512 * 1024
+	auditWriteRetries = 5
+)
+
+func (p *Provider) AuditList(opts structs.AuditListOptions) (structs.AuditEntries, error) {
+	since := time.Now().UTC().Add(-24 * time.Hour)
+	if opts.Since != nil {
+		since = time.Now().UTC().Add(-*opts.Since)
+	}
+
+	cms, err := p.Cluster.CoreV1().ConfigMaps(p.Namespace).List(context.TODO(), am.ListOptions{
+		LabelSelector: "system=convox,type=audit",
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	es := structs.AuditEntries{}
+
+	for _, cm := range cms.Items {
+		if cm.Labels[auditDayLabel] < since.Format("20060102") {
+			continue
+		}
+
+		s := bufio.NewScanner(strings.NewReader(cm.Data[auditEntriesKey]))
+		s.Buffer(make([]byte, 0, 64*1024), auditSegmentBytes)
+
+		for s.Scan() {
+			var e structs.AuditEntry
+
+			if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+				p.logger.Errorf("ns=audit at=list segment=%s error=%q", cm.Name, err)
+				continue
+			}
+
+			if e.Time.Before(since) || !auditMatch(e, opts) {
+				continue
+			}
+
+			es = append(es, e)
+		}
+	}
+
+	sort.Slice(es, es.Less)
+
+	if opts.Limit != nil && *opts.Limit > 0 && len(es) > *opts.Limit {
+		es = es[len(es)-*opts.Limit:]
+	}
+
+	return es, nil
+}
+
+func (p *Provider) AuditRecord(e structs.AuditEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	line := string(data) + "\n"
+	day := e.Time.UTC().Format("20060102")
+
+	for i := 0; i < auditWriteRetries; i++ {
+		cm, err := p.auditSegment(day, len(line))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		cm.Data[auditEntriesKey] += line
+
+		_, err = p.Cluster.CoreV1().ConfigMaps(p.Namespace).Update(context.TODO(), cm, am.UpdateOptions{})
+		if ae.IsConflict(err) {
+			continue
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return nil
+	}
+
+	return errors.WithStack(fmt.Errorf("could not record audit entry %s: too many conflicts", e.Id))
+}
+
+// auditSegment returns the first segment for the day with room for size more
+// bytes, creating it if needed. The first segment of each day also prunes
+// segments that have aged out of retention.
+func (p *Provider) auditSegment(day string, size int) (*ac.ConfigMap, error) {
+	cms := p.Cluster.CoreV1().ConfigMaps(p.Namespace)
+
+	for n := 0; ; n++ {
+		name := fmt.Sprintf("audit-%s-%d", day, n)
+
+		cm, err := cms.Get(context.TODO(), name, am.GetOptions{})
+		if ae.IsNotFound(err) {
+			cm, err = cms.Create(context.TODO(), &ac.ConfigMap{
+				ObjectMeta: am.ObjectMeta{
+					Namespace: p.Namespace,
+					Name:      name,
+					Labels: map[string]string{
+						"system":      "convox",
+						"rack":        p.Name,
+						"type":        "audit",
+						auditDayLabel: day,
+					},
+				},
+			}, am.CreateOptions{})
+			if ae.IsAlreadyExists(err) {
+				cm, err = cms.Get(context.TODO(), name, am.GetOptions{})
+			} else if err == nil && n == 0 {
+				if err := p.auditPrune(); err != nil {
+					p.logger.Errorf("ns=audit at=prune error=%q", err)
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+
+		if len(cm.Data[auditEntriesKey])+size <= auditSegmentBytes {
+			return cm, nil
+		}
+	}
+}
+
+func (p *Provider) auditPrune() error {
+	cutoff := time.Now().UTC().Add(-auditRetention).Format("20060102")
+
+	cms, err := p.Cluster.CoreV1().ConfigMaps(p.Namespace).List(context.TODO(), am.ListOptions{
+		LabelSelector: "system=convox,type=audit",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, cm := range cms.Items {
+		if cm.Labels[auditDayLabel] >= cutoff {
+			continue
+		}
+
+		if err := p.Cluster.CoreV1().ConfigMaps(p.Namespace).Delete(context.TODO(), cm.Name, am.DeleteOptions{}); err != nil && !ae.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func auditMatch(e structs.AuditEntry, opts structs.AuditListOptions) bool {
+	if opts.Actor != nil && *opts.Actor != "" && e.Actor != *opts.Actor {
+		return false
+	}
+
+	if opts.App != nil && *opts.App != "" && e.App != *opts.App {
+		return false
+	}
+
+	if opts.Route != nil && *opts.Route != "" && e.Route != *opts.Route {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,54 @@
+package k8s_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/provider/k8s"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRecordList(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		now := time.Now().UTC()
+
+		entries := []structs.AuditEntry{
+			{Id: "E1", Time: now.Add(-48 * time.Hour), Actor: "alice", Route: "AppCreate", App: "app1", Status: 200},
+			{Id: "E2", Time: now.Add(-2 * time.Hour), Actor: "alice", Route: "ReleaseCreate", App: "app1", Status: 200},
+			{Id: "E3", Time: now.Add(-1 * time.Hour), Actor: "bob", Route: "ReleaseCreate", App: "app2", Status: 403},
+			{Id: "E4", Time: now, Actor: "alice", Route: "ServiceRestart", App: "app1", Status: 200},
+		}
+
+		for _, e := range entries {
+			require.NoError(t, p.AuditRecord(e))
+		}
+
+		es, err := p.AuditList(structs.AuditListOptions{})
+		require.NoError(t, err)
+		require.Equal(t, []string{"E2", "E3", "E4"}, auditIds(es))
+
+		es, err = p.AuditList(structs.AuditListOptions{Since: options.Duration(72 * time.Hour)})
+		require.NoError(t, err)
+		require.Equal(t, []string{"E1", "E2", "E3", "E4"}, auditIds(es))
+
+		es, err = p.AuditList(structs.AuditListOptions{Actor: options.String("alice"), App: options.String("app1")})
+		require.NoError(t, err)
+		require.Equal(t, []string{"E2", "E4"}, auditIds(es))
+
+		es, err = p.AuditList(structs.AuditListOptions{Route: options.String("ReleaseCreate"), Limit: options.Int(1)})
+		require.NoError(t, err)
+		require.Equal(t, []string{"E3"}, auditIds(es))
+	})
+}
+
+func auditIds(es structs.AuditEntries) []string {
+	ids := []string{}
+
+	for _, e := range es {
+		ids = append(ids, e.Id)
+	}
+
+	return ids
+}
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	cmapiutil "github.com/cert-manager/cert-manager/pkg/api/util"
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
 	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
 	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
 	"github.com/convox/convox/pkg/common"
@@ -227,12 +228,18 @@ func (p *Provider) generatedCertificateList() (structs.Certificates, error) {
 			}
 		}
 		if _, has := certMap[certList.Items[i].Name]; !has && !ready {
-			certs = append(certs, structs.Certificate{
+			c := structs.Certificate{
 				Id:      certList.Items[i].Name,
 				Domain:  certList.Items[i].Spec.CommonName,
 				Domains: certList.Items[i].Spec.DNSNames,
 				Status:  "Not Ready",
-			})
+			}
+
+			if certList.Items[i].Status.LastFailureTime != nil {
+				c.RenewError = certificateFailureMessage(&certList.Items[i])
+			}
+
+			certs = append(certs, c)
 		}
 	}
 
@@ -240,11 +247,24 @@ func (p *Provider) generatedCertificateList() (structs.Certificates, error) {
 }
 
 func (p *Provider) certFromNamespace(ns ac.Namespace) (structs.Certificates, error) {
-	ss, err := p.Cluster.CoreV1().Secrets(ns.Name).List(context.TODO(), am.ListOptions{
+	cs, _, err := p.certificatesInNamespace(ns.Name)
+	return cs, err
+}
+
+// certificatesInNamespace returns the certificates of a namespace along with
+// the failed issuances of its cert-manager Certificates, which include the
+// Certificates that have not been issued yet.
+func (p *Provider) certificatesInNamespace(ns string) (structs.Certificates, map[string]certificateRenewFailure, error) {
+	ss, err := p.Cluster.CoreV1().Secrets(ns).List(context.TODO(), am.ListOptions{
 		FieldSelector: "type=kubernetes.io/tls",
 	})
 	if err != nil {
-		return nil, errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
+	}
+
+	failures, err := p.certificateRenewFailures(ns)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	cs := structs.Certificates{}
@@ -252,13 +272,124 @@ func (p *Provider) certFromNamespace(ns ac.Namespace) (structs.Certificates, err
 	for _, s := range ss.Items {
 		c, err := p.certificateFromSecret(&s)
 		if err != nil {
-			return nil, errors.WithStack(err)
+			return nil, nil, errors.WithStack(err)
 		}
 
+		c.RenewError = failures[s.Name].Error
+
 		cs = append(cs, *c)
 	}
 
-	return cs, nil
+	return cs, failures, nil
+}
+
+// certificateRenewFailure is the last failed issuance of a cert-manager
+// Certificate.
+type certificateRenewFailure struct {
+	Certificate string
+	Domain      string
+	Error       string
+}
+
+// certificateRenewFailures returns the cert-manager Certificates of a
+// namespace whose last issuance failed, keyed by the name of their secret.
+// The reason of the failed acme Order is used when there is one as it names
+// the challenge that failed.
+func (p *Provider) certificateRenewFailures(ns string) (map[string]certificateRenewFailure, error) {
+	certs, err := p.CertManagerClient.CertmanagerV1().Certificates(ns).List(context.TODO(), am.ListOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	failures := map[string]certificateRenewFailure{}
+
+	var reasons map[string]string
+
+	for i := range certs.Items {
+		crt := &certs.Items[i]
+
+		if crt.Status.LastFailureTime == nil {
+			continue
+		}
+
+		if reasons == nil {
+			if reasons, err = p.certificateOrderFailures(ns); err != nil {
+				return nil, err
+			}
+		}
+
+		failures[crt.Spec.SecretName] = certificateRenewFailure{
+			Certificate: crt.Name,
+			Domain:      common.CoalesceString(crt.Spec.CommonName, strings.Join(crt.Spec.DNSNames, ",")),
+			Error:       common.CoalesceString(reasons[crt.Name], certificateFailureMessage(crt)),
+		}
+	}
+
+	return failures, nil
+}
+
+// certificateOrderFailures returns the reason of the most recent failed acme
+// Order of each Certificate in a namespace. Orders belong to the
+// CertificateRequest that created them, which is annotated with the name of
+// its Certificate.
+func (p *Provider) certificateOrderFailures(ns string) (map[string]string, error) {
+	crs, err := p.CertManagerClient.CertmanagerV1().CertificateRequests(ns).List(context.TODO(), am.ListOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	owners := map[string]string{}
+
+	for _, cr := range crs.Items {
+		owners[cr.Name] = cr.Annotations[cmapi.CertificateNameKey]
+	}
+
+	orders, err := p.CertManagerClient.AcmeV1().Orders(ns).List(context.TODO(), am.ListOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	reasons := map[string]string{}
+	failed := map[string]time.Time{}
+
+	for _, o := range orders.Items {
+		switch o.Status.State {
+		case cmacme.Errored, cmacme.Invalid, cmacme.Expired:
+		default:
+			continue
+		}
+
+		if o.Status.Reason == "" || o.Status.FailureTime == nil {
+			continue
+		}
+
+		for _, ref := range o.OwnerReferences {
+			crt := owners[ref.Name]
+
+			if ref.Kind != "CertificateRequest" || crt == "" || o.Status.FailureTime.Time.Before(failed[crt]) {
+				continue
+			}
+
+			failed[crt] = o.Status.FailureTime.Time
+			reasons[crt] = o.Status.Reason
+		}
+	}
+
+	return reasons, nil
+}
+
+// certificateFailureMessage is the message of the condition that reports
+// the failed issuance of a Certificate.
+func certificateFailureMessage(crt *cmapi.Certificate) string {
+	for _, t := range []cmapi.CertificateConditionType{cmapi.CertificateConditionIssuing, cmapi.CertificateConditionReady} {
+		for _, c := range crt.Status.Conditions {
+			if c.Type == t && c.Status == cmmeta.ConditionFalse && c.Message != "" {
+				return c.Message
+			}
+		}
+	}
+
+	return "issuance failed"
 }
 
 func (*Provider) certificateFromSecret(s *ac.Secret) (*structs.Certificate, error) {
@@ -0,0 +1,296 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	ac "k8s.io/api/core/v1"
+	ae "k8s.io/apimachinery/pkg/api/errors"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	certificateMonitorLeaseName = "convox-certificate-monitor"
+	certificateMonitorConfigMap = "certificate-monitor"
+)
+
+// certificateExpiryThresholds are the days before expiry at which a
+// certificate:expiring event is sent, largest first.
+var certificateExpiryThresholds = []int{30, 14, 7, 1}
+
+// certificateMonitorState is what the certificate monitor has already
+// reported so that each threshold and failure is only sent once. It is kept
+// in a ConfigMap of the rack namespace between checks.
+type certificateMonitorState struct {
+	// expiring is the last threshold sent for a certificate, keyed by its
+	// namespace, id and expiration so that a renewed certificate starts over
+	expiring map[string]int
+
+	// failed is the last renewal error sent for a cert-manager Certificate
+	failed map[string]string
+}
+
+func newCertificateMonitorState() *certificateMonitorState {
+	return &certificateMonitorState{
+		expiring: map[string]int{},
+		failed:   map[string]string{},
+	}
+}
+
+// certificateMonitorLoad reads the state that the monitor saved in the rack
+// namespace, so that a restart or a new leader does not send the same events
+// again.
+func (p *Provider) certificateMonitorLoad() *certificateMonitorState {
+	state := newCertificateMonitorState()
+
+	cm, err := p.Cluster.CoreV1().ConfigMaps(p.Namespace).Get(context.TODO(), certificateMonitorConfigMap, am.GetOptions{})
+	if err != nil {
+		if !ae.IsNotFound(err) {
+			fmt.Printf("ns=certificate_monitor at=error kind=state_load err=%q\n", err)
+		}
+		return state
+	}
+
+	if err := json.Unmarshal([]byte(cm.Data["expiring"]), &state.expiring); err != nil {
+		fmt.Printf("ns=certificate_monitor at=error kind=state_load err=%q\n", err)
+	}
+
+	if err := json.Unmarshal([]byte(cm.Data["failed"]), &state.failed); err != nil {
+		fmt.Printf("ns=certificate_monitor at=error kind=state_load err=%q\n", err)
+	}
+
+	if state.expiring == nil {
+		state.expiring = map[string]int{}
+	}
+
+	if state.failed == nil {
+		state.failed = map[string]string{}
+	}
+
+	return state
+}
+
+// certificateMonitorSave writes state to the rack namespace when it changed.
+func (p *Provider) certificateMonitorSave(state *certificateMonitorState) error {
+	expiring, err := json.Marshal(state.expiring)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	failed, err := json.Marshal(state.failed)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	data := map[string]string{"expiring": string(expiring), "failed": string(failed)}
+
+	cms := p.Cluster.CoreV1().ConfigMaps(p.Namespace)
+
+	cm, err := cms.Get(context.TODO(), certificateMonitorConfigMap, am.GetOptions{})
+	switch {
+	case ae.IsNotFound(err):
+		_, err := cms.Create(context.TODO(), &ac.ConfigMap{
+			ObjectMeta: am.ObjectMeta{Name: certificateMonitorConfigMap},
+			Data:       data,
+		}, am.CreateOptions{})
+		return errors.WithStack(err)
+	case err != nil:
+		return errors.WithStack(err)
+	}
+
+	if reflect.DeepEqual(cm.Data, data) {
+		return nil
+	}
+
+	cm.Data = data
+
+	_, err = cms.Update(context.TODO(), cm, am.UpdateOptions{})
+
+	return errors.WithStack(err)
+}
+
+// certificateMonitorInterval is hourly unless CERTIFICATE_CHECK_INTERVAL is
+// set, 0 turns the monitor off.
+func certificateMonitorInterval() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("CERTIFICATE_CHECK_INTERVAL"))
+	switch {
+	case err != nil:
+		return time.Hour
+	case d <= 0:
+		return 0
+	case d < time.Minute:
+		return time.Minute
+	}
+
+	return d
+}
+
+// certificateExpiryThreshold is the smallest threshold that a certificate
+// with days left has crossed, or 0 when it has not crossed any.
+func certificateExpiryThreshold(days int) int {
+	threshold := 0
+
+	for _, t := range certificateExpiryThresholds {
+		if days <= t {
+			threshold = t
+		}
+	}
+
+	return threshold
+}
+
+// runCertificateMonitor checks the certificates of the rack and its apps on
+// an interval and sends certificate:expiring and certificate:renew-failed
+// events. It runs on the elected leader only.
+func (p *Provider) runCertificateMonitor(ctx context.Context) {
+	fmt.Printf("ns=certificate_monitor at=start\n")
+
+	tick := time.NewTicker(certificateMonitorInterval())
+	defer tick.Stop()
+
+	state := p.certificateMonitorLoad()
+
+	p.certificateMonitorCheck(time.Now(), state)
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("ns=certificate_monitor at=stop\n")
+			return
+		case <-tick.C:
+			p.certificateMonitorCheck(time.Now(), state)
+		}
+	}
+}
+
+// certificateMonitorCheck sends the events for the certificates that crossed
+// an expiry threshold and the Certificates whose renewal failed since the
+// previous check.
+func (p *Provider) certificateMonitorCheck(now time.Time, state *certificateMonitorState) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("ns=certificate_monitor at=error kind=panic_recovered recovered=%v\n", r)
+		}
+	}()
+
+	ns, err := p.ListNamespacesFromInformer(fmt.Sprintf("system=convox,rack=%s", p.Name))
+	if err != nil {
+		fmt.Printf("ns=certificate_monitor at=error kind=namespace_list err=%q\n", err)
+		return
+	}
+
+	expiring := map[string]int{}
+	failed := map[string]string{}
+	complete := true
+
+	for _, n := range ns.Items {
+		cs, failures, err := p.certificatesInNamespace(n.Name)
+		if err != nil {
+			fmt.Printf("ns=certificate_monitor at=error namespace=%s err=%q\n", n.Name, err)
+			complete = false
+			continue
+		}
+
+		app := ""
+
+		if n.Labels["type"] == "app" {
+			app = n.Labels["app"]
+		}
+
+		for _, c := range cs {
+			p.certificateMonitorExpiry(now, state, expiring, n.Name, app, c)
+		}
+
+		for secret, f := range failures {
+			p.certificateMonitorFailure(state, failed, n.Name, app, secret, f)
+		}
+	}
+
+	// certificates that were renewed, recovered or removed are forgotten but
+	// only when every namespace was checked so a failed list does not resend
+	if complete {
+		state.expiring = expiring
+		state.failed = failed
+	}
+
+	if err := p.certificateMonitorSave(state); err != nil {
+		fmt.Printf("ns=certificate_monitor at=error kind=state_save err=%q\n", err)
+	}
+}
+
+func (p *Provider) certificateMonitorExpiry(now time.Time, state *certificateMonitorState, seen map[string]int, ns, app string, c structs.Certificate) {
+	if c.Expiration.IsZero() {
+		return
+	}
+
+	key := fmt.Sprintf("%s/%s/%d", ns, c.Id, c.Expiration.Unix())
+
+	days := c.DaysToExpiry(now)
+	threshold := certificateExpiryThreshold(days)
+
+	if sent, ok := state.expiring[key]; ok && (threshold == 0 || sent <= threshold) {
+		seen[key] = sent
+		return
+	}
+
+	if threshold == 0 {
+		return
+	}
+
+	state.expiring[key] = threshold
+	seen[key] = threshold
+
+	data := map[string]string{
+		"id":      c.Id,
+		"domain":  c.Domain,
+		"domains": strings.Join(c.Domains, ","),
+		"expires": c.Expiration.UTC().Format(time.RFC3339),
+		"days":    strconv.Itoa(days),
+	}
+
+	if app != "" {
+		data["app"] = app
+	}
+
+	p.EventSend("certificate:expiring", structs.EventSendOptions{
+		Data:   data,
+		Status: options.String("warning"),
+	})
+}
+
+func (p *Provider) certificateMonitorFailure(state *certificateMonitorState, seen map[string]string, ns, app, secret string, f certificateRenewFailure) {
+	key := fmt.Sprintf("%s/%s", ns, f.Certificate)
+
+	seen[key] = f.Error
+
+	if state.failed[key] == f.Error {
+		return
+	}
+
+	state.failed[key] = f.Error
+
+	data := map[string]string{
+		"id":          secret,
+		"certificate": f.Certificate,
+		"domain":      f.Domain,
+		"error":       f.Error,
+	}
+
+	if app != "" {
+		data["app"] = app
+	}
+
+	p.EventSend("certificate:renew-failed", structs.EventSendOptions{
+		Data:   data,
+		Status: options.String("error"),
+	})
+}
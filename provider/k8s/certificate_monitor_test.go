@@ -0,0 +1,199 @@
+package k8s_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	cmacme "github.com/cert-manager/cert-manager/pkg/apis/acme/v1"
+	cmapi "github.com/cert-manager/cert-manager/pkg/apis/certmanager/v1"
+	cmmeta "github.com/cert-manager/cert-manager/pkg/apis/meta/v1"
+	cmclient "github.com/cert-manager/cert-manager/pkg/client/clientset/versioned"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/provider/k8s"
+	"github.com/stretchr/testify/require"
+	ac "k8s.io/api/core/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+type certificateEvent struct {
+	Action string            `json:"action"`
+	Data   map[string]string `json:"data"`
+	Status string            `json:"status"`
+}
+
+func TestCertificateMonitor(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Second)
+
+	var (
+		lock   sync.Mutex
+		events []certificateEvent
+	)
+
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e certificateEvent
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &e)
+		lock.Lock()
+		events = append(events, e)
+		lock.Unlock()
+	}))
+	defer hook.Close()
+
+	received := func() []certificateEvent {
+		drainPendingDispatches()
+		lock.Lock()
+		defer lock.Unlock()
+		es := events
+		events = nil
+		sort.Slice(es, func(i, j int) bool { return es[i].Action < es[j].Action })
+		return es
+	}
+
+	testProvider(t, func(p *k8s.Provider) {
+		k8s.SetWebhooksForTest(p, []string{hook.URL})
+
+		p.CertManagerClient = testCertManager(t, certificateMonitorFixtures(now))
+
+		_, err := p.Cluster.CoreV1().Secrets("ns1").Create(context.TODO(), &ac.Secret{
+			ObjectMeta: am.ObjectMeta{Name: "app1-domains"},
+			Type:       ac.SecretTypeTLS,
+			Data:       map[string][]byte{"tls.crt": testCertificatePEM(t, "app1.example.org", now.Add(10*24*time.Hour+time.Hour))},
+		}, am.CreateOptions{})
+		require.NoError(t, err)
+
+		cs, err := p.CertificateList(structs.CertificateListOptions{})
+		require.NoError(t, err)
+		require.Len(t, cs, 1)
+		require.Equal(t, "app1-domains", cs[0].Id)
+		require.Equal(t, "acme: challenge failed", cs[0].RenewError)
+
+		check := k8s.CertificateMonitorCheckForTest(p)
+
+		check(now)
+
+		es := received()
+		require.Len(t, es, 2)
+		require.Equal(t, "certificate:expiring", es[0].Action)
+		require.Equal(t, "warning", es[0].Status)
+		require.Equal(t, "app1-domains", es[0].Data["id"])
+		require.Equal(t, "app1.example.org", es[0].Data["domain"])
+		require.Equal(t, "10", es[0].Data["days"])
+		require.Equal(t, "certificate:renew-failed", es[1].Action)
+		require.Equal(t, "error", es[1].Status)
+		require.Equal(t, "app1-domains", es[1].Data["certificate"])
+		require.Equal(t, "acme: challenge failed", es[1].Data["error"])
+
+		// nothing new since the last check
+		check(now.Add(time.Hour))
+		require.Empty(t, received())
+
+		// nor after a restart of the monitor
+		check = k8s.CertificateMonitorCheckForTest(p)
+		check(now.Add(2 * time.Hour))
+		require.Empty(t, received())
+
+		// the next threshold is sent once
+		check(now.Add(4 * 24 * time.Hour))
+
+		es = received()
+		require.Len(t, es, 1)
+		require.Equal(t, "certificate:expiring", es[0].Action)
+		require.Equal(t, "6", es[0].Data["days"])
+
+		check(now.Add(5 * 24 * time.Hour))
+		require.Empty(t, received())
+	})
+}
+
+func certificateMonitorFixtures(now time.Time) map[string]interface{} {
+	failed := am.NewTime(now.Add(-time.Hour))
+
+	return map[string]interface{}{
+		"/apis/cert-manager.io/v1/namespaces/ns1/certificates": cmapi.CertificateList{
+			Items: []cmapi.Certificate{
+				{
+					ObjectMeta: am.ObjectMeta{Name: "app1-domains", Namespace: "ns1"},
+					Spec:       cmapi.CertificateSpec{CommonName: "app1.example.org", SecretName: "app1-domains"},
+					Status: cmapi.CertificateStatus{
+						Conditions: []cmapi.CertificateCondition{
+							{Type: cmapi.CertificateConditionReady, Status: cmmeta.ConditionTrue},
+							{Type: cmapi.CertificateConditionIssuing, Status: cmmeta.ConditionFalse, Reason: "Failed", Message: "The certificate request has failed to complete"},
+						},
+						LastFailureTime: &failed,
+					},
+				},
+				{
+					ObjectMeta: am.ObjectMeta{Name: "app1-other", Namespace: "ns1"},
+					Spec:       cmapi.CertificateSpec{CommonName: "other.example.org", SecretName: "app1-other"},
+				},
+			},
+		},
+		"/apis/cert-manager.io/v1/namespaces/ns1/certificaterequests": cmapi.CertificateRequestList{
+			Items: []cmapi.CertificateRequest{
+				{ObjectMeta: am.ObjectMeta{Name: "app1-domains-1", Annotations: map[string]string{cmapi.CertificateNameKey: "app1-domains"}}},
+			},
+		},
+		"/apis/acme.cert-manager.io/v1/namespaces/ns1/orders": cmacme.OrderList{
+			Items: []cmacme.Order{
+				{
+					ObjectMeta: am.ObjectMeta{Name: "app1-domains-1-1", OwnerReferences: []am.OwnerReference{{Kind: "CertificateRequest", Name: "app1-domains-1"}}},
+					Status:     cmacme.OrderStatus{State: cmacme.Invalid, Reason: "acme: challenge failed", FailureTime: &failed},
+				},
+			},
+		},
+	}
+}
+
+// testCertManager is a cert-manager client for an api that serves fixed
+// lists by path.
+func testCertManager(t *testing.T, lists map[string]interface{}) cmclient.Interface {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list, ok := lists[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+	}))
+	t.Cleanup(srv.Close)
+
+	cm, err := cmclient.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+
+	return cm
+}
+
+func testCertificatePEM(t *testing.T, domain string, expires time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    expires.Add(-90 * 24 * time.Hour),
+		NotAfter:     expires,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -126,6 +126,10 @@ func (a *AtomController) Update(prev, cur interface{}) error {
 
 	a.logger.Logf("atom update: %s/%s\n", d.Namespace, d.Name)
 
+	if pd, err := assertAtom(prev); err == nil && pd.Status != d.Status {
+		go a.provider.releaseTimelineAtomStatus(d.Namespace, string(pd.Status), string(d.Status))
+	}
+
 	return a.syncAtom(d)
 }
 
@@ -185,6 +189,13 @@ func (a *AtomController) processDependency(obj *atomv1.Atom) {
 				return
 			}
 		}
+
+		if rType == "job" {
+			if err := a.processJobDependency(obj, dep); err != nil {
+				a.logger.Logf("%s", err.Error())
+				return
+			}
+		}
 	}
 	_, err := a.PatchAtom(a.provider.ctx, obj, func(atm *atomv1.Atom) *atomv1.Atom {
 		atm.Spec.Dependencies = nil
@@ -244,6 +255,37 @@ func (a *AtomController) processElasticacheDependency(obj *atomv1.Atom, dep stri
 	return nil
 }
 
+// processJobDependency runs a before-promote job. A job that fails moves
+// the atom to Error so that the promote rolls back instead of waiting on the
+// dependency forever.
+func (a *AtomController) processJobDependency(obj *atomv1.Atom, dep string) error {
+	app, release, job := parseJobDependencyId(dep)
+
+	superseded := func() bool {
+		cur, err := a.atom.AtomV1().Atoms(obj.Namespace).Get(a.provider.ctx, obj.Name, v1.GetOptions{})
+		return err == nil && cur.Spec.CurrentVersion != obj.Spec.CurrentVersion
+	}
+
+	timeout := time.Duration(obj.Spec.ProgressDeadlineSeconds) * time.Second
+
+	err := a.provider.runReleaseJob(a.provider.ctx, app, release, job, timeout, superseded)
+	switch {
+	case err == nil:
+		return nil
+	case err == errReleaseJobSuperseded || superseded():
+		return fmt.Errorf("job %s of %s superseded", job, release)
+	}
+
+	if _, perr := a.PatchAtom(a.provider.ctx, obj, func(atm *atomv1.Atom) *atomv1.Atom {
+		atm.Status = "Error"
+		return atm
+	}, v1.PatchOptions{}); perr != nil {
+		return fmt.Errorf("failed to fail atom after job %s: %s", job, perr)
+	}
+
+	return fmt.Errorf("job %s of %s failed: %s", job, release, err)
+}
+
 func (a *AtomController) resolveDependencyInAtomVersion(obj *atomv1.Atom, dep string, data []byte) error {
 	atomVersion, err := a.atom.AtomV1().AtomVersions(obj.Namespace).Get(a.provider.ctx, obj.Spec.CurrentVersion, v1.GetOptions{})
 	if err != nil {
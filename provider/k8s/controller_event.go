@@ -147,6 +147,8 @@ func (c *EventController) Add(obj interface{}) error {
 		}
 	case "v1/ConfigMap":
 	case "v1/Pod":
+		c.Provider.releaseTimelinePodEvent(e)
+
 		switch e.Reason {
 		case "Killing":
 		default:
@@ -96,6 +96,8 @@ func (c *PodController) Delete(obj interface{}) error {
 
 	fmt.Printf("pod delete: %s/%s\n", p.ObjectMeta.Namespace, p.ObjectMeta.Name)
 
+	c.Provider.releaseTimelinePodDeleted(p)
+
 	return nil
 }
 
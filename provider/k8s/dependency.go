@@ -0,0 +1,43 @@
+package k8s
+
+import (
+	"fmt"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+)
+
+// releaseDependencyEnvironment returns the urls of the services of other
+// apps that m depends on by the env var they are injected as. The services
+// are reached through their internal names so the urls do not depend on
+// namespace naming. A dependency on a missing app or service, or on a
+// service that is not internal, fails the promote.
+func (p *Provider) releaseDependencyEnvironment(m *manifest.Manifest) (map[string]string, error) {
+	env := map[string]string{}
+
+	for _, d := range m.Dependencies {
+		dm, _, err := common.AppManifest(p, d.App)
+		if err != nil {
+			return nil, errors.WithStack(structs.ErrBadRequest("dependency %s: %s", d, err))
+		}
+
+		s, err := dm.Service(d.Service)
+		if err != nil {
+			return nil, errors.WithStack(structs.ErrBadRequest("dependency %s: %s", d, err))
+		}
+
+		if !s.Internal && !s.InternalRouter {
+			return nil, errors.WithStack(structs.ErrBadRequest("dependency %s: service must be internal or internalRouter", d))
+		}
+
+		if s.Port.Port == 0 {
+			return nil, errors.WithStack(structs.ErrBadRequest("dependency %s: service has no port", d))
+		}
+
+		env[d.Env()] = fmt.Sprintf("%s://%s:%d", common.CoalesceString(s.Port.Scheme, "http"), d.Host(), s.Port.Port)
+	}
+
+	return env, nil
+}
@@ -0,0 +1,66 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/mock"
+	ca "github.com/convox/convox/provider/k8s/pkg/apis/convox/v1"
+	cvfake "github.com/convox/convox/provider/k8s/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	ac "k8s.io/api/core/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReleaseDependencyEnvironment(t *testing.T) {
+	p := &Provider{Engine: &mock.TestEngine{}, Name: "rack1", Cluster: fake.NewSimpleClientset(), Convox: cvfake.NewSimpleClientset()}
+
+	_, err := p.Cluster.CoreV1().Namespaces().Create(context.TODO(), &ac.Namespace{
+		ObjectMeta: am.ObjectMeta{
+			Name:        "rack1-billing",
+			Annotations: map[string]string{"convox.com/app-release": "R1", "convox.com/app-status": "running"},
+			Labels:      map[string]string{"name": "billing", "rack": "rack1", "system": "convox", "type": "app"},
+		},
+	}, am.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = p.Convox.ConvoxV1().Releases("rack1-billing").Create(&ca.Release{
+		ObjectMeta: am.ObjectMeta{Name: "r1"},
+		Spec: ca.ReleaseSpec{
+			Created:  "20261018.120000.000000000",
+			Manifest: "services:\n  api:\n    internal: true\n    port: 4000\n  secure:\n    internalRouter: true\n    port:\n      port: 4443\n      scheme: https\n  web:\n    port: 3000\n  worker:\n    internal: true\n",
+		},
+	})
+	require.NoError(t, err)
+
+	m := &manifest.Manifest{Dependencies: manifest.Dependencies{
+		{App: "billing", Service: "api", As: "BILLING_URL"},
+		{App: "billing", Service: "secure"},
+	}}
+
+	env, err := p.releaseDependencyEnvironment(m)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"BILLING_URL":        "http://api.billing.convox.internal:4000",
+		"BILLING_SECURE_URL": "https://secure.billing.convox.internal:4443",
+	}, env)
+
+	tests := map[string]string{
+		"nosuch": "dependency nosuch/api: app not found: nosuch",
+		"web":    "dependency billing/web: service must be internal or internalRouter",
+		"worker": "dependency billing/worker: service has no port",
+		"other":  "dependency billing/other: no such service: other",
+	}
+
+	for service, msg := range tests {
+		d := manifest.Dependency{App: "billing", Service: service}
+		if service == "nosuch" {
+			d = manifest.Dependency{App: "nosuch", Service: "api"}
+		}
+
+		_, err := p.releaseDependencyEnvironment(&manifest.Manifest{Dependencies: manifest.Dependencies{d}})
+		require.EqualError(t, err, msg)
+	}
+}
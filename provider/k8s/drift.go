@@ -0,0 +1,399 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	ae "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	driftLeaseName = "convox-app-drift"
+)
+
+var (
+	// driftIgnoredKinds run to completion, their live objects come and go
+	driftIgnoredKinds = map[string]bool{"Job": true, "Pod": true}
+
+	// driftIgnoredMetadata are set by the cluster on every object
+	driftIgnoredMetadata = map[string]bool{
+		"creationTimestamp": true,
+		"generation":        true,
+		"managedFields":     true,
+		"resourceVersion":   true,
+		"selfLink":          true,
+		"uid":               true,
+	}
+
+	reDriftSeparator = regexp.MustCompile(`(?m)^---\s*$`)
+)
+
+// AppDrift renders the active release of an app the way a promote does and
+// compares the result with the live objects. Only the fields the release
+// sets are compared so defaults and status added by the cluster are not
+// reported.
+func (p *Provider) AppDrift(name string) (structs.Drifts, error) {
+	a, err := p.AppGet(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if a.Release == "" {
+		return structs.Drifts{}, nil
+	}
+
+	items, _, err := p.releaseRender(a, a.Release, structs.ReleasePromoteOptions{}, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(p.DiscoveryClient))
+
+	ds := structs.Drifts{}
+	seen := map[string]bool{}
+
+	for _, item := range items {
+		for _, doc := range reDriftSeparator.Split(string(item), -1) {
+			var obj map[string]interface{}
+
+			if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			if len(obj) == 0 {
+				continue
+			}
+
+			d, err := p.driftObject(mapper, p.AppNamespace(name), obj)
+			if err != nil {
+				return nil, err
+			}
+
+			if d == nil || seen[d.Kind+"/"+d.Name] {
+				continue
+			}
+
+			seen[d.Kind+"/"+d.Name] = true
+
+			if d.Missing || len(d.Changes) > 0 {
+				ds = append(ds, *d)
+			}
+		}
+	}
+
+	sort.Slice(ds, func(i, j int) bool {
+		if ds[i].Kind != ds[j].Kind {
+			return ds[i].Kind < ds[j].Kind
+		}
+		return ds[i].Name < ds[j].Name
+	})
+
+	return ds, nil
+}
+
+// driftObject compares one rendered object with its live counterpart.
+func (p *Provider) driftObject(mapper meta.RESTMapper, namespace string, obj map[string]interface{}) (*structs.Drift, error) {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	md, _ := obj["metadata"].(map[string]interface{})
+	name, _ := md["name"].(string)
+
+	if kind == "" || name == "" || driftIgnoredKinds[kind] {
+		return nil, nil
+	}
+
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	rm, err := mapper.RESTMapping(gv.WithKind(kind).GroupKind(), gv.Version)
+	if err != nil {
+		// kinds the cluster does not serve can not have drifted
+		return nil, nil
+	}
+
+	ri := p.DynamicClient.Resource(rm.Resource)
+
+	var live map[string]interface{}
+
+	if rm.Scope.Name() == meta.RESTScopeNameNamespace {
+		ns, _ := md["namespace"].(string)
+
+		lo, err := ri.Namespace(common.CoalesceString(ns, namespace)).Get(context.TODO(), name, am.GetOptions{})
+		if err != nil && !ae.IsNotFound(err) {
+			return nil, errors.WithStack(err)
+		}
+		if err == nil {
+			live = lo.Object
+		}
+	} else {
+		lo, err := ri.Get(context.TODO(), name, am.GetOptions{})
+		if err != nil && !ae.IsNotFound(err) {
+			return nil, errors.WithStack(err)
+		}
+		if err == nil {
+			live = lo.Object
+		}
+	}
+
+	d := &structs.Drift{Kind: kind, Name: name}
+
+	if live == nil {
+		d.Missing = true
+		return d, nil
+	}
+
+	for _, k := range driftKeys(obj) {
+		switch k {
+		case "apiVersion", "kind", "status":
+			continue
+		}
+
+		driftCompare(k, obj[k], live[k], kind == "Secret", &d.Changes)
+	}
+
+	return d, nil
+}
+
+// driftCompare appends the fields of desired at path that live does not
+// match. Named list items such as containers and env vars are matched by
+// name so an added or removed item is reported on its own.
+func driftCompare(path string, desired, live interface{}, secret bool, changes *[]structs.DriftChange) {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, _ := live.(map[string]interface{})
+
+		for _, k := range driftKeys(d) {
+			if path == "metadata" && driftIgnoredMetadata[k] {
+				continue
+			}
+
+			driftCompare(path+"."+k, d[k], l[k], secret, changes)
+		}
+	case []interface{}:
+		l, _ := live.([]interface{})
+
+		if driftNamed(d) && driftNamed(l) {
+			driftCompareNamed(path, d, l, secret, changes)
+			return
+		}
+
+		if len(d) != len(l) {
+			driftChange(path, fmt.Sprintf("%d items", len(d)), fmt.Sprintf("%d items", len(l)), false, changes)
+			return
+		}
+
+		for i := range d {
+			driftCompare(fmt.Sprintf("%s[%d]", path, i), d[i], l[i], secret, changes)
+		}
+	default:
+		if ds, ls := driftString(desired), driftString(live); !driftEqual(ds, ls) {
+			driftChange(path, ds, ls, secret && strings.HasPrefix(path, "data."), changes)
+		}
+	}
+}
+
+func driftCompareNamed(path string, desired, live []interface{}, secret bool, changes *[]structs.DriftChange) {
+	lives := map[string]interface{}{}
+
+	for _, li := range live {
+		lives[driftItemName(li)] = li
+	}
+
+	names := map[string]bool{}
+
+	for _, di := range desired {
+		name := driftItemName(di)
+		names[name] = true
+
+		li, ok := lives[name]
+		if !ok {
+			driftChange(fmt.Sprintf("%s[%s]", path, name), "present", "missing", false, changes)
+			continue
+		}
+
+		driftCompare(fmt.Sprintf("%s[%s]", path, name), di, li, secret, changes)
+	}
+
+	for _, li := range live {
+		if name := driftItemName(li); !names[name] {
+			driftChange(fmt.Sprintf("%s[%s]", path, name), "missing", "present", false, changes)
+		}
+	}
+}
+
+func driftChange(path, expected, actual string, redact bool, changes *[]structs.DriftChange) {
+	if redact {
+		expected, actual = "****", "****"
+	}
+
+	*changes = append(*changes, structs.DriftChange{Path: path, Expected: expected, Actual: actual})
+}
+
+// driftEqual compares scalar values, resource quantities by amount since the
+// cluster rewrites them to their canonical form.
+func driftEqual(desired, live string) bool {
+	if desired == live {
+		return true
+	}
+
+	dq, err := resource.ParseQuantity(desired)
+	if err != nil {
+		return false
+	}
+
+	lq, err := resource.ParseQuantity(live)
+	if err != nil {
+		return false
+	}
+
+	return dq.Cmp(lq) == 0
+}
+
+func driftItemName(v interface{}) string {
+	m, _ := v.(map[string]interface{})
+	name, _ := m["name"].(string)
+	return name
+}
+
+func driftKeys(m map[string]interface{}) []string {
+	ks := []string{}
+
+	for k := range m {
+		ks = append(ks, k)
+	}
+
+	sort.Strings(ks)
+
+	return ks
+}
+
+// driftNamed is true for lists whose items all have a name.
+func driftNamed(items []interface{}) bool {
+	for _, i := range items {
+		if driftItemName(i) == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func driftString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(t)
+		return string(data)
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// driftInterval is how often the drift worker checks every app, zero when
+// the worker is off.
+func driftInterval() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("DRIFT_CHECK_INTERVAL"))
+	if err != nil || d < time.Minute {
+		return 0
+	}
+
+	return d
+}
+
+// runDriftDetection checks the apps for drift on an interval and sends an
+// app:drift event when the drift of an app appears or changes. It runs on
+// the elected leader only.
+func (p *Provider) runDriftDetection(ctx context.Context) {
+	fmt.Printf("ns=app_drift at=start\n")
+
+	tick := time.NewTicker(driftInterval())
+	defer tick.Stop()
+
+	last := map[string]string{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("ns=app_drift at=stop\n")
+			return
+		case <-tick.C:
+			p.driftCheck(last)
+		}
+	}
+}
+
+// driftCheck sends events for the apps whose drift differs from last, the
+// drifted objects of each app as of the previous check.
+func (p *Provider) driftCheck(last map[string]string) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("ns=app_drift at=error kind=panic_recovered recovered=%v\n", r)
+		}
+	}()
+
+	as, err := p.AppList()
+	if err != nil {
+		fmt.Printf("ns=app_drift at=error kind=app_list err=%q\n", err)
+		return
+	}
+
+	for _, a := range as {
+		if a.Release == "" || a.Status != "running" {
+			continue
+		}
+
+		ds, err := p.AppDrift(a.Name)
+		if err != nil {
+			fmt.Printf("ns=app_drift at=error app=%s err=%q\n", a.Name, err)
+			continue
+		}
+
+		objects := []string{}
+
+		for _, d := range ds {
+			objects = append(objects, fmt.Sprintf("%s/%s", d.Kind, d.Name))
+		}
+
+		summary := strings.Join(objects, ",")
+
+		if summary == last[a.Name] {
+			continue
+		}
+
+		last[a.Name] = summary
+
+		if summary == "" {
+			continue
+		}
+
+		p.EventSend("app:drift", structs.EventSendOptions{
+			Data:   map[string]string{"app": a.Name, "release": a.Release, "objects": summary},
+			Status: options.String("warning"),
+		})
+	}
+}
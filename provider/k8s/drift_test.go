@@ -0,0 +1,147 @@
+package k8s_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/provider/k8s"
+	cvfake "github.com/convox/convox/provider/k8s/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+)
+
+func TestAppDrift(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		kc := p.Convox.(*cvfake.Clientset)
+		kk := p.Cluster.(*fake.Clientset)
+
+		require.NoError(t, appCreateWithAnnotation(kk, "rack1", "app1", map[string]string{
+			"convox.com/app-release": "release1",
+			"convox.com/app-status":  "running",
+		}))
+		require.NoError(t, buildCreate(kc, "rack1-app1", "build1", "basic"))
+		require.NoError(t, releaseCreateInline(kc, "rack1-app1", "release1", "services:\n  web:\n    port: 3000\n"))
+
+		p.DiscoveryClient = driftDiscovery()
+
+		a, err := p.AppGet("app1")
+		require.NoError(t, err)
+
+		items, err := k8s.ReleaseRenderForTest(p, a, "release1")
+		require.NoError(t, err)
+
+		live := driftLiveObjects(t, items)
+
+		// nothing running yet
+		p.DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+		ds, err := p.AppDrift("app1")
+		require.NoError(t, err)
+		require.Contains(t, ds, structs.Drift{Kind: "Deployment", Name: "web", Missing: true})
+
+		// running as released
+		p.DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), live...)
+
+		ds, err = p.AppDrift("app1")
+		require.NoError(t, err)
+		require.Equal(t, structs.Drifts{}, ds)
+
+		// edited in place
+		for _, o := range live {
+			u := o.(*unstructured.Unstructured)
+
+			switch u.GetKind() {
+			case "Deployment":
+				cs, _, _ := unstructured.NestedSlice(u.Object, "spec", "template", "spec", "containers")
+				c := cs[0].(map[string]interface{})
+				c["image"] = "hotfix:latest"
+				c["env"] = append(c["env"].([]interface{}), map[string]interface{}{"name": "DEBUG", "value": "1"})
+				require.NoError(t, unstructured.SetNestedSlice(u.Object, cs, "spec", "template", "spec", "containers"))
+				u.SetResourceVersion("2")
+				u.SetManagedFields([]am.ManagedFieldsEntry{{Manager: "kubectl-edit"}})
+			case "Secret":
+				require.NoError(t, unstructured.SetNestedField(u.Object, "c2VjcmV0", "data", "PORT"))
+			}
+		}
+
+		p.DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), live...)
+
+		ds, err = p.AppDrift("app1")
+		require.NoError(t, err)
+		require.Len(t, ds, 2)
+
+		require.Equal(t, structs.Drift{Kind: "Deployment", Name: "web", Changes: []structs.DriftChange{
+			{Path: "spec.template.spec.containers[app1].env[DEBUG]", Expected: "missing", Actual: "present"},
+			{Path: "spec.template.spec.containers[app1].image", Expected: "repo1:web.build1", Actual: "hotfix:latest"},
+		}}, ds[0])
+
+		require.Equal(t, structs.Drift{Kind: "Secret", Name: "env-web", Changes: []structs.DriftChange{
+			{Path: "data.PORT", Expected: "****", Actual: "****"},
+		}}, ds[1])
+	})
+}
+
+func driftDiscovery() *fakediscovery.FakeDiscovery {
+	return &fakediscovery.FakeDiscovery{Fake: &ktesting.Fake{Resources: []*am.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []am.APIResource{
+				{Name: "namespaces", Kind: "Namespace", Namespaced: false},
+				{Name: "secrets", Kind: "Secret", Namespaced: true},
+				{Name: "services", Kind: "Service", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []am.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			},
+		},
+	}}}
+}
+
+// driftLiveObjects turns rendered templates into the objects the cluster
+// would hold after applying them.
+func driftLiveObjects(t *testing.T, items [][]byte) []runtime.Object {
+	os := []runtime.Object{}
+	seen := map[string]bool{}
+
+	for _, item := range items {
+		for _, doc := range regexp.MustCompile(`(?m)^---\s*$`).Split(string(item), -1) {
+			var obj map[string]interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(doc), &obj))
+
+			if len(obj) == 0 {
+				continue
+			}
+
+			u := &unstructured.Unstructured{Object: obj}
+
+			switch u.GetKind() {
+			case "Namespace", "Secret", "Service", "Deployment":
+			default:
+				continue
+			}
+
+			if u.GetNamespace() == "" && u.GetKind() != "Namespace" {
+				u.SetNamespace("rack1-app1")
+			}
+
+			if key := u.GetKind() + "/" + u.GetName(); !seen[key] {
+				seen[key] = true
+				u.SetUID("server-set")
+				os = append(os, u)
+			}
+		}
+	}
+
+	return os
+}
@@ -61,6 +61,30 @@ func TestEcrCachedResourceImage(t *testing.T) {
 			options:      map[string]string{"version": "15-3.3"},
 			want:         prefix + "/postgis/postgis:15-3.3",
 		},
+		{
+			name:         "rabbitmq default version",
+			resourceType: "rabbitmq",
+			options:      map[string]string{},
+			want:         prefix + "/library/rabbitmq:3.13",
+		},
+		{
+			name:         "mongodb library image",
+			resourceType: "mongodb",
+			options:      map[string]string{"version": "6.0"},
+			want:         prefix + "/library/mongo:6.0",
+		},
+		{
+			name:         "kafka non-library image",
+			resourceType: "kafka",
+			options:      map[string]string{},
+			want:         prefix + "/apache/kafka:3.8.0",
+		},
+		{
+			name:         "minio non-library image",
+			resourceType: "minio",
+			options:      map[string]string{},
+			want:         prefix + "/minio/minio:RELEASE.2024-10-13T13-34-11Z",
+		},
 		{
 			name:         "unknown resource type",
 			resourceType: "unknown",
@@ -557,7 +557,13 @@ func RunReleasePromoteWatcherForTest(p *Provider, ctx context.Context, app strin
 // ReleasePromote / Atom.Apply / template-render scaffolding. Test-only;
 // production callers must go through ReleasePromote which wraps this helper.
 func ReleaseTemplateServicesForTest(p *Provider, a *structs.App, e structs.Environment, r *structs.Release, ss manifest.Services, opts structs.ReleasePromoteOptions) ([]byte, error) {
-	return p.releaseTemplateServices(a, e, r, ss, opts)
+	return p.releaseTemplateServices(a, e, r, ss, opts, true)
+}
+
+// ReleaseTemplateServicesDryForTest renders the services the way AppDrift
+// does, without the side effects of a promote.
+func ReleaseTemplateServicesDryForTest(p *Provider, a *structs.App, e structs.Environment, r *structs.Release, ss manifest.Services) ([]byte, error) {
+	return p.releaseTemplateServices(a, e, r, ss, structs.ReleasePromoteOptions{}, false)
 }
 
 // ScanReleasePromoteAnnotationsForTest exposes the cold-start GC scan so
@@ -680,3 +686,42 @@ func HashParamValueForTest(p *Provider, value string) string {
 func ResetRackUIDCacheForTest(namespace string) {
 	rackUIDByNamespace.Delete(namespace)
 }
+
+// ReleaseJobPollIntervalForTest lets tests shorten the job status poll.
+var ReleaseJobPollIntervalForTest = &releaseJobPollInterval
+
+// RunReleaseJobForTest starts a job of a release and waits for it to finish.
+func RunReleaseJobForTest(p *Provider, app, release, job string, timeout time.Duration) error {
+	return p.runReleaseJob(context.Background(), app, release, job, timeout, nil)
+}
+
+// ReleaseRenderForTest exposes the render-only path AppDrift compares the
+// live objects against. Test-only.
+func ReleaseRenderForTest(p *Provider, a *structs.App, id string) ([][]byte, error) {
+	items, _, err := p.releaseRender(a, id, structs.ReleasePromoteOptions{}, nil, false)
+	return items, err
+}
+
+// CertificateMonitorCheckForTest returns a check of the certificate monitor
+// that starts from the saved state, as after a restart, and keeps its state
+// between calls.
+func CertificateMonitorCheckForTest(p *Provider) func(now time.Time) {
+	state := p.certificateMonitorLoad()
+
+	return func(now time.Time) {
+		p.certificateMonitorCheck(now, state)
+	}
+}
+
+// ReleasePromotionPollIntervalForTest and ReleasePromotionCheckIntervalForTest
+// let tests shorten the progressive promote runner.
+var (
+	ReleasePromotionPollIntervalForTest  = &releasePromotionPollInterval
+	ReleasePromotionCheckIntervalForTest = &releasePromotionCheckInterval
+	ReleasePromotionStaleAfterForTest    = &releasePromotionStaleAfter
+)
+
+// RecoverReleasePromotionForTest exposes the recovery of a stale promote.
+func RecoverReleasePromotionForTest(p *Provider, ctx context.Context, app string, annotations map[string]string) {
+	p.recoverReleasePromotion(ctx, app, annotations)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/convox/convox/provider/k8s/pkg/client/clientset/versioned/scheme"
 	"github.com/pkg/errors"
 	ac "k8s.io/api/core/v1"
+	ae "k8s.io/apimachinery/pkg/api/errors"
 	am "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
@@ -92,7 +93,17 @@ func (p *Provider) environment(a *structs.App, r *structs.Release, s manifest.Se
 		env[k] = v
 	}
 
-	return env, nil
+	if err := secretCheck(a, env); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// releases hold secret references, the values only reach the env secret
+	renv, err := p.Secrets.Resolve(env)
+	if err != nil {
+		return nil, errors.WithStack(structs.ErrBadRequest("%s", err))
+	}
+
+	return renv, nil
 }
 
 func (*Provider) appEnvironment(a *structs.App) map[string]string {
@@ -519,3 +530,35 @@ func serviceSecurityContext(s *manifest.Service) *ac.SecurityContext {
 
 	return sc
 }
+
+// systemConfigMap loads a rack-level configmap used as a small key/value
+// store, creating it on first use.
+func (p *Provider) systemConfigMap(name string) (*ac.ConfigMap, error) {
+	cms := p.Cluster.CoreV1().ConfigMaps(p.Namespace)
+
+	cm, err := cms.Get(context.TODO(), name, am.GetOptions{})
+	if ae.IsNotFound(err) {
+		cm, err = cms.Create(context.TODO(), &ac.ConfigMap{
+			ObjectMeta: am.ObjectMeta{
+				Namespace: p.Namespace,
+				Name:      name,
+				Labels: map[string]string{
+					"system": "convox",
+					"rack":   p.Name,
+				},
+			},
+		}, am.CreateOptions{})
+		if ae.IsAlreadyExists(err) {
+			cm, err = cms.Get(context.TODO(), name, am.GetOptions{})
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+
+	return cm, nil
+}
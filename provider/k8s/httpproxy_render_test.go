@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/convox/convox/pkg/manifest"
@@ -130,3 +131,32 @@ func TestRenderTemplateHTTPProxyStreamingTimeout(t *testing.T) {
 	require.NoError(t, err)
 	require.Contains(t, string(capped), "response: 120s")
 }
+
+func TestRenderTemplateHTTPProxyCanary(t *testing.T) {
+	p := Provider{Engine: &mock.TestEngine{}}
+	p.templater = templater.New(template.TemplatesFS)
+
+	plain, err := p.RenderTemplate("app/httpproxy", httpProxyRenderParams("contour", false, nil, "infinity"))
+	require.NoError(t, err)
+	require.NotContains(t, string(plain), "weight:")
+	require.NotContains(t, string(plain), "web-canary")
+
+	params := httpProxyRenderParams("contour", false, nil, "infinity")
+	params["Canary"] = &canaryRoute{PrimaryWeight: 90, CanaryWeight: 10}
+	params["Service"] = manifest.Service{
+		Name:    "web",
+		Port:    manifest.ServicePortScheme{Port: 8080, Scheme: "http"},
+		Timeout: 60,
+		Domains: manifest.ServiceDomains{"custom.example.com"},
+	}
+
+	split, err := p.RenderTemplate("app/httpproxy", params)
+	require.NoError(t, err)
+	require.Equal(t, 2, strings.Count(string(split), "    - name: web\n      port: 8080\n      weight: 90\n"))
+	require.Equal(t, 2, strings.Count(string(split), "    - name: web-canary\n      port: 8080\n      weight: 10\n"))
+
+	params["Canary"] = (*canaryRoute)(nil)
+	none, err := p.RenderTemplate("app/httpproxy", params)
+	require.NoError(t, err)
+	require.NotContains(t, string(none), "web-canary")
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/convox/convox/pkg/manifest"
 	"github.com/convox/convox/pkg/metrics"
 	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/secrets"
 	"github.com/convox/convox/pkg/structs"
 	"github.com/convox/convox/pkg/templater"
 	"github.com/convox/convox/provider/aws/provisioner/elasticache"
@@ -98,6 +99,7 @@ type Provider struct {
 	Resolver                            string
 	BuildDisableResolver                bool
 	RestClient                          rest.Interface
+	Secrets                             *secrets.Resolver
 	Router                              string
 	RouterType                          string
 	ProxyProtocol                       bool
@@ -251,6 +253,13 @@ func FromEnv() (*Provider, error) {
 	p.ReleasesToRetainTaskRunIntervalHour, _ = strconv.Atoi(os.Getenv("RELEASES_TO_RETAIN_TASK_RUN_INTERVAL_HOUR"))
 	p.FeatureGates = options.GetFeatureGates()
 
+	sr, err := secrets.FromEnv()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	p.Secrets = sr
+
 	// Invalid signing key degrades to unsigned dispatch rather than crashing.
 	if rawKey := os.Getenv("WEBHOOK_SIGNING_KEY"); rawKey != "" {
 		if err := cxhmac.ValidateSigningKeys(rawKey); err != nil {
@@ -430,6 +439,28 @@ func (p *Provider) Start() error {
 		go p.RunSharedInformer(make(chan struct{}))
 	}
 
+	if err := RunUsingLeaderElection(context.Background(), p.Namespace, resourceBackupLeaseName, p.Cluster, p.runResourceBackups, func() {
+		fmt.Printf("ns=resource_backups at=lost_leadership\n")
+	}); err != nil {
+		_ = log.Errorf("resource backups elector failed to start: %v", err)
+	}
+
+	if err := RunUsingLeaderElection(context.Background(), p.Namespace, appPreviewLeaseName, p.Cluster, p.runAppPreviews, func() {
+		fmt.Printf("ns=app_previews at=lost_leadership\n")
+	}); err != nil {
+		_ = log.Errorf("app previews elector failed to start: %v", err)
+	}
+
+	if err := RunUsingLeaderElection(context.Background(), p.Namespace, secretRefreshLeaseName, p.Cluster, p.runSecretRefresh, func() {
+		fmt.Printf("ns=secret_refresh at=lost_leadership\n")
+	}); err != nil {
+		_ = log.Errorf("secret refresh elector failed to start: %v", err)
+	}
+
+	if err := p.Workers(); err != nil {
+		_ = log.Errorf("workers failed to start: %v", err)
+	}
+
 	if p.costTrackingEnabled() {
 		leaseNs := p.Namespace
 		if err := RunUsingLeaderElection(context.Background(), leaseNs, budgetLeaseName, p.Cluster, p.runBudgetAccumulator, func() {
@@ -0,0 +1,229 @@
+package k8s
+
+import (
+	"bytes"
+	"net"
+	"strings"
+
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+)
+
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// releaseTemplateNetwork renders a NetworkPolicy for every service of m that
+// is isolated by the app wide default deny or by its own network section.
+// Returns nil when no service needs one.
+func (p *Provider) releaseTemplateNetwork(a *structs.App, m *manifest.Manifest) ([]byte, error) {
+	items := [][]byte{}
+
+	for _, s := range m.Services {
+		isolated := s.Network.Isolated(m.Network)
+		restricted := s.Network.Restricted()
+
+		if !isolated && !restricted {
+			continue
+		}
+
+		params := map[string]interface{}{
+			"App":        a,
+			"Isolated":   isolated,
+			"Namespace":  p.AppNamespace(a.Name),
+			"Rack":       p.Name,
+			"Restricted": restricted,
+			"Service":    s,
+		}
+
+		if isolated {
+			rules, err := p.networkIngressRules(m, s)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			params["Ingress"] = rules
+		}
+
+		if restricted {
+			rules, err := p.networkEgressRules(m, s)
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			params["Egress"] = rules
+		}
+
+		data, err := p.RenderTemplate("app/network", params)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		items = append(items, data)
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	return bytes.Join(items, []byte("---\n")), nil
+}
+
+// networkRule is an ingress or egress rule of a NetworkPolicy. No peers
+// matches every source or destination and no ports matches every port.
+type networkRule struct {
+	Peers []networkPeer
+	Ports []networkPort
+}
+
+type networkPeer struct {
+	CIDR      string
+	Except    []string
+	Namespace *networkSelector
+	Pod       *networkSelector
+}
+
+// networkSelector matches every namespace or pod when MatchLabels is empty.
+type networkSelector struct {
+	MatchLabels map[string]string
+}
+
+type networkPort struct {
+	Protocol string
+	Port     int
+}
+
+// networkIngressRules allows traffic from the rack namespace, where the
+// router and api run, from anywhere to the ports of balancers that target
+// the service, and from the peers of its ingress section.
+func (p *Provider) networkIngressRules(m *manifest.Manifest, s manifest.Service) ([]networkRule, error) {
+	rules := []networkRule{
+		{Peers: []networkPeer{namespacePeer(p.Namespace)}},
+	}
+
+	// balancers send traffic from the nodes or from the client addresses
+	// depending on the cloud, so their ports can not be limited by source
+	for _, b := range m.Balancers {
+		if b.Service != s.Name {
+			continue
+		}
+
+		ports := []networkPort{}
+
+		for _, bp := range b.Ports {
+			ports = append(ports, newNetworkPort(bp.Protocol, bp.Target))
+		}
+
+		rules = append(rules, networkRule{Ports: ports})
+	}
+
+	for _, f := range s.Network.Ingress.From {
+		peer, err := p.networkPeer(m, f, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, networkRule{Peers: []networkPeer{*peer}})
+	}
+
+	return rules, nil
+}
+
+// networkEgressRules always allows dns lookups. Without any egress peers
+// the service can reach every pod and every address outside of the deny
+// list.
+func (p *Provider) networkEgressRules(m *manifest.Manifest, s manifest.Service) ([]networkRule, error) {
+	deny, err := s.Network.DenyCIDRs()
+	if err != nil {
+		return nil, structs.ErrBadRequest("service %s network egress %s", s.Name, err)
+	}
+
+	rules := []networkRule{
+		{Ports: []networkPort{newNetworkPort("udp", 53), newNetworkPort("tcp", 53)}},
+	}
+
+	if len(s.Network.Egress.To) == 0 {
+		rules = append(rules, networkRule{
+			Peers: []networkPeer{
+				{Namespace: &networkSelector{}},
+				{CIDR: "0.0.0.0/0", Except: cidrsWithin("0.0.0.0/0", deny)},
+			},
+		})
+
+		return rules, nil
+	}
+
+	for _, t := range s.Network.Egress.To {
+		peer, err := p.networkPeer(m, t, deny)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, networkRule{Peers: []networkPeer{*peer}})
+	}
+
+	return rules, nil
+}
+
+// networkPeer resolves the manifest peer s. Cidr peers exclude the deny
+// ranges that fall inside of them.
+func (p *Provider) networkPeer(m *manifest.Manifest, s string, deny []string) (*networkPeer, error) {
+	np, err := m.NetworkPeer(s)
+	if err != nil {
+		return nil, structs.ErrBadRequest("invalid network peer: %s", err)
+	}
+
+	switch {
+	case np.CIDR != "":
+		return &networkPeer{CIDR: np.CIDR, Except: cidrsWithin(np.CIDR, deny)}, nil
+	case np.App != "":
+		peer := namespacePeer(p.AppNamespace(np.App))
+		peer.Pod = &networkSelector{}
+		if np.Service != "" {
+			peer.Pod.MatchLabels = map[string]string{"service": np.Service}
+		}
+		return &peer, nil
+	case np.Resource != "":
+		return &networkPeer{Pod: &networkSelector{MatchLabels: map[string]string{"resource": np.Resource}}}, nil
+	default:
+		return &networkPeer{Pod: &networkSelector{MatchLabels: map[string]string{"service": np.Service}}}, nil
+	}
+}
+
+func namespacePeer(ns string) networkPeer {
+	return networkPeer{Namespace: &networkSelector{MatchLabels: map[string]string{namespaceNameLabel: ns}}}
+}
+
+func newNetworkPort(protocol string, port int) networkPort {
+	if strings.EqualFold(protocol, "udp") {
+		return networkPort{Protocol: "UDP", Port: port}
+	}
+
+	return networkPort{Protocol: "TCP", Port: port}
+}
+
+// cidrsWithin returns the ranges of cs that are contained in cidr.
+func cidrsWithin(cidr string, cs []string) []string {
+	_, outer, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil
+	}
+
+	on, _ := outer.Mask.Size()
+
+	within := []string{}
+
+	for _, c := range cs {
+		_, inner, err := net.ParseCIDR(c)
+		if err != nil {
+			continue
+		}
+
+		if in, _ := inner.Mask.Size(); in > on && outer.Contains(inner.IP) {
+			within = append(within, inner.String())
+		}
+	}
+
+	if len(within) == 0 {
+		return nil
+	}
+
+	return within
+}
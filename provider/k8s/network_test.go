@@ -0,0 +1,173 @@
+package k8s
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/mock"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/pkg/templater"
+	"github.com/convox/convox/provider/k8s/template"
+	"github.com/stretchr/testify/require"
+	nv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func renderNetworkPolicies(t *testing.T, src string) map[string]nv1.NetworkPolicy {
+	t.Helper()
+
+	m, err := manifest.Load([]byte(src), map[string]string{})
+	require.NoError(t, err)
+
+	p := &Provider{Engine: &mock.TestEngine{}, Name: "rack1", Namespace: "rack1-system"}
+	p.templater = templater.New(template.TemplatesFS)
+
+	data, err := p.releaseTemplateNetwork(&structs.App{Name: "app1"}, m)
+	require.NoError(t, err)
+
+	nps := map[string]nv1.NetworkPolicy{}
+
+	for _, part := range bytes.Split(data, []byte("---\n")) {
+		if len(bytes.TrimSpace(part)) == 0 {
+			continue
+		}
+
+		var np nv1.NetworkPolicy
+		require.NoError(t, yaml.Unmarshal(part, &np))
+		nps[np.Name] = np
+	}
+
+	return nps
+}
+
+func TestReleaseTemplateNetworkNone(t *testing.T) {
+	nps := renderNetworkPolicies(t, `
+services:
+  web:
+    port: 3000
+`)
+
+	require.Empty(t, nps)
+}
+
+func TestReleaseTemplateNetworkIngress(t *testing.T) {
+	nps := renderNetworkPolicies(t, `
+balancers:
+  custom:
+    service: payments
+    ports:
+      8443: 3001
+services:
+  marketing:
+    port: 3000
+  payments:
+    port: 3001
+    network:
+      ingress:
+        from:
+          - api
+          - app:billing/worker
+          - 10.1.0.0/16
+  api:
+    port: 3002
+`)
+
+	require.Len(t, nps, 1)
+
+	np := nps["service-payments"]
+	require.Equal(t, "rack1-app1", np.Namespace)
+	require.Equal(t, map[string]string{"service": "payments"}, np.Spec.PodSelector.MatchLabels)
+	require.Equal(t, []nv1.PolicyType{nv1.PolicyTypeIngress}, np.Spec.PolicyTypes)
+	require.Empty(t, np.Spec.Egress)
+
+	rules := np.Spec.Ingress
+	require.Len(t, rules, 5)
+
+	require.Equal(t, map[string]string{"kubernetes.io/metadata.name": "rack1-system"}, rules[0].From[0].NamespaceSelector.MatchLabels)
+
+	require.Empty(t, rules[1].From)
+	require.Equal(t, 3001, rules[1].Ports[0].Port.IntValue())
+
+	require.Nil(t, rules[2].From[0].NamespaceSelector)
+	require.Equal(t, map[string]string{"service": "api"}, rules[2].From[0].PodSelector.MatchLabels)
+
+	require.Equal(t, map[string]string{"kubernetes.io/metadata.name": "rack1-billing"}, rules[3].From[0].NamespaceSelector.MatchLabels)
+	require.Equal(t, map[string]string{"service": "worker"}, rules[3].From[0].PodSelector.MatchLabels)
+
+	require.Equal(t, "10.1.0.0/16", rules[4].From[0].IPBlock.CIDR)
+}
+
+func TestReleaseTemplateNetworkDefaultDeny(t *testing.T) {
+	nps := renderNetworkPolicies(t, `
+network:
+  defaultDeny: true
+services:
+  web:
+    port: 3000
+  worker:
+    network:
+      ingress:
+        from:
+          - web
+`)
+
+	require.Len(t, nps, 2)
+
+	web := nps["service-web"]
+	require.Equal(t, []nv1.PolicyType{nv1.PolicyTypeIngress}, web.Spec.PolicyTypes)
+	require.Len(t, web.Spec.Ingress, 1)
+	require.Equal(t, map[string]string{"kubernetes.io/metadata.name": "rack1-system"}, web.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels)
+
+	worker := nps["service-worker"]
+	require.Len(t, worker.Spec.Ingress, 2)
+	require.Equal(t, map[string]string{"service": "web"}, worker.Spec.Ingress[1].From[0].PodSelector.MatchLabels)
+}
+
+func TestReleaseTemplateNetworkEgress(t *testing.T) {
+	nps := renderNetworkPolicies(t, `
+resources:
+  database:
+    type: postgres
+services:
+  web:
+    port: 3000
+    network:
+      egress:
+        deny:
+          - metadata-endpoint
+  worker:
+    resources:
+      - database
+    network:
+      egress:
+        to:
+          - database
+          - 0.0.0.0/0
+        deny:
+          - metadata-endpoint
+          - 10.0.0.0/8
+`)
+
+	require.Len(t, nps, 2)
+
+	web := nps["service-web"]
+	require.Equal(t, []nv1.PolicyType{nv1.PolicyTypeEgress}, web.Spec.PolicyTypes)
+	require.Empty(t, web.Spec.Ingress)
+	require.Len(t, web.Spec.Egress, 2)
+	require.Len(t, web.Spec.Egress[0].Ports, 2)
+	require.Equal(t, 53, web.Spec.Egress[0].Ports[0].Port.IntValue())
+	require.NotNil(t, web.Spec.Egress[1].To[0].NamespaceSelector)
+	require.Equal(t, "0.0.0.0/0", web.Spec.Egress[1].To[1].IPBlock.CIDR)
+	require.Equal(t, []string{"169.254.169.254/32"}, web.Spec.Egress[1].To[1].IPBlock.Except)
+
+	worker := nps["service-worker"]
+	require.Len(t, worker.Spec.Egress, 3)
+	require.Equal(t, map[string]string{"resource": "database"}, worker.Spec.Egress[1].To[0].PodSelector.MatchLabels)
+	require.Equal(t, []string{"169.254.169.254/32", "10.0.0.0/8"}, worker.Spec.Egress[2].To[0].IPBlock.Except)
+}
+
+func TestCidrsWithin(t *testing.T) {
+	require.Equal(t, []string{"10.1.0.0/16"}, cidrsWithin("10.0.0.0/8", []string{"10.1.0.0/16", "192.168.0.0/16", "10.0.0.0/8"}))
+	require.Nil(t, cidrsWithin("10.0.0.0/8", []string{"169.254.169.254/32"}))
+}
@@ -7,7 +7,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/convox/convox/pkg/structs"
 	"github.com/pkg/errors"
@@ -49,8 +51,30 @@ func (p *Provider) ObjectFetch(app, key string) (io.ReadCloser, error) {
 	return fd, nil
 }
 
+// ObjectList returns the keys directly under prefix, like a bucket listing
+// with a / delimiter.
 func (p *Provider) ObjectList(app, prefix string) ([]string, error) {
-	return nil, errors.WithStack(structs.ErrNotImplemented("unimplemented"))
+	dir, base := path.Split(prefix)
+
+	fis, err := os.ReadDir(p.objectFilename(app, dir))
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	keys := []string{}
+
+	for _, fi := range fis {
+		if fi.IsDir() || !strings.HasPrefix(fi.Name(), base) {
+			continue
+		}
+
+		keys = append(keys, dir+fi.Name())
+	}
+
+	return keys, nil
 }
 
 func (p *Provider) ObjectStore(app, key string, r io.Reader, opts structs.ObjectStoreOptions) (*structs.Object, error) {
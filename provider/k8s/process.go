@@ -192,7 +192,7 @@ func (p *Provider) ProcessGet(app, pid string) (*structs.Process, error) {
 func (p *Provider) ProcessList(app string, opts structs.ProcessListOptions) (structs.Processes, error) {
 	filters := []string{
 		"system=convox",
-		"type in (process,service,timer)",
+		"type in (job,process,service,timer)",
 	}
 
 	if opts.Release != nil {
@@ -3,6 +3,7 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -371,3 +372,38 @@ func (pc *PrometheusClient) QueryGPURange(ctx context.Context, app string, servi
 
 	return out, nil
 }
+
+// QueryVector runs one of the templated queries from prometheus_queries.go,
+// substituting its $namespace and $service placeholders. Both are kubernetes
+// object names so they need no escaping inside the label matchers.
+func (pc *PrometheusClient) QueryVector(ctx context.Context, query, namespace, service string) (model.Vector, error) {
+	if pc == nil {
+		return nil, nil
+	}
+	if !promCircuitBreaker.Allowed() {
+		return nil, ErrPromCircuitOpen
+	}
+
+	expr := strings.NewReplacer(
+		"$namespace", namespace,
+		"$service", service,
+	).Replace(query)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	val, _, err := pc.api.Query(ctx, expr, time.Now())
+	if err != nil {
+		promCircuitBreaker.RecordFailure()
+		return nil, err
+	}
+
+	promCircuitBreaker.RecordSuccess()
+
+	vec, ok := val.(model.Vector)
+	if !ok {
+		return model.Vector{}, nil
+	}
+
+	return vec, nil
+}
@@ -47,6 +47,7 @@ const (
 	HttpLatencyP50ByService    = `histogram_quantile(0.5, sum by (le) (rate(http_request_duration_seconds_bucket{namespace=~"$namespace"}[5m])))`
 	HttpLatencyP95ByService    = `histogram_quantile(0.95, sum by (le) (rate(http_request_duration_seconds_bucket{namespace=~"$namespace"}[5m])))`
 	HttpLatencyP99ByService    = `histogram_quantile(0.99, sum by (le) (rate(http_request_duration_seconds_bucket{namespace=~"$namespace"}[5m])))`
+	HttpLatencyP95ForService   = `histogram_quantile(0.95, sum by (le) (rate(http_request_duration_seconds_bucket{namespace=~"$namespace",service=~"$service"}[5m])))`
 	HttpPendingByService       = `sum(http_requests_pending{namespace=~"$namespace",service=~"$service"}) or vector(0)`
 	K8sReplicaCountByService   = `sum(kube_pod_status_phase{namespace=~"$namespace",phase="Running",pod=~"$service-.*"})`
 	K8sRestartsByService       = `sum(increase(kube_pod_container_status_restarts_total{namespace=~"$namespace"}[1h]))`
@@ -100,6 +101,7 @@ func AllPromQLConstants() map[string]string {
 		"HttpLatencyP50ByService":       HttpLatencyP50ByService,
 		"HttpLatencyP95ByService":       HttpLatencyP95ByService,
 		"HttpLatencyP99ByService":       HttpLatencyP99ByService,
+		"HttpLatencyP95ForService":      HttpLatencyP95ForService,
 		"HttpPendingByService":          HttpPendingByService,
 		"K8sReplicaCountByService":      K8sReplicaCountByService,
 		"K8sRestartsByService":          K8sRestartsByService,
@@ -135,6 +137,7 @@ func httpRedSourceConsts() []string {
 		"HttpLatencyP50ByService",
 		"HttpLatencyP95ByService",
 		"HttpLatencyP99ByService",
+		"HttpLatencyP95ForService",
 		"HttpPendingByService",
 	}
 }
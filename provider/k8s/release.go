@@ -72,6 +72,14 @@ func (p *Provider) ReleaseCreate(app string, opts structs.ReleaseCreateOptions)
 		r.Description = *opts.Description
 	}
 
+	if actor := p.ContextActor(); actor != "unknown" {
+		r.Creator = actor
+	}
+
+	if err := p.releaseSecretCheck(app, r.Env); err != nil {
+		return nil, err
+	}
+
 	ro, err := p.releaseCreate(r)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -117,18 +125,144 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 		return errors.WithStack(err)
 	}
 
+	if err := p.releasePromoteGate(app, id, opts); err != nil {
+		return err
+	}
+
+	switch common.DefaultString(opts.Strategy, structs.ReleasePromoteStrategyRolling) {
+	case structs.ReleasePromoteStrategyRolling:
+	case structs.ReleasePromoteStrategyBlueGreen, structs.ReleasePromoteStrategyCanary:
+		return p.releasePromoteProgressive(app, id, opts)
+	default:
+		return structs.ErrBadRequest("unknown promote strategy: %s", *opts.Strategy)
+	}
+
 	a, err := p.AppGet(app)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 
+	p.cancelReleasePromotion(app)
+
+	items, dependencies, err := p.releaseTemplate(a, id, opts, nil)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		jobs, err := p.releaseJobDependencies(app, id)
+		if err != nil {
+			return err
+		}
+
+		dependencies = append(dependencies, jobs...)
+	}
+
+	tdata := bytes.Join(items, []byte("---\n"))
+
+	timeout := int32(common.DefaultInt(opts.Timeout, 3000))
+
+	if id != "" {
+		p.releaseTimelineStart(app, id, fmt.Sprintf("rolling promote from %s by %s", common.CoalesceString(a.Release, "none"), common.CoalesceString(p.ContextActor(), "unknown")))
+	}
+
+	if err := p.Apply(p.AppNamespace(app), "app", PromoteApplyConfig{
+		Version:      id,
+		Data:         tdata,
+		Labels:       fmt.Sprintf("system=convox,provider=k8s,rack=%s,app=%s,release=%s", p.Name, app, id),
+		Timeout:      timeout,
+		Dependencies: dependencies,
+	}); err != nil {
+		if id != "" {
+			p.releaseTimelineEvent(app, id, structs.ReleaseTimelineEvent{Source: structs.TimelineSourcePromote, Reason: "Error", Message: err.Error()})
+		}
+		return errors.WithStack(err)
+	}
+
+	// Capture context BEFORE the goroutine launch — the watcher outlives
+	// the request-scoped p.ctx, so it must NOT reference p.ContextActor()
+	// at later points. Mirrors build.go:600-608.
+	capturedActor := p.ContextActor()
+
+	// Read the release-id mirror that p.Apply just wrote, so the watcher
+	// can detect supersession by a NEWER promote. p.Atom.Status() returns
+	// (status, release-id, err); we use the release-id as the watcher's
+	// supersession discriminator and compare to `convox.com/app-release`
+	// (mirrored by the AtomController) on each tick. Fallback to the
+	// inbound `id` parameter if Atom.Status() fails — same value Apply
+	// just wrote, so the version-mismatch check still works correctly.
+	_, atomVer, _ := p.Atom.Status(p.AppNamespace(app), "app")
+	if atomVer == "" {
+		atomVer = id
+	}
+
+	// Persist watch state BEFORE emitting :start. If a fast-fail occurs
+	// between annotation-write and goroutine-launch, the cold-start GC
+	// scan at next api-pod startup recovers it (timeout path).
+	// Annotation-write failures are logged but do NOT block promote
+	// success — the rollout itself proceeded; the user just doesn't
+	// get a second event.
+	state := structs.ReleasePromoteWatchState{
+		SchemaVersion: 1,
+		ReleaseID:     id,
+		AtomVersion:   atomVer,
+		StartedAt:     time.Now().UTC(),
+		ExpiresAt:     time.Now().UTC().Add(time.Duration(timeout) * time.Second),
+		Actor:         capturedActor,
+	}
+	if err := p.writeReleasePromoteWatchAnnotation(p.ctx, app, &state); err != nil {
+		fmt.Printf("ns=release_watcher at=warn kind=annotation_write app=%s err=%q\n", app, err)
+	}
+
+	// :start emit uses the captured actor (audit-trail consistency with
+	// the future app:promote:completed / app:promote:errored /
+	// app:promote:cancelled events the watcher will emit). The action
+	// name `release:promote` is preserved verbatim — existing prior art
+	// that webhook consumers / audit-log scrapers depend on. New event
+	// types use the canonical app:<resource>:<verb> form.
+	_ = p.EventSend("release:promote", structs.EventSendOptions{
+		Data:   map[string]string{"app": app, "id": id, "actor": capturedActor},
+		Status: options.String("start"),
+	})
+
+	p.FlushStateLog(app)
+
+	// jobs without a hook run alongside the rollout
+	p.startReleaseJobs(p.ctx, app, id, "")
+
+	// Per-(app, release-id) lock — sync.Map.LoadOrStore is the atomic
+	// check-and-set primitive. If a watcher is already in-flight for
+	// this exact pair, the second promote skips the goroutine launch
+	// (the existing watcher continues; it will see the supersession
+	// via the release annotation mismatch on its next tick).
+	if acquired, release := tryAcquireWatchSlot(app, id); acquired {
+		s := state // own a heap copy so the goroutine doesn't alias
+		// the watcher drives batched rollouts, it outlives the request
+		go p.runReleasePromoteWatcher(context.WithoutCancel(p.ctx), app, &s, release)
+	}
+
+	return nil
+}
+
+// releaseTemplate renders every object that makes up release id of app a. A
+// non-nil canary also renders the canary workloads of a progressive promote
+// and splits the traffic of the routed services between the two.
+func (p *Provider) releaseTemplate(a *structs.App, id string, opts structs.ReleasePromoteOptions, canary *releaseCanary) ([][]byte, []string, error) {
+	return p.releaseRender(a, id, opts, canary, true)
+}
+
+// releaseRender renders the objects of a release. With prepare false it only
+// renders and skips the steps that create or provision things the template
+// does not hold, such as registry secrets, app configs and aws resources,
+// and sends none of the events that report what a promote decided.
+func (p *Provider) releaseRender(a *structs.App, id string, opts structs.ReleasePromoteOptions, canary *releaseCanary, prepare bool) ([][]byte, []string, error) {
 	items := [][]byte{}
 	dependencies := []string{}
 
 	// app
 	data, err := p.releaseTemplateApp(a, opts)
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, nil, errors.WithStack(err)
 	}
 
 	items = append(items, data)
@@ -137,16 +271,16 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 	if ca, err := p.Cluster.CoreV1().Secrets("convox-system").Get(context.TODO(), "ca", am.GetOptions{}); err == nil {
 		data, err := p.releaseTemplateCA(a, ca)
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, nil, errors.WithStack(err)
 		}
 
 		items = append(items, data)
 	}
 
 	if id != "" {
-		m, r, err := common.ReleaseManifest(p, app, id)
+		m, r, err := common.ReleaseManifest(p, a.Name, id)
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, nil, errors.WithStack(err)
 		}
 
 		// Reject manifest-tier budget enforcement when the rack-level
@@ -170,18 +304,30 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 		// provider/k8s/budget_shutdown.go, so they take effect without
 		// persistence.
 		if err := p.requireCostTrackingForManifestBudget(m); err != nil {
-			return errors.WithStack(err)
+			return nil, nil, errors.WithStack(err)
 		}
 
 		e, err := structs.NewEnvironment([]byte(r.Env))
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, nil, errors.WithStack(err)
+		}
+
+		// dependencies on other apps, env set on the release takes precedence
+		denv, err := p.releaseDependencyEnvironment(m)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for k, v := range denv {
+			if _, ok := e[k]; !ok {
+				e[k] = v
+			}
 		}
 
 		// docker hub auth secret (once per promote, before resource/service/timer loops)
-		if p.hasDockerHubAuth() {
-			if err := p.ensureDockerHubSecret(p.AppNamespace(app)); err != nil {
-				return errors.WithStack(err)
+		if prepare && p.hasDockerHubAuth() {
+			if err := p.ensureDockerHubSecret(p.AppNamespace(a.Name)); err != nil {
+				return nil, nil, errors.WithStack(err)
 			}
 		}
 
@@ -189,7 +335,7 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 		for _, b := range m.Balancers {
 			data, err := p.releaseTemplateBalancer(a, r, b, m.Labels)
 			if err != nil {
-				return errors.WithStack(err)
+				return nil, nil, errors.WithStack(err)
 			}
 
 			items = append(items, data)
@@ -197,9 +343,9 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 
 		// ingress
 		if rss := m.Services.Routable().External(); len(rss) > 0 {
-			data, err := p.releaseTemplateIngress(a, rss, opts)
+			data, err := p.releaseTemplateIngress(a, rss, opts, canary)
 			if err != nil {
-				return errors.WithStack(err)
+				return nil, nil, errors.WithStack(err)
 			}
 
 			items = append(items, data)
@@ -209,11 +355,11 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 		rssInternal := m.Services.Routable().InternalRouter()
 		if len(rssInternal) > 0 {
 			if p.DomainInternal == "" {
-				return structs.ErrBadRequest("please enable the rack's internal router first: convox rack params set internal_router=true")
+				return nil, nil, structs.ErrBadRequest("please enable the rack's internal router first: convox rack params set internal_router=true")
 			}
-			data, err := p.releaseTemplateIngressInternal(a, rssInternal, opts)
+			data, err := p.releaseTemplateIngressInternal(a, rssInternal, opts, canary)
 			if err != nil {
-				return errors.WithStack(err)
+				return nil, nil, errors.WithStack(err)
 			}
 
 			items = append(items, data)
@@ -224,7 +370,7 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 			if !r.IsCustomManagedResource() {
 				data, err := p.releaseTemplateResource(a, e, r)
 				if err != nil {
-					return errors.WithStack(err)
+					return nil, nil, errors.WithStack(err)
 				}
 
 				items = append(items, data)
@@ -232,23 +378,43 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 		}
 
 		// services
-		data, err := p.releaseTemplateServices(a, e, r, m.Services, opts)
+		data, err := p.releaseTemplateServices(a, e, r, m.Services, opts, prepare)
 		if err != nil {
-			return errors.WithStack(err)
+			return nil, nil, errors.WithStack(err)
 		}
 
 		items = append(items, data)
 
+		// network policies
+		ndata, err := p.releaseTemplateNetwork(a, m)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+		if ndata != nil {
+			items = append(items, ndata)
+		}
+
+		// canaries
+		if canary != nil {
+			data, err := p.releaseTemplateCanaries(a, canary)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			items = append(items, data)
+		}
+
 		// timers
 		for _, t := range m.Timers {
 			s, err := m.Service(t.Service)
 			if err != nil {
-				return errors.WithStack(err)
+				return nil, nil, errors.WithStack(err)
 			}
 
 			data, err := p.releaseTemplateTimer(a, e, r, s, t)
 			if err != nil {
-				return errors.WithStack(err)
+				return nil, nil, errors.WithStack(err)
 			}
 
 			items = append(items, data)
@@ -256,10 +422,14 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 
 		items = append(items, data)
 
+		if !prepare {
+			return items, dependencies, nil
+		}
+
 		// rds resources
 		rdsItems, rdsDeps, err := p.releaseRdsResources(a, e, m)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		items = append(items, rdsItems...)
@@ -270,7 +440,7 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 		// elasticache resources
 		elasticacheItems, elastiDeps, err := p.releaseElasticacheResources(a, e, m)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		items = append(items, elasticacheItems...)
@@ -279,83 +449,11 @@ func (p *Provider) ReleasePromote(app, id string, opts structs.ReleasePromoteOpt
 		}
 
 		if err := p.releaseAppConfigs(a, m); err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
 
-	tdata := bytes.Join(items, []byte("---\n"))
-
-	timeout := int32(common.DefaultInt(opts.Timeout, 3000))
-
-	if err := p.Apply(p.AppNamespace(app), "app", PromoteApplyConfig{
-		Version:      id,
-		Data:         tdata,
-		Labels:       fmt.Sprintf("system=convox,provider=k8s,rack=%s,app=%s,release=%s", p.Name, app, id),
-		Timeout:      timeout,
-		Dependencies: dependencies,
-	}); err != nil {
-		return errors.WithStack(err)
-	}
-
-	// Capture context BEFORE the goroutine launch — the watcher outlives
-	// the request-scoped p.ctx, so it must NOT reference p.ContextActor()
-	// at later points. Mirrors build.go:600-608.
-	capturedActor := p.ContextActor()
-
-	// Read the release-id mirror that p.Apply just wrote, so the watcher
-	// can detect supersession by a NEWER promote. p.Atom.Status() returns
-	// (status, release-id, err); we use the release-id as the watcher's
-	// supersession discriminator and compare to `convox.com/app-release`
-	// (mirrored by the AtomController) on each tick. Fallback to the
-	// inbound `id` parameter if Atom.Status() fails — same value Apply
-	// just wrote, so the version-mismatch check still works correctly.
-	_, atomVer, _ := p.Atom.Status(p.AppNamespace(app), "app")
-	if atomVer == "" {
-		atomVer = id
-	}
-
-	// Persist watch state BEFORE emitting :start. If a fast-fail occurs
-	// between annotation-write and goroutine-launch, the cold-start GC
-	// scan at next api-pod startup recovers it (timeout path).
-	// Annotation-write failures are logged but do NOT block promote
-	// success — the rollout itself proceeded; the user just doesn't
-	// get a second event.
-	state := structs.ReleasePromoteWatchState{
-		SchemaVersion: 1,
-		ReleaseID:     id,
-		AtomVersion:   atomVer,
-		StartedAt:     time.Now().UTC(),
-		ExpiresAt:     time.Now().UTC().Add(time.Duration(timeout) * time.Second),
-		Actor:         capturedActor,
-	}
-	if err := p.writeReleasePromoteWatchAnnotation(p.ctx, app, &state); err != nil {
-		fmt.Printf("ns=release_watcher at=warn kind=annotation_write app=%s err=%q\n", app, err)
-	}
-
-	// :start emit uses the captured actor (audit-trail consistency with
-	// the future app:promote:completed / app:promote:errored /
-	// app:promote:cancelled events the watcher will emit). The action
-	// name `release:promote` is preserved verbatim — existing prior art
-	// that webhook consumers / audit-log scrapers depend on. New event
-	// types use the canonical app:<resource>:<verb> form.
-	_ = p.EventSend("release:promote", structs.EventSendOptions{
-		Data:   map[string]string{"app": app, "id": id, "actor": capturedActor},
-		Status: options.String("start"),
-	})
-
-	p.FlushStateLog(app)
-
-	// Per-(app, release-id) lock — sync.Map.LoadOrStore is the atomic
-	// check-and-set primitive. If a watcher is already in-flight for
-	// this exact pair, the second promote skips the goroutine launch
-	// (the existing watcher continues; it will see the supersession
-	// via the release annotation mismatch on its next tick).
-	if acquired, release := tryAcquireWatchSlot(app, id); acquired {
-		s := state // own a heap copy so the goroutine doesn't alias
-		go p.runReleasePromoteWatcher(p.ctx, app, &s, release)
-	}
-
-	return nil
+	return items, dependencies, nil
 }
 
 func (p *Provider) releaseCreate(r *structs.Release) (*structs.Release, error) {
@@ -425,7 +523,7 @@ func (p *Provider) releaseList(app string, limit int) (structs.Releases, error)
 }
 
 func (p *Provider) releaseMarshal(r *structs.Release) *ca.Release {
-	return &ca.Release{
+	kr := &ca.Release{
 		ObjectMeta: am.ObjectMeta{
 			Namespace: p.AppNamespace(r.App),
 			Name:      strings.ToLower(r.Id),
@@ -443,6 +541,12 @@ func (p *Provider) releaseMarshal(r *structs.Release) *ca.Release {
 			Manifest:    r.Manifest,
 		},
 	}
+
+	if r.Creator != "" {
+		kr.Annotations = map[string]string{structs.ReleaseCreatorAnnotation: r.Creator}
+	}
+
+	return kr
 }
 
 func (p *Provider) releaseTemplateApp(a *structs.App, opts structs.ReleasePromoteOptions) ([]byte, error) {
@@ -501,9 +605,9 @@ func (p *Provider) releaseTemplateCA(a *structs.App, ca *v1.Secret) ([]byte, err
 	return data, nil
 }
 
-func (p *Provider) releaseTemplateIngress(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions) ([]byte, error) {
+func (p *Provider) releaseTemplateIngress(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions, canary *releaseCanary) ([]byte, error) {
 	if p.RouterType == "contour" {
-		return p.releaseTemplateHTTPProxy(a, ss, opts)
+		return p.releaseTemplateHTTPProxy(a, ss, opts, canary)
 	}
 
 	idles, err := p.Engine.AppIdles(a.Name)
@@ -570,9 +674,9 @@ func (p *Provider) releaseTemplateIngress(a *structs.App, ss manifest.Services,
 	return bytes.Join(items, []byte("---\n")), nil
 }
 
-func (p *Provider) releaseTemplateIngressInternal(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions) ([]byte, error) {
+func (p *Provider) releaseTemplateIngressInternal(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions, canary *releaseCanary) ([]byte, error) {
 	if p.RouterType == "contour" {
-		return p.releaseTemplateHTTPProxyInternal(a, ss, opts)
+		return p.releaseTemplateHTTPProxyInternal(a, ss, opts, canary)
 	}
 
 	idles, err := p.Engine.AppIdles(a.Name)
@@ -640,20 +744,20 @@ func (p *Provider) releaseTemplateIngressInternal(a *structs.App, ss manifest.Se
 	return bytes.Join(items, []byte("---\n")), nil
 }
 
-func (p *Provider) releaseTemplateHTTPProxy(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions) ([]byte, error) {
-	return p.releaseTemplateHTTPProxyCore(a, ss, opts, p.ProxyProtocol, p.Engine.IngressClass(), false)
+func (p *Provider) releaseTemplateHTTPProxy(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions, canary *releaseCanary) ([]byte, error) {
+	return p.releaseTemplateHTTPProxyCore(a, ss, opts, canary, p.ProxyProtocol, p.Engine.IngressClass(), false)
 }
 
-func (p *Provider) releaseTemplateHTTPProxyInternal(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions) ([]byte, error) {
+func (p *Provider) releaseTemplateHTTPProxyInternal(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions, canary *releaseCanary) ([]byte, error) {
 	for i := range ss {
 		if len(ss[i].Domains) > 0 {
 			log.Printf("WARNING: service %q has custom domains on the internal router; HTTP01 certificate challenges require the domain to be publicly reachable", ss[i].Name)
 		}
 	}
-	return p.releaseTemplateHTTPProxyCore(a, ss, opts, false, p.Engine.IngressInternalClass(), true)
+	return p.releaseTemplateHTTPProxyCore(a, ss, opts, canary, false, p.Engine.IngressInternalClass(), true)
 }
 
-func (p *Provider) releaseTemplateHTTPProxyCore(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions, proxyProtocol bool, ingressClassName string, internal bool) ([]byte, error) {
+func (p *Provider) releaseTemplateHTTPProxyCore(a *structs.App, ss manifest.Services, opts structs.ReleasePromoteOptions, canary *releaseCanary, proxyProtocol bool, ingressClassName string, internal bool) ([]byte, error) {
 	idles, err := p.Engine.AppIdles(a.Name)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -735,6 +839,7 @@ func (p *Provider) releaseTemplateHTTPProxyCore(a *structs.App, ss manifest.Serv
 			"Annotations":      translation.Annotations,
 			"App":              a.Name,
 			"BackendProtocol":  contourBackendProtocol(s.Port.Scheme),
+			"Canary":           canary.route(s.Name),
 			"ConvoxDomainTLS":  convoxDomainTLS,
 			"Host":             p.ServiceHost(a.Name, s),
 			"Idles":            common.DefaultBool(opts.Idle, idles),
@@ -880,7 +985,9 @@ func (p *Provider) releaseTemplateResourceMasked(a *structs.App, r manifest.Reso
 	return data, nil
 }
 
-func (p *Provider) releaseTemplateServices(a *structs.App, e structs.Environment, r *structs.Release, ss manifest.Services, opts structs.ReleasePromoteOptions) ([]byte, error) {
+// releaseTemplateServices renders the services of release r. With prepare
+// false it leaves the cluster and the event stream alone.
+func (p *Provider) releaseTemplateServices(a *structs.App, e structs.Environment, r *structs.Release, ss manifest.Services, opts structs.ReleasePromoteOptions, prepare bool) ([]byte, error) {
 	items := [][]byte{}
 
 	pss, err := p.ServiceList(a.Name)
@@ -978,16 +1085,18 @@ func (p *Provider) releaseTemplateServices(a *structs.App, e structs.Environment
 			// release:autoscale-disabled and release:manifest-advisory
 			// system-emit convention. Cardinality bounded by service
 			// count which is operator-controlled.
-			_ = p.EventSend("app:scale-override:honored", structs.EventSendOptions{
-				Data: map[string]string{
-					"actor":           "system",
-					"app":             a.Name,
-					"service":         s.Name,
-					"release":         r.Id,
-					"preserved_count": strconv.Itoa(sc[s.Name]),
-					"yaml_count_min":  strconv.Itoa(s.Scale.Count.Min),
-				},
-			})
+			if prepare {
+				_ = p.EventSend("app:scale-override:honored", structs.EventSendOptions{
+					Data: map[string]string{
+						"actor":           "system",
+						"app":             a.Name,
+						"service":         s.Name,
+						"release":         r.Id,
+						"preserved_count": strconv.Itoa(sc[s.Name]),
+						"yaml_count_min":  strconv.Itoa(s.Scale.Count.Min),
+					},
+				})
+			}
 		} else {
 			// Existing default — runtime count wins when non-zero,
 			// else yaml min.
@@ -1008,7 +1117,9 @@ func (p *Provider) releaseTemplateServices(a *structs.App, e structs.Environment
 		// suppressed.
 		if triggersOverride[s.Name] {
 			wantsAutoscale = false
-			p.stripAtomLabelFromOverrideCRD(a.Name, s.Name, triggersOverrideCRD[s.Name])
+			if prepare {
+				p.stripAtomLabelFromOverrideCRD(a.Name, s.Name, triggersOverrideCRD[s.Name])
+			}
 		}
 		// Agent services render as DaemonSets; KEDA ScaledObject only targets
 		// Deployments, so skip the autoscale path entirely. The
@@ -1020,36 +1131,42 @@ func (p *Provider) releaseTemplateServices(a *structs.App, e structs.Environment
 		// identity is in scope at the release path.
 		if s.Agent.Enabled && wantsAutoscale {
 			wantsAutoscale = false
-			_ = p.EventSend("release:agent-autoscale-ignored", structs.EventSendOptions{
-				Data: map[string]string{
-					"actor":   "system",
-					"app":     a.Name,
-					"service": s.Name,
-					"release": r.Id,
-				},
-			})
+			if prepare {
+				_ = p.EventSend("release:agent-autoscale-ignored", structs.EventSendOptions{
+					Data: map[string]string{
+						"actor":   "system",
+						"app":     a.Name,
+						"service": s.Name,
+						"release": r.Id,
+					},
+				})
+			}
 		}
 		if !p.IsKedaEnabled && wantsAutoscale {
-			_ = p.EventSend("release:autoscale-disabled", structs.EventSendOptions{
-				Data: map[string]string{
-					"actor":   "system",
-					"app":     a.Name,
-					"service": s.Name,
-					"reason":  "rack has keda_enable=false; autoscale ignored, using Count.Min static replicas",
-				},
-			})
+			if prepare {
+				_ = p.EventSend("release:autoscale-disabled", structs.EventSendOptions{
+					Data: map[string]string{
+						"actor":   "system",
+						"app":     a.Name,
+						"service": s.Name,
+						"reason":  "rack has keda_enable=false; autoscale ignored, using Count.Min static replicas",
+					},
+				})
+			}
 			wantsAutoscale = false
 		}
 
 		if !s.Agent.Enabled && s.Scale.Min != nil && *s.Scale.Min == 0 && !s.Scale.Autoscale.IsEnabled() && !s.Scale.IsKedaEnabled() {
-			_ = p.EventSend("release:manifest-advisory", structs.EventSendOptions{
-				Data: map[string]string{
-					"actor":   "system",
-					"app":     a.Name,
-					"service": s.Name,
-					"reason":  "scale.min=0 without autoscale fields will keep the service at zero replicas permanently; set scale.autoscale.cpu.threshold or equivalent to enable scale-up",
-				},
-			})
+			if prepare {
+				_ = p.EventSend("release:manifest-advisory", structs.EventSendOptions{
+					Data: map[string]string{
+						"actor":   "system",
+						"app":     a.Name,
+						"service": s.Name,
+						"reason":  "scale.min=0 without autoscale fields will keep the service at zero replicas permanently; set scale.autoscale.cpu.threshold or equivalent to enable scale-up",
+					},
+				})
+			}
 		}
 
 		ipsBlocks, ipsNames, err := renderImagePullSecrets(a.Name, p.AppNamespace(a.Name), &s, func(k string) (string, bool) {
@@ -1065,8 +1182,6 @@ func (p *Provider) releaseTemplateServices(a *structs.App, e structs.Environment
 			"Annotations":            s.AnnotationsMap(),
 			"App":                    a,
 			"Environment":            env,
-			"MaxSurge":               max - 100,
-			"MaxUnavailable":         100 - min,
 			"Namespace":              p.AppNamespace(a.Name),
 			"Password":               p.Password,
 			"Rack":                   p.Name,
@@ -1081,6 +1196,10 @@ func (p *Provider) releaseTemplateServices(a *structs.App, e structs.Environment
 			"ImagePullSecretNames":   ipsNames,
 		}
 
+		for k, v := range rolloutParams(s, replicas, min, max, opts.Min != nil || opts.Max != nil) {
+			params[k] = v
+		}
+
 		if ip, err := p.Engine.ResolverHost(); err == nil {
 			params["Resolver"] = ip
 		}
@@ -1108,15 +1227,17 @@ func (p *Provider) releaseTemplateServices(a *structs.App, e structs.Environment
 				// continues unconditionally for this service — the skip is scoped
 				// to the ScaledObject only, not the entire iteration.
 				skippedStatus := "skipped"
-				_ = p.EventSend("release:prometheus-skipped", structs.EventSendOptions{
-					Data: map[string]string{
-						"actor":   "system",
-						"app":     a.Name,
-						"service": s.Name,
-						"reason":  "PROMETHEUS_URL not set; skipping KEDA prometheus trigger creation",
-					},
-					Status: &skippedStatus,
-				})
+				if prepare {
+					_ = p.EventSend("release:prometheus-skipped", structs.EventSendOptions{
+						Data: map[string]string{
+							"actor":   "system",
+							"app":     a.Name,
+							"service": s.Name,
+							"reason":  "PROMETHEUS_URL not set; skipping KEDA prometheus trigger creation",
+						},
+						Status: &skippedStatus,
+					})
+				}
 			} else {
 				var triggers []kedav1alpha1.ScaleTriggers
 				if s.Scale.Autoscale.IsEnabled() {
@@ -1239,6 +1360,36 @@ func (p *Provider) releaseTemplateTimer(a *structs.App, e structs.Environment, r
 	return data, nil
 }
 
+func (p *Provider) releaseTemplateJob(a *structs.App, r *structs.Release, s *manifest.Service, j manifest.Job) ([]byte, error) {
+	params := map[string]interface{}{
+		"Annotations":          j.AnnotationsMap(),
+		"App":                  a,
+		"Job":                  j,
+		"Name":                 releaseJobName(j.Name, r.Id),
+		"Namespace":            p.AppNamespace(a.Name),
+		"Rack":                 p.Name,
+		"Release":              r,
+		"Service":              s,
+		"DockerHubAuth":        p.hasDockerHubAuth(),
+		"ImagePullSecretNames": imagePullSecretNames(a.Name, s.Name, s.ImagePullSecrets),
+	}
+
+	if ip, err := p.Engine.ResolverHost(); err == nil {
+		params["Resolver"] = ip
+	}
+
+	if options.GetFeatureGates()[options.FeatureGateExternalDnsResolver] {
+		params["DisableDnsSearches"] = true
+	}
+
+	data, err := p.RenderTemplate("app/job", params)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return data, nil
+}
+
 func (p *Provider) releaseTemplateEfs(a *structs.App, s manifest.Service) ([]byte, error) {
 	for i := range s.VolumeOptions {
 		if s.VolumeOptions[i].AwsEfs != nil && len(p.EfsFileSystemId) <= 2 {
@@ -1316,6 +1467,12 @@ func (p *Provider) releaseUnmarshal(kr *ca.Release) (*structs.Release, error) {
 		Manifest:    kr.Spec.Manifest,
 	}
 
+	if as := releaseApprovalsFromAnnotations(kr.Annotations); len(as) > 0 {
+		r.Approvals = as
+	}
+
+	r.Creator = kr.Annotations[structs.ReleaseCreatorAnnotation]
+
 	if len(r.Env) == 0 {
 		if s, err := p.Cluster.CoreV1().Secrets(p.AppNamespace(r.App)).Get(
 			context.TODO(), fmt.Sprintf("release-%s", kr.ObjectMeta.Name), am.GetOptions{},
@@ -1660,6 +1817,10 @@ var resourceDefaultImages = map[string]struct {
 	"mariadb":   {image: "mariadb", defaultVersion: "10.6.0", isLibrary: true},
 	"memcached": {image: "memcached", defaultVersion: "1.4.34", isLibrary: true},
 	"postgis":   {image: "postgis/postgis", defaultVersion: "10-3.2", isLibrary: false},
+	"rabbitmq":  {image: "rabbitmq", defaultVersion: "3.13", isLibrary: true},
+	"mongodb":   {image: "mongo", defaultVersion: "7.0", isLibrary: true},
+	"kafka":     {image: "apache/kafka", defaultVersion: "3.8.0", isLibrary: false},
+	"minio":     {image: "minio/minio", defaultVersion: "RELEASE.2024-10-13T13-34-11Z", isLibrary: false},
 }
 
 // ecrCachedResourceImage returns the ECR pull-through cache URL for a resource's Docker Hub image.
@@ -2,6 +2,7 @@ package k8s_test
 
 import (
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/convox/convox/pkg/atom"
@@ -105,3 +106,28 @@ func TestRelease_AgentAutoscaleIgnored_FiresEvent(t *testing.T) {
 	assert.Equal(t, "collector", data["service"], "Data.service must name the agent service")
 	assert.Equal(t, "release1", data["release"], "Data.release must be the release id")
 }
+
+// TestRelease_AgentAutoscaleIgnored_DryRenderSilent — the render behind
+// AppDrift must not report promote decisions to the event stream.
+func TestRelease_AgentAutoscaleIgnored_DryRenderSilent(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		captured []map[string]any
+	)
+	srv := webhookCaptureServer(&mu, &captured)
+	defer srv.Close()
+
+	setup := setupAgentAutoscaleTest(t)
+
+	testProvider(t, func(p *k8s.Provider) {
+		k8s.SetWebhooksForTest(p, []string{srv.URL})
+		app, rel, ss := setup(p)
+		_, err := k8s.ReleaseTemplateServicesDryForTest(p, app, structs.Environment{}, rel, ss)
+		require.NoError(t, err)
+		drainPendingDispatches()
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Empty(t, findAllByAction(captured, "release:agent-autoscale-ignored"), "a dry render must not send release events")
+}
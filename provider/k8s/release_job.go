@@ -0,0 +1,491 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	ac "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kyaml "sigs.k8s.io/yaml"
+)
+
+var releaseJobPollInterval = 5 * time.Second
+
+var errReleaseJobSuperseded = errors.New("superseded-by-newer-promote")
+
+func releaseJobName(job, release string) string {
+	return fmt.Sprintf("job-%s-%s", job, strings.ToLower(release))
+}
+
+// jobDependencyId is the atom dependency that holds a promote in Pending
+// until a before-promote job of release has completed. It carries the
+// release because a progressive promote applies the atom at the version
+// of the release it is shifting traffic away from.
+func jobDependencyId(app, release, job string) string {
+	return fmt.Sprintf("##|app:%s|type:job|resource:%s|release:%s|##", app, job, release)
+}
+
+func parseJobDependencyId(id string) (string, string, string) {
+	app, _, job := parseResourceSubstitutionId(id)
+
+	release := ""
+
+	for _, p := range strings.Split(id, "|") {
+		if strings.HasPrefix(p, "release:") {
+			release = strings.TrimPrefix(p, "release:")
+		}
+	}
+
+	return app, release, job
+}
+
+// releaseJobDependencies returns the atom dependencies for the
+// before-promote jobs of release.
+func (p *Provider) releaseJobDependencies(app, release string) ([]string, error) {
+	m, _, err := common.ReleaseManifest(p, app, release)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	deps := []string{}
+
+	for _, j := range m.Jobs.Hooked(manifest.JobHookBeforePromote) {
+		deps = append(deps, jobDependencyId(app, release, j.Name))
+	}
+
+	return deps, nil
+}
+
+// releaseJobCompletedAnnotation is the app namespace annotation that records
+// the release a job last completed for. It outlives the job itself, which is
+// removed after ttlSecondsAfterFinished.
+func releaseJobCompletedAnnotation(job string) string {
+	return fmt.Sprintf("convox.com/job-completed-%s", job)
+}
+
+// startReleaseJobs runs the jobs of release attached to hook in the
+// background. They run on a context of their own so that the request or
+// watcher that started them can not cut them short.
+func (p *Provider) startReleaseJobs(ctx context.Context, app, release, hook string) {
+	if release == "" {
+		return
+	}
+
+	go p.runReleaseJobs(context.WithoutCancel(ctx), app, release, hook)
+}
+
+// runReleaseJobs runs the jobs of release attached to hook one at a time,
+// stopping at the first one that fails. Jobs without a hook do not depend on
+// each other and run side by side.
+func (p *Provider) runReleaseJobs(ctx context.Context, app, release, hook string) {
+	m, _, err := common.ReleaseManifest(p, app, release)
+	if err != nil {
+		fmt.Printf("ns=release_job at=manifest app=%s release=%s error=%q\n", app, release, err)
+		return
+	}
+
+	for _, j := range m.Jobs.Hooked(hook) {
+		if hook == "" {
+			go p.runReleaseJob(ctx, app, release, j.Name, 0, nil)
+			continue
+		}
+
+		if err := p.runReleaseJob(ctx, app, release, j.Name, 0, nil); err != nil {
+			return
+		}
+	}
+}
+
+// runReleaseJob starts job of release unless it has already completed and
+// waits for it to finish. A job that failed on an earlier attempt is
+// replaced. A zero timeout waits for as long as the job runs.
+func (p *Provider) runReleaseJob(ctx context.Context, app, release, job string, timeout time.Duration, superseded func() bool) error {
+	if p.releaseJobCompleted(ctx, app, release, job) {
+		return nil
+	}
+
+	err := p.startReleaseJob(ctx, app, release, job)
+	if err == nil {
+		err = p.waitReleaseJob(ctx, app, releaseJobName(job, release), timeout, superseded)
+	}
+
+	switch {
+	case err == nil:
+		if err := p.markReleaseJobCompleted(ctx, app, release, job); err != nil {
+			fmt.Printf("ns=release_job at=warn kind=annotation_write app=%s job=%s err=%q\n", app, job, err)
+		}
+		p.releaseJobLog(app, "job %s of %s completed", job, release)
+		p.releaseJobEvent(app, release, job, "success", "")
+	case err == errReleaseJobSuperseded, ctx.Err() != nil:
+		p.releaseJobLog(app, "job %s of %s stopped: %s", job, release, err)
+	default:
+		p.releaseJobLog(app, "job %s of %s failed: %s", job, release, err)
+		p.releaseJobEvent(app, release, job, "error", err.Error())
+	}
+
+	return err
+}
+
+// releaseJobCompleted is true once job has completed for release, even after
+// the job itself has been cleaned up.
+func (p *Provider) releaseJobCompleted(ctx context.Context, app, release, job string) bool {
+	ns, err := p.Cluster.CoreV1().Namespaces().Get(ctx, p.AppNamespace(app), am.GetOptions{})
+	if err != nil {
+		return false
+	}
+
+	return ns.Annotations[releaseJobCompletedAnnotation(job)] == release
+}
+
+func (p *Provider) markReleaseJobCompleted(ctx context.Context, app, release, job string) error {
+	patch, err := patchBytes(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				releaseJobCompletedAnnotation(job): release,
+			},
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = p.Cluster.CoreV1().Namespaces().Patch(ctx, p.AppNamespace(app), types.MergePatchType, patch, am.PatchOptions{})
+	return errors.WithStack(err)
+}
+
+func (p *Provider) startReleaseJob(ctx context.Context, app, release, job string) error {
+	ns := p.AppNamespace(app)
+	name := releaseJobName(job, release)
+
+	kj, err := p.Cluster.BatchV1().Jobs(ns).Get(ctx, name, am.GetOptions{})
+	switch {
+	case kerr.IsNotFound(err):
+	case err != nil:
+		return errors.WithStack(err)
+	default:
+		if done, jerr := releaseJobFinished(kj); !done || jerr == nil {
+			return nil
+		}
+
+		bg := am.DeletePropagationBackground
+
+		if err := p.Cluster.BatchV1().Jobs(ns).Delete(ctx, name, am.DeleteOptions{PropagationPolicy: &bg}); err != nil && !kerr.IsNotFound(err) {
+			return errors.WithStack(err)
+		}
+	}
+
+	a, err := p.AppGet(app)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	m, r, err := common.ReleaseManifest(p, app, release)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var mj *manifest.Job
+
+	for i := range m.Jobs {
+		if m.Jobs[i].Name == job {
+			mj = &m.Jobs[i]
+		}
+	}
+
+	if mj == nil {
+		return errors.WithStack(fmt.Errorf("no such job: %s", job))
+	}
+
+	s, err := m.Service(mj.Service)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	env, err := p.releaseJobEnv(ctx, a, r, m, s, job)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	data, err := p.releaseTemplateJob(a, r, s, *mj)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	data, err = ApplyLabels(data, fmt.Sprintf("system=convox,provider=k8s,rack=%s,app=%s,release=%s", p.Name, app, release))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, part := range bytes.Split(data, []byte("---\n")) {
+		var kind struct {
+			Kind string `json:"kind"`
+		}
+
+		if err := kyaml.Unmarshal(part, &kind); err != nil {
+			return errors.WithStack(err)
+		}
+
+		switch kind.Kind {
+		case "ServiceAccount":
+			var sa ac.ServiceAccount
+
+			if err := kyaml.Unmarshal(part, &sa); err != nil {
+				return errors.WithStack(err)
+			}
+
+			if _, err := p.Cluster.CoreV1().ServiceAccounts(ns).Create(ctx, &sa, am.CreateOptions{}); err != nil && !kerr.IsAlreadyExists(err) {
+				return errors.WithStack(err)
+			}
+		case "Job":
+			var j batchv1.Job
+
+			if err := kyaml.Unmarshal(part, &j); err != nil {
+				return errors.WithStack(err)
+			}
+
+			kj, err := p.Cluster.BatchV1().Jobs(ns).Create(ctx, &j, am.CreateOptions{})
+			if err != nil {
+				return errors.WithStack(err)
+			}
+
+			if err := p.releaseJobEnvOwn(ctx, env, kj); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+
+	p.releaseJobLog(app, "job %s of %s started", job, release)
+
+	return nil
+}
+
+// releaseJobEnvName is the secret that holds the environment of job for
+// release. A job can not use the env secret of its service, which is only
+// written once the release is applied and so still holds the previous
+// release, or nothing at all, while a before-promote job runs.
+func releaseJobEnvName(job, release string) string {
+	return fmt.Sprintf("env-%s", releaseJobName(job, release))
+}
+
+// releaseJobEnv writes the environment and resource urls of service s as of
+// release r to the env secret of job.
+func (p *Provider) releaseJobEnv(ctx context.Context, a *structs.App, r *structs.Release, m *manifest.Manifest, s *manifest.Service, job string) (*ac.Secret, error) {
+	e := structs.Environment{}
+
+	if err := e.Load([]byte(r.Env)); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	env, err := p.environment(a, r, *s, e)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, sr := range s.ResourceMap() {
+		data, err := p.releaseJobResource(ctx, a, e, m, sr.Name)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		env[sr.Env] = data[sr.GetConfigMapKey()]
+	}
+
+	sec := &ac.Secret{
+		ObjectMeta: am.ObjectMeta{
+			Namespace: p.AppNamespace(a.Name),
+			Name:      releaseJobEnvName(job, r.Id),
+			Labels: map[string]string{
+				"system":   "convox",
+				"provider": "k8s",
+				"rack":     p.Name,
+				"app":      a.Name,
+				"release":  r.Id,
+				"job":      job,
+				"type":     "env",
+			},
+		},
+		Type: ac.SecretTypeOpaque,
+		Data: map[string][]byte{},
+	}
+
+	for k, v := range env {
+		sec.Data[k] = []byte(v)
+	}
+
+	ss := p.Cluster.CoreV1().Secrets(sec.Namespace)
+
+	_, err = ss.Create(ctx, sec, am.CreateOptions{})
+	switch {
+	case kerr.IsAlreadyExists(err):
+	case err != nil:
+		return nil, errors.WithStack(err)
+	default:
+		return sec, nil
+	}
+
+	cur, err := ss.Get(ctx, sec.Name, am.GetOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cur.Data = sec.Data
+
+	if _, err := ss.Update(ctx, cur, am.UpdateOptions{}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return cur, nil
+}
+
+// releaseJobEnvOwn hands the env secret of a job to the job so that it is
+// removed along with it.
+func (p *Provider) releaseJobEnvOwn(ctx context.Context, sec *ac.Secret, j *batchv1.Job) error {
+	patch, err := patchBytes(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"ownerReferences": []am.OwnerReference{
+				*am.NewControllerRef(j, batchv1.SchemeGroupVersion.WithKind("Job")),
+			},
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = p.Cluster.CoreV1().Secrets(sec.Namespace).Patch(ctx, sec.Name, types.MergePatchType, patch, am.PatchOptions{})
+	return errors.WithStack(err)
+}
+
+// releaseJobResource returns the connection details of resource name as of
+// the environment e of a release. Resources the rack runs itself are
+// rendered, as they may not have been applied yet. Those it provisions
+// elsewhere are read from their config map once they are available.
+func (p *Provider) releaseJobResource(ctx context.Context, a *structs.App, e structs.Environment, m *manifest.Manifest, name string) (map[string]string, error) {
+	r, err := m.Resource(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if !r.IsCustomManagedResource() {
+		data, err := p.releaseTemplateResource(a, e, *r)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, part := range bytes.Split(data, []byte("---\n")) {
+			var cm ac.ConfigMap
+
+			if err := kyaml.Unmarshal(part, &cm); err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			if cm.Kind == "ConfigMap" {
+				return cm.Data, nil
+			}
+		}
+	}
+
+	cm, err := p.Cluster.CoreV1().ConfigMaps(p.AppNamespace(a.Name)).Get(ctx, fmt.Sprintf("resource-%s", nameFilter(name)), am.GetOptions{})
+	if kerr.IsNotFound(err) {
+		return nil, fmt.Errorf("resource %s is not available yet", name)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return cm.Data, nil
+}
+
+func (p *Provider) waitReleaseJob(ctx context.Context, app, name string, timeout time.Duration, superseded func() bool) error {
+	ns := p.AppNamespace(app)
+
+	tick := time.NewTicker(releaseJobPollInterval)
+	defer tick.Stop()
+
+	var deadline <-chan time.Time
+
+	if timeout > 0 {
+		t := time.NewTimer(timeout)
+		defer t.Stop()
+		deadline = t.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			p.stopReleaseJob(app, name)
+			return fmt.Errorf("did not finish within %s", timeout)
+		case <-tick.C:
+			if superseded != nil && superseded() {
+				p.stopReleaseJob(app, name)
+				return errReleaseJobSuperseded
+			}
+
+			kj, err := p.Cluster.BatchV1().Jobs(ns).Get(ctx, name, am.GetOptions{})
+			if kerr.IsNotFound(err) {
+				return fmt.Errorf("job was deleted")
+			}
+			if err != nil {
+				continue
+			}
+
+			if done, err := releaseJobFinished(kj); done {
+				return err
+			}
+		}
+	}
+}
+
+func (p *Provider) stopReleaseJob(app, name string) {
+	bg := am.DeletePropagationBackground
+
+	if err := p.Cluster.BatchV1().Jobs(p.AppNamespace(app)).Delete(context.Background(), name, am.DeleteOptions{PropagationPolicy: &bg}); err != nil && !kerr.IsNotFound(err) {
+		fmt.Printf("ns=release_job at=stop app=%s job=%s error=%q\n", app, name, err)
+	}
+}
+
+func releaseJobFinished(j *batchv1.Job) (bool, error) {
+	for _, c := range j.Status.Conditions {
+		if c.Status != ac.ConditionTrue {
+			continue
+		}
+
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, nil
+		case batchv1.JobFailed:
+			return true, fmt.Errorf("%s", common.CoalesceString(c.Message, c.Reason, "failed"))
+		}
+	}
+
+	return false, nil
+}
+
+func (p *Provider) releaseJobEvent(app, release, job, status, errMsg string) {
+	opts := structs.EventSendOptions{
+		Data:   map[string]string{"app": app, "id": release, "job": job},
+		Status: options.String(status),
+	}
+
+	if errMsg != "" {
+		opts.Error = options.String(errMsg)
+	}
+
+	_ = p.EventSend("app:job:completed", opts)
+}
+
+func (p *Provider) releaseJobLog(app, format string, args ...interface{}) {
+	_ = p.systemLog("", app, "job", time.Now(), fmt.Sprintf(format, args...))
+}
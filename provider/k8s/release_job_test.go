@@ -0,0 +1,255 @@
+package k8s_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/atom"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/provider/k8s"
+	ca "github.com/convox/convox/provider/k8s/pkg/apis/convox/v1"
+	cvfake "github.com/convox/convox/provider/k8s/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	ac "k8s.io/api/core/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func jobsManifestYaml() string {
+	return `services:
+  web:
+    image: docker.io/library/nginx
+    port: 5000
+jobs:
+  migrate:
+    command: bin/migrate --all
+    hook: before-promote
+    service: web
+  backfill:
+    backoffLimit: 2
+    command: bin/backfill
+    completions: 3
+    hook: after-promote
+    parallelism: 2
+    service: web
+  warm:
+    command: bin/warm
+    service: web
+`
+}
+
+func setupReleaseJobTest(t *testing.T, p *k8s.Provider) (*fake.Clientset, *atom.MockInterface) {
+	t.Helper()
+
+	prev := *k8s.ReleaseJobPollIntervalForTest
+	*k8s.ReleaseJobPollIntervalForTest = 10 * time.Millisecond
+	t.Cleanup(func() { *k8s.ReleaseJobPollIntervalForTest = prev })
+
+	kk := p.Cluster.(*fake.Clientset)
+	require.NoError(t, appCreate(kk, "rack1", "app1"))
+
+	cc := p.Convox.(*cvfake.Clientset)
+	require.NoError(t, buildCreate(cc, "rack1-app1", "build1", "basic"))
+	require.NoError(t, releaseCreateInline(cc, "rack1-app1", "release1", jobsManifestYaml()))
+
+	aa := p.Atom.(*atom.MockInterface)
+	aa.On("Status", "rack1-app1", "app").Return("Running", "release1", nil).Maybe()
+
+	return kk, aa
+}
+
+// finishJob waits for the named job to be created and marks it with a
+// terminal condition.
+func finishJob(t *testing.T, kk *fake.Clientset, name string, condition batchv1.JobConditionType, message string) *batchv1.Job {
+	t.Helper()
+
+	var j *batchv1.Job
+
+	require.Eventually(t, func() bool {
+		var err error
+		j, err = kk.BatchV1().Jobs("rack1-app1").Get(context.TODO(), name, am.GetOptions{})
+		return err == nil
+	}, 2*time.Second, 5*time.Millisecond)
+
+	u := j.DeepCopy()
+	u.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: ac.ConditionTrue, Message: message}}
+
+	_, err := kk.BatchV1().Jobs("rack1-app1").UpdateStatus(context.TODO(), u, am.UpdateOptions{})
+	require.NoError(t, err)
+
+	return j
+}
+
+func TestReleaseJobRun(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		kk, _ := setupReleaseJobTest(t, p)
+
+		done := make(chan error, 1)
+		go func() { done <- k8s.RunReleaseJobForTest(p, "app1", "release1", "backfill", time.Minute) }()
+
+		j := finishJob(t, kk, "job-backfill-release1", batchv1.JobComplete, "")
+		require.NoError(t, <-done)
+
+		require.Equal(t, int32(2), *j.Spec.BackoffLimit)
+		require.Equal(t, int32(3), *j.Spec.Completions)
+		require.Equal(t, int32(2), *j.Spec.Parallelism)
+		require.Equal(t, int32(86400), *j.Spec.TTLSecondsAfterFinished)
+		require.Equal(t, "after-promote", j.Annotations["convox.com/job-hook"])
+		require.Equal(t, "app1", j.Labels["app"])
+		require.Equal(t, "release1", j.Labels["release"])
+
+		pt := j.Spec.Template
+		require.Equal(t, "job", pt.Labels["type"])
+		require.Equal(t, "backfill", pt.Labels["name"])
+		require.Equal(t, "web", pt.Labels["service"])
+		require.Equal(t, ac.RestartPolicyNever, pt.Spec.RestartPolicy)
+		require.Equal(t, "job-backfill", pt.Spec.ServiceAccountName)
+		require.Equal(t, []string{"bin/backfill"}, pt.Spec.Containers[0].Args)
+
+		_, err := kk.CoreV1().ServiceAccounts("rack1-app1").Get(context.TODO(), "job-backfill", am.GetOptions{})
+		require.NoError(t, err)
+
+		// a completed job is not run again, even after it has been cleaned up
+		require.NoError(t, kk.BatchV1().Jobs("rack1-app1").Delete(context.TODO(), "job-backfill-release1", am.DeleteOptions{}))
+		require.NoError(t, k8s.RunReleaseJobForTest(p, "app1", "release1", "backfill", time.Minute))
+
+		_, err = kk.BatchV1().Jobs("rack1-app1").Get(context.TODO(), "job-backfill-release1", am.GetOptions{})
+		require.Error(t, err)
+	})
+}
+
+func TestReleaseJobRunFailed(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		kk, _ := setupReleaseJobTest(t, p)
+
+		done := make(chan error, 1)
+		go func() { done <- k8s.RunReleaseJobForTest(p, "app1", "release1", "migrate", time.Minute) }()
+
+		first := finishJob(t, kk, "job-migrate-release1", batchv1.JobFailed, "BackoffLimitExceeded")
+		require.EqualError(t, <-done, "BackoffLimitExceeded")
+
+		// a failed job is replaced on the next attempt
+		go func() { done <- k8s.RunReleaseJobForTest(p, "app1", "release1", "migrate", time.Minute) }()
+
+		require.Eventually(t, func() bool {
+			j, err := kk.BatchV1().Jobs("rack1-app1").Get(context.TODO(), "job-migrate-release1", am.GetOptions{})
+			return err == nil && len(j.Status.Conditions) == 0 && j.UID == first.UID
+		}, 2*time.Second, 5*time.Millisecond)
+
+		finishJob(t, kk, "job-migrate-release1", batchv1.JobComplete, "")
+		require.NoError(t, <-done)
+	})
+}
+
+func TestReleaseJobRunTimeout(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		kk, _ := setupReleaseJobTest(t, p)
+
+		err := k8s.RunReleaseJobForTest(p, "app1", "release1", "migrate", 50*time.Millisecond)
+		require.EqualError(t, err, "did not finish within 50ms")
+
+		_, err = kk.BatchV1().Jobs("rack1-app1").Get(context.TODO(), "job-migrate-release1", am.GetOptions{})
+		require.Error(t, err)
+	})
+}
+
+func TestReleasePromoteJobs(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		kk, aa := setupReleaseJobTest(t, p)
+
+		aa.On("Apply", "rack1-app1", "app", mock.Anything).Return(nil).Once().Run(func(args mock.Arguments) {
+			cfg := args.Get(2).(*atom.ApplyConfig)
+
+			require.Equal(t, []string{"##|app:app1|type:job|resource:migrate|release:release1|##"}, cfg.Dependencies)
+
+			tmpl := string(cfg.Template)
+			require.NotContains(t, tmpl, "job-warm-release1")
+			require.NotContains(t, tmpl, "job-migrate-release1")
+			require.NotContains(t, tmpl, "job-backfill-release1")
+		})
+
+		require.NoError(t, p.ReleasePromote("app1", "release1", structs.ReleasePromoteOptions{}))
+
+		// jobs without a hook are started next to the rollout
+		j := finishJob(t, kk, "job-warm-release1", batchv1.JobComplete, "")
+		require.Equal(t, []string{"bin/warm"}, j.Spec.Template.Spec.Containers[0].Args)
+	})
+}
+
+func TestReleasePromoteJobsFirstRelease(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		kk := p.Cluster.(*fake.Clientset)
+		require.NoError(t, appCreate(kk, "rack1", "app1"))
+
+		cc := p.Convox.(*cvfake.Clientset)
+		require.NoError(t, buildCreate(cc, "rack1-app1", "build1", "basic"))
+
+		_, err := cc.ConvoxV1().Releases("rack1-app1").Create(&ca.Release{
+			ObjectMeta: am.ObjectMeta{Name: "release1"},
+			Spec: ca.ReleaseSpec{
+				Build:   "build1",
+				Created: "20200101.000000.000000000",
+				Env:     "FOO=bar",
+				Manifest: `resources:
+  database:
+    type: postgres
+services:
+  web:
+    image: docker.io/library/nginx
+    port: 5000
+    resources:
+      - database
+jobs:
+  migrate:
+    command: bin/migrate
+    hook: before-promote
+    service: web
+`,
+			},
+		})
+		require.NoError(t, err)
+
+		prev := *k8s.ReleaseJobPollIntervalForTest
+		*k8s.ReleaseJobPollIntervalForTest = 10 * time.Millisecond
+		t.Cleanup(func() { *k8s.ReleaseJobPollIntervalForTest = prev })
+
+		aa := p.Atom.(*atom.MockInterface)
+		aa.On("Status", "rack1-app1", "app").Return("Running", "release1", nil).Maybe()
+		aa.On("Apply", "rack1-app1", "app", mock.Anything).Return(nil).Once().Run(func(args mock.Arguments) {
+			cfg := args.Get(2).(*atom.ApplyConfig)
+			require.Equal(t, []string{"##|app:app1|type:job|resource:migrate|release:release1|##"}, cfg.Dependencies)
+		})
+
+		require.NoError(t, p.ReleasePromote("app1", "release1", structs.ReleasePromoteOptions{}))
+
+		// the atom controller runs the job while the apply is held
+		done := make(chan error, 1)
+		go func() { done <- k8s.RunReleaseJobForTest(p, "app1", "release1", "migrate", time.Minute) }()
+
+		j := finishJob(t, kk, "job-migrate-release1", batchv1.JobComplete, "")
+		require.NoError(t, <-done)
+
+		c := j.Spec.Template.Spec.Containers[0]
+		require.Len(t, c.EnvFrom, 1)
+		require.Equal(t, "env-job-migrate-release1", c.EnvFrom[0].SecretRef.Name)
+
+		for _, e := range c.Env {
+			require.Nil(t, e.ValueFrom.ConfigMapKeyRef, e.Name)
+		}
+
+		// the service env secret is only written once the release is applied
+		_, err = kk.CoreV1().Secrets("rack1-app1").Get(context.TODO(), "env-web", am.GetOptions{})
+		require.Error(t, err)
+
+		sec, err := kk.CoreV1().Secrets("rack1-app1").Get(context.TODO(), "env-job-migrate-release1", am.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "bar", string(sec.Data["FOO"]))
+		require.Contains(t, string(sec.Data["DATABASE_URL"]), "postgres://app:")
+		require.Contains(t, string(sec.Data["DATABASE_URL"]), "@resource-database.rack1-app1.")
+		require.Len(t, sec.OwnerReferences, 1)
+		require.Equal(t, "job-migrate-release1", sec.OwnerReferences[0].Name)
+	})
+}
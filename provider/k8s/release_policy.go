@@ -0,0 +1,246 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// deployPolicy is the combined deploy policy an app's promotes must satisfy.
+type deployPolicy struct {
+	Approvals int
+	Freeze    []manifest.FreezeWindow
+}
+
+// ReleaseApprove records the calling user's approval of release id. Each
+// user other than the one that created the release can approve it once, so
+// approvals need a token that carries a user identity.
+func (p *Provider) ReleaseApprove(app, id string, opts structs.ReleaseApproveOptions) (*structs.Release, error) {
+	actor := p.ContextActor()
+	if actor == "" || actor == "unknown" {
+		return nil, structs.ErrBadRequest("approving a release requires a token that identifies the user")
+	}
+
+	rc := p.Convox.ConvoxV1().Releases(p.AppNamespace(app))
+
+	kr, err := rc.Get(strings.ToLower(id), am.GetOptions{})
+	if kerr.IsNotFound(err) {
+		return nil, structs.ErrNotFound("release not found: %s", id)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if kr.Annotations[structs.ReleaseCreatorAnnotation] == actor {
+		return nil, structs.ErrConflict("%s created release %s and can not approve it", actor, id)
+	}
+
+	approvals := releaseApprovalsFromAnnotations(kr.Annotations)
+
+	for _, a := range approvals {
+		if a.Actor == actor {
+			return nil, structs.ErrConflict("%s has already approved release %s", actor, id)
+		}
+	}
+
+	approvals = append(approvals, structs.ReleaseApproval{
+		Actor:   actor,
+		Comment: common.DefaultString(opts.Comment, ""),
+		Created: time.Now().UTC(),
+	})
+
+	raw, err := json.Marshal(approvals)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if kr.Annotations == nil {
+		kr.Annotations = map[string]string{}
+	}
+
+	kr.Annotations[structs.ReleaseApprovalsAnnotation] = string(raw)
+
+	kr, err = rc.Update(kr)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	r, err := p.releaseUnmarshal(kr)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	data := map[string]string{"app": app, "id": r.Id, "actor": actor, "approvals": strconv.Itoa(len(approvals))}
+
+	if opts.Comment != nil {
+		data["comment"] = *opts.Comment
+	}
+
+	_ = p.EventSend("release:approve", structs.EventSendOptions{Data: data})
+
+	return r, nil
+}
+
+// releasePromoteGate refuses to promote release id while it is missing
+// approvals or a deploy freeze window is open. Promotes of the active
+// release, which apply parameter and scale changes, and promotes of preview
+// apps, which copy a base app's release, are never gated.
+func (p *Provider) releasePromoteGate(app, id string, opts structs.ReleasePromoteOptions) error {
+	if id == "" {
+		return nil
+	}
+
+	a, err := p.AppGet(app)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if strings.EqualFold(a.Release, id) || a.Preview != nil {
+		return nil
+	}
+
+	dp, err := p.deployPolicy(a, id)
+	if err != nil {
+		return err
+	}
+
+	if dp.Approvals > 0 {
+		kr, err := p.Convox.ConvoxV1().Releases(p.AppNamespace(app)).Get(strings.ToLower(id), am.GetOptions{})
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if n := len(releaseApprovalsFromAnnotations(kr.Annotations)); n < dp.Approvals {
+			return structs.ErrConflict("release %s has %d of %d required approvals", id, n, dp.Approvals)
+		}
+	}
+
+	now := time.Now()
+
+	for _, w := range dp.Freeze {
+		if !w.Contains(now) {
+			continue
+		}
+
+		if opts.OverrideFreeze == nil {
+			return structs.ErrConflict("promotes are frozen by %q until %s, an admin can promote anyway with --override-freeze", w.String(), w.Until(now).UTC().Format(time.RFC3339))
+		}
+
+		reason := strings.TrimSpace(*opts.OverrideFreeze)
+		if reason == "" {
+			return structs.ErrBadRequest("overriding a deploy freeze requires a justification")
+		}
+
+		_ = p.EventSend("release:freeze:override", structs.EventSendOptions{
+			Data:   map[string]string{"app": app, "id": id, "actor": p.ContextActor(), "reason": reason, "window": w.String()},
+			Status: options.String("override"),
+		})
+
+		break
+	}
+
+	return nil
+}
+
+// deployPolicy combines the deploy parameters of app a with the deploy
+// sections of its active release and of release id. The strictest setting
+// wins so that a new release cannot relax the policy it is promoted under.
+func (p *Provider) deployPolicy(a *structs.App, id string) (*deployPolicy, error) {
+	dp := &deployPolicy{}
+
+	if v := a.Parameters[structs.AppParamDeployApprovals]; v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, structs.ErrBadRequest("invalid %s: %s", structs.AppParamDeployApprovals, v)
+		}
+		dp.Approvals = n
+	}
+
+	if v := a.Parameters[structs.AppParamDeployFreeze]; v != "" {
+		ws, err := manifest.ParseFreezeWindows(deployFreezeParam(v))
+		if err != nil {
+			return nil, structs.ErrBadRequest("invalid %s: %s", structs.AppParamDeployFreeze, err)
+		}
+		dp.Freeze = append(dp.Freeze, ws...)
+	}
+
+	for _, rid := range []string{a.Release, id} {
+		if rid == "" {
+			continue
+		}
+
+		m, _, err := common.ReleaseManifest(p, a.Name, rid)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if m.Deploy.Approvals > dp.Approvals {
+			dp.Approvals = m.Deploy.Approvals
+		}
+
+		ws, err := manifest.ParseFreezeWindows(m.Deploy.Freeze)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		dp.Freeze = append(dp.Freeze, ws...)
+	}
+
+	return dp, nil
+}
+
+// validateDeployParameter checks the value of the deploy policy app
+// parameter k.
+func validateDeployParameter(k, v string) error {
+	if v == "" {
+		return nil
+	}
+
+	switch k {
+	case structs.AppParamDeployApprovals:
+		if n, err := strconv.Atoi(v); err != nil || n < 0 {
+			return structs.ErrBadRequest("%s must be a number of approvals", k)
+		}
+	case structs.AppParamDeployFreeze:
+		if _, err := manifest.ParseFreezeWindows(deployFreezeParam(v)); err != nil {
+			return structs.ErrBadRequest("invalid %s: %s", k, err)
+		}
+	}
+
+	return nil
+}
+
+func deployFreezeParam(v string) []string {
+	ws := []string{}
+
+	for _, w := range strings.Split(v, ";") {
+		if w = strings.TrimSpace(w); w != "" {
+			ws = append(ws, w)
+		}
+	}
+
+	return ws
+}
+
+func releaseApprovalsFromAnnotations(annotations map[string]string) []structs.ReleaseApproval {
+	approvals := []structs.ReleaseApproval{}
+
+	if data, ok := annotations[structs.ReleaseApprovalsAnnotation]; ok && data != "" {
+		if err := json.Unmarshal([]byte(data), &approvals); err != nil {
+			fmt.Printf("ns=release_policy at=approvals error=%q\n", err)
+			return []structs.ReleaseApproval{}
+		}
+	}
+
+	return approvals
+}
@@ -0,0 +1,226 @@
+package k8s_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/atom"
+	"github.com/convox/convox/pkg/mock"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/provider/k8s"
+	cvfake "github.com/convox/convox/provider/k8s/pkg/client/clientset/versioned/fake"
+	tmock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// deployEngine accepts the deploy policy app parameters, which the test
+// engine does not know about.
+type deployEngine struct {
+	*mock.TestEngine
+}
+
+func (deployEngine) AppParameters() map[string]string {
+	return map[string]string{
+		structs.AppParamDeployApprovals: "",
+		structs.AppParamDeployFreeze:    "",
+	}
+}
+
+func deployManifestYaml(deploy string) string {
+	return fmt.Sprintf(`services:
+  web:
+    image: docker.io/library/nginx
+    port: 5000
+deploy:
+%s
+`, deploy)
+}
+
+func setupReleasePolicyTest(t *testing.T, p *k8s.Provider, params, deploy string) *atom.MockInterface {
+	t.Helper()
+
+	p.Engine = deployEngine{&mock.TestEngine{}}
+
+	kk := p.Cluster.(*fake.Clientset)
+	require.NoError(t, appCreateWithAnnotation(kk, "rack1", "app1", map[string]string{
+		"convox.com/app-release": "release1",
+		"convox.com/app-status":  "running",
+		"convox.com/params":      params,
+	}))
+
+	cc := p.Convox.(*cvfake.Clientset)
+	require.NoError(t, buildCreate(cc, "rack1-app1", "build1", "basic"))
+	require.NoError(t, releaseCreateInline(cc, "rack1-app1", "release1", deployManifestYaml("  approvals: 0")))
+	require.NoError(t, releaseCreateInline(cc, "rack1-app1", "release2", deployManifestYaml(deploy)))
+
+	aa := p.Atom.(*atom.MockInterface)
+	aa.On("Status", "rack1-app1", "app").Return("Running", "release1", nil).Maybe()
+
+	return aa
+}
+
+func withActor(p *k8s.Provider, actor string) *k8s.Provider {
+	pp, _ := p.WithContext(context.WithValue(context.Background(), structs.ConvoxJwtUserCtxKey, actor)).(*k8s.Provider)
+	return pp
+}
+
+// openFreezeWindow returns a freeze window that is open now and stays open
+// for the next hour.
+func openFreezeWindow() string {
+	now := time.Now().UTC()
+	start, end := now.Add(-time.Hour), now.Add(time.Hour)
+
+	return fmt.Sprintf("%s %s-%s %s", start.Weekday().String()[0:3], start.Format("15:04"), end.Weekday().String()[0:3], end.Format("15:04"))
+}
+
+func TestReleaseApprove(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		setupReleasePolicyTest(t, p, "{}", "  approvals: 2")
+
+		_, err := p.ReleaseApprove("app1", "release2", structs.ReleaseApproveOptions{})
+		require.EqualError(t, err, "approving a release requires a token that identifies the user")
+
+		r, err := withActor(p, "alice@example.com").ReleaseApprove("app1", "release2", structs.ReleaseApproveOptions{Comment: options.String("lgtm")})
+		require.NoError(t, err)
+		require.Len(t, r.Approvals, 1)
+		require.Equal(t, "alice@example.com", r.Approvals[0].Actor)
+		require.Equal(t, "lgtm", r.Approvals[0].Comment)
+
+		_, err = withActor(p, "alice@example.com").ReleaseApprove("app1", "release2", structs.ReleaseApproveOptions{})
+		require.EqualError(t, err, "alice@example.com has already approved release release2")
+
+		r, err = withActor(p, "bob@example.com").ReleaseApprove("app1", "RELEASE2", structs.ReleaseApproveOptions{})
+		require.NoError(t, err)
+		require.Len(t, r.Approvals, 2)
+
+		_, err = withActor(p, "bob@example.com").ReleaseApprove("app1", "release9", structs.ReleaseApproveOptions{})
+		require.EqualError(t, err, "release not found: release9")
+	})
+}
+
+func TestReleasePromoteApprovals(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		aa := setupReleasePolicyTest(t, p, "{}", "  approvals: 2")
+
+		err := p.ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{})
+		require.EqualError(t, err, "release release2 has 0 of 2 required approvals")
+
+		_, err = withActor(p, "alice@example.com").ReleaseApprove("app1", "release2", structs.ReleaseApproveOptions{})
+		require.NoError(t, err)
+
+		err = p.ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{})
+		require.EqualError(t, err, "release release2 has 1 of 2 required approvals")
+
+		_, err = withActor(p, "bob@example.com").ReleaseApprove("app1", "release2", structs.ReleaseApproveOptions{})
+		require.NoError(t, err)
+
+		aa.On("Apply", "rack1-app1", "app", tmock.Anything).Return(nil).Once()
+
+		require.NoError(t, p.ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{}))
+	})
+}
+
+func TestReleaseApproveCreator(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		setupReleasePolicyTest(t, p, "{}", "  approvals: 1")
+
+		r, err := withActor(p, "alice@example.com").ReleaseCreate("app1", structs.ReleaseCreateOptions{Build: options.String("build1")})
+		require.NoError(t, err)
+		require.Equal(t, "alice@example.com", r.Creator)
+
+		_, err = withActor(p, "alice@example.com").ReleaseApprove("app1", r.Id, structs.ReleaseApproveOptions{})
+		require.EqualError(t, err, fmt.Sprintf("alice@example.com created release %s and can not approve it", r.Id))
+
+		r, err = withActor(p, "bob@example.com").ReleaseApprove("app1", r.Id, structs.ReleaseApproveOptions{})
+		require.NoError(t, err)
+		require.Len(t, r.Approvals, 1)
+	})
+}
+
+func TestReleasePromoteDevelopmentGated(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		setupReleasePolicyTest(t, p, "{}", "  approvals: 1")
+
+		err := p.ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{Development: options.Bool(true)})
+		require.EqualError(t, err, "release release2 has 0 of 1 required approvals")
+	})
+}
+
+func TestReleasePromoteApprovalsParameter(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		setupReleasePolicyTest(t, p, `{"DeployApprovals":"1"}`, "  approvals: 0")
+
+		err := p.ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{})
+		require.EqualError(t, err, "release release2 has 0 of 1 required approvals")
+	})
+}
+
+func TestReleasePromoteActiveReleaseNotGated(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		aa := setupReleasePolicyTest(t, p, fmt.Sprintf(`{"DeployApprovals":"3","DeployFreeze":%q}`, openFreezeWindow()), "  approvals: 0")
+
+		aa.On("Apply", "rack1-app1", "app", tmock.Anything).Return(nil).Once()
+
+		require.NoError(t, p.ReleasePromote("app1", "release1", structs.ReleasePromoteOptions{}))
+	})
+}
+
+func TestReleasePromoteFreeze(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		w := openFreezeWindow()
+
+		aa := setupReleasePolicyTest(t, p, "{}", fmt.Sprintf("  freeze:\n    - %s", w))
+
+		err := p.ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), fmt.Sprintf("promotes are frozen by %q until ", w))
+
+		err = p.ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{OverrideFreeze: options.String("  ")})
+		require.EqualError(t, err, "overriding a deploy freeze requires a justification")
+
+		aa.On("Apply", "rack1-app1", "app", tmock.Anything).Return(nil).Once()
+
+		require.NoError(t, p.ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{OverrideFreeze: options.String("hotfix for checkout outage")}))
+	})
+}
+
+func TestReleasePromoteFreezeActiveRelease(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		kk := p.Cluster.(*fake.Clientset)
+		require.NoError(t, appCreateWithAnnotation(kk, "rack1", "app1", map[string]string{
+			"convox.com/app-release": "release1",
+			"convox.com/app-status":  "running",
+		}))
+
+		// the freeze of the active release holds even though the new release drops it
+		cc := p.Convox.(*cvfake.Clientset)
+		require.NoError(t, releaseCreateInline(cc, "rack1-app1", "release1", deployManifestYaml(fmt.Sprintf("  freeze:\n    - %s", openFreezeWindow()))))
+		require.NoError(t, releaseCreateInline(cc, "rack1-app1", "release2", deployManifestYaml("  approvals: 0")))
+
+		err := p.ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "promotes are frozen by")
+	})
+}
+
+func TestAppUpdateDeployParameters(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		p.Engine = deployEngine{&mock.TestEngine{}}
+
+		kk := p.Cluster.(*fake.Clientset)
+		require.NoError(t, appCreateWithAnnotation(kk, "rack1", "app1", map[string]string{
+			"convox.com/app-release": "release1",
+			"convox.com/app-status":  "running",
+		}))
+
+		err := p.AppUpdate("app1", structs.AppUpdateOptions{Parameters: map[string]string{"DeployApprovals": "two"}})
+		require.EqualError(t, err, "DeployApprovals must be a number of approvals")
+
+		err = p.AppUpdate("app1", structs.AppUpdateOptions{Parameters: map[string]string{"DeployFreeze": "Fri 16:00-Mon 08:00;weekends"}})
+		require.EqualError(t, err, `invalid DeployFreeze: freeze window "weekends" invalid, must be of the form "Fri 16:00-Mon 08:00 [timezone]"`)
+	})
+}
@@ -0,0 +1,871 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// vars (not const) so tests can override timing without real wall-clock waits.
+var (
+	releasePromotionPollInterval  = 5 * time.Second
+	releasePromotionCheckInterval = 30 * time.Second
+	releasePromotionStaleAfter    = 5 * time.Minute
+)
+
+const (
+	releasePromotionDefaultErrorThreshold   = 1
+	releasePromotionDefaultInterval         = 5 * time.Minute
+	releasePromotionDefaultLatencyThreshold = 20
+	releasePromotionDefaultSteps            = "10,50,100"
+)
+
+var errReleasePromotionSuperseded = errors.New("superseded-by-newer-promote")
+
+// per-(app, release-id) singleton gate for the goroutine driving a progressive
+// promote.
+var releasePromotionInflight sync.Map
+
+// releaseCanary describes the canary workloads rendered alongside a release
+// while a progressive promote is shifting traffic to them. Each routed
+// service in Services gets a <service>-canary deployment running Release.
+type releaseCanary struct {
+	Release  string
+	Services map[string]bool
+	Weight   int
+	Full     bool
+}
+
+// canaryRoute is the traffic split rendered into a service's HTTPProxy.
+type canaryRoute struct {
+	PrimaryWeight int
+	CanaryWeight  int
+}
+
+func (c *releaseCanary) route(service string) *canaryRoute {
+	if c == nil || !c.Services[service] {
+		return nil
+	}
+
+	return &canaryRoute{PrimaryWeight: 100 - c.Weight, CanaryWeight: c.Weight}
+}
+
+// replicas sizes a canary relative to its primary: in proportion to its share
+// of the traffic, or at full size for blue-green promotes.
+func (c *releaseCanary) replicas(primary int) int {
+	if primary < 1 {
+		primary = 1
+	}
+
+	if c.Full || c.Weight >= 100 {
+		return primary
+	}
+
+	n := (primary*c.Weight + 99) / 100
+
+	if n < 1 {
+		n = 1
+	}
+
+	return n
+}
+
+func promotionCanary(pr *structs.ReleasePromotion, services []string) *releaseCanary {
+	c := &releaseCanary{
+		Release:  pr.Release,
+		Services: map[string]bool{},
+		Weight:   pr.Weight,
+		Full:     pr.Strategy == structs.ReleasePromoteStrategyBlueGreen,
+	}
+
+	for _, s := range services {
+		c.Services[s] = true
+	}
+
+	return c
+}
+
+func canaryServiceName(service string) string {
+	return service + "-canary"
+}
+
+// parseReleasePromotionSteps returns the traffic percentages a progressive
+// promote moves through. Blue-green promotes start the new release with no
+// traffic and then move all of it at once.
+func parseReleasePromotionSteps(strategy string, steps *string) ([]int, error) {
+	if strategy == structs.ReleasePromoteStrategyBlueGreen {
+		if steps != nil {
+			return nil, structs.ErrBadRequest("steps can only be set for canary promotes")
+		}
+
+		return []int{0, 100}, nil
+	}
+
+	ss := []int{}
+	last := 0
+
+	for _, part := range strings.Split(common.DefaultString(steps, releasePromotionDefaultSteps), ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(part), "%")))
+		if err != nil {
+			return nil, structs.ErrBadRequest("invalid step: %q", part)
+		}
+
+		if n <= last || n > 100 {
+			return nil, structs.ErrBadRequest("steps must increase from 1 to 100")
+		}
+
+		ss = append(ss, n)
+		last = n
+	}
+
+	if last != 100 {
+		return nil, structs.ErrBadRequest("steps must end at 100")
+	}
+
+	return ss, nil
+}
+
+// releasePromoteProgressive starts a canary or blue-green promote of release
+// id. The first step is applied before returning so that template and
+// validation errors reach the caller; the remaining steps, the metric gates
+// and the final promote or rollback run in the background.
+func (p *Provider) releasePromoteProgressive(app, id string, opts structs.ReleasePromoteOptions) error {
+	strategy := common.DefaultString(opts.Strategy, "")
+
+	if id == "" {
+		return structs.ErrBadRequest("a release is required for a %s promote", strategy)
+	}
+
+	if p.RouterType != "contour" {
+		return structs.ErrBadRequest("%s promotes require the contour router", strategy)
+	}
+
+	if p.PromClient == nil {
+		return structs.ErrBadRequest("%s promotes require prometheus_url to be set on the rack", strategy)
+	}
+
+	steps, err := parseReleasePromotionSteps(strategy, opts.Steps)
+	if err != nil {
+		return err
+	}
+
+	a, err := p.AppGet(app)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if a.Release == "" || a.Release == id {
+		return structs.ErrBadRequest("%s promotes shift traffic from a different running release", strategy)
+	}
+
+	if cur := a.Promotion; cur != nil && cur.Status == structs.ReleasePromotionRunning && time.Since(cur.Updated) < releasePromotionStaleAfter {
+		return structs.ErrConflict("a %s promote of %s is already running", cur.Strategy, cur.Release)
+	}
+
+	services, err := p.releasePromotionServices(app, a.Release, id)
+	if err != nil {
+		return err
+	}
+
+	if len(services) == 0 {
+		return structs.ErrBadRequest("%s promotes require a routed service present in both releases", strategy)
+	}
+
+	now := time.Now().UTC()
+
+	pr := &structs.ReleasePromotion{
+		Release:          id,
+		Base:             a.Release,
+		Strategy:         strategy,
+		Steps:            steps,
+		Weight:           steps[0],
+		Status:           structs.ReleasePromotionRunning,
+		Actor:            p.ContextActor(),
+		Interval:         common.DefaultDuration(opts.Interval, releasePromotionDefaultInterval),
+		ErrorThreshold:   common.DefaultInt(opts.ErrorThreshold, releasePromotionDefaultErrorThreshold),
+		LatencyThreshold: common.DefaultInt(opts.LatencyThreshold, releasePromotionDefaultLatencyThreshold),
+		Services:         services,
+		Idle:             opts.Idle,
+		Min:              opts.Min,
+		Max:              opts.Max,
+		Timeout:          opts.Timeout,
+		Started:          now,
+		Updated:          now,
+	}
+
+	// before-promote jobs of the new release hold the first step
+	jobs, err := p.releaseJobDependencies(app, id)
+	if err != nil {
+		return err
+	}
+
+	p.releaseTimelineStart(app, id, fmt.Sprintf("%s promote from %s by %s", strategy, pr.Base, common.CoalesceString(pr.Actor, "unknown")))
+
+	if err := p.releasePromotionApply(a, pr.Base, opts, promotionCanary(pr, services), jobs...); err != nil {
+		return err
+	}
+
+	if err := p.writeReleasePromotion(p.ctx, app, pr); err != nil {
+		return err
+	}
+
+	_ = p.EventSend("release:promote", structs.EventSendOptions{
+		Data:   map[string]string{"app": app, "id": id, "actor": pr.Actor, "strategy": strategy},
+		Status: options.String("start"),
+	})
+
+	p.releasePromotionLog(app, "%s promote of %s started: steps %s every %s", strategy, id, stepsString(steps), pr.Interval)
+
+	// jobs without a hook run alongside the promote
+	p.startReleaseJobs(p.ctx, app, id, "")
+
+	// the runner outlives the request that started it
+	if _, loaded := releasePromotionInflight.LoadOrStore(app+"/"+id, struct{}{}); !loaded {
+		go p.runReleasePromotion(context.WithoutCancel(p.ctx), app, pr, opts, services)
+	}
+
+	return nil
+}
+
+// releasePromotionServices returns the routed services present in both the
+// base release and release id, which are the only ones traffic can shift
+// between.
+func (p *Provider) releasePromotionServices(app, base, id string) ([]string, error) {
+	bm, _, err := common.ReleaseManifest(p, app, base)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	m, _, err := common.ReleaseManifest(p, app, id)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	routed := func(s manifest.Service) bool {
+		return !s.Internal || s.InternalRouter
+	}
+
+	current := map[string]bool{}
+
+	for _, s := range bm.Services.Routable().Filter(routed) {
+		current[s.Name] = true
+	}
+
+	services := []string{}
+
+	for _, s := range m.Services.Routable().Filter(routed) {
+		if current[s.Name] && !s.Agent.Enabled {
+			services = append(services, s.Name)
+		}
+	}
+
+	return services, nil
+}
+
+// runReleasePromotion drives the promote from pr.Step, which is the first
+// step for a new promote and the step that was in progress for a resumed
+// one.
+func (p *Provider) runReleasePromotion(ctx context.Context, app string, pr *structs.ReleasePromotion, opts structs.ReleasePromoteOptions, services []string) {
+	defer releasePromotionInflight.Delete(app + "/" + pr.Release)
+
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("ns=release_promotion at=panic app=%s id=%s recover=%q stack=%q\n", app, pr.Release, r, debug.Stack())
+		}
+	}()
+
+	timeout := time.Duration(common.DefaultInt(opts.Timeout, 3000)) * time.Second
+
+	start := pr.Step
+
+	for i := start; i < len(pr.Steps); i++ {
+		weight := pr.Steps[i]
+
+		if i > start {
+			pr.Step = i
+			pr.Weight = weight
+
+			a, err := p.AppGet(app)
+			if err != nil {
+				p.rollbackReleasePromotion(ctx, app, pr, opts, fmt.Sprintf("step %d%%: %s", weight, err))
+				return
+			}
+
+			if err := p.releasePromotionApply(a, pr.Base, opts, promotionCanary(pr, services)); err != nil {
+				p.rollbackReleasePromotion(ctx, app, pr, opts, fmt.Sprintf("step %d%%: %s", weight, err))
+				return
+			}
+		}
+
+		switch err := p.waitReleasePromotionApplied(ctx, app, pr, pr.Base, timeout); {
+		case err == errReleasePromotionSuperseded:
+			p.endReleasePromotion(ctx, app, pr, structs.ReleasePromotionCancelled, err.Error())
+			return
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			p.rollbackReleasePromotion(ctx, app, pr, opts, fmt.Sprintf("step %d%%: %s", weight, err))
+			return
+		}
+
+		if err := p.writeReleasePromotion(ctx, app, pr); err != nil {
+			fmt.Printf("ns=release_promotion at=warn kind=annotation_write app=%s err=%q\n", app, err)
+		}
+
+		_ = p.EventSend("release:promote:step", structs.EventSendOptions{
+			Data: map[string]string{"app": app, "id": pr.Release, "actor": pr.Actor, "weight": strconv.Itoa(weight)},
+		})
+
+		p.releasePromotionLog(app, "%s is serving %d%% of traffic", pr.Release, weight)
+
+		// a blue-green release takes no traffic while it comes up, so there
+		// is nothing to measure until it does
+		if weight == 0 {
+			continue
+		}
+
+		reason, err := p.bakeReleasePromotion(ctx, app, pr, services)
+		switch {
+		case err == errReleasePromotionSuperseded:
+			p.endReleasePromotion(ctx, app, pr, structs.ReleasePromotionCancelled, err.Error())
+			return
+		case ctx.Err() != nil:
+			return
+		case reason != "":
+			p.rollbackReleasePromotion(ctx, app, pr, opts, reason)
+			return
+		}
+	}
+
+	if err := p.finishReleasePromotion(ctx, app, pr, opts, services, timeout); err != nil {
+		switch {
+		case err == errReleasePromotionSuperseded:
+			p.endReleasePromotion(ctx, app, pr, structs.ReleasePromotionCancelled, err.Error())
+		case ctx.Err() != nil:
+		default:
+			p.rollbackReleasePromotion(ctx, app, pr, opts, fmt.Sprintf("promote: %s", err))
+		}
+		return
+	}
+
+	p.endReleasePromotion(ctx, app, pr, structs.ReleasePromotionPromoted, "")
+}
+
+// finishReleasePromotion rolls the new release out to the primary services
+// while the canaries still hold all of the traffic, then removes the
+// canaries once the primaries are serving the new release.
+func (p *Provider) finishReleasePromotion(ctx context.Context, app string, pr *structs.ReleasePromotion, opts structs.ReleasePromoteOptions, services []string, timeout time.Duration) error {
+	canary := promotionCanary(pr, services)
+	canary.Weight = 100
+
+	// past the last step so that a resumed promote picks up here
+	pr.Step = len(pr.Steps)
+
+	if err := p.writeReleasePromotion(ctx, app, pr); err != nil {
+		fmt.Printf("ns=release_promotion at=warn kind=annotation_write app=%s err=%q\n", app, err)
+	}
+
+	for _, c := range []*releaseCanary{canary, nil} {
+		a, err := p.AppGet(app)
+		if err != nil {
+			return err
+		}
+
+		if err := p.releasePromotionApply(a, pr.Release, opts, c); err != nil {
+			return err
+		}
+
+		if err := p.waitReleasePromotionApplied(ctx, app, pr, pr.Release, timeout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bakeReleasePromotion holds the current step for the promote interval,
+// checking the canaries for a regression as it goes. A regression ends the
+// step early; the step only passes if a final check can read the metrics.
+func (p *Provider) bakeReleasePromotion(ctx context.Context, app string, pr *structs.ReleasePromotion, services []string) (string, error) {
+	end := time.NewTimer(pr.Interval)
+	defer end.Stop()
+
+	check := time.NewTicker(releasePromotionCheckInterval)
+	defer check.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-check.C:
+			if err := p.heartbeatReleasePromotion(ctx, app, pr); err != nil {
+				return "", err
+			}
+
+			reason, err := p.releasePromotionRegression(ctx, app, pr, services)
+			if err != nil {
+				fmt.Printf("ns=release_promotion at=check app=%s id=%s error=%q\n", app, pr.Release, err)
+				continue
+			}
+
+			if reason != "" {
+				return reason, nil
+			}
+		case <-end.C:
+			if err := p.heartbeatReleasePromotion(ctx, app, pr); err != nil {
+				return "", err
+			}
+
+			reason, err := p.releasePromotionRegression(ctx, app, pr, services)
+			if err != nil {
+				return fmt.Sprintf("metrics unavailable: %s", err), nil
+			}
+
+			return reason, nil
+		}
+	}
+}
+
+type releasePromotionStats struct {
+	Requests float64
+	Errors   float64
+	P95      float64
+}
+
+func (s releasePromotionStats) errorRate() float64 {
+	if s.Requests <= 0 {
+		return 0
+	}
+
+	return s.Errors / s.Requests * 100
+}
+
+func (p *Provider) releasePromotionRegression(ctx context.Context, app string, pr *structs.ReleasePromotion, services []string) (string, error) {
+	for _, s := range services {
+		base, err := p.releasePromotionStats(ctx, app, s)
+		if err != nil {
+			return "", err
+		}
+
+		canary, err := p.releasePromotionStats(ctx, app, canaryServiceName(s))
+		if err != nil {
+			return "", err
+		}
+
+		if reason := releasePromotionCompare(s, base, canary, pr.ErrorThreshold, pr.LatencyThreshold); reason != "" {
+			return reason, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (p *Provider) releasePromotionStats(ctx context.Context, app, service string) (releasePromotionStats, error) {
+	ns := p.AppNamespace(app)
+	stats := releasePromotionStats{P95: math.NaN()}
+
+	requests, err := p.PromClient.QueryVector(ctx, HttpRequestRateByService, ns, service)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, v := range requests {
+		stats.Requests += float64(v.Value)
+	}
+
+	errs, err := p.PromClient.QueryVector(ctx, HttpErrorRateByStatusClass, ns, service)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, v := range errs {
+		if strings.HasPrefix(string(v.Metric["status_code"]), "5") {
+			stats.Errors += float64(v.Value)
+		}
+	}
+
+	latency, err := p.PromClient.QueryVector(ctx, HttpLatencyP95ForService, ns, service)
+	if err != nil {
+		return stats, err
+	}
+
+	if len(latency) > 0 {
+		stats.P95 = float64(latency[0].Value)
+	}
+
+	return stats, nil
+}
+
+// releasePromotionCompare returns why the canary of service regressed against
+// the release it is replacing, or an empty string if it did not. Server
+// errors are compared as a percentage of requests; latency as p95. A canary
+// that has not served any requests yet has nothing to compare.
+func releasePromotionCompare(service string, base, canary releasePromotionStats, errorThreshold, latencyThreshold int) string {
+	if canary.Requests <= 0 {
+		return ""
+	}
+
+	if ce, be := canary.errorRate(), base.errorRate(); ce > be+float64(errorThreshold) {
+		return fmt.Sprintf("%s error rate %.2f%% exceeds %.2f%% on the current release", service, ce, be)
+	}
+
+	if valid := func(f float64) bool { return !math.IsNaN(f) && !math.IsInf(f, 0) && f > 0 }; valid(canary.P95) && valid(base.P95) {
+		if canary.P95 > base.P95*(1+float64(latencyThreshold)/100) {
+			return fmt.Sprintf("%s p95 latency %s exceeds %s on the current release", service, promotionSeconds(canary.P95), promotionSeconds(base.P95))
+		}
+	}
+
+	return ""
+}
+
+func promotionSeconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second)).Round(time.Millisecond)
+}
+
+func (p *Provider) rollbackReleasePromotion(ctx context.Context, app string, pr *structs.ReleasePromotion, opts structs.ReleasePromoteOptions, reason string) {
+	p.releasePromotionLog(app, "rolling back to %s: %s", pr.Base, reason)
+
+	a, err := p.AppGet(app)
+	if err == nil {
+		err = p.releasePromotionApply(a, pr.Base, opts, nil)
+	}
+	if err != nil {
+		fmt.Printf("ns=release_promotion at=rollback app=%s id=%s error=%q\n", app, pr.Release, err)
+		reason = fmt.Sprintf("%s (rollback failed: %s)", reason, err)
+	}
+
+	p.endReleasePromotion(ctx, app, pr, structs.ReleasePromotionRolledBack, reason)
+}
+
+func (p *Provider) endReleasePromotion(ctx context.Context, app string, pr *structs.ReleasePromotion, status, reason string) {
+	pr.Status = status
+	pr.Reason = reason
+
+	if err := p.writeReleasePromotion(ctx, app, pr); err != nil {
+		fmt.Printf("ns=release_promotion at=warn kind=annotation_write app=%s err=%q\n", app, err)
+	}
+
+	state := &structs.ReleasePromoteWatchState{ReleaseID: pr.Release, Actor: pr.Actor}
+
+	switch status {
+	case structs.ReleasePromotionPromoted:
+		p.releasePromotionLog(app, "%s promoted", pr.Release)
+		p.emitReleasePromoteResult(app, state, "success", "")
+		p.startReleaseJobs(ctx, app, pr.Release, manifest.JobHookAfterPromote)
+	case structs.ReleasePromotionRolledBack:
+		p.emitReleasePromoteResult(app, state, "error", "rolled-back: "+reason)
+	default:
+		p.emitReleasePromoteResult(app, state, "cancelled", reason)
+	}
+}
+
+// cancelReleasePromotion marks a running progressive promote of app as
+// cancelled so that its runner stops without touching the release that
+// replaced it.
+func (p *Provider) cancelReleasePromotion(app string) {
+	pr, err := p.readReleasePromotion(p.ctx, app)
+	if err != nil || pr == nil || pr.Status != structs.ReleasePromotionRunning {
+		return
+	}
+
+	pr.Status = structs.ReleasePromotionCancelled
+	pr.Reason = errReleasePromotionSuperseded.Error()
+
+	if err := p.writeReleasePromotion(p.ctx, app, pr); err != nil {
+		fmt.Printf("ns=release_promotion at=warn kind=annotation_write app=%s err=%q\n", app, err)
+	}
+}
+
+// heartbeatReleasePromotion records that the promote is still being driven
+// and notices if it has been cancelled or replaced in the meantime.
+func (p *Provider) heartbeatReleasePromotion(ctx context.Context, app string, pr *structs.ReleasePromotion) error {
+	cur, err := p.readReleasePromotion(ctx, app)
+	if err != nil {
+		return nil
+	}
+
+	if cur == nil || cur.Release != pr.Release || cur.Status != structs.ReleasePromotionRunning {
+		return errReleasePromotionSuperseded
+	}
+
+	if err := p.writeReleasePromotion(ctx, app, pr); err != nil {
+		fmt.Printf("ns=release_promotion at=warn kind=annotation_write app=%s err=%q\n", app, err)
+	}
+
+	return nil
+}
+
+func (p *Provider) waitReleasePromotionApplied(ctx context.Context, app string, pr *structs.ReleasePromotion, release string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout + releasePromoteWatchGracePeriod)
+
+	tick := time.NewTicker(releasePromotionPollInterval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tick.C:
+			if err := p.heartbeatReleasePromotion(ctx, app, pr); err != nil {
+				return err
+			}
+
+			status, current, err := p.Atom.Status(p.AppNamespace(app), "app")
+			if err != nil {
+				continue
+			}
+
+			if current != release {
+				return errReleasePromotionSuperseded
+			}
+
+			if result, msg, terminal := mapAppStatusToWatchResult(status); terminal {
+				if result == "success" {
+					return nil
+				}
+				return errors.New(msg)
+			}
+
+			if time.Now().After(deadline) {
+				return errors.New("deadline-exceeded")
+			}
+		}
+	}
+}
+
+func (p *Provider) releasePromotionApply(a *structs.App, id string, opts structs.ReleasePromoteOptions, canary *releaseCanary, jobs ...string) error {
+	items, dependencies, err := p.releaseTemplate(a, id, opts, canary)
+	if err != nil {
+		return err
+	}
+
+	dependencies = append(dependencies, jobs...)
+
+	if err := p.Apply(p.AppNamespace(a.Name), "app", PromoteApplyConfig{
+		Version:      id,
+		Data:         bytes.Join(items, []byte("---\n")),
+		Labels:       fmt.Sprintf("system=convox,provider=k8s,rack=%s,app=%s,release=%s", p.Name, a.Name, id),
+		Timeout:      int32(common.DefaultInt(opts.Timeout, 3000)),
+		Dependencies: dependencies,
+	}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// releaseTemplateCanaries renders the canary workloads of a progressive
+// promote. They are copies of the routed services of the canary release
+// named <service>-canary and labeled type=canary so that they are not listed
+// as services of the app.
+func (p *Provider) releaseTemplateCanaries(a *structs.App, canary *releaseCanary) ([]byte, error) {
+	m, r, err := common.ReleaseManifest(p, a.Name, canary.Release)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	e, err := structs.NewEnvironment([]byte(r.Env))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	pss, err := p.ServiceList(a.Name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sc := map[string]int{}
+
+	for _, s := range pss {
+		sc[s.Name] = s.Count
+	}
+
+	items := [][]byte{}
+
+	for _, s := range m.Services {
+		if !canary.Services[s.Name] {
+			continue
+		}
+
+		env, err := p.environment(a, r, s, e)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		replicas := canary.replicas(common.CoalesceInt(sc[s.Name], s.Scale.Count.Min))
+
+		cs := s
+		cs.Name = canaryServiceName(s.Name)
+		cs.Scale.Count.Min = replicas
+		cs.Scale.Count.Max = replicas
+
+		ipsBlocks, ipsNames, err := renderImagePullSecrets(a.Name, p.AppNamespace(a.Name), &cs, func(k string) (string, bool) {
+			v, ok := env[k]
+			return v, ok
+		})
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		items = append(items, ipsBlocks...)
+
+		params := map[string]interface{}{
+			"Annotations":          cs.AnnotationsMap(),
+			"App":                  a,
+			"DockerHubAuth":        p.hasDockerHubAuth(),
+			"Environment":          env,
+			"ImagePullSecretNames": ipsNames,
+			"Namespace":            p.AppNamespace(a.Name),
+			"Password":             p.Password,
+			"Rack":                 p.Name,
+			"Release":              r,
+			"Replicas":             replicas,
+			"Resources":            cs.ResourceMap(),
+			"Service":              cs,
+		}
+
+		// canaries run at a fixed count, batches only apply to the service
+		cs.Deployment.Batches = nil
+
+		for k, v := range rolloutParams(cs, replicas, cs.Deployment.Minimum, cs.Deployment.Maximum, false) {
+			params[k] = v
+		}
+
+		if ip, err := p.Engine.ResolverHost(); err == nil {
+			params["Resolver"] = ip
+		}
+
+		if options.GetFeatureGates()[options.FeatureGateExternalDnsResolver] {
+			params["DisableDnsSearches"] = true
+		}
+
+		data, err := p.RenderTemplate("app/service", params)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		data, err = ApplyLabels(data, "type=canary")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		items = append(items, data)
+	}
+
+	return bytes.Join(items, []byte("---\n")), nil
+}
+
+func (p *Provider) readReleasePromotion(ctx context.Context, app string) (*structs.ReleasePromotion, error) {
+	ns, err := p.Cluster.CoreV1().Namespaces().Get(ctx, p.AppNamespace(app), am.GetOptions{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return releasePromotionFromAnnotations(ns.Annotations), nil
+}
+
+func (p *Provider) writeReleasePromotion(ctx context.Context, app string, pr *structs.ReleasePromotion) error {
+	pr.Updated = time.Now().UTC()
+
+	raw, err := json.Marshal(pr)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	patch, err := patchBytes(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{
+				structs.ReleasePromotionAnnotation: string(raw),
+			},
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	_, err = p.Cluster.CoreV1().Namespaces().Patch(ctx, p.AppNamespace(app), types.MergePatchType, patch, am.PatchOptions{})
+	return errors.WithStack(err)
+}
+
+func releasePromotionFromAnnotations(annotations map[string]string) *structs.ReleasePromotion {
+	raw := annotations[structs.ReleasePromotionAnnotation]
+	if raw == "" {
+		return nil
+	}
+
+	var pr structs.ReleasePromotion
+
+	if err := json.Unmarshal([]byte(raw), &pr); err != nil {
+		return nil
+	}
+
+	return &pr
+}
+
+// recoverReleasePromotion resumes a progressive promote that nothing has
+// driven for a while, such as one whose api pod went away mid-step. The step
+// that was in progress is baked again from the start. A promote that can not
+// be resumed is rolled back to the current release.
+func (p *Provider) recoverReleasePromotion(ctx context.Context, app string, annotations map[string]string) {
+	pr := releasePromotionFromAnnotations(annotations)
+	if pr == nil || pr.Status != structs.ReleasePromotionRunning || time.Since(pr.Updated) < releasePromotionStaleAfter {
+		return
+	}
+
+	key := app + "/" + pr.Release
+
+	if _, loaded := releasePromotionInflight.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	opts := structs.ReleasePromoteOptions{Idle: pr.Idle, Min: pr.Min, Max: pr.Max, Timeout: pr.Timeout}
+
+	services := pr.Services
+
+	if len(services) == 0 {
+		ss, err := p.releasePromotionServices(app, pr.Base, pr.Release)
+		if err != nil || len(ss) == 0 {
+			go func() {
+				defer releasePromotionInflight.Delete(key)
+
+				p.rollbackReleasePromotion(ctx, app, pr, opts, "promote was interrupted")
+			}()
+			return
+		}
+		services = ss
+	}
+
+	p.releasePromotionLog(app, "resuming %s promote of %s at %d%%", pr.Strategy, pr.Release, pr.Weight)
+
+	go p.runReleasePromotion(ctx, app, pr, opts, services)
+}
+
+func (p *Provider) releasePromotionLog(app, format string, args ...interface{}) {
+	_ = p.systemLog("", app, "promote", time.Now(), fmt.Sprintf(format, args...))
+}
+
+func stepsString(steps []int) string {
+	ss := make([]string, len(steps))
+
+	for i, s := range steps {
+		ss[i] = fmt.Sprintf("%d%%", s)
+	}
+
+	return strings.Join(ss, ",")
+}
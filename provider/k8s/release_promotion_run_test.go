@@ -0,0 +1,177 @@
+package k8s_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/atom"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/provider/k8s"
+	cvfake "github.com/convox/convox/provider/k8s/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+const promotionManifestYaml = `services:
+  web:
+    image: docker.io/library/nginx
+    port: 5000
+`
+
+// setupReleasePromotionTest creates app1 running release1 with release2
+// ready to promote, a prometheus that reports healthy traffic and an atom
+// whose current release follows what was last applied.
+func setupReleasePromotionTest(t *testing.T, p *k8s.Provider) *fake.Clientset {
+	t.Helper()
+
+	for v, d := range map[*time.Duration]time.Duration{
+		k8s.ReleasePromotionPollIntervalForTest:  10 * time.Millisecond,
+		k8s.ReleasePromotionCheckIntervalForTest: 10 * time.Millisecond,
+	} {
+		prev := *v
+		*v = d
+		t.Cleanup(func() { *v = prev })
+	}
+
+	t.Cleanup(k8s.ResetPromCircuitBreakerForTest)
+
+	prom := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"50"]}]}}`))
+	}))
+	t.Cleanup(prom.Close)
+
+	pc, err := k8s.NewPrometheusClient(prom.URL)
+	require.NoError(t, err)
+
+	p.PromClient = pc
+	p.RouterType = "contour"
+	p.DiscoveryClient = &fakediscovery.FakeDiscovery{Fake: &ktesting.Fake{Resources: []*am.APIResourceList{
+		{GroupVersion: "projectcontour.io/v1"},
+	}}}
+
+	kk := p.Cluster.(*fake.Clientset)
+	require.NoError(t, appCreateWithAnnotation(kk, "rack1", "app1", map[string]string{"convox.com/app-release": "release1"}))
+
+	cc := p.Convox.(*cvfake.Clientset)
+	require.NoError(t, buildCreate(cc, "rack1-app1", "build1", "basic"))
+	require.NoError(t, releaseCreateInline(cc, "rack1-app1", "release1", promotionManifestYaml))
+	require.NoError(t, releaseCreateInline(cc, "rack1-app1", "release2", promotionManifestYaml))
+
+	var lock sync.Mutex
+	current := "release1"
+
+	aa := p.Atom.(*atom.MockInterface)
+
+	aa.On("Apply", "rack1-app1", "app", mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		lock.Lock()
+		defer lock.Unlock()
+		current = args.Get(2).(*atom.ApplyConfig).Release
+	})
+
+	aa.On("Status", "rack1-app1", "app").Return("Running", func(string, string) string {
+		lock.Lock()
+		defer lock.Unlock()
+		return current
+	}, nil)
+
+	return kk
+}
+
+func releasePromotionAnnotation(t *testing.T, kk *fake.Clientset) *structs.ReleasePromotion {
+	ns, err := kk.CoreV1().Namespaces().Get(context.TODO(), "rack1-app1", am.GetOptions{})
+	require.NoError(t, err)
+
+	raw := ns.Annotations[structs.ReleasePromotionAnnotation]
+	if raw == "" {
+		return nil
+	}
+
+	var pr structs.ReleasePromotion
+	require.NoError(t, json.Unmarshal([]byte(raw), &pr))
+
+	return &pr
+}
+
+func TestReleasePromoteCanaryOutlivesRequest(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		kk := setupReleasePromotionTest(t, p)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		err := p.WithContext(ctx).ReleasePromote("app1", "release2", structs.ReleasePromoteOptions{
+			Strategy: options.String(structs.ReleasePromoteStrategyCanary),
+			Steps:    options.String("50,100"),
+			Interval: options.Duration(20 * time.Millisecond),
+		})
+		require.NoError(t, err)
+
+		// the request ends before the first step advances
+		cancel()
+
+		pr := releasePromotionAnnotation(t, kk)
+		require.Equal(t, 0, pr.Step)
+		require.Equal(t, []string{"web"}, pr.Services)
+
+		require.Eventually(t, func() bool {
+			return releasePromotionAnnotation(t, kk).Status == structs.ReleasePromotionPromoted
+		}, 5*time.Second, 10*time.Millisecond)
+
+		pr = releasePromotionAnnotation(t, kk)
+		require.Equal(t, 100, pr.Weight)
+		require.Empty(t, pr.Reason)
+	})
+}
+
+func TestReleasePromotionRecoverResumes(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		kk := setupReleasePromotionTest(t, p)
+
+		stale := time.Now().UTC().Add(-time.Hour)
+
+		raw, err := json.Marshal(structs.ReleasePromotion{
+			Release:  "release2",
+			Base:     "release1",
+			Strategy: structs.ReleasePromoteStrategyCanary,
+			Steps:    []int{10, 50, 100},
+			Step:     1,
+			Weight:   50,
+			Status:   structs.ReleasePromotionRunning,
+			Interval: 20 * time.Millisecond,
+			Services: []string{"web"},
+			Started:  stale,
+			Updated:  stale,
+		})
+		require.NoError(t, err)
+
+		ns, err := kk.CoreV1().Namespaces().Get(context.TODO(), "rack1-app1", am.GetOptions{})
+		require.NoError(t, err)
+
+		ns.Annotations[structs.ReleasePromotionAnnotation] = string(raw)
+
+		_, err = kk.CoreV1().Namespaces().Update(context.TODO(), ns, am.UpdateOptions{})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// a stale promote is picked up where it left off, not rolled back
+		k8s.RecoverReleasePromotionForTest(p, ctx, "app1", ns.Annotations)
+
+		cancel()
+
+		require.Eventually(t, func() bool {
+			pr := releasePromotionAnnotation(t, kk)
+			return pr != nil && pr.Status == structs.ReleasePromotionPromoted
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
@@ -0,0 +1,141 @@
+package k8s
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReleasePromotionSteps(t *testing.T) {
+	cases := []struct {
+		name     string
+		strategy string
+		steps    *string
+		expected []int
+		err      string
+	}{
+		{name: "canary default", strategy: "canary", expected: []int{10, 50, 100}},
+		{name: "canary custom", strategy: "canary", steps: options.String("5, 25%,100"), expected: []int{5, 25, 100}},
+		{name: "canary single step", strategy: "canary", steps: options.String("100"), expected: []int{100}},
+		{name: "canary not increasing", strategy: "canary", steps: options.String("50,10,100"), err: "steps must increase from 1 to 100"},
+		{name: "canary zero", strategy: "canary", steps: options.String("0,100"), err: "steps must increase from 1 to 100"},
+		{name: "canary over 100", strategy: "canary", steps: options.String("50,150"), err: "steps must increase from 1 to 100"},
+		{name: "canary short", strategy: "canary", steps: options.String("10,50"), err: "steps must end at 100"},
+		{name: "canary invalid", strategy: "canary", steps: options.String("ten,100"), err: `invalid step: "ten"`},
+		{name: "blue-green", strategy: "blue-green", expected: []int{0, 100}},
+		{name: "blue-green steps", strategy: "blue-green", steps: options.String("10,100"), err: "steps can only be set for canary promotes"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			steps, err := parseReleasePromotionSteps(c.strategy, c.steps)
+			if c.err != "" {
+				require.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, steps)
+		})
+	}
+}
+
+func TestReleaseCanaryReplicas(t *testing.T) {
+	require.Equal(t, 1, (&releaseCanary{Weight: 10}).replicas(4))
+	require.Equal(t, 2, (&releaseCanary{Weight: 50}).replicas(4))
+	require.Equal(t, 3, (&releaseCanary{Weight: 60}).replicas(4))
+	require.Equal(t, 4, (&releaseCanary{Weight: 100}).replicas(4))
+	require.Equal(t, 1, (&releaseCanary{Weight: 10}).replicas(0))
+	require.Equal(t, 4, (&releaseCanary{Weight: 0, Full: true}).replicas(4))
+}
+
+func TestReleaseCanaryRoute(t *testing.T) {
+	var none *releaseCanary
+	require.Nil(t, none.route("web"))
+
+	c := &releaseCanary{Services: map[string]bool{"web": true}, Weight: 10}
+	require.Nil(t, c.route("worker"))
+	require.Equal(t, &canaryRoute{PrimaryWeight: 90, CanaryWeight: 10}, c.route("web"))
+}
+
+func TestReleasePromotionCompare(t *testing.T) {
+	base := releasePromotionStats{Requests: 100, Errors: 1, P95: 0.2}
+
+	require.Equal(t, "", releasePromotionCompare("web", base, releasePromotionStats{Requests: 10, Errors: 0.1, P95: 0.2}, 1, 20))
+	require.Equal(t, "", releasePromotionCompare("web", base, releasePromotionStats{Requests: 0, Errors: 0, P95: math.NaN()}, 1, 20))
+	require.Equal(t, "", releasePromotionCompare("web", base, releasePromotionStats{Requests: 10, Errors: 0.15, P95: 0.23}, 1, 20))
+
+	require.Equal(t,
+		"web error rate 5.00% exceeds 1.00% on the current release",
+		releasePromotionCompare("web", base, releasePromotionStats{Requests: 10, Errors: 0.5, P95: 0.2}, 1, 20),
+	)
+
+	require.Equal(t,
+		"web p95 latency 300ms exceeds 200ms on the current release",
+		releasePromotionCompare("web", base, releasePromotionStats{Requests: 10, Errors: 0, P95: 0.3}, 1, 20),
+	)
+
+	// no latency histogram on either side is not a regression
+	require.Equal(t, "", releasePromotionCompare("web", releasePromotionStats{Requests: 100, P95: math.NaN()}, releasePromotionStats{Requests: 10, P95: 0.3}, 1, 20))
+}
+
+func TestReleasePromotionStats(t *testing.T) {
+	t.Cleanup(ResetPromCircuitBreakerForTest)
+
+	queries := []string{}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		q := r.Form.Get("query")
+		queries = append(queries, q)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case strings.HasPrefix(q, "histogram_quantile"):
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"0.25"]}]}}`))
+		case strings.HasPrefix(q, "sum by (status_code)"):
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"status_code":"404"},"value":[1700000000,"3"]},{"metric":{"status_code":"500"},"value":[1700000000,"1"]},{"metric":{"status_code":"503"},"value":[1700000000,"0.5"]}]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"50"]}]}}`))
+		}
+	}))
+	defer srv.Close()
+
+	pc, err := NewPrometheusClient(srv.URL)
+	require.NoError(t, err)
+
+	p := &Provider{Name: "rack1", PromClient: pc}
+
+	stats, err := p.releasePromotionStats(context.Background(), "app1", "web-canary")
+	require.NoError(t, err)
+	require.Equal(t, releasePromotionStats{Requests: 50, Errors: 1.5, P95: 0.25}, stats)
+	require.InDelta(t, 3.0, stats.errorRate(), 0.0001)
+
+	require.Len(t, queries, 3)
+	for _, q := range queries {
+		require.Contains(t, q, `namespace=~"rack1-app1"`)
+		require.Contains(t, q, `service=~"web-canary"`)
+	}
+}
+
+func TestReleasePromotionFromAnnotations(t *testing.T) {
+	require.Nil(t, releasePromotionFromAnnotations(nil))
+	require.Nil(t, releasePromotionFromAnnotations(map[string]string{structs.ReleasePromotionAnnotation: "{"}))
+
+	pr := releasePromotionFromAnnotations(map[string]string{
+		structs.ReleasePromotionAnnotation: `{"release":"R2","base":"R1","strategy":"canary","steps":[10,100],"step":1,"weight":100,"status":"running"}`,
+	})
+	require.NotNil(t, pr)
+	require.Equal(t, "R2", pr.Release)
+	require.Equal(t, "R1", pr.Base)
+	require.Equal(t, []int{10, 100}, pr.Steps)
+	require.Equal(t, 100, pr.Weight)
+	require.Equal(t, structs.ReleasePromotionRunning, pr.Status)
+}
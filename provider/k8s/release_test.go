@@ -99,6 +99,40 @@ func TestReleasePromote(t *testing.T) {
 	})
 }
 
+func TestReleasePromoteStrategyValidation(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		aa := p.Atom.(*atom.MockInterface)
+		kc := p.Convox.(*cvfake.Clientset)
+		kk := p.Cluster.(*fake.Clientset)
+
+		require.NoError(t, appCreate(kk, "rack1", "app1"))
+		require.NoError(t, releaseCreate(kc, "rack1-app1", "release1", "basic"))
+
+		aa.On("Status", "rack1-app1", "app").Return("Running", "release1", nil)
+
+		err := p.ReleasePromote("app1", "release1", structs.ReleasePromoteOptions{Strategy: options.String("bogus")})
+		require.EqualError(t, err, "unknown promote strategy: bogus")
+
+		p.RouterType = "nginx"
+		err = p.ReleasePromote("app1", "release1", structs.ReleasePromoteOptions{Strategy: options.String("canary")})
+		require.EqualError(t, err, "canary promotes require the contour router")
+
+		p.RouterType = "contour"
+		err = p.ReleasePromote("app1", "release1", structs.ReleasePromoteOptions{Strategy: options.String("blue-green")})
+		require.EqualError(t, err, "blue-green promotes require prometheus_url to be set on the rack")
+
+		pc, err := k8s.NewPrometheusClient("http://prometheus.invalid")
+		require.NoError(t, err)
+		p.PromClient = pc
+
+		err = p.ReleasePromote("app1", "release1", structs.ReleasePromoteOptions{Strategy: options.String("canary"), Steps: options.String("50")})
+		require.EqualError(t, err, "steps must end at 100")
+
+		err = p.ReleasePromote("app1", "release1", structs.ReleasePromoteOptions{Strategy: options.String("canary")})
+		require.EqualError(t, err, "canary promotes shift traffic from a different running release")
+	})
+}
+
 func releaseApply(aa *atom.MockInterface, ns, id, atm, fixture string) error {
 	data, err := os.ReadFile(fmt.Sprintf("testdata/release-%s.yml", fixture))
 	if err != nil {
@@ -6,9 +6,11 @@ import (
 	"fmt"
 	"os"
 	"runtime/debug"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/convox/convox/pkg/manifest"
 	"github.com/convox/convox/pkg/options"
 	"github.com/convox/convox/pkg/structs"
 	"github.com/pkg/errors"
@@ -63,6 +65,9 @@ func (p *Provider) runReleasePromoteWatcher(
 			resultError = fmt.Sprintf("watcher-panic: %v", r)
 		}
 		p.emitReleasePromoteResult(app, state, resultStatus, resultError)
+		if resultStatus == "success" {
+			p.startReleaseJobs(ctx, app, state.ReleaseID, manifest.JobHookAfterPromote)
+		}
 		if hook := releasePromoteCleanupDeferPanicHookForTest; hook != nil {
 			hook(app, state.ReleaseID)
 		}
@@ -110,6 +115,9 @@ func (p *Provider) runReleasePromoteWatcher(
 				resultError = "watcher-timeout"
 				return
 			}
+			if err := p.releaseRolloutBatches(ctx, app, state.ReleaseID, time.Now().UTC()); err != nil {
+				fmt.Printf("ns=release_watcher at=warn kind=rollout_batches app=%s id=%s err=%q\n", app, state.ReleaseID, err)
+			}
 		}
 	}
 }
@@ -146,6 +154,12 @@ func (p *Provider) emitReleasePromoteResult(app string, state *structs.ReleasePr
 		fmt.Printf("ns=release_watcher at=warn kind=unknown_status app=%s status=%q\n", app, status)
 		action = "app:promote:errored"
 	}
+	p.releaseTimelineEvent(app, state.ReleaseID, structs.ReleaseTimelineEvent{
+		Source:  structs.TimelineSourceWatcher,
+		Reason:  strings.TrimPrefix(action, "app:promote:"),
+		Message: errMsg,
+	})
+
 	data := map[string]string{"app": app, "id": state.ReleaseID, "actor": state.Actor}
 	opts := structs.EventSendOptions{
 		Data:   data,
@@ -267,14 +281,15 @@ func (p *Provider) scanReleasePromoteAnnotations(ctx context.Context) {
 	now := time.Now().UTC()
 	for i := range nsList.Items {
 		ns := &nsList.Items[i]
-		raw := ns.Annotations[structs.ReleasePromoteWatchAnnotation]
-		if raw == "" {
-			continue
-		}
 		app := ns.Labels["app"]
 		if app == "" {
 			app = ns.Labels["name"]
 		}
+		p.recoverReleasePromotion(ctx, app, ns.Annotations)
+		raw := ns.Annotations[structs.ReleasePromoteWatchAnnotation]
+		if raw == "" {
+			continue
+		}
 		var state structs.ReleasePromoteWatchState
 		if err := json.Unmarshal([]byte(raw), &state); err != nil {
 			fmt.Printf("ns=release_watcher at=warn kind=corrupt_json app=%s err=%q\n", app, err)
@@ -292,6 +307,9 @@ func (p *Provider) scanReleasePromoteAnnotations(ctx context.Context) {
 			atomStatus := ns.Annotations["convox.com/app-status"]
 			if status, errMsg, terminal := mapAppStatusToWatchResult(atomStatus); terminal {
 				p.emitReleasePromoteResult(app, &state, status, errMsg)
+				if status == "success" {
+					p.startReleaseJobs(ctx, app, state.ReleaseID, manifest.JobHookAfterPromote)
+				}
 			} else {
 				p.emitReleasePromoteResult(app, &state, "error", "watcher-timeout")
 			}
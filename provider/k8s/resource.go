@@ -37,6 +37,8 @@ func (p *Provider) ResourceConsole(app, name string, rw io.ReadWriter, opts stru
 		return resourceConsoleCommand(rw, opts, "mysql", "-h", cn.Host, "-P", cn.Port, "-u", cn.Username, fmt.Sprintf("-p%s", cn.Password), "-D", cn.Database)
 	case "memcached":
 		return resourceConsoleCommand(rw, opts, "telnet", cn.Host, cn.Port)
+	case "mongodb":
+		return p.resourcePodConsole(app, name, r.Type, rw, opts, "mongosh", u)
 	case "mysql":
 		return resourceConsoleCommand(rw, opts, "mysql", "-h", cn.Host, "-P", cn.Port, "-u", cn.Username, fmt.Sprintf("-p%s", cn.Password), "-D", cn.Database)
 	case "postgis":
@@ -64,8 +66,12 @@ func (p *Provider) ResourceExport(app, name string) (io.ReadCloser, error) {
 	switch r.Type {
 	case "mariadb", "rds-mariadb", "mysql", "rds-mysql":
 		return resourceExportMysql(u)
+	case "mongodb":
+		return p.resourcePodExport(app, name, r.Type, resourceExportMongodbCommand(u)...)
 	case "postgis", "postgres", "rds-postgres":
 		return resourceExportPostgres(u)
+	case "rabbitmq":
+		return p.resourcePodExport(app, name, r.Type, "rabbitmqctl", "export_definitions", "-")
 	default:
 		return nil, errors.WithStack(structs.ErrBadRequest("export not available for resources of type: %s", r.Type))
 	}
@@ -141,8 +147,12 @@ func (p *Provider) ResourceImport(app, name string, r io.Reader) error {
 	switch rr.Type {
 	case "mariadb", "rds-maridadb", "mysql", "rds-mysql":
 		return resourceImportMysql(rr, r)
+	case "mongodb":
+		return p.resourcePodImport(app, name, rr.Type, r, resourceImportMongodbCommand(rr.Url)...)
 	case "postgis", "postgres", "rds-postgres":
 		return resourceImportPostgres(rr, r)
+	case "rabbitmq":
+		return p.resourcePodImport(app, name, rr.Type, r, "rabbitmqctl", "import_definitions")
 	default:
 		return errors.WithStack(structs.ErrBadRequest("import not available for resources of type: %s", rr.Type))
 	}
@@ -268,6 +278,10 @@ func (p *Provider) SystemResourceList() (structs.Resources, error) {
 
 func (p *Provider) SystemResourceTypes() (structs.ResourceTypes, error) {
 	rst := structs.ResourceTypes{
+		containerResourceType("kafka", "3.8.0"),
+		containerResourceType("minio", "RELEASE.2024-10-13T13-34-11Z"),
+		containerResourceType("mongodb", "7.0"),
+		containerResourceType("rabbitmq", "3.13"),
 		structs.ResourceType{
 			Name: "webhook",
 			Parameters: structs.ResourceParameters{
@@ -283,6 +297,36 @@ func (p *Provider) SystemResourceTypes() (structs.ResourceTypes, error) {
 	return rst, nil
 }
 
+// containerResourceType describes the options of a resource type that runs
+// as a container in the app namespace.
+func containerResourceType(name, version string) structs.ResourceType {
+	return structs.ResourceType{
+		Name: name,
+		Parameters: structs.ResourceParameters{
+			structs.ResourceParameter{
+				Default:     "",
+				Description: "cpu limit in millicores",
+				Name:        "cpu",
+			},
+			structs.ResourceParameter{
+				Default:     "",
+				Description: "memory limit in megabytes",
+				Name:        "mem",
+			},
+			structs.ResourceParameter{
+				Default:     "10",
+				Description: "size of the data volume in gigabytes",
+				Name:        "storage",
+			},
+			structs.ResourceParameter{
+				Default:     version,
+				Description: fmt.Sprintf("%s docker image tag", name),
+				Name:        "version",
+			},
+		},
+	}
+}
+
 func (p *Provider) SystemResourceUnlink(name, app string) (*structs.Resource, error) {
 	return nil, errors.WithStack(structs.ErrBadRequest("unavailable on v3 racks"))
 }
@@ -0,0 +1,302 @@
+package k8s
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+)
+
+const (
+	resourceBackupLeaseName    = "convox-resource-backups"
+	resourceBackupTickInterval = 30 * time.Second
+	resourceBackupIdFormat     = "20060102150405"
+	resourceBackupSuffix       = ".sql.gz"
+)
+
+// resourceBackupsRunning holds the app/resource pairs with a backup in
+// progress so that a slow dump is not started again on its next schedule.
+var resourceBackupsRunning sync.Map
+
+// objectStorage is the object api of the provider. Cloud providers replace
+// the local disk storage of this package with their buckets.
+type objectStorage interface {
+	ObjectDelete(app, key string) error
+	ObjectFetch(app, key string) (io.ReadCloser, error)
+	ObjectList(app, prefix string) ([]string, error)
+	ObjectStore(app, key string, r io.Reader, opts structs.ObjectStoreOptions) (*structs.Object, error)
+}
+
+// objects returns the object storage of the provider the rack runs.
+func (p *Provider) objects() objectStorage {
+	if o, ok := p.Engine.(objectStorage); ok {
+		return o
+	}
+
+	return p
+}
+
+func (p *Provider) ResourceBackupList(app, name string) (structs.ResourceBackups, error) {
+	m, _, err := common.AppManifest(p, app)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := m.Resource(name); err != nil {
+		return nil, errors.WithStack(structs.ErrNotFound("%s", err))
+	}
+
+	return p.resourceBackups(app, name)
+}
+
+func (p *Provider) ResourceBackupRestore(app, name, id string) error {
+	if _, err := time.Parse(resourceBackupIdFormat, id); err != nil {
+		return errors.WithStack(structs.ErrBadRequest("invalid backup id: %s", id))
+	}
+
+	r, err := p.objects().ObjectFetch(app, resourceBackupKey(name, id))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer r.Close()
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return p.ResourceImport(app, name, gz)
+}
+
+// runResourceBackups starts the backups that are due every minute. It runs
+// on the elected leader only so that every backup is taken once.
+func (p *Provider) runResourceBackups(ctx context.Context) {
+	fmt.Printf("ns=resource_backups at=start\n")
+
+	tick := time.NewTicker(resourceBackupTickInterval)
+	defer tick.Stop()
+
+	// ticks come twice a minute so no minute is missed, only act on the first
+	last := time.Time{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("ns=resource_backups at=stop\n")
+			return
+		case now := <-tick.C:
+			minute := now.UTC().Truncate(time.Minute)
+
+			if minute.Equal(last) {
+				continue
+			}
+
+			last = minute
+
+			p.resourceBackupTick(minute)
+		}
+	}
+}
+
+func (p *Provider) resourceBackupTick(at time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("ns=resource_backups at=error kind=panic_recovered recovered=%v\n", r)
+		}
+	}()
+
+	as, err := p.AppList()
+	if err != nil {
+		fmt.Printf("ns=resource_backups at=error kind=app_list err=%q\n", err)
+		return
+	}
+
+	for _, a := range as {
+		if a.Release == "" {
+			continue
+		}
+
+		m, _, err := common.AppManifest(p, a.Name)
+		if err != nil {
+			fmt.Printf("ns=resource_backups at=error kind=manifest app=%s err=%q\n", a.Name, err)
+			continue
+		}
+
+		for _, r := range m.Resources {
+			if r.Backup == nil {
+				continue
+			}
+
+			cs, err := manifest.ParseCronSchedule(r.Backup.Schedule)
+			if err != nil || !cs.Matches(at) {
+				continue
+			}
+
+			go p.resourceBackupRun(a.Name, r, at)
+		}
+	}
+}
+
+func (p *Provider) resourceBackupRun(app string, r manifest.Resource, at time.Time) {
+	id := fmt.Sprintf("%s/%s", app, r.Name)
+
+	if _, running := resourceBackupsRunning.LoadOrStore(id, true); running {
+		fmt.Printf("ns=resource_backups at=skip app=%s resource=%s reason=running\n", app, r.Name)
+		return
+	}
+	defer resourceBackupsRunning.Delete(id)
+
+	if err := p.resourceBackup(app, r, at); err != nil {
+		fmt.Printf("ns=resource_backups at=error app=%s resource=%s err=%q\n", app, r.Name, err)
+		return
+	}
+
+	fmt.Printf("ns=resource_backups at=success app=%s resource=%s id=%s\n", app, r.Name, at.UTC().Format(resourceBackupIdFormat))
+}
+
+func (p *Provider) resourceBackup(app string, r manifest.Resource, at time.Time) error {
+	u, err := p.resourceUrl(app, r.Name)
+	if err != nil {
+		return err
+	}
+
+	cmd, err := resourceBackupCommand(r.Type, u)
+	if err != nil {
+		return err
+	}
+
+	if _, err := p.resourceBackupStore(app, r.Name, cmd, at); err != nil {
+		return err
+	}
+
+	return p.resourceBackupPrune(app, r.Name, r.Backup.Retention)
+}
+
+// resourceBackupStore streams the output of cmd, compressed, to the object
+// storage. A failed dump does not leave a partial backup behind.
+func (p *Provider) resourceBackupStore(app, name string, cmd *exec.Cmd, at time.Time) (*structs.ResourceBackup, error) {
+	b := &structs.ResourceBackup{
+		Id:       at.UTC().Format(resourceBackupIdFormat),
+		Resource: name,
+		Created:  at.UTC().Truncate(time.Second),
+	}
+
+	key := resourceBackupKey(name, b.Id)
+
+	rr, ww := io.Pipe()
+
+	gz := gzip.NewWriter(ww)
+
+	var stderr bytes.Buffer
+
+	cmd.Stdout = gz
+	cmd.Stderr = &stderr
+
+	go func() {
+		err := cmd.Run()
+		if err != nil {
+			err = fmt.Errorf("%s: %s", err, strings.TrimSpace(stderr.String()))
+		} else {
+			err = gz.Close()
+		}
+		ww.CloseWithError(err)
+	}()
+
+	if _, err := p.objects().ObjectStore(app, key, rr, structs.ObjectStoreOptions{}); err != nil {
+		rr.CloseWithError(err)
+		p.objects().ObjectDelete(app, key) //nolint:errcheck // the backup may not have been created
+		return nil, errors.WithStack(err)
+	}
+
+	return b, nil
+}
+
+// resourceBackupPrune deletes all but the newest retention backups.
+func (p *Provider) resourceBackupPrune(app, name string, retention int) error {
+	bs, err := p.resourceBackups(app, name)
+	if err != nil {
+		return err
+	}
+
+	if len(bs) <= retention {
+		return nil
+	}
+
+	for _, b := range bs[retention:] {
+		if err := p.objects().ObjectDelete(app, resourceBackupKey(name, b.Id)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+// resourceBackups returns the backups of a resource, newest first.
+func (p *Provider) resourceBackups(app, name string) (structs.ResourceBackups, error) {
+	keys, err := p.objects().ObjectList(app, resourceBackupPrefix(name))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	bs := structs.ResourceBackups{}
+
+	// providers list keys relative to the app or to the bucket
+	for _, k := range keys {
+		base := path.Base(k)
+
+		if !strings.HasSuffix(base, resourceBackupSuffix) {
+			continue
+		}
+
+		id := strings.TrimSuffix(base, resourceBackupSuffix)
+
+		t, err := time.Parse(resourceBackupIdFormat, id)
+		if err != nil {
+			continue
+		}
+
+		bs = append(bs, structs.ResourceBackup{Id: id, Resource: name, Created: t})
+	}
+
+	sort.Slice(bs, bs.Less)
+
+	return bs, nil
+}
+
+// resourceBackupCommand dumps a resource with statements that drop existing
+// objects first, so that restoring a backup replaces the data in place.
+func resourceBackupCommand(kind, url string) (*exec.Cmd, error) {
+	switch kind {
+	case "mariadb", "mysql":
+		cn, err := parseResourceURL(url)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return exec.Command("mysqldump", "-h", cn.Host, "-P", cn.Port, "-u", cn.Username, fmt.Sprintf("-p%s", cn.Password), "--single-transaction", "--add-drop-table", cn.Database), nil
+	case "postgis", "postgres":
+		return exec.Command("pg_dump", "--no-acl", "--no-owner", "--clean", "--if-exists", url), nil
+	default:
+		return nil, errors.WithStack(structs.ErrBadRequest("backup not available for resources of type: %s", kind))
+	}
+}
+
+func resourceBackupPrefix(name string) string {
+	return fmt.Sprintf("backups/resources/%s/", name)
+}
+
+func resourceBackupKey(name, id string) string {
+	return fmt.Sprintf("%s%s%s", resourceBackupPrefix(name), id, resourceBackupSuffix)
+}
@@ -0,0 +1,126 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	ac "k8s.io/api/core/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// resourcePod returns the running pod of a containerized resource. Resource
+// types whose tools are not installed on the rack api are reached through
+// the client binaries shipped in their own image.
+func (p *Provider) resourcePod(app, name string) (string, error) {
+	pds, err := p.Cluster.CoreV1().Pods(p.AppNamespace(app)).List(context.TODO(), am.ListOptions{
+		LabelSelector: fmt.Sprintf("type=resource,resource=%s", name),
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	for _, pd := range pds.Items {
+		if pd.Status.Phase == ac.PodRunning && pd.DeletionTimestamp == nil {
+			return pd.Name, nil
+		}
+	}
+
+	return "", errors.WithStack(structs.ErrNotFound("no running pod for resource: %s", name))
+}
+
+func (p *Provider) resourcePodExec(app, name, container string, command []string, sopts remotecommand.StreamOptions) error {
+	pid, err := p.resourcePod(app, name)
+	if err != nil {
+		return err
+	}
+
+	req := p.Cluster.CoreV1().RESTClient().Post().Resource("pods").Name(pid).Namespace(p.AppNamespace(app)).SubResource("exec").Param("container", container)
+
+	eo := &ac.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdin:     sopts.Stdin != nil,
+		Stdout:    sopts.Stdout != nil,
+		Stderr:    sopts.Stderr != nil,
+		TTY:       sopts.Tty,
+	}
+
+	req.VersionedParams(eo, scheme.ParameterCodec)
+
+	e, err := remotecommand.NewSPDYExecutor(p.Config, "POST", req.URL())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return e.StreamWithContext(p.ctx, sopts)
+}
+
+func (p *Provider) resourcePodConsole(app, name, container string, rw io.ReadWriter, opts structs.ResourceConsoleOptions, command ...string) error {
+	inr, inw := io.Pipe()
+	go io.Copy(inw, rw)
+
+	sopts := remotecommand.StreamOptions{
+		Stdin:  inr,
+		Stdout: rw,
+		Tty:    true,
+	}
+
+	if opts.Height != nil && opts.Width != nil {
+		sopts.TerminalSizeQueue = &terminalSize{Height: *opts.Height, Width: *opts.Width}
+	}
+
+	if err := p.resourcePodExec(app, name, container, command, sopts); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (p *Provider) resourcePodExport(app, name, container string, command ...string) (io.ReadCloser, error) {
+	if _, err := p.resourcePod(app, name); err != nil {
+		return nil, err
+	}
+
+	rr, ww := io.Pipe()
+
+	go func() {
+		var stderr bytes.Buffer
+
+		defer ww.Close()
+
+		if err := p.resourcePodExec(app, name, container, command, remotecommand.StreamOptions{Stdout: ww, Stderr: &stderr}); err != nil {
+			if msg := strings.TrimSpace(stderr.String()); msg != "" {
+				err = fmt.Errorf("%s: %s", err, msg)
+			}
+			fmt.Fprintf(ww, "ERROR: could not export: %v\n", err)
+		}
+	}()
+
+	return rr, nil
+}
+
+func (p *Provider) resourcePodImport(app, name, container string, r io.Reader, command ...string) error {
+	var out bytes.Buffer
+
+	if err := p.resourcePodExec(app, name, container, command, remotecommand.StreamOptions{Stdin: r, Stdout: &out, Stderr: &out}); err != nil {
+		fmt.Printf("ns=resource at=import app=%s resource=%s output=%q\n", app, name, out.String())
+		return errors.WithStack(fmt.Errorf("ERROR: import failed"))
+	}
+
+	return nil
+}
+
+func resourceExportMongodbCommand(url string) []string {
+	return []string{"mongodump", fmt.Sprintf("--uri=%s", url), "--archive", "--quiet"}
+}
+
+func resourceImportMongodbCommand(url string) []string {
+	return []string{"mongorestore", fmt.Sprintf("--uri=%s", url), "--archive", "--drop"}
+}
@@ -0,0 +1,339 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// batched rollouts are driven by the release watcher, which pauses the
+// deployment of a service once a batch has been rolled out and resumes it
+// when the batch is ready and its pause has passed. The progress of each
+// deployment is kept in an annotation as <release>/<batch>/<ready time>.
+const (
+	rolloutBatchesAnnotation = "convox.com/rollout-batches"
+	rolloutPauseAnnotation   = "convox.com/rollout-pause"
+	rolloutPausedAnnotation  = "convox.com/rollout-paused"
+	rolloutStateAnnotation   = "convox.com/rollout-batch"
+)
+
+// rolloutParams returns the template params for the deployment strategy of
+// s running replicas processes. The min and max percentages are used unless
+// the service sets explicit counts and override is false. A batched rollout
+// never creates more new processes at once than its smallest batch and keeps
+// every old one until the new ones are ready, so the release watcher can
+// pause it at the end of each batch before it runs ahead.
+func rolloutParams(s manifest.Service, replicas, min, max int, override bool) map[string]interface{} {
+	surge := fmt.Sprintf("%d%%", max-100)
+	unavailable := fmt.Sprintf("%d%%", 100-min)
+
+	if !override && s.Deployment.MaxSurge != "" {
+		surge = s.Deployment.MaxSurge
+	}
+
+	if !override && s.Deployment.MaxUnavailable != "" {
+		unavailable = s.Deployment.MaxUnavailable
+	}
+
+	if len(s.Deployment.Batches) > 0 {
+		surge = strconv.Itoa(rolloutBatchStep(s.Deployment.BatchCounts(replicas)))
+		unavailable = "0"
+	}
+
+	ready := 1
+	if s.Deployment.MinReadySeconds != nil {
+		ready = *s.Deployment.MinReadySeconds
+	}
+
+	return map[string]interface{}{
+		"MaxSurge":        rolloutIntOrPercent(surge),
+		"MaxUnavailable":  rolloutIntOrPercent(unavailable),
+		"MinReadySeconds": ready,
+		"RolloutBatches":  strings.Join(s.Deployment.Batches, ","),
+		"RolloutPause":    s.Deployment.Pause,
+		"Strategy":        s.Deployment.Strategy,
+	}
+}
+
+// rolloutBatchStep returns the number of processes of the smallest batch.
+func rolloutBatchStep(counts []int) int {
+	step, prev := 0, 0
+
+	for _, c := range counts {
+		if n := c - prev; n > 0 && (step == 0 || n < step) {
+			step = n
+		}
+
+		prev = c
+	}
+
+	return max(step, 1)
+}
+
+// rolloutIntOrPercent renders counts as numbers and percentages as strings
+// as the deployment api expects them.
+func rolloutIntOrPercent(v string) string {
+	if strings.HasSuffix(v, "%") {
+		return strconv.Quote(v)
+	}
+
+	return v
+}
+
+// rolloutState is the progress of a batched rollout of a release.
+type rolloutState struct {
+	Release string
+	Batch   int
+	Ready   time.Time
+}
+
+func parseRolloutState(v string) rolloutState {
+	parts := strings.SplitN(v, "/", 3)
+	if len(parts) != 3 {
+		return rolloutState{}
+	}
+
+	s := rolloutState{Release: parts[0]}
+	s.Batch, _ = strconv.Atoi(parts[1])
+	s.Ready, _ = time.Parse(time.RFC3339, parts[2])
+
+	return s
+}
+
+func (s rolloutState) String() string {
+	ready := ""
+	if !s.Ready.IsZero() {
+		ready = s.Ready.UTC().Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("%s/%d/%s", s.Release, s.Batch, ready)
+}
+
+// rolloutStep decides whether the deployment of a batched rollout should be
+// paused given the number of processes of the new release that have been
+// created and that are available. It returns the new progress.
+func rolloutStep(s rolloutState, counts []int, pause time.Duration, updated, available int, now time.Time) (rolloutState, bool) {
+	if s.Batch >= len(counts) {
+		return s, false
+	}
+
+	target := counts[s.Batch]
+
+	if available < target {
+		// stop creating processes once the batch exists, let them become ready
+		return s, updated >= target
+	}
+
+	if s.Batch == len(counts)-1 {
+		s.Batch++
+		return s, false
+	}
+
+	if s.Ready.IsZero() {
+		s.Ready = now
+	}
+
+	if now.Sub(s.Ready) < pause {
+		return s, true
+	}
+
+	s.Batch++
+	s.Ready = time.Time{}
+
+	return s, false
+}
+
+// releaseRolloutBatches advances the batched rollouts of the services of
+// release id. It is called on each tick of the release watcher.
+func (p *Provider) releaseRolloutBatches(ctx context.Context, app, id string, now time.Time) error {
+	ns, err := p.GetNamespaceFromInformer(p.AppNamespace(app))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	held := ns.Annotations[rolloutPausedAnnotation] == "true"
+
+	ds, err := p.Cluster.AppsV1().Deployments(p.AppNamespace(app)).List(ctx, am.ListOptions{LabelSelector: "type=service"})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i := range ds.Items {
+		d := &ds.Items[i]
+
+		batches := d.Annotations[rolloutBatchesAnnotation]
+
+		if batches == "" || held || d.Spec.Template.Labels["release"] != id {
+			continue
+		}
+
+		s := parseRolloutState(d.Annotations[rolloutStateAnnotation])
+		if s.Release != id {
+			s = rolloutState{Release: id}
+		}
+
+		replicas := 1
+		if d.Spec.Replicas != nil {
+			replicas = int(*d.Spec.Replicas)
+		}
+
+		available, err := p.rolloutAvailable(ctx, d, id)
+		if err != nil {
+			return err
+		}
+
+		pause, _ := time.ParseDuration(d.Annotations[rolloutPauseAnnotation])
+		counts := manifest.ServiceDeployment{Batches: strings.Split(batches, ",")}.BatchCounts(replicas)
+
+		next, paused := rolloutStep(s, counts, pause, int(d.Status.UpdatedReplicas), available, now)
+
+		if next == s && paused == d.Spec.Paused {
+			continue
+		}
+
+		if next.Batch != s.Batch && next.Batch < len(counts) {
+			p.releaseTimelineEvent(app, id, structs.ReleaseTimelineEvent{
+				Source:  structs.TimelineSourceRollout,
+				Object:  d.Name,
+				Reason:  "Batch",
+				Message: fmt.Sprintf("batch %d of %d: %d processes", next.Batch+1, len(counts), counts[next.Batch]),
+			})
+		}
+
+		if err := p.rolloutPatch(ctx, d, paused, next.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rolloutAvailable counts the available processes of release id of a
+// deployment.
+func (p *Provider) rolloutAvailable(ctx context.Context, d *appsv1.Deployment, id string) (int, error) {
+	rss, err := p.Cluster.AppsV1().ReplicaSets(d.Namespace).List(ctx, am.ListOptions{
+		LabelSelector: fmt.Sprintf("service=%s,release=%s", d.Spec.Template.Labels["service"], id),
+	})
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	available := 0
+
+	for _, rs := range rss.Items {
+		available += int(rs.Status.AvailableReplicas)
+	}
+
+	return available, nil
+}
+
+func (p *Provider) rolloutPatch(ctx context.Context, d *appsv1.Deployment, paused bool, state string) error {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{"paused": paused},
+	}
+
+	if state != "" {
+		patch["metadata"] = map[string]interface{}{
+			"annotations": map[string]string{rolloutStateAnnotation: state},
+		}
+	}
+
+	data, err := patchBytes(patch)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := p.Cluster.AppsV1().Deployments(d.Namespace).Patch(ctx, d.Name, types.MergePatchType, data, am.PatchOptions{}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// ReleasePause holds the rollout in progress of an app until it is resumed.
+// The promote timeout keeps running while the rollout is held.
+func (p *Provider) ReleasePause(app string) error {
+	return p.releaseHold(app, true)
+}
+
+// ReleaseResume continues a rollout held by ReleasePause.
+func (p *Provider) ReleaseResume(app string) error {
+	return p.releaseHold(app, false)
+}
+
+func (p *Provider) releaseHold(app string, hold bool) error {
+	ns, err := p.Cluster.CoreV1().Namespaces().Get(context.TODO(), p.AppNamespace(app), am.GetOptions{})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	held := ns.Annotations[rolloutPausedAnnotation] == "true"
+
+	switch {
+	case hold && !timelineActiveStatuses[ns.Annotations["convox.com/app-status"]]:
+		return structs.ErrBadRequest("app %s has no rollout in progress", app)
+	case hold && held:
+		return structs.ErrConflict("the rollout of app %s is already paused", app)
+	case !hold && !held:
+		return structs.ErrBadRequest("the rollout of app %s is not paused", app)
+	}
+
+	value := interface{}(nil)
+	if hold {
+		value = "true"
+	}
+
+	data, err := patchBytes(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{rolloutPausedAnnotation: value},
+		},
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if _, err := p.Cluster.CoreV1().Namespaces().Patch(context.TODO(), ns.Name, types.MergePatchType, data, am.PatchOptions{}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	ds, err := p.Cluster.AppsV1().Deployments(ns.Name).List(context.TODO(), am.ListOptions{LabelSelector: "type=service"})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// the release watcher pauses batched rollouts again between batches
+	for i := range ds.Items {
+		if ds.Items[i].Spec.Paused == hold {
+			continue
+		}
+
+		if err := p.rolloutPatch(context.TODO(), &ds.Items[i], hold, ""); err != nil {
+			return err
+		}
+	}
+
+	reason := "Resumed"
+	if hold {
+		reason = "Paused"
+	}
+
+	if id := ns.Annotations[timelineAnnotation]; id != "" {
+		p.releaseTimelineEvent(app, id, structs.ReleaseTimelineEvent{
+			Source:  structs.TimelineSourceRollout,
+			Reason:  reason,
+			Message: fmt.Sprintf("by %s", common.CoalesceString(p.ContextActor(), "unknown")),
+		})
+	}
+
+	return nil
+}
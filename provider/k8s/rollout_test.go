@@ -0,0 +1,213 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/manifest"
+	"github.com/convox/convox/pkg/mock"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	ac "k8s.io/api/core/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRolloutParams(t *testing.T) {
+	ready := 30
+
+	s := manifest.Service{Deployment: manifest.ServiceDeployment{
+		Batches:         []string{"1", "25%", "100%"},
+		MaxSurge:        "2",
+		MaxUnavailable:  "10%",
+		MinReadySeconds: &ready,
+		Pause:           "2m",
+		Strategy:        "rolling",
+	}}
+
+	// batches cap each step of the rollout at the smallest batch: 1, 2 and 8 of 8
+	require.Equal(t, map[string]interface{}{
+		"MaxSurge":        "1",
+		"MaxUnavailable":  "0",
+		"MinReadySeconds": 30,
+		"RolloutBatches":  "1,25%,100%",
+		"RolloutPause":    "2m",
+		"Strategy":        "rolling",
+	}, rolloutParams(s, 8, 50, 200, false))
+
+	s.Deployment.Batches = []string{"4", "100%"}
+
+	params := rolloutParams(s, 20, 50, 200, true)
+	require.Equal(t, "4", params["MaxSurge"])
+	require.Equal(t, "0", params["MaxUnavailable"])
+
+	s.Deployment.Batches = nil
+
+	params = rolloutParams(s, 8, 50, 200, false)
+	require.Equal(t, "2", params["MaxSurge"])
+	require.Equal(t, `"10%"`, params["MaxUnavailable"])
+
+	// explicit --min/--max on a promote win over the manifest
+	params = rolloutParams(s, 8, 50, 200, true)
+	require.Equal(t, `"100%"`, params["MaxSurge"])
+	require.Equal(t, `"50%"`, params["MaxUnavailable"])
+
+	params = rolloutParams(manifest.Service{}, 1, 100, 200, false)
+	require.Equal(t, `"100%"`, params["MaxSurge"])
+	require.Equal(t, `"0%"`, params["MaxUnavailable"])
+	require.Equal(t, 1, params["MinReadySeconds"])
+	require.Equal(t, "", params["RolloutBatches"])
+}
+
+func TestRolloutState(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	s := rolloutState{Release: "R2", Batch: 1, Ready: now}
+	require.Equal(t, "R2/1/2026-10-18T12:00:00Z", s.String())
+	require.Equal(t, s, parseRolloutState(s.String()))
+
+	require.Equal(t, "R2/0/", rolloutState{Release: "R2"}.String())
+	require.Equal(t, rolloutState{Release: "R2"}, parseRolloutState("R2/0/"))
+	require.Equal(t, rolloutState{}, parseRolloutState("invalid"))
+}
+
+func TestRolloutStep(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	counts := []int{1, 3, 10}
+
+	// batch created but not ready yet
+	s, paused := rolloutStep(rolloutState{Release: "R2"}, counts, time.Minute, 1, 0, now)
+	require.Equal(t, rolloutState{Release: "R2"}, s)
+	require.True(t, paused)
+
+	// batch not created yet
+	_, paused = rolloutStep(rolloutState{Release: "R2"}, counts, time.Minute, 0, 0, now)
+	require.False(t, paused)
+
+	// batch ready, waiting for the pause
+	s, paused = rolloutStep(rolloutState{Release: "R2"}, counts, time.Minute, 1, 1, now)
+	require.Equal(t, rolloutState{Release: "R2", Ready: now}, s)
+	require.True(t, paused)
+
+	s, paused = rolloutStep(s, counts, time.Minute, 1, 1, now.Add(30*time.Second))
+	require.Equal(t, rolloutState{Release: "R2", Ready: now}, s)
+	require.True(t, paused)
+
+	// pause passed, on to the next batch
+	s, paused = rolloutStep(s, counts, time.Minute, 1, 1, now.Add(time.Minute))
+	require.Equal(t, rolloutState{Release: "R2", Batch: 1}, s)
+	require.False(t, paused)
+
+	// the last batch is never paused
+	s, paused = rolloutStep(rolloutState{Release: "R2", Batch: 2}, counts, time.Minute, 10, 10, now)
+	require.Equal(t, rolloutState{Release: "R2", Batch: 3}, s)
+	require.False(t, paused)
+
+	s, paused = rolloutStep(s, counts, time.Minute, 10, 10, now)
+	require.Equal(t, rolloutState{Release: "R2", Batch: 3}, s)
+	require.False(t, paused)
+}
+
+func TestReleaseRolloutBatches(t *testing.T) {
+	p := &Provider{
+		Engine:  &mock.TestEngine{},
+		Name:    "rack1",
+		Cluster: fake.NewSimpleClientset(),
+	}
+
+	_, err := p.Cluster.CoreV1().Namespaces().Create(context.TODO(), &ac.Namespace{
+		ObjectMeta: am.ObjectMeta{Name: "rack1-app1", Labels: map[string]string{"name": "app1"}},
+	}, am.CreateOptions{})
+	require.NoError(t, err)
+
+	replicas := int32(4)
+
+	_, err = p.Cluster.AppsV1().Deployments("rack1-app1").Create(context.TODO(), &appsv1.Deployment{
+		ObjectMeta: am.ObjectMeta{
+			Name:        "web",
+			Labels:      map[string]string{"type": "service"},
+			Annotations: map[string]string{rolloutBatchesAnnotation: "1,100%", rolloutPauseAnnotation: "1m"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: ac.PodTemplateSpec{ObjectMeta: am.ObjectMeta{Labels: map[string]string{"release": "R2", "service": "web"}}},
+		},
+		Status: appsv1.DeploymentStatus{UpdatedReplicas: 1},
+	}, am.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = p.Cluster.AppsV1().ReplicaSets("rack1-app1").Create(context.TODO(), &appsv1.ReplicaSet{
+		ObjectMeta: am.ObjectMeta{Name: "web-r2", Labels: map[string]string{"release": "R2", "service": "web"}},
+		Status:     appsv1.ReplicaSetStatus{AvailableReplicas: 1},
+	}, am.CreateOptions{})
+	require.NoError(t, err)
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	deployment := func() *appsv1.Deployment {
+		d, err := p.Cluster.AppsV1().Deployments("rack1-app1").Get(context.TODO(), "web", am.GetOptions{})
+		require.NoError(t, err)
+		return d
+	}
+
+	require.NoError(t, p.releaseRolloutBatches(context.TODO(), "app1", "R2", now))
+	require.True(t, deployment().Spec.Paused)
+	require.Equal(t, "R2/0/2026-10-18T12:00:00Z", deployment().Annotations[rolloutStateAnnotation])
+
+	require.NoError(t, p.releaseRolloutBatches(context.TODO(), "app1", "R2", now.Add(time.Minute)))
+	require.False(t, deployment().Spec.Paused)
+	require.Equal(t, "R2/1/", deployment().Annotations[rolloutStateAnnotation])
+
+	// other releases are left alone
+	require.NoError(t, p.releaseRolloutBatches(context.TODO(), "app1", "R3", now.Add(2*time.Minute)))
+	require.Equal(t, "R2/1/", deployment().Annotations[rolloutStateAnnotation])
+}
+
+func TestReleasePauseResume(t *testing.T) {
+	p := &Provider{
+		Engine:  &mock.TestEngine{},
+		Name:    "rack1",
+		Cluster: fake.NewSimpleClientset(),
+	}
+
+	_, err := p.Cluster.CoreV1().Namespaces().Create(context.TODO(), &ac.Namespace{
+		ObjectMeta: am.ObjectMeta{
+			Name:        "rack1-app1",
+			Labels:      map[string]string{"name": "app1"},
+			Annotations: map[string]string{"convox.com/app-status": "Running"},
+		},
+	}, am.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = p.Cluster.AppsV1().Deployments("rack1-app1").Create(context.TODO(), &appsv1.Deployment{
+		ObjectMeta: am.ObjectMeta{Name: "web", Labels: map[string]string{"type": "service"}},
+	}, am.CreateOptions{})
+	require.NoError(t, err)
+
+	require.EqualError(t, p.ReleasePause("app1"), "app app1 has no rollout in progress")
+	require.EqualError(t, p.ReleaseResume("app1"), "the rollout of app app1 is not paused")
+
+	ns, err := p.Cluster.CoreV1().Namespaces().Get(context.TODO(), "rack1-app1", am.GetOptions{})
+	require.NoError(t, err)
+	ns.Annotations["convox.com/app-status"] = "Updating"
+	_, err = p.Cluster.CoreV1().Namespaces().Update(context.TODO(), ns, am.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, p.ReleasePause("app1"))
+	require.EqualError(t, p.ReleasePause("app1"), "the rollout of app app1 is already paused")
+
+	d, err := p.Cluster.AppsV1().Deployments("rack1-app1").Get(context.TODO(), "web", am.GetOptions{})
+	require.NoError(t, err)
+	require.True(t, d.Spec.Paused)
+
+	require.NoError(t, p.ReleaseResume("app1"))
+
+	d, err = p.Cluster.AppsV1().Deployments("rack1-app1").Get(context.TODO(), "web", am.GetOptions{})
+	require.NoError(t, err)
+	require.False(t, d.Spec.Paused)
+
+	ns, err = p.Cluster.CoreV1().Namespaces().Get(context.TODO(), "rack1-app1", am.GetOptions{})
+	require.NoError(t, err)
+	require.NotContains(t, ns.Annotations, rolloutPausedAnnotation)
+}
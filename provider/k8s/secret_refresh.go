@@ -0,0 +1,250 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/secrets"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	ae "k8s.io/apimachinery/pkg/api/errors"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	secretRefreshDefault      = 5 * time.Minute
+	secretRefreshLeaseName    = "convox-secret-refresh"
+	secretRefreshTickInterval = 30 * time.Second
+)
+
+// runSecretRefresh resolves the secret references of each app again on the
+// interval of its SecretRefresh parameter. It runs on the elected leader
+// only.
+func (p *Provider) runSecretRefresh(ctx context.Context) {
+	fmt.Printf("ns=secret_refresh at=start\n")
+
+	tick := time.NewTicker(secretRefreshTickInterval)
+	defer tick.Stop()
+
+	last := map[string]time.Time{}
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Printf("ns=secret_refresh at=stop\n")
+			return
+		case now := <-tick.C:
+			p.secretRefreshTick(now, last)
+		}
+	}
+}
+
+// secretRefreshTick refreshes the apps whose interval has passed since the
+// refresh recorded in last.
+func (p *Provider) secretRefreshTick(now time.Time, last map[string]time.Time) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("ns=secret_refresh at=error kind=panic_recovered recovered=%v\n", r)
+		}
+	}()
+
+	as, err := p.AppList()
+	if err != nil {
+		fmt.Printf("ns=secret_refresh at=error kind=app_list err=%q\n", err)
+		return
+	}
+
+	for i := range as {
+		a := as[i]
+
+		if a.Release == "" {
+			continue
+		}
+
+		interval := secretRefreshInterval(a.Parameters[structs.AppParamSecretRefresh])
+
+		if interval == 0 || now.Sub(last[a.Name]) < interval {
+			continue
+		}
+
+		last[a.Name] = now
+
+		if err := p.secretRefresh(&a); err != nil {
+			fmt.Printf("ns=secret_refresh at=error app=%s err=%q\n", a.Name, err)
+		}
+	}
+}
+
+// secretRefresh writes the current values of the secrets referenced by the
+// release env of a to the env secrets of its services.
+func (p *Provider) secretRefresh(a *structs.App) error {
+	r, err := p.ReleaseGet(a.Name, a.Release)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	e, err := structs.NewEnvironment([]byte(r.Env))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	refs := map[string]string{}
+
+	for _, k := range secrets.Refs(e) {
+		refs[k] = e[k]
+	}
+
+	if len(refs) == 0 {
+		return nil
+	}
+
+	if err := secretCheck(a, refs); err != nil {
+		return errors.WithStack(err)
+	}
+
+	values, err := p.Secrets.Resolve(refs)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	m, _, err := common.AppManifest(p, a.Name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	restart := a.Parameters[structs.AppParamSecretRestart] == "true"
+
+	for _, s := range m.Services {
+		changed, err := p.secretRefreshService(a.Name, s.Name, values)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if !changed {
+			continue
+		}
+
+		fmt.Printf("ns=secret_refresh at=changed app=%s service=%s restart=%t\n", a.Name, s.Name, restart)
+
+		if restart {
+			if err := p.ServiceRestart(a.Name, s.Name); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// secretRefreshService updates the keys of values the env secret of a
+// service already holds and reports whether any of them changed.
+func (p *Provider) secretRefreshService(app, service string, values map[string]string) (bool, error) {
+	ss := p.Cluster.CoreV1().Secrets(p.AppNamespace(app))
+
+	s, err := ss.Get(context.TODO(), fmt.Sprintf("env-%s", service), am.GetOptions{})
+	if ae.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	changed := false
+
+	for k, v := range values {
+		if cur, ok := s.Data[k]; ok && string(cur) != v {
+			s.Data[k] = []byte(v)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return false, nil
+	}
+
+	if _, err := ss.Update(context.TODO(), s, am.UpdateOptions{}); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return true, nil
+}
+
+// secretRefreshInterval parses the SecretRefresh app parameter.
+func secretRefreshInterval(v string) time.Duration {
+	if v == "" {
+		return secretRefreshDefault
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0
+	}
+
+	return d
+}
+
+// validateSecretParameter checks the value of the secret refresh app
+// parameter k.
+func validateSecretParameter(k, v string) error {
+	if v == "" {
+		return nil
+	}
+
+	switch k {
+	case structs.AppParamSecretRefresh:
+		if v == "0" {
+			return nil
+		}
+
+		if d, err := time.ParseDuration(v); err != nil || d < time.Minute {
+			return structs.ErrBadRequest("%s must be a duration of at least 1m, or 0 to turn it off", k)
+		}
+	case structs.AppParamSecretRestart:
+		if v != "true" && v != "false" {
+			return structs.ErrBadRequest("%s must be true or false", k)
+		}
+	case structs.AppParamSecretPaths:
+		if _, err := secrets.ParsePrefixes(v); err != nil {
+			return structs.ErrBadRequest("%s: %s", k, err)
+		}
+	}
+
+	return nil
+}
+
+// releaseSecretCheck refuses a release env of app that references a secret
+// outside the SecretPaths of the app.
+func (p *Provider) releaseSecretCheck(app, env string) error {
+	e, err := structs.NewEnvironment([]byte(env))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(secrets.Refs(e)) == 0 {
+		return nil
+	}
+
+	a, err := p.AppGet(app)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	return secretCheck(a, e)
+}
+
+// secretCheck returns an error when env references a secret outside the
+// SecretPaths of a.
+func secretCheck(a *structs.App, env map[string]string) error {
+	ps, err := secrets.ParsePrefixes(a.Parameters[structs.AppParamSecretPaths])
+	if err != nil {
+		return structs.ErrBadRequest("%s: %s", structs.AppParamSecretPaths, err)
+	}
+
+	if err := secrets.Check(env, ps); err != nil {
+		return structs.ErrBadRequest("%s, an admin can allow it with the %s app parameter", err, structs.AppParamSecretPaths)
+	}
+
+	return nil
+}
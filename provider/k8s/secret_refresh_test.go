@@ -0,0 +1,137 @@
+package k8s
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/convox/convox/pkg/mock"
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/secrets"
+	"github.com/convox/convox/pkg/structs"
+	ca "github.com/convox/convox/provider/k8s/pkg/apis/convox/v1"
+	cvfake "github.com/convox/convox/provider/k8s/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	ac "k8s.io/api/core/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSecretRefresh(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "db.yml"), []byte("password: new\n"), 0600))
+
+	p := &Provider{
+		Engine:  &mock.TestEngine{},
+		Name:    "rack1",
+		Cluster: fake.NewSimpleClientset(),
+		Convox:  cvfake.NewSimpleClientset(),
+		Secrets: &secrets.Resolver{Stores: map[string]secrets.Store{secrets.StoreFile: &secrets.File{Root: dir}}},
+	}
+
+	_, err := p.Cluster.CoreV1().Namespaces().Create(context.TODO(), &ac.Namespace{
+		ObjectMeta: am.ObjectMeta{
+			Name:        "rack1-app1",
+			Annotations: map[string]string{"convox.com/app-release": "R1", "convox.com/app-status": "running"},
+			Labels:      map[string]string{"name": "app1", "rack": "rack1", "system": "convox", "type": "app"},
+		},
+	}, am.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = p.Convox.ConvoxV1().Releases("rack1-app1").Create(&ca.Release{
+		ObjectMeta: am.ObjectMeta{Name: "r1"},
+		Spec: ca.ReleaseSpec{
+			Created:  "20261018.120000.000000000",
+			Env:      "DB_PASS=secretref://file/db.yml#password\nFOO=bar",
+			Manifest: "services:\n  web:\n    port: 3000\n  worker:\n    command: work\n",
+		},
+	})
+	require.NoError(t, err)
+
+	for _, s := range []string{"web", "worker"} {
+		_, err = p.Cluster.CoreV1().Secrets("rack1-app1").Create(context.TODO(), &ac.Secret{
+			ObjectMeta: am.ObjectMeta{Name: "env-" + s},
+			Data:       map[string][]byte{"DB_PASS": []byte("old"), "FOO": []byte("bar")},
+		}, am.CreateOptions{})
+		require.NoError(t, err)
+
+		_, err = p.Cluster.AppsV1().Deployments("rack1-app1").Create(context.TODO(), &appsv1.Deployment{
+			ObjectMeta: am.ObjectMeta{Name: s},
+		}, am.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	a := &structs.App{Name: "app1", Release: "R1", Parameters: map[string]string{structs.AppParamSecretRestart: "true"}}
+
+	require.EqualError(t, p.secretRefresh(a), "env DB_PASS: secret reference not allowed: secretref://file/db.yml#password, an admin can allow it with the SecretPaths app parameter")
+
+	a.Parameters[structs.AppParamSecretPaths] = "file/db.yml"
+
+	require.NoError(t, p.secretRefresh(a))
+
+	for _, s := range []string{"web", "worker"} {
+		sec, err := p.Cluster.CoreV1().Secrets("rack1-app1").Get(context.TODO(), "env-"+s, am.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, map[string][]byte{"DB_PASS": []byte("new"), "FOO": []byte("bar")}, sec.Data)
+
+		d, err := p.Cluster.AppsV1().Deployments("rack1-app1").Get(context.TODO(), s, am.GetOptions{})
+		require.NoError(t, err)
+		require.NotEmpty(t, d.Spec.Template.Annotations["convox.com/restart"])
+	}
+
+	// unchanged values leave the services alone
+	changed, err := p.secretRefreshService("app1", "web", map[string]string{"DB_PASS": "new"})
+	require.NoError(t, err)
+	require.False(t, changed)
+}
+
+// secretEngine accepts the SecretPaths app parameter, which the test engine
+// does not know about.
+type secretEngine struct {
+	*mock.TestEngine
+}
+
+func (secretEngine) AppParameters() map[string]string {
+	return map[string]string{structs.AppParamSecretPaths: ""}
+}
+
+func TestReleaseCreateSecretPaths(t *testing.T) {
+	p := &Provider{
+		Engine:  secretEngine{&mock.TestEngine{}},
+		Name:    "rack1",
+		Cluster: fake.NewSimpleClientset(),
+		Convox:  cvfake.NewSimpleClientset(),
+	}
+
+	_, err := p.Cluster.CoreV1().Namespaces().Create(context.TODO(), &ac.Namespace{
+		ObjectMeta: am.ObjectMeta{
+			Name:        "rack1-app1",
+			Annotations: map[string]string{"convox.com/app-release": "R1", "convox.com/app-status": "running", "convox.com/params": `{"SecretPaths":"aws-sm/prod/app1"}`},
+			Labels:      map[string]string{"name": "app1", "rack": "rack1", "system": "convox", "type": "app"},
+		},
+	}, am.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = p.ReleaseCreate("app1", structs.ReleaseCreateOptions{Env: options.String("DB_PASS=aws-sm://prod/app2/db#password")})
+	require.EqualError(t, err, "env DB_PASS: secret reference not allowed: aws-sm://prod/app2/db#password, an admin can allow it with the SecretPaths app parameter")
+
+	r, err := p.ReleaseCreate("app1", structs.ReleaseCreateOptions{Env: options.String("DB_PASS=aws-sm://prod/app1/db#password\nFOO=bar")})
+	require.NoError(t, err)
+	require.Equal(t, "DB_PASS=aws-sm://prod/app1/db#password\nFOO=bar", r.Env)
+}
+
+func TestSecretParameters(t *testing.T) {
+	require.Equal(t, secretRefreshDefault, secretRefreshInterval(""))
+	require.Equal(t, int64(0), int64(secretRefreshInterval("0")))
+	require.Equal(t, "1h0m0s", secretRefreshInterval("1h").String())
+
+	require.NoError(t, validateSecretParameter(structs.AppParamSecretRefresh, "0"))
+	require.NoError(t, validateSecretParameter(structs.AppParamSecretRefresh, "10m"))
+	require.EqualError(t, validateSecretParameter(structs.AppParamSecretRefresh, "10s"), "SecretRefresh must be a duration of at least 1m, or 0 to turn it off")
+	require.NoError(t, validateSecretParameter(structs.AppParamSecretRestart, "true"))
+	require.EqualError(t, validateSecretParameter(structs.AppParamSecretRestart, "yes"), "SecretRestart must be true or false")
+	require.NoError(t, validateSecretParameter(structs.AppParamSecretPaths, "aws-sm/prod/app1,vault/secret/data/app1"))
+	require.EqualError(t, validateSecretParameter(structs.AppParamSecretPaths, "s3/bucket"), "SecretPaths: invalid secret path: s3/bucket")
+}
@@ -0,0 +1,201 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	ae "k8s.io/apimachinery/pkg/api/errors"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	systemTokenConfigMap      = "system-tokens"
+	systemTokenDefaultExpires = "30d"
+	systemTokenMaxExpires     = 8760 * time.Hour
+	systemTokenUsedInterval   = time.Minute
+	systemTokenWriteRetries   = 5
+)
+
+var systemTokenNamePattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]{0,62}$`)
+
+func (p *Provider) SystemTokenCreate(opts structs.SystemTokenCreateOptions) (*structs.SystemToken, error) {
+	name := common.DefaultString(opts.Name, "")
+	if !systemTokenNamePattern.MatchString(name) {
+		return nil, errors.WithStack(structs.ErrBadRequest("invalid token name: %q", name))
+	}
+
+	role, err := structs.SystemTokenRole(common.DefaultString(opts.Role, "write"))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	expires, err := common.ParseDuration(common.DefaultString(opts.Expires, systemTokenDefaultExpires))
+	if err != nil {
+		return nil, errors.WithStack(structs.ErrBadRequest("%s", err))
+	}
+	if expires <= 0 || expires > systemTokenMaxExpires {
+		return nil, errors.WithStack(structs.ErrBadRequest("expires must be greater than zero and at most %s", systemTokenMaxExpires))
+	}
+
+	for _, id := range opts.Policies {
+		if _, err := p.AccessPolicyGet(id); err != nil {
+			return nil, err
+		}
+	}
+
+	t := structs.NewSystemToken(name)
+	t.Role = role
+	t.Apps = opts.Apps
+	t.Policies = opts.Policies
+	t.CreatedBy = p.ContextActor()
+	t.Expires = t.Created.Add(expires)
+
+	err = p.systemTokenMutate(func(data map[string]string) (bool, error) {
+		ts, err := systemTokensFromData(data)
+		if err != nil {
+			return false, errors.WithStack(err)
+		}
+
+		for _, et := range ts {
+			if et.Name == name && et.Revoked.IsZero() && et.Expires.After(time.Now()) {
+				return false, errors.WithStack(structs.ErrConflict("token already exists: %s", name))
+			}
+		}
+
+		return true, systemTokenSave(data, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (p *Provider) SystemTokenList() (structs.SystemTokens, error) {
+	cm, err := p.systemConfigMap(systemTokenConfigMap)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	ts, err := systemTokensFromData(cm.Data)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sort.Slice(ts, ts.Less)
+
+	return ts, nil
+}
+
+func (p *Provider) SystemTokenRevoke(id string) error {
+	return p.systemTokenUpdate(id, func(t *structs.SystemToken) bool {
+		if !t.Revoked.IsZero() {
+			return false
+		}
+		t.Revoked = time.Now().UTC()
+		return true
+	})
+}
+
+// SystemTokenUsed records when a token was last used. Writes are skipped
+// while the recorded time is recent so that every api instance does not
+// rewrite the token map on every request.
+func (p *Provider) SystemTokenUsed(id string) error {
+	return p.systemTokenUpdate(id, func(t *structs.SystemToken) bool {
+		if time.Since(t.LastUsed) < systemTokenUsedInterval {
+			return false
+		}
+		t.LastUsed = time.Now().UTC()
+		return true
+	})
+}
+
+func (p *Provider) systemTokenUpdate(id string, fn func(t *structs.SystemToken) bool) error {
+	return p.systemTokenMutate(func(data map[string]string) (bool, error) {
+		raw, ok := data[id]
+		if !ok {
+			return false, errors.WithStack(structs.ErrNotFound("no such token: %s", id))
+		}
+
+		var t structs.SystemToken
+
+		if err := json.Unmarshal([]byte(raw), &t); err != nil {
+			return false, errors.WithStack(err)
+		}
+
+		if !fn(&t) {
+			return false, nil
+		}
+
+		return true, systemTokenSave(data, &t)
+	})
+}
+
+// systemTokenMutate applies fn to a fresh read of the token map and writes
+// it back, reading and applying it again when another writer got there
+// first so that concurrent creates, revokes and last-used updates are not
+// lost. fn returns false to skip the write.
+func (p *Provider) systemTokenMutate(fn func(data map[string]string) (bool, error)) error {
+	for i := 0; i < systemTokenWriteRetries; i++ {
+		cm, err := p.systemConfigMap(systemTokenConfigMap)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		write, err := fn(cm.Data)
+		if err != nil {
+			return err
+		}
+		if !write {
+			return nil
+		}
+
+		_, err = p.Cluster.CoreV1().ConfigMaps(p.Namespace).Update(context.TODO(), cm, am.UpdateOptions{})
+		if ae.IsConflict(err) {
+			continue
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return nil
+	}
+
+	return errors.WithStack(fmt.Errorf("could not update %s: too many conflicts", systemTokenConfigMap))
+}
+
+func systemTokenSave(data map[string]string, t *structs.SystemToken) error {
+	t.Token = ""
+
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	data[t.Id] = string(raw)
+
+	return nil
+}
+
+func systemTokensFromData(data map[string]string) (structs.SystemTokens, error) {
+	ts := structs.SystemTokens{}
+
+	for _, raw := range data {
+		var t structs.SystemToken
+
+		if err := json.Unmarshal([]byte(raw), &t); err != nil {
+			return nil, err
+		}
+
+		ts = append(ts, t)
+	}
+
+	return ts, nil
+}
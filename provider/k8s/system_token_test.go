@@ -0,0 +1,140 @@
+package k8s_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/options"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/convox/convox/provider/k8s"
+	"github.com/stretchr/testify/require"
+	ac "k8s.io/api/core/v1"
+	ae "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestSystemTokenCreate(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		tk, err := p.SystemTokenCreate(structs.SystemTokenCreateOptions{
+			Apps:    []string{"app1"},
+			Expires: options.String("7d"),
+			Name:    options.String("ci"),
+			Role:    options.String("read"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "ci", tk.Name)
+		require.Equal(t, structs.ConvoxRoleRead, tk.Role)
+		require.Equal(t, []string{"app1"}, tk.Apps)
+		require.Equal(t, 7*24*time.Hour, tk.Expires.Sub(tk.Created))
+
+		_, err = p.SystemTokenCreate(structs.SystemTokenCreateOptions{Name: options.String("ci")})
+		require.EqualError(t, err, "token already exists: ci")
+
+		ts, err := p.SystemTokenList()
+		require.NoError(t, err)
+		require.Len(t, ts, 1)
+		require.Equal(t, tk.Id, ts[0].Id)
+		require.Empty(t, ts[0].Token)
+	})
+}
+
+func TestSystemTokenCreateInvalid(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		_, err := p.SystemTokenCreate(structs.SystemTokenCreateOptions{Name: options.String("bad name")})
+		require.EqualError(t, err, "invalid token name: \"bad name\"")
+
+		_, err = p.SystemTokenCreate(structs.SystemTokenCreateOptions{Name: options.String("ci"), Expires: options.String("400d")})
+		require.EqualError(t, err, "expires must be greater than zero and at most 8760h0m0s")
+
+		_, err = p.SystemTokenCreate(structs.SystemTokenCreateOptions{Name: options.String("ci"), Policies: []string{"missing"}})
+		require.EqualError(t, err, "no such access policy: missing")
+	})
+}
+
+func TestSystemTokenRevoke(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		tk, err := p.SystemTokenCreate(structs.SystemTokenCreateOptions{Name: options.String("ci")})
+		require.NoError(t, err)
+
+		require.NoError(t, p.SystemTokenRevoke(tk.Id))
+
+		ts, err := p.SystemTokenList()
+		require.NoError(t, err)
+		require.Len(t, ts, 1)
+		require.False(t, ts[0].Revoked.IsZero())
+
+		_, err = p.SystemTokenCreate(structs.SystemTokenCreateOptions{Name: options.String("ci")})
+		require.NoError(t, err)
+
+		err = p.SystemTokenRevoke("TMISSING")
+		require.EqualError(t, err, "no such token: TMISSING")
+	})
+}
+
+func TestSystemTokenRevokeConflict(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		tk, err := p.SystemTokenCreate(structs.SystemTokenCreateOptions{Name: options.String("ci")})
+		require.NoError(t, err)
+
+		kk := p.Cluster.(*fake.Clientset)
+
+		conflicts := 0
+
+		// another writer creates a token between the read and the write
+		kk.PrependReactor("update", "configmaps", func(action ktesting.Action) (bool, runtime.Object, error) {
+			if conflicts > 0 {
+				return false, nil, nil
+			}
+
+			conflicts++
+
+			gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+			obj, err := kk.Tracker().Get(gvr, "ns1", "system-tokens")
+			require.NoError(t, err)
+
+			cm := obj.(*ac.ConfigMap).DeepCopy()
+			cm.Data["TDEPLOY0000"] = `{"id":"TDEPLOY0000","name":"deploy"}`
+
+			require.NoError(t, kk.Tracker().Update(gvr, cm, "ns1"))
+
+			return true, nil, ae.NewConflict(schema.GroupResource{Resource: "configmaps"}, "system-tokens", nil)
+		})
+
+		require.NoError(t, p.SystemTokenRevoke(tk.Id))
+		require.Equal(t, 1, conflicts)
+
+		ts, err := p.SystemTokenList()
+		require.NoError(t, err)
+		require.Len(t, ts, 2)
+
+		for _, st := range ts {
+			require.Equal(t, st.Name == "ci", !st.Revoked.IsZero())
+		}
+	})
+}
+
+func TestSystemTokenUsed(t *testing.T) {
+	testProvider(t, func(p *k8s.Provider) {
+		tk, err := p.SystemTokenCreate(structs.SystemTokenCreateOptions{Name: options.String("ci")})
+		require.NoError(t, err)
+
+		require.NoError(t, p.SystemTokenUsed(tk.Id))
+
+		ts, err := p.SystemTokenList()
+		require.NoError(t, err)
+		require.False(t, ts[0].LastUsed.IsZero())
+
+		used := ts[0].LastUsed
+
+		// recent uses are not written again
+		require.NoError(t, p.SystemTokenUsed(tk.Id))
+
+		ts, err = p.SystemTokenList()
+		require.NoError(t, err)
+		require.Equal(t, used, ts[0].LastUsed)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	cv "github.com/convox/convox/provider/k8s/pkg/client/clientset/versioned"
 	"github.com/convox/logger"
 	yaml "gopkg.in/yaml.v2"
+	ae "k8s.io/apimachinery/pkg/api/errors"
 	am "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -187,6 +188,9 @@ func (a *releaseCleaner) appReleaseAndBuildCleanup(app *structs.App) error {
 			a.logger.Errorf("failed to delete release '%s': %s", rs[i].Name, err)
 			return err
 		}
+		if err := a.cluster.CoreV1().ConfigMaps(appNamespace).Delete(a.ctx, timelineName(rs[i].Name), am.DeleteOptions{}); err != nil && !ae.IsNotFound(err) {
+			a.logger.Errorf("failed to delete timeline of release '%s': %s", rs[i].Name, err)
+		}
 		time.Sleep(50 * time.Millisecond) // to avoid rate limit
 	}
 
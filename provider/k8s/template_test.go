@@ -84,8 +84,8 @@ func TestRenderTemplateServiceSecurityContext(t *testing.T) {
 			"Annotations":    svc.AnnotationsMap(),
 			"App":            a,
 			"Environment":    map[string]string{},
-			"MaxSurge":       100,
-			"MaxUnavailable": 100,
+			"MaxSurge":       `"100%"`,
+			"MaxUnavailable": `"100%"`,
 			"Namespace":      "ns",
 			"Password":       "pass",
 			"Rack":           "rack",
@@ -193,8 +193,8 @@ func TestRenderTemplateService(t *testing.T) {
 		"Annotations":    m.Services[0].AnnotationsMap(),
 		"App":            a,
 		"Environment":    map[string]string{},
-		"MaxSurge":       100,
-		"MaxUnavailable": 100,
+		"MaxSurge":       `"100%"`,
+		"MaxUnavailable": `"100%"`,
 		"Namespace":      "ns",
 		"Password":       "pass",
 		"Rack":           "rack",
@@ -243,8 +243,8 @@ func gpuTemplateFixture(t *testing.T, src string) (*Provider, map[string]interfa
 		"Annotations":    s.AnnotationsMap(),
 		"App":            &structs.App{Name: "test-app"},
 		"Environment":    map[string]string{},
-		"MaxSurge":       100,
-		"MaxUnavailable": 100,
+		"MaxSurge":       `"100%"`,
+		"MaxUnavailable": `"100%"`,
 		"Namespace":      "ns",
 		"Password":       "pass",
 		"Rack":           "rack",
@@ -603,8 +603,8 @@ func TestRenderTemplateServiceHealthPort(t *testing.T) {
 			"Annotations":    svc.AnnotationsMap(),
 			"App":            &structs.App{Name: "test-app"},
 			"Environment":    map[string]string{},
-			"MaxSurge":       100,
-			"MaxUnavailable": 100,
+			"MaxSurge":       `"100%"`,
+			"MaxUnavailable": `"100%"`,
 			"Namespace":      "ns",
 			"Password":       "pass",
 			"Rack":           "rack",
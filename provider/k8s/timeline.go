@@ -0,0 +1,432 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/convox/convox/pkg/common"
+	"github.com/convox/convox/pkg/structs"
+	"github.com/pkg/errors"
+	ac "k8s.io/api/core/v1"
+	ae "k8s.io/apimachinery/pkg/api/errors"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// timeline events of a release are appended as json lines to a configmap in
+// the app namespace. The namespace annotations point the controllers at the
+// release being promoted and record when its rollout ended so the drain of
+// old replicas that follows is still recorded.
+const (
+	timelineAnnotation      = "convox.com/release-timeline"
+	timelineEndedAnnotation = "convox.com/release-timeline-ended"
+	timelineDrainGrace      = 5 * time.Minute
+	timelineEntriesKey      = "entries"
+	timelineMaxBytes        = 512 * 1024
+	timelineWriteRetries    = 5
+)
+
+var (
+	// timelineActiveStatuses are the atom statuses of a rollout in progress
+	timelineActiveStatuses = map[string]bool{
+		"Cancelled": true,
+		"Deadline":  true,
+		"Error":     true,
+		"Pending":   true,
+		"Rollback":  true,
+		"Updating":  true,
+	}
+
+	// timelinePodReasons are the pod events that make up a rollout
+	timelinePodReasons = map[string]bool{
+		"BackOff":          true,
+		"Failed":           true,
+		"FailedScheduling": true,
+		"Killing":          true,
+		"Pulled":           true,
+		"Pulling":          true,
+		"Started":          true,
+		"Unhealthy":        true,
+	}
+)
+
+func (p *Provider) ReleaseTimeline(app, id string) (*structs.ReleaseTimeline, error) {
+	if _, err := p.ReleaseGet(app, id); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cm, err := p.Cluster.CoreV1().ConfigMaps(p.AppNamespace(app)).Get(context.TODO(), timelineName(id), am.GetOptions{})
+	if ae.IsNotFound(err) {
+		return nil, structs.ErrNotFound("no timeline recorded for release: %s", id)
+	}
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	es := []structs.ReleaseTimelineEvent{}
+
+	s := bufio.NewScanner(strings.NewReader(cm.Data[timelineEntriesKey]))
+	s.Buffer(make([]byte, 0, 64*1024), timelineMaxBytes)
+
+	for s.Scan() {
+		var e structs.ReleaseTimelineEvent
+
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			p.logger.Errorf("ns=timeline at=get release=%s error=%q", id, err)
+			continue
+		}
+
+		es = append(es, e)
+	}
+
+	return releaseTimelineBuild(app, id, es), nil
+}
+
+// releaseTimelineBuild sorts the events of a timeline and derives its status
+// and phases from them.
+func releaseTimelineBuild(app, id string, es []structs.ReleaseTimelineEvent) *structs.ReleaseTimeline {
+	sort.SliceStable(es, func(i, j int) bool { return es[i].Time.Before(es[j].Time) })
+
+	t := &structs.ReleaseTimeline{App: app, Release: id, Events: es, Phases: []structs.ReleaseTimelinePhase{}}
+
+	if len(es) == 0 {
+		return t
+	}
+
+	t.Started = es[0].Time
+
+	for _, e := range es {
+		switch e.Source {
+		case structs.TimelineSourceAtom:
+			t.Status = e.Reason
+
+			if !timelineActiveStatuses[e.Reason] {
+				t.Ended = e.Time
+			}
+		case structs.TimelineSourcePromote:
+			t.Status = e.Reason
+			t.Ended = time.Time{}
+		case structs.TimelineSourceWatcher:
+			t.Status = e.Reason
+			t.Ended = e.Time
+		}
+	}
+
+	atom := func(e structs.ReleaseTimelineEvent, reasons ...string) bool {
+		return e.Source == structs.TimelineSourceAtom && (len(reasons) == 0 || timelineReason(e, reasons...))
+	}
+
+	terminal := func(e structs.ReleaseTimelineEvent) bool {
+		return atom(e) && !timelineActiveStatuses[e.Reason]
+	}
+
+	// a rollout stops waiting on readiness when it completes or fails
+	settled := func(e structs.ReleaseTimelineEvent) bool {
+		return atom(e) && !timelineReason(e, "Pending", "Updating")
+	}
+
+	pod := func(current bool, reasons ...string) func(structs.ReleaseTimelineEvent) bool {
+		return func(e structs.ReleaseTimelineEvent) bool {
+			return e.Source == structs.TimelineSourcePod && e.Release != "" && (e.Release == id) == current && timelineReason(e, reasons...)
+		}
+	}
+
+	t.Phases = timelineAppendPhase(t.Phases, "dependencies", es,
+		func(e structs.ReleaseTimelineEvent) bool { return atom(e, "Pending") },
+		func(e structs.ReleaseTimelineEvent) bool { return atom(e) && e.Reason != "Pending" }, false)
+
+	t.Phases = timelineAppendPhase(t.Phases, "image-pull", es, pod(true, "Pulling"), pod(true, "Pulled"), true)
+
+	t.Phases = timelineAppendPhase(t.Phases, "readiness", es, pod(true, "Started"), settled, false)
+
+	t.Phases = timelineAppendPhase(t.Phases, "drain", es, pod(false, "Killing"), pod(false, "Deleted"), true)
+
+	t.Phases = timelineAppendPhase(t.Phases, "rollback", es,
+		func(e structs.ReleaseTimelineEvent) bool { return atom(e, "Rollback") }, terminal, false)
+
+	return t
+}
+
+// timelineAppendPhase appends the phase that starts at the first event
+// matching start and ends at the first following event matching end, or the
+// last one when last is set.
+func timelineAppendPhase(ps []structs.ReleaseTimelinePhase, name string, es []structs.ReleaseTimelineEvent, start, end func(structs.ReleaseTimelineEvent) bool, last bool) []structs.ReleaseTimelinePhase {
+	var ph *structs.ReleaseTimelinePhase
+
+	for _, e := range es {
+		if ph == nil {
+			if start(e) {
+				ph = &structs.ReleaseTimelinePhase{Name: name, Started: e.Time}
+			}
+			continue
+		}
+
+		if end(e) {
+			ph.Ended = e.Time
+
+			if !last {
+				break
+			}
+		}
+	}
+
+	if ph == nil {
+		return ps
+	}
+
+	if !ph.Ended.IsZero() {
+		ph.Duration = ph.Ended.Sub(ph.Started)
+	}
+
+	return append(ps, *ph)
+}
+
+func timelineReason(e structs.ReleaseTimelineEvent, reasons ...string) bool {
+	for _, r := range reasons {
+		if e.Reason == r {
+			return true
+		}
+	}
+
+	return false
+}
+
+func timelineName(release string) string {
+	return fmt.Sprintf("timeline-%s", strings.ToLower(release))
+}
+
+// releaseTimelineStart points the app at the timeline of release id and
+// records the start of its promote. A hold left over from an earlier
+// rollout is cleared as well.
+func (p *Provider) releaseTimelineStart(app, id, message string) {
+	patch, err := patchBytes(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				rolloutPausedAnnotation: nil,
+				timelineAnnotation:      id,
+				timelineEndedAnnotation: nil,
+			},
+		},
+	})
+	if err != nil {
+		fmt.Printf("ns=timeline at=start app=%s release=%s error=%q\n", app, id, err)
+		return
+	}
+
+	if _, err := p.Cluster.CoreV1().Namespaces().Patch(context.TODO(), p.AppNamespace(app), types.MergePatchType, patch, am.PatchOptions{}); err != nil {
+		fmt.Printf("ns=timeline at=start app=%s release=%s error=%q\n", app, id, err)
+	}
+
+	p.releaseTimelineEvent(app, id, structs.ReleaseTimelineEvent{
+		Source:  structs.TimelineSourcePromote,
+		Reason:  "Promote",
+		Message: message,
+	})
+}
+
+// releaseTimelineAtomStatus records a status change of the atom of an app
+// namespace to the timeline of the release being promoted.
+func (p *Provider) releaseTimelineAtomStatus(namespace, from, to string) {
+	_, app, id := p.releaseTimelineCurrent(namespace)
+	if id == "" {
+		return
+	}
+
+	now := time.Now().UTC()
+
+	p.releaseTimelineEvent(app, id, structs.ReleaseTimelineEvent{
+		Time:    now,
+		Source:  structs.TimelineSourceAtom,
+		Object:  "atom",
+		Reason:  to,
+		Message: fmt.Sprintf("%s => %s", common.CoalesceString(from, "None"), to),
+	})
+
+	ended := interface{}(nil)
+	if !timelineActiveStatuses[to] {
+		ended = now.Format(time.RFC3339)
+	}
+
+	patch, err := patchBytes(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{timelineEndedAnnotation: ended},
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	if _, err := p.Cluster.CoreV1().Namespaces().Patch(context.TODO(), namespace, types.MergePatchType, patch, am.PatchOptions{}); err != nil {
+		fmt.Printf("ns=timeline at=atom app=%s release=%s error=%q\n", app, id, err)
+	}
+}
+
+// releaseTimelinePodEvent records a kubernetes event of a pod to the
+// timeline of the release being promoted in its namespace.
+func (p *Provider) releaseTimelinePodEvent(e *ac.Event) {
+	if !timelinePodReasons[e.Reason] {
+		return
+	}
+
+	// most events land outside any rollout, skip the pod lookup for those
+	if !p.releaseTimelineActive(e.InvolvedObject.Namespace) {
+		return
+	}
+
+	release := ""
+
+	if pod, err := p.Cluster.CoreV1().Pods(e.InvolvedObject.Namespace).Get(context.TODO(), e.InvolvedObject.Name, am.GetOptions{}); err == nil {
+		release = pod.Labels["release"]
+	}
+
+	p.releaseTimelinePod(e.InvolvedObject.Namespace, structs.ReleaseTimelineEvent{
+		Time:    e.LastTimestamp.Time.UTC(),
+		Source:  structs.TimelineSourcePod,
+		Object:  e.InvolvedObject.Name,
+		Release: release,
+		Reason:  e.Reason,
+		Message: e.Message,
+	})
+}
+
+// releaseTimelinePodDeleted records the removal of a pod, which ends the
+// drain of an old replica.
+func (p *Provider) releaseTimelinePodDeleted(pod *ac.Pod) {
+	if pod.Labels["type"] != "service" {
+		return
+	}
+
+	p.releaseTimelinePod(pod.Namespace, structs.ReleaseTimelineEvent{
+		Time:    time.Now().UTC(),
+		Source:  structs.TimelineSourcePod,
+		Object:  pod.Name,
+		Release: pod.Labels["release"],
+		Reason:  "Deleted",
+	})
+}
+
+// releaseTimelinePod records a pod event while a rollout is in progress, and
+// events of old replicas for a while after it ends.
+func (p *Provider) releaseTimelinePod(namespace string, e structs.ReleaseTimelineEvent) {
+	ns, app, id := p.releaseTimelineCurrent(namespace)
+	if id == "" {
+		return
+	}
+
+	if ended := ns.Annotations[timelineEndedAnnotation]; ended != "" {
+		t, err := time.Parse(time.RFC3339, ended)
+		if err != nil || e.Release == "" || e.Release == id || time.Since(t) > timelineDrainGrace {
+			return
+		}
+	}
+
+	p.releaseTimelineEvent(app, id, e)
+}
+
+// releaseTimelineActive returns true if namespace has a timeline that can
+// still take pod events.
+func (p *Provider) releaseTimelineActive(namespace string) bool {
+	ns, _, id := p.releaseTimelineCurrent(namespace)
+	if id == "" {
+		return false
+	}
+
+	if ended := ns.Annotations[timelineEndedAnnotation]; ended != "" {
+		t, err := time.Parse(time.RFC3339, ended)
+		if err != nil || time.Since(t) > timelineDrainGrace {
+			return false
+		}
+	}
+
+	return true
+}
+
+// releaseTimelineCurrent returns the app of a namespace and the release whose
+// timeline it records to, if any.
+func (p *Provider) releaseTimelineCurrent(namespace string) (*ac.Namespace, string, string) {
+	ns, err := p.GetNamespaceFromInformer(namespace)
+	if err != nil {
+		return nil, "", ""
+	}
+
+	app := common.CoalesceString(ns.Labels["app"], ns.Labels["name"])
+	if app == "" {
+		return nil, "", ""
+	}
+
+	return ns, app, ns.Annotations[timelineAnnotation]
+}
+
+// releaseTimelineEvent appends e to the timeline of release id, logging
+// failures since recording never holds up a rollout.
+func (p *Provider) releaseTimelineEvent(app, id string, e structs.ReleaseTimelineEvent) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	if err := p.releaseTimelineRecord(app, id, e); err != nil {
+		fmt.Printf("ns=timeline at=record app=%s release=%s error=%q\n", app, id, err)
+	}
+}
+
+func (p *Provider) releaseTimelineRecord(app, id string, e structs.ReleaseTimelineEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	line := string(data) + "\n"
+	cms := p.Cluster.CoreV1().ConfigMaps(p.AppNamespace(app))
+
+	for i := 0; i < timelineWriteRetries; i++ {
+		cm, err := cms.Get(context.TODO(), timelineName(id), am.GetOptions{})
+		if ae.IsNotFound(err) {
+			cm, err = cms.Create(context.TODO(), &ac.ConfigMap{
+				ObjectMeta: am.ObjectMeta{
+					Namespace: p.AppNamespace(app),
+					Name:      timelineName(id),
+					Labels: map[string]string{
+						"system":  "convox",
+						"rack":    p.Name,
+						"app":     app,
+						"type":    "timeline",
+						"release": id,
+					},
+				},
+			}, am.CreateOptions{})
+			if ae.IsAlreadyExists(err) {
+				continue
+			}
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+
+		if len(cm.Data[timelineEntriesKey])+len(line) > timelineMaxBytes {
+			return nil
+		}
+
+		cm.Data[timelineEntriesKey] += line
+
+		_, err = cms.Update(context.TODO(), cm, am.UpdateOptions{})
+		if ae.IsConflict(err) {
+			continue
+		}
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		return nil
+	}
+
+	return errors.WithStack(fmt.Errorf("could not record timeline event: too many conflicts"))
+}
@@ -0,0 +1,158 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/convox/convox/pkg/mock"
+	"github.com/convox/convox/pkg/structs"
+	ca "github.com/convox/convox/provider/k8s/pkg/apis/convox/v1"
+	cvfake "github.com/convox/convox/provider/k8s/pkg/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/require"
+	ac "k8s.io/api/core/v1"
+	am "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReleaseTimeline(t *testing.T) {
+	p := &Provider{
+		Engine:  &mock.TestEngine{},
+		Name:    "rack1",
+		Cluster: fake.NewSimpleClientset(),
+		Convox:  cvfake.NewSimpleClientset(),
+	}
+
+	_, err := p.Cluster.CoreV1().Namespaces().Create(context.TODO(), &ac.Namespace{
+		ObjectMeta: am.ObjectMeta{
+			Name:   "rack1-app1",
+			Labels: map[string]string{"name": "app1", "rack": "rack1", "system": "convox", "type": "app"},
+		},
+	}, am.CreateOptions{})
+	require.NoError(t, err)
+
+	for _, id := range []string{"r1", "r2"} {
+		_, err = p.Convox.ConvoxV1().Releases("rack1-app1").Create(&ca.Release{
+			ObjectMeta: am.ObjectMeta{Name: id},
+			Spec:       ca.ReleaseSpec{Created: "20261018.120000.000000000"},
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = p.ReleaseTimeline("app1", "R2")
+	require.EqualError(t, err, "no timeline recorded for release: R2")
+
+	// nothing is recorded before a promote points the app at a timeline
+	p.releaseTimelineAtomStatus("rack1-app1", "Running", "Pending")
+
+	_, err = p.ReleaseTimeline("app1", "R2")
+	require.Error(t, err)
+
+	for _, name := range []string{"web-old", "web-new"} {
+		release := map[string]string{"web-old": "R1", "web-new": "R2"}[name]
+
+		_, err = p.Cluster.CoreV1().Pods("rack1-app1").Create(context.TODO(), &ac.Pod{
+			ObjectMeta: am.ObjectMeta{Name: name, Labels: map[string]string{"release": release, "type": "service"}},
+		}, am.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	podEvent := func(pod, reason string) *ac.Event {
+		return &ac.Event{
+			InvolvedObject: ac.ObjectReference{Kind: "Pod", Namespace: "rack1-app1", Name: pod},
+			LastTimestamp:  am.NewTime(time.Now().UTC()),
+			Reason:         reason,
+			Message:        reason + " " + pod,
+		}
+	}
+
+	// pods are not looked up while no timeline is recording
+	kk := p.Cluster.(*fake.Clientset)
+	kk.ClearActions()
+	p.releaseTimelinePodEvent(podEvent("web-new", "Pulling"))
+
+	for _, a := range kk.Actions() {
+		require.False(t, a.Matches("get", "pods"), "unexpected pod lookup")
+	}
+
+	p.releaseTimelineStart("app1", "R2", "rolling promote from R1 by user1")
+	p.releaseTimelineAtomStatus("rack1-app1", "Running", "Pending")
+	p.releaseTimelineAtomStatus("rack1-app1", "Pending", "Updating")
+	p.releaseTimelinePodEvent(podEvent("web-new", "Pulling"))
+	p.releaseTimelinePodEvent(podEvent("web-new", "Created"))
+	p.releaseTimelinePodEvent(podEvent("web-new", "Pulled"))
+	p.releaseTimelinePodEvent(podEvent("web-new", "Started"))
+	p.releaseTimelinePodEvent(podEvent("web-new", "Unhealthy"))
+	p.releaseTimelinePodEvent(podEvent("web-old", "Killing"))
+	p.releaseTimelineAtomStatus("rack1-app1", "Updating", "Running")
+
+	// old replicas are still drained after the rollout ends, other pods are not
+	p.releaseTimelinePodEvent(podEvent("web-new", "Unhealthy"))
+	p.releaseTimelinePodDeleted(&ac.Pod{ObjectMeta: am.ObjectMeta{Name: "web-old", Namespace: "rack1-app1", Labels: map[string]string{"release": "R1", "type": "service"}}})
+
+	tl, err := p.ReleaseTimeline("app1", "R2")
+	require.NoError(t, err)
+
+	reasons := []string{}
+	for _, e := range tl.Events {
+		reasons = append(reasons, e.Source+":"+e.Reason)
+	}
+
+	require.Equal(t, []string{
+		"promote:Promote",
+		"atom:Pending",
+		"atom:Updating",
+		"pod:Pulling",
+		"pod:Pulled",
+		"pod:Started",
+		"pod:Unhealthy",
+		"pod:Killing",
+		"atom:Running",
+		"pod:Deleted",
+	}, reasons)
+
+	require.Equal(t, "Running", tl.Status)
+	require.False(t, tl.Ended.IsZero())
+	require.Equal(t, "R1", tl.Events[7].Release)
+
+	phases := []string{}
+	for _, ph := range tl.Phases {
+		phases = append(phases, ph.Name)
+		require.False(t, ph.Ended.IsZero(), ph.Name)
+	}
+
+	require.Equal(t, []string{"dependencies", "image-pull", "readiness", "drain"}, phases)
+
+	// the result of the watcher ends the timeline
+	p.releaseTimelineEvent("app1", "R2", structs.ReleaseTimelineEvent{Source: structs.TimelineSourceWatcher, Reason: "completed"})
+
+	tl, err = p.ReleaseTimeline("app1", "R2")
+	require.NoError(t, err)
+	require.Equal(t, "completed", tl.Status)
+}
+
+func TestReleaseTimelineBuildRollback(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	at := func(s int) time.Time { return start.Add(time.Duration(s) * time.Second) }
+
+	tl := releaseTimelineBuild("app1", "R2", []structs.ReleaseTimelineEvent{
+		{Time: at(0), Source: "promote", Reason: "Promote"},
+		{Time: at(1), Source: "atom", Reason: "Updating"},
+		{Time: at(5), Source: "pod", Release: "R2", Reason: "Pulling"},
+		{Time: at(9), Source: "pod", Release: "R2", Reason: "Pulled"},
+		{Time: at(10), Source: "pod", Release: "R2", Reason: "Started"},
+		{Time: at(40), Source: "pod", Release: "R2", Reason: "Unhealthy"},
+		{Time: at(300), Source: "atom", Reason: "Deadline"},
+		{Time: at(301), Source: "atom", Reason: "Rollback"},
+		{Time: at(330), Source: "atom", Reason: "Reverted"},
+		{Time: at(331), Source: "watcher", Reason: "errored", Message: "rollout-failed: Reverted"},
+	})
+
+	require.Equal(t, "errored", tl.Status)
+	require.Equal(t, 331*time.Second, tl.Ended.Sub(tl.Started))
+	require.Equal(t, []structs.ReleaseTimelinePhase{
+		{Name: "image-pull", Started: at(5), Ended: at(9), Duration: 4 * time.Second},
+		{Name: "readiness", Started: at(10), Ended: at(300), Duration: 290 * time.Second},
+		{Name: "rollback", Started: at(301), Ended: at(330), Duration: 29 * time.Second},
+	}, tl.Phases)
+}
@@ -1,5 +1,12 @@
 package k8s
 
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
 const (
 	BuildMax = 30
 )
@@ -7,6 +14,22 @@ const (
 func (p *Provider) Workers() error {
 	// go common.Tick(1*time.Hour, workerHandler(p.workerBuildCleanup))
 
+	if driftInterval() > 0 {
+		if err := RunUsingLeaderElection(context.Background(), p.Namespace, driftLeaseName, p.Cluster, p.runDriftDetection, func() {
+			fmt.Printf("ns=app_drift at=lost_leadership\n")
+		}); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if certificateMonitorInterval() > 0 {
+		if err := RunUsingLeaderElection(context.Background(), p.Namespace, certificateMonitorLeaseName, p.Cluster, p.runCertificateMonitor, func() {
+			fmt.Printf("ns=certificate_monitor at=lost_leadership\n")
+		}); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
 	return nil
 }
 
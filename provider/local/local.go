@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/convox/convox/pkg/logstorage"
 	"github.com/convox/convox/pkg/structs"
 	"github.com/convox/convox/provider/k8s"
 )
@@ -14,6 +15,8 @@ type Provider struct {
 
 	Registry string
 	Secret   string
+
+	logs *logstorage.Store
 }
 
 func FromEnv() (*Provider, error) {
@@ -26,6 +29,7 @@ func FromEnv() (*Provider, error) {
 		Provider: k,
 		Registry: os.Getenv("REGISTRY"),
 		Secret:   os.Getenv("SECRET"),
+		logs:     newLogStore(k.Storage),
 	}
 
 	k.Engine = p
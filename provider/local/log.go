@@ -5,6 +5,11 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/convox/convox/pkg/common"
@@ -12,19 +17,27 @@ import (
 	"github.com/convox/convox/pkg/structs"
 )
 
-var logs = logstorage.New()
+const (
+	logRetention = 7 * 24 * time.Hour
+	logSizeLimit = 1024 * 1024 * 1024
+)
 
 func (p *Provider) Log(app, stream string, ts time.Time, message string) error {
-	logs.Append(app, ts, stream, message)
-	logs.Append(fmt.Sprintf("%s/%s", app, stream), ts, stream, message)
+	p.logs.Append(app, ts, stream, message)
+	p.logs.Append(fmt.Sprintf("%s/%s", app, stream), ts, stream, message)
 
 	return nil
 }
 
 func (p *Provider) AppLogs(name string, opts structs.LogsOptions) (io.ReadCloser, error) {
+	q, err := logsQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	r, w := io.Pipe()
 
-	go subscribeLogs(p.Context(), w, name, opts)
+	go subscribeLogs(p.Context(), p.logs, w, name, q, opts)
 
 	return r, nil
 }
@@ -59,27 +72,93 @@ func (p *Provider) ProcessLogs(app, pid string, opts structs.LogsOptions) (io.Re
 		return nil, err
 	}
 
+	q, err := logsQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	stream := fmt.Sprintf("%s/service/%s/%s", app, ps.Name, pid)
 
 	r, w := io.Pipe()
 
 	ctx, cancel := context.WithCancel(p.Context())
 
-	go subscribeLogs(ctx, w, stream, opts)
+	go subscribeLogs(ctx, p.logs, w, stream, q, opts)
 	go p.watchForProcessTermination(ctx, app, pid, cancel)
 
 	return r, nil
 }
 
 func (p *Provider) SystemLogs(opts structs.LogsOptions) (io.ReadCloser, error) {
+	q, err := logsQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+
 	r, w := io.Pipe()
 
-	go subscribeLogs(p.Context(), w, "system", opts)
+	go subscribeLogs(p.Context(), p.logs, w, "system", q, opts)
 
 	return r, nil
 }
 
-func subscribeLogs(ctx context.Context, w io.WriteCloser, stream string, opts structs.LogsOptions) {
+// newLogStore keeps logs on the rack storage volume so history survives api
+// restarts, falling back to memory when the volume is not usable.
+func newLogStore(storage string) *logstorage.Store {
+	opts := logstorage.DiskOptions{
+		MaxBytes:  logSizeLimit,
+		Retention: logRetention,
+	}
+
+	if v := os.Getenv("LOG_RETENTION"); v != "" {
+		if d, err := common.ParseDuration(v); err == nil {
+			opts.Retention = d
+		} else {
+			fmt.Printf("ns=provider.local at=logs error=%q\n", err)
+		}
+	}
+
+	if v := os.Getenv("LOG_SIZE_LIMIT"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			opts.MaxBytes = n
+		} else {
+			fmt.Printf("ns=provider.local at=logs error=%q\n", err)
+		}
+	}
+
+	d, err := logstorage.NewDisk(filepath.Join(storage, "logs"), opts)
+	if err != nil {
+		fmt.Printf("ns=provider.local at=logs backend=memory error=%q\n", err)
+		return logstorage.New()
+	}
+
+	return logstorage.NewWithBackend(d)
+}
+
+// logsQuery selects the logs for a logs request. A filter wrapped in slashes
+// is a regular expression, any other filter is matched as a substring.
+func logsQuery(opts structs.LogsOptions) (logstorage.Query, error) {
+	q := logstorage.Query{
+		Start: time.Now().UTC().Add(-1 * common.DefaultDuration(opts.Since, 0)),
+	}
+
+	f := common.DefaultString(opts.Filter, "")
+
+	if len(f) > 2 && strings.HasPrefix(f, "/") && strings.HasSuffix(f, "/") {
+		re, err := regexp.Compile(f[1 : len(f)-1])
+		if err != nil {
+			return q, structs.ErrBadRequest("invalid filter: %s", err)
+		}
+
+		q.Pattern = re
+	} else {
+		q.Match = f
+	}
+
+	return q, nil
+}
+
+func subscribeLogs(ctx context.Context, logs *logstorage.Store, w io.WriteCloser, stream string, q logstorage.Query, opts structs.LogsOptions) {
 	defer w.Close()
 
 	ch := make(chan logstorage.Log, 1000)
@@ -87,10 +166,7 @@ func subscribeLogs(ctx context.Context, w io.WriteCloser, stream string, opts st
 	sctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
-	since := time.Now().UTC().Add(-1 * common.DefaultDuration(opts.Since, 0))
-	follow := common.DefaultBool(opts.Follow, true)
-
-	logs.Subscribe(sctx, ch, stream, since, follow)
+	logs.SubscribeQuery(sctx, ch, stream, q, common.DefaultBool(opts.Follow, true))
 
 	for {
 		select {
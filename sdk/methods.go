@@ -13,6 +13,55 @@ import (
 	"github.com/convox/stdsdk"
 )
 
+func (c *Client) AccessPolicyDelete(id string) error {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	err = c.Delete(fmt.Sprintf("/system/access/policies/%s", id), ro, nil)
+
+	return err
+}
+
+func (c *Client) AccessPolicyGet(id string) (*structs.AccessPolicy, error) {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	var v *structs.AccessPolicy
+
+	err = c.Get(fmt.Sprintf("/system/access/policies/%s", id), ro, &v)
+
+	return v, err
+}
+
+func (c *Client) AccessPolicyList() (structs.AccessPolicies, error) {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	var v structs.AccessPolicies
+
+	err = c.Get("/system/access/policies", ro, &v)
+
+	return v, err
+}
+
+func (c *Client) AccessPolicySet(id string, opts structs.AccessPolicyOptions) (*structs.AccessPolicy, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v *structs.AccessPolicy
+
+	err = c.Put(fmt.Sprintf("/system/access/policies/%s", id), ro, &v)
+
+	return v, err
+}
+
 func (c *Client) AppCancel(name string) error {
 	var err error
 
@@ -97,6 +146,18 @@ func (c *Client) AppDiagnose(app string, opts structs.AppDiagnoseOptions) (*stru
 	return v, err
 }
 
+func (c *Client) AppDrift(name string) (structs.Drifts, error) {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	var v structs.Drifts
+
+	err = c.Get(fmt.Sprintf("/apps/%s/drift", name), ro, &v)
+
+	return v, err
+}
+
 func (c *Client) AppGet(name string) (*structs.App, error) {
 	var err error
 
@@ -186,6 +247,23 @@ func (c *Client) AppMetrics(name string, opts structs.MetricsOptions) (structs.M
 	return v, err
 }
 
+func (c *Client) AppPreviewCreate(base, name string, opts structs.AppPreviewCreateOptions) (*structs.App, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ro.Params["name"] = name
+
+	var v *structs.App
+
+	err = c.Post(fmt.Sprintf("/apps/%s/previews", base), ro, &v)
+
+	return v, err
+}
+
 func (c *Client) AppUpdate(name string, opts structs.AppUpdateOptions) error {
 	var err error
 
@@ -293,6 +371,27 @@ func (c *Client) AppCost(app string) (*structs.AppCost, error) {
 	return v, nil
 }
 
+func (c *Client) AuditList(opts structs.AuditListOptions) (structs.AuditEntries, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v structs.AuditEntries
+
+	err = c.Get("/system/audit", ro, &v)
+
+	return v, err
+}
+
+// skipcq
+func (*Client) AuditRecord(e structs.AuditEntry) error {
+	err := fmt.Errorf("not available via api")
+	return err
+}
+
 func (c *Client) BalancerList(app string) (structs.Balancers, error) {
 	var err error
 
@@ -906,6 +1005,21 @@ func (c *Client) RegistryRemove(server string) error {
 	return err
 }
 
+func (c *Client) ReleaseApprove(app, id string, opts structs.ReleaseApproveOptions) (*structs.Release, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v *structs.Release
+
+	err = c.Post(fmt.Sprintf("/apps/%s/releases/%s/approvals", app, id), ro, &v)
+
+	return v, err
+}
+
 func (c *Client) ReleaseCreate(app string, opts structs.ReleaseCreateOptions) (*structs.Release, error) {
 	var err error
 
@@ -948,6 +1062,16 @@ func (c *Client) ReleaseList(app string, opts structs.ReleaseListOptions) (struc
 	return v, err
 }
 
+func (c *Client) ReleasePause(app string) error {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	err = c.Post(fmt.Sprintf("/apps/%s/releases/pause", app), ro, nil)
+
+	return err
+}
+
 func (c *Client) ReleasePromote(app, id string, opts structs.ReleasePromoteOptions) error {
 	var err error
 
@@ -961,6 +1085,50 @@ func (c *Client) ReleasePromote(app, id string, opts structs.ReleasePromoteOptio
 	return err
 }
 
+func (c *Client) ReleaseResume(app string) error {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	err = c.Post(fmt.Sprintf("/apps/%s/releases/resume", app), ro, nil)
+
+	return err
+}
+
+func (c *Client) ReleaseTimeline(app, id string) (*structs.ReleaseTimeline, error) {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	var v *structs.ReleaseTimeline
+
+	err = c.Get(fmt.Sprintf("/apps/%s/releases/%s/timeline", app, id), ro, &v)
+
+	return v, err
+}
+
+func (c *Client) ResourceBackupList(app, name string) (structs.ResourceBackups, error) {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	var v structs.ResourceBackups
+
+	err = c.Get(fmt.Sprintf("/apps/%s/resources/%s/backups", app, name), ro, &v)
+
+	return v, err
+}
+
+func (c *Client) ResourceBackupRestore(app, name, id string) error {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	err = c.Post(fmt.Sprintf("/apps/%s/resources/%s/backups/%s/restore", app, name, id), ro, nil)
+
+	return err
+}
+
 func (c *Client) ResourceConsole(app, name string, rw io.ReadWriter, opts structs.ResourceConsoleOptions) error {
 	var err error
 
@@ -1338,6 +1506,49 @@ func (c *Client) SystemReleases() (structs.Releases, error) {
 	return v, err
 }
 
+func (c *Client) SystemTokenCreate(opts structs.SystemTokenCreateOptions) (*structs.SystemToken, error) {
+	var err error
+
+	ro, err := stdsdk.MarshalOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var v *structs.SystemToken
+
+	err = c.Post("/system/tokens", ro, &v)
+
+	return v, err
+}
+
+func (c *Client) SystemTokenList() (structs.SystemTokens, error) {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	var v structs.SystemTokens
+
+	err = c.Get("/system/tokens", ro, &v)
+
+	return v, err
+}
+
+func (c *Client) SystemTokenRevoke(id string) error {
+	var err error
+
+	ro := stdsdk.RequestOptions{Headers: stdsdk.Headers{}, Params: stdsdk.Params{}, Query: stdsdk.Query{}}
+
+	err = c.Delete(fmt.Sprintf("/system/tokens/%s", id), ro, nil)
+
+	return err
+}
+
+// skipcq
+func (*Client) SystemTokenUsed(id string) error {
+	err := fmt.Errorf("not available via api")
+	return err
+}
+
 func (c *Client) SystemResourceCreate(kind string, opts structs.ResourceCreateOptions) (*structs.Resource, error) {
 	var err error
 
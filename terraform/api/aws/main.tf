@@ -67,6 +67,7 @@ module "k8s" {
     RELEASES_TO_RETAIN_AFTER_ACTIVE           = var.releases_to_retain_after_active
     RELEASES_TO_RETAIN_TASK_RUN_INTERVAL_HOUR = var.releases_to_retain_task_run_interval_hour
     RELEASE_WATCHER_GC_INTERVAL               = var.release_watcher_gc_interval
+    SECRET_STORES                             = var.secret_stores
     GPU_METRICS_MAX_PODS                      = var.gpu_metrics_max_pods
     GPU_METRICS_MAX_CONCURRENT                = var.gpu_metrics_max_concurrent
     KARPENTER_ENABLED                         = var.karpenter_enabled
@@ -176,6 +176,12 @@ variable "release_watcher_gc_interval" {
   description = "Release-watcher GC sweep interval (e.g. 5m, 30m). Range 60s-1h enforced by pkg/cli/rack.go validator. Becomes RELEASE_WATCHER_GC_INTERVAL env var on the api Deployment via the env map at api/aws/main.tf."
 }
 
+variable "secret_stores" {
+  type        = string
+  default     = ""
+  description = "Comma separated external secret stores the api may read (aws-sm, file, sops, vault). Becomes SECRET_STORES env var on the api Deployment via the env map at api/aws/main.tf."
+}
+
 variable "gpu_metrics_max_pods" {
   type        = string
   default     = "100"
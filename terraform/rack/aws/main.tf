@@ -63,6 +63,7 @@ module "api" {
   releases_to_retain_after_active           = var.releases_to_retain_after_active
   releases_to_retain_task_run_interval_hour = var.releases_to_retain_task_run_interval_hour
   release_watcher_gc_interval               = var.release_watcher_gc_interval
+  secret_stores                             = var.secret_stores
   gpu_metrics_max_pods                      = var.gpu_metrics_max_pods
   gpu_metrics_max_concurrent                = var.gpu_metrics_max_concurrent
   subnets                                   = var.subnets
@@ -204,6 +204,12 @@ variable "release_watcher_gc_interval" {
   description = "Release-watcher GC sweep interval (e.g. 5m, 30m). Range 60s-1h enforced by pkg/cli/rack.go validator. Plumbed into the api Deployment as RELEASE_WATCHER_GC_INTERVAL env var."
 }
 
+variable "secret_stores" {
+  type        = string
+  default     = ""
+  description = "Comma separated external secret stores the api may read (aws-sm, file, sops, vault). Plumbed into the api Deployment as SECRET_STORES env var."
+}
+
 variable "gpu_metrics_max_pods" {
   type        = string
   default     = "100"
@@ -121,6 +121,7 @@ locals {
     releases_to_retain_after_active = var.releases_to_retain_after_active
     releases_to_retain_task_run_interval_hour = var.releases_to_retain_task_run_interval_hour
     schedule_rack_scale_down = var.schedule_rack_scale_down
+    secret_stores = var.secret_stores
     schedule_rack_scale_up = var.schedule_rack_scale_up
     settings = var.settings
     ssl_ciphers = var.ssl_ciphers
@@ -257,6 +258,7 @@ locals {
     releases_to_retain_after_active = "0"
     releases_to_retain_task_run_interval_hour = "24"
     schedule_rack_scale_down = ""
+    secret_stores = ""
     schedule_rack_scale_up = ""
     settings = ""
     ssl_ciphers = ""
@@ -584,6 +584,15 @@ variable "release_watcher_gc_interval" {
   description = "Release-watcher GC sweep interval (e.g. 5m, 30m). Range 60s-1h; empty defaults to 5m. Read by the provider at Initialize via RELEASE_WATCHER_GC_INTERVAL env on the api Deployment."
 }
 
+# External secret stores the api reads env secret references from. Each
+# store is off unless listed here; empty turns them all off. Read once at
+# provider Initialize from the SECRET_STORES env var on the api Deployment.
+variable "secret_stores" {
+  type        = string
+  default     = ""
+  description = "Comma separated external secret stores the api may read (aws-sm, file, sops, vault). Empty turns them all off. Read by the provider at Initialize via SECRET_STORES env on the api Deployment."
+}
+
 # Grafana deep-link template variable name overrides. Operators with imported
 # dashboards using non-default var names (e.g. `var-cluster_name` instead of
 # `var-rack`) configure the substitutions here. Console reads from the rack